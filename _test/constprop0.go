@@ -0,0 +1,34 @@
+package main
+
+import "fmt"
+
+func div(a, b int) int { return a / b }
+
+func main() {
+	debug := false
+	n := 5
+	s := ""
+	for i := 0; i < n; i++ {
+		if debug && i > 2 {
+			fmt.Println("debug", i)
+		}
+		s += "x"
+	}
+	m := 3
+	p := &m
+	*p = 4
+	k := 1
+	f := func() { k++ }
+	f()
+	t := "a"
+	t += "b"
+	fmt.Println(s, m, k, t, !debug || n > 10)
+
+	defer func() { fmt.Println("recovered:", recover()) }()
+	z := 0
+	fmt.Println(div(n, z))
+}
+
+// Output:
+// xxxxx 4 2 ab true
+// recovered: runtime error: integer divide by zero
@@ -0,0 +1,26 @@
+package main
+
+import "fmt"
+
+func f() int {
+	a := uint(70)
+	return 1 << a
+}
+
+func g() int64 {
+	var a uint = 70
+	var b int64 = 1 << a
+	return b
+}
+
+func h() int {
+	x := 1 << 40
+	return x * x
+}
+
+func main() {
+	fmt.Println(f(), g(), h())
+}
+
+// Output:
+// 0 0 0
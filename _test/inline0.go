@@ -0,0 +1,45 @@
+package main
+
+import "fmt"
+
+type point struct{ x, y int }
+
+func (p point) X() int { return p.x }
+
+func (p *point) Y() int { return p.y }
+
+func (p *point) sum() int { return p.x + p.y }
+
+func sq(a int) int { return a * a }
+
+func max3(a, b, c int) bool { return a > b && a > c }
+
+func scale(f float64) float64 { return 2 * f }
+
+func mix(p point, k int) int { return p.x*k - p.y }
+
+func neg(b bool) bool { return !b }
+
+func main() {
+	p := point{3, 4}
+	q := &point{5, 6}
+	s := 0
+	for i := 0; i < 5; i++ {
+		s += sq(i) + sq(sq(2))
+	}
+	fmt.Println(s, sq(7), p.X(), q.X(), p.Y(), q.Y(), q.sum(), p.sum())
+	fmt.Println(max3(3, 1, 2), max3(p.x, q.x, 0), scale(1.25), mix(p, 10), mix(*q, sq(2)))
+	if neg(p.x > 2) {
+		fmt.Println("bad")
+	} else {
+		fmt.Println("ok")
+	}
+	f := func(n int) int { return sq(n) + 1 }
+	fmt.Println(f(3))
+}
+
+// Output:
+// 110 49 3 5 4 6 11 7
+// true false 2.5 26 14
+// ok
+// 10
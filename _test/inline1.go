@@ -0,0 +1,22 @@
+package main
+
+import "fmt"
+
+func sh(a uint) int { return 1 << a }
+
+func shr(a uint) int64 { return 1 << (a + 1) >> 1 }
+
+func mul(a, b int) int { return a * b }
+
+func add8(a uint8) uint8 { return a + 100 }
+
+func main() {
+	fmt.Println(sh(70), sh(3), shr(63), shr(3))
+	fmt.Println(mul(1<<40, 1<<40), mul(3, 4))
+	fmt.Println(add8(200))
+}
+
+// Output:
+// 0 8 0 8
+// 0 12
+// 44
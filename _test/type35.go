@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+)
+
+type Config struct {
+	Name string
+	Port int
+}
+
+type Server struct {
+	Conf  Config
+	Peers []*Config
+}
+
+func main() {
+	c := Config{"a", 80}
+	s := Server{Conf: c, Peers: []*Config{&c}}
+	t := reflect.TypeOf(c)
+
+	fmt.Printf("%T %T %v\n", c, &c, c)
+	fmt.Printf("%T %+v\n", s, s.Conf)
+	fmt.Println(t.Name(), t.PkgPath(), t.String(), reflect.TypeOf(s.Peers))
+}
+
+// Output:
+// main.Config *main.Config {a 80}
+// main.Server {Name:a Port:80}
+// Config main main.Config []*main.Config
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type Celsius float64
+
+func (c Celsius) Fahrenheit() float64 { return float64(c)*9/5 + 32 }
+
+type Temps []Celsius
+
+func (t Temps) Len() int           { return len(t) }
+func (t Temps) Less(i, j int) bool { return t[i] < t[j] }
+func (t Temps) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
+
+type (
+	Name   string
+	Grid   [2][2]int
+	Index  map[Name]int
+	Queue  chan Celsius
+	Ref    *Celsius
+	Flag   bool
+	Handle func(Name) int
+)
+
+const freezing Celsius = 0
+
+func main() {
+	c := Celsius(21.5) + 1
+	t := Temps{c, freezing, -3}
+	sort.Sort(t)
+	t = append(t, 100)
+	var n Name = "x"
+	n += "y"
+	g := Grid{{1, 2}, {3, 4}}
+	m := Index{n: len(t)}
+	q := make(Queue, 1)
+	q <- c
+	r := Ref(&c)
+	f := Flag(c > freezing)
+	h := Handle(func(n Name) int { return len(n) })
+
+	fmt.Printf("%T %T %T %T %T %T %T %T %T\n", c, t, n, g, m, q, r, f, h)
+	fmt.Println(c, t, strings.ToUpper(string(n)), g[1][0], m["xy"], <-q, *r, f, h(n), c.Fahrenheit())
+	fmt.Println(reflect.TypeOf(t).Elem(), reflect.TypeOf(r).Elem(), reflect.TypeOf(t).Kind(), float64(c)*2)
+}
+
+// Output:
+// main.Celsius main.Temps main.Name main.Grid map[main.Name]int main.Queue main.Ref main.Flag func(main.Name) int
+// 22.5 [-3 0 22.5 100] XY 3 4 22.5 22.5 true 2 72.5
+// main.Celsius main.Celsius slice 45
@@ -13,4 +13,4 @@ func main() {
 }
 
 // Output:
-// int
+// main.T
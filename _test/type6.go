@@ -13,4 +13,4 @@ func main() {
 type T int
 
 // Output:
-// int
+// main.T
@@ -0,0 +1,45 @@
+package main
+
+import "fmt"
+
+func sum(n int) (s int) {
+	for i := 0; i < n; i++ {
+		var odd bool
+		if i%2 == 1 {
+			odd = true
+		}
+		x := i * 2
+		if odd {
+			x++
+		}
+		s += x
+	}
+	return s
+}
+
+func wrap(a int8, b uint8, c float32) (int8, uint8, float32) {
+	for i := 0; i < 3; i++ {
+		a += 100
+		b -= 100
+		c = c*c + 0.1
+	}
+	return a, b, c
+}
+
+func ptr(n int) *int {
+	x := n
+	x--
+	return &x
+}
+
+func main() {
+	fmt.Println(sum(10))
+	fmt.Println(wrap(1, 2, 3))
+	p, q := ptr(3), ptr(5)
+	fmt.Println(*p, *q)
+}
+
+// Output:
+// 95
+// 45 214 6874.169
+// 2 4
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+func f(s string) int {
+	x, err := strconv.Atoi(s)
+	if err != nil {
+		return -1
+	}
+	y := x + 1
+	return y
+}
+
+func main() {
+	fmt.Println(f("5"), f("a"))
+}
+
+// Output:
+// 6 -1
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+func f(a []string) int {
+	var x, s int
+	var err error
+	for _, v := range a {
+		x, err = strconv.Atoi(v)
+		if err != nil {
+			continue
+		}
+		s += x * 2
+	}
+	return s
+}
+
+func main() {
+	fmt.Println(f([]string{"1", "b", "3"}))
+}
+
+// Output:
+// 8
@@ -0,0 +1,26 @@
+package main
+
+import "fmt"
+
+func divmod(a, b int) (int, int) {
+	if b == 0 {
+		return 0, 0
+	}
+	return a / b, a % b
+}
+
+func f(n int) int {
+	s := 0
+	for i := 1; i < n; i++ {
+		q, r := divmod(n, i)
+		s += q + r
+	}
+	return s
+}
+
+func main() {
+	fmt.Println(f(10))
+}
+
+// Output:
+// 39
@@ -0,0 +1,27 @@
+package main
+
+import "fmt"
+
+func f(c chan int) int {
+	s := 0
+	for {
+		v, ok := <-c
+		if !ok {
+			break
+		}
+		s += v * 2
+	}
+	return s
+}
+
+func main() {
+	c := make(chan int, 3)
+	c <- 1
+	c <- 2
+	c <- 3
+	close(c)
+	fmt.Println(f(c))
+}
+
+// Output:
+// 12
@@ -0,0 +1,22 @@
+package main
+
+import "fmt"
+
+func f(a []interface{}) int {
+	s := 0
+	for _, i := range a {
+		v, ok := i.(int)
+		if !ok {
+			continue
+		}
+		s += v + 1
+	}
+	return s
+}
+
+func main() {
+	fmt.Println(f([]interface{}{1, "a", 3}))
+}
+
+// Output:
+// 6
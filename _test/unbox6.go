@@ -0,0 +1,27 @@
+package main
+
+import "fmt"
+
+func f(m map[string]int, keys []string) int {
+	s := 0
+	for _, k := range keys {
+		v, ok := m[k]
+		if !ok {
+			continue
+		}
+		s += v * 3
+	}
+	var found bool
+	_, found = m["z"]
+	if !found {
+		s++
+	}
+	return s
+}
+
+func main() {
+	fmt.Println(f(map[string]int{"a": 1, "b": 2}, []string{"a", "c", "b"}))
+}
+
+// Output:
+// 10
@@ -0,0 +1,400 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/build"
+	"go/format"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/traefik/yaegi/interp"
+	"github.com/traefik/yaegi/stdlib"
+	"github.com/traefik/yaegi/stdlib/syscall"
+	"github.com/traefik/yaegi/stdlib/unrestricted"
+	"github.com/traefik/yaegi/stdlib/unsafe"
+)
+
+// bundleDir is the directory of the generated program holding the bundled sources.
+const bundleDir = "bundle"
+
+// Directories of the bundle holding the source files in GOPATH, and the other
+// source files, relative to their closest common directory.
+const (
+	gopathDir = "gopath"
+	srcDir    = "src"
+)
+
+func buildCmd(arg []string) error {
+	var noAutoImport bool
+	var tags, out string
+
+	// The following flags are initialized from environment and configuration file.
+	useSyscall, useUnrestricted, useUnsafe := symbolChoices()
+
+	bflag := flag.NewFlagSet("build", flag.ContinueOnError)
+	bflag.StringVar(&out, "o", "", "set the output directory of the generated program, the base name of path by default")
+	bflag.BoolVar(&useSyscall, "syscall", useSyscall, "include syscall symbols")
+	bflag.BoolVar(&useUnrestricted, "unrestricted", useUnrestricted, "include unrestricted symbols")
+	bflag.StringVar(&tags, "tags", defaultTags(), "set a list of build tags")
+	bflag.BoolVar(&useUnsafe, "unsafe", useUnsafe, "include unsafe symbols")
+	bflag.BoolVar(&noAutoImport, "noautoimport", false, "do not auto import pre-compiled packages in scripts")
+	bflag.Usage = func() {
+		fmt.Println("Usage: yaegi build [options] path")
+		fmt.Println("Generate a Go program embedding the script or package at path, and the")
+		fmt.Println("source packages it imports, and running them with the interpreter.")
+		fmt.Println("The program is then built with: go mod tidy && go build")
+		fmt.Println("Options:")
+		bflag.PrintDefaults()
+	}
+	if err := bflag.Parse(arg); err != nil {
+		return err
+	}
+	args := bflag.Args()
+	if len(args) != 1 {
+		bflag.Usage()
+		return fmt.Errorf("one path expected")
+	}
+	path := args[0]
+	if out == "" {
+		out = strings.TrimSuffix(filepath.Base(path), ".go")
+		if out == "." || out == string(filepath.Separator) {
+			out = "main"
+		}
+	}
+
+	// Compile the program, recording the source files read by the interpreter.
+	env := environ(useSyscall, useUnrestricted, useUnsafe)
+	rfs := &recordFS{files: map[string]bool{}}
+	i := interp.New(interp.Options{
+		GoPath:               build.Default.GOPATH,
+		BuildTags:            strings.Split(tags, ","),
+		Env:                  env,
+		Unrestricted:         useUnrestricted,
+		SourcecodeFilesystem: rfs,
+	})
+	if err := i.Use(stdlib.Symbols); err != nil {
+		return err
+	}
+	if err := i.Use(interp.Symbols); err != nil {
+		return err
+	}
+	if useSyscall {
+		if err := i.Use(syscall.Symbols); err != nil {
+			return err
+		}
+	}
+	if useUnsafe {
+		if err := i.Use(unsafe.Symbols); err != nil {
+			return err
+		}
+	}
+	if useUnrestricted {
+		if err := i.Use(unrestricted.Symbols); err != nil {
+			return err
+		}
+	}
+	if isScript(path) && !noAutoImport {
+		i.ImportUsed()
+	}
+
+	// The program is only compiled, not run: neither package initializations
+	// nor main are executed at build time.
+	if err := i.Check(path); err != nil {
+		return err
+	}
+
+	p := bundle{
+		Entry:        path,
+		GoPath:       "/" + gopathDir,
+		Tags:         tags,
+		Env:          configEnv(useSyscall, useUnrestricted, useUnsafe),
+		Script:       isScript(path),
+		AutoImport:   !noAutoImport,
+		Syscall:      useSyscall,
+		Unsafe:       useUnsafe,
+		Unrestricted: useUnrestricted,
+	}
+	files, err := p.locate(rfs.list(), build.Default.GOPATH)
+	if err != nil {
+		return err
+	}
+	if err := p.write(out, files); err != nil {
+		return err
+	}
+	fmt.Printf("%s written, build it with: cd %s && go mod tidy && go build\n", out, out)
+	return nil
+}
+
+// recordFS is a filesystem of the host, recording the regular files opened.
+type recordFS struct {
+	files map[string]bool
+}
+
+func (r *recordFS) Open(name string) (fs.File, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if fi, err := f.Stat(); err == nil && fi.Mode().IsRegular() {
+		r.files[name] = true
+	}
+	return f, nil
+}
+
+// list returns the absolute paths of the files opened, sorted.
+func (r *recordFS) list() []string {
+	var l []string
+	seen := map[string]bool{}
+	for name := range r.files {
+		if p, err := filepath.Abs(name); err == nil && !seen[p] {
+			seen[p] = true
+			l = append(l, p)
+		}
+	}
+	sort.Strings(l)
+	return l
+}
+
+// bundle describes the program generated by yaegi build. The paths are the
+// ones of the bundle, which does not depend on the location of the sources
+// at build time.
+type bundle struct {
+	Entry        string // script or package path, or import path
+	GoPath       string
+	Tags         string
+	Env          []string // added to the environment of the process
+	Script       bool
+	AutoImport   bool
+	Syscall      bool
+	Unsafe       bool
+	Unrestricted bool
+}
+
+// locate returns the paths in the bundle of the source files, indexed by
+// their absolute path, and sets the entry point accordingly. The files in
+// goPath are stored in gopathDir, and the other ones in srcDir, relative to
+// their closest common directory.
+func (p *bundle) locate(files []string, goPath string) (map[string]string, error) {
+	res := map[string]string{}
+	root := ""
+	for _, file := range files {
+		if rel, ok := relPath(goPath, file); ok {
+			res[file] = filepath.Join(gopathDir, rel)
+			continue
+		}
+		dir := filepath.Dir(file)
+		if root == "" {
+			root = dir
+			continue
+		}
+		for {
+			if _, ok := relPath(root, dir); ok {
+				break
+			}
+			parent := filepath.Dir(root)
+			if parent == root {
+				break
+			}
+			root = parent
+		}
+	}
+	for _, file := range files {
+		if res[file] == "" {
+			rel, _ := relPath(root, file)
+			res[file] = filepath.Join(srcDir, rel)
+		}
+	}
+
+	if _, err := os.Stat(p.Entry); err != nil {
+		// Import path of a package in GOPATH.
+		return res, nil
+	}
+	entry, err := filepath.Abs(p.Entry)
+	if err != nil {
+		return nil, err
+	}
+	if rel, ok := relPath(goPath, entry); ok {
+		p.Entry = "/" + filepath.ToSlash(filepath.Join(gopathDir, rel))
+	} else if rel, ok := relPath(root, entry); ok {
+		p.Entry = "/" + filepath.ToSlash(filepath.Join(srcDir, rel))
+	} else {
+		return nil, fmt.Errorf("%s: no source file found", p.Entry)
+	}
+	return res, nil
+}
+
+// relPath returns the path of file relative to dir, if located in dir.
+func relPath(dir, file string) (string, bool) {
+	if dir == "" {
+		return "", false
+	}
+	rel, err := filepath.Rel(dir, file)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+// write writes in dir the generated program, embedding the source files
+// at their path in the bundle, given by locate.
+func (p bundle) write(dir string, files map[string]string) error {
+	for file, path := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		name := filepath.Join(dir, bundleDir, path)
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(name, b, 0o644); err != nil {
+			return err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := bundleTemplate.Execute(&buf, p); err != nil {
+		return err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), src, 0o644); err != nil {
+		return err
+	}
+
+	gomod := "module " + filepath.Base(dir) + "\n\ngo 1.22\n"
+	if v := yaegiVersion(); v != "" {
+		gomod += "\nrequire github.com/traefik/yaegi " + v + "\n"
+	}
+	return os.WriteFile(filepath.Join(dir, "go.mod"), []byte(gomod), 0o644)
+}
+
+// yaegiVersion returns the module version of yaegi, or "" if unknown.
+func yaegiVersion() string {
+	if strings.HasPrefix(version, "v") {
+		return version
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok && bi.Main.Path == "github.com/traefik/yaegi" && strings.HasPrefix(bi.Main.Version, "v") && !strings.HasSuffix(bi.Main.Version, "+dirty") {
+		return bi.Main.Version
+	}
+	return ""
+}
+
+var bundleTemplate = template.Must(template.New("bundle").Parse(`// Code generated by "yaegi build"; DO NOT EDIT.
+
+package main
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/traefik/yaegi/interp"
+	"github.com/traefik/yaegi/stdlib"
+{{- if .Syscall}}
+	"github.com/traefik/yaegi/stdlib/syscall"
+{{- end}}
+{{- if .Unrestricted}}
+	"github.com/traefik/yaegi/stdlib/unrestricted"
+{{- end}}
+{{- if .Unsafe}}
+	"github.com/traefik/yaegi/stdlib/unsafe"
+{{- end}}
+)
+
+//go:embed all:bundle
+var bundle embed.FS
+
+const (
+	entry  = {{printf "%q" .Entry}}
+	goPath = {{printf "%q" .GoPath}}
+	tags   = {{printf "%q" .Tags}}
+)
+
+// env is added to the environment of the process.
+var env = {{printf "%#v" .Env}}
+
+// bundleFS serves the bundled source files by their path in the bundle,
+// relative to its root directory "/".
+type bundleFS struct{ fs.FS }
+
+func (b bundleFS) Open(name string) (fs.File, error) {
+	name = filepath.ToSlash(name[len(filepath.VolumeName(name)):])
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if name == "" {
+		name = "."
+	}
+	return b.FS.Open(name)
+}
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		if p, ok := err.(interp.Panic); ok {
+			fmt.Fprintln(os.Stderr, string(p.Stack))
+		}
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	src, err := fs.Sub(bundle, "bundle")
+	if err != nil {
+		return err
+	}
+	i := interp.New(interp.Options{
+		GoPath:               goPath,
+		BuildTags:            strings.Split(tags, ","),
+		Env:                  append(os.Environ(), env...),
+		Unrestricted:         {{.Unrestricted}},
+		SourcecodeFilesystem: bundleFS{src},
+	})
+	if err := i.Use(stdlib.Symbols); err != nil {
+		return err
+	}
+	if err := i.Use(interp.Symbols); err != nil {
+		return err
+	}
+{{- if .Syscall}}
+	if err := i.Use(syscall.Symbols); err != nil {
+		return err
+	}
+{{- end}}
+{{- if .Unsafe}}
+	if err := i.Use(unsafe.Symbols); err != nil {
+		return err
+	}
+{{- end}}
+{{- if .Unrestricted}}
+	if err := i.Use(unrestricted.Symbols); err != nil {
+		return err
+	}
+{{- end}}
+{{if .Script}}
+	b, err := fs.ReadFile(bundleFS{src}, entry)
+	if err != nil {
+		return err
+	}
+{{- if .AutoImport}}
+	i.ImportUsed()
+{{- end}}
+	_, err = i.Eval(strings.Replace(string(b), "#!", "//", 1))
+{{- else}}
+	_, err = i.EvalPath(entry)
+{{- end}}
+	return err
+}
+`))
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/build"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// configFile is the name of the project configuration file of the yaegi
+// command, searched in the working directory and its parents.
+const configFile = "yaegi.json"
+
+// config is the project configuration of the yaegi command. The flags and
+// the YAEGI_* environment variables take precedence over it.
+type config struct {
+	GoPath       string            `json:"gopath"`       // GOPATH, relative to the directory of the configuration file
+	Tags         []string          `json:"tags"`         // build tags
+	Syscall      bool              `json:"syscall"`      // include syscall symbols
+	Unsafe       bool              `json:"unsafe"`       // include unsafe symbols
+	Unrestricted bool              `json:"unrestricted"` // include unrestricted symbols
+	Env          map[string]string `json:"env"`          // environment variables of scripts
+	Use          []string          `json:"use"`          // symbol packages, as the -use flag of run, directories relative to the file
+	Limits       struct {
+		Timeout string `json:"timeout"` // maximum duration of a run, as in time.ParseDuration
+		Memory  string `json:"memory"`  // soft memory limit, in bytes or with a unit such as "512MiB"
+	} `json:"limits"`
+
+	file    string        // path of the configuration file, empty if none
+	timeout time.Duration // parsed Limits.Timeout
+	memory  int64         // parsed Limits.Memory
+}
+
+// cfg is the configuration of the yaegi command, see loadConfig.
+var cfg config
+
+// loadConfig loads the configuration file given by the YAEGI_CONFIG
+// environment variable, or else the first one found in the working directory
+// or its parents. No configuration is loaded if YAEGI_CONFIG is set to "off".
+func loadConfig() (config, error) {
+	var c config
+	file, ok := os.LookupEnv("YAEGI_CONFIG")
+	switch {
+	case file == "off":
+		return c, nil
+	case !ok || file == "":
+		if file = findConfig(); file == "" {
+			return c, nil
+		}
+	}
+
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return c, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&c); err != nil {
+		return c, fmt.Errorf("%s: %w", file, err)
+	}
+	c.file = file
+
+	if c.GoPath != "" && !filepath.IsAbs(c.GoPath) {
+		c.GoPath = filepath.Join(filepath.Dir(file), c.GoPath)
+	}
+	for i, u := range c.Use {
+		if strings.HasPrefix(u, ".") {
+			c.Use[i] = filepath.Join(filepath.Dir(file), u)
+		}
+	}
+	if c.Limits.Timeout != "" {
+		if c.timeout, err = time.ParseDuration(c.Limits.Timeout); err != nil {
+			return c, fmt.Errorf("%s: invalid timeout: %w", file, err)
+		}
+	}
+	if c.Limits.Memory != "" {
+		if c.memory, err = parseSize(c.Limits.Memory); err != nil {
+			return c, fmt.Errorf("%s: invalid memory limit: %w", file, err)
+		}
+	}
+	return c, nil
+}
+
+// findConfig returns the path of the configuration file in the working
+// directory or its closest parent, or "" if none is found.
+func findConfig() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for {
+		file := filepath.Join(dir, configFile)
+		if _, err := os.Stat(file); err == nil {
+			return file
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// parseSize returns the number of bytes of s, an integer followed by an
+// optional unit B, KiB, MiB, GiB or TiB, as in GOMEMLIMIT.
+func parseSize(s string) (int64, error) {
+	units := []struct {
+		suffix string
+		shift  uint
+	}{{"TiB", 40}, {"GiB", 30}, {"MiB", 20}, {"KiB", 10}, {"B", 0}}
+	var shift uint
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			s, shift = strings.TrimSuffix(s, u.suffix), u.shift
+			break
+		}
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || n < 0 || n > (1<<63-1)>>shift {
+		return 0, fmt.Errorf("%q is not a valid size", s)
+	}
+	return n << shift, nil
+}
+
+// apply applies the configuration to the default build context, used to
+// locate and select the source files of packages.
+func (c config) apply() {
+	if c.GoPath != "" {
+		build.Default.GOPATH = c.GoPath
+	}
+	build.Default.BuildTags = append(build.Default.BuildTags, c.Tags...)
+}
+
+// boolEnv returns the value of the boolean environment variable key if set,
+// or def otherwise.
+func boolEnv(key string, def bool) bool {
+	if v, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return v
+	}
+	return def
+}
+
+// symbolChoices returns whether to include the syscall, unrestricted and
+// unsafe symbols, from the environment or the configuration.
+func symbolChoices() (useSyscall, useUnrestricted, useUnsafe bool) {
+	return boolEnv("YAEGI_SYSCALL", cfg.Syscall), boolEnv("YAEGI_UNRESTRICTED", cfg.Unrestricted), boolEnv("YAEGI_UNSAFE", cfg.Unsafe)
+}
+
+// defaultTags returns the build tags of the configuration, in the form of the -tags flag.
+func defaultTags() string {
+	return strings.Join(cfg.Tags, ",")
+}
+
+// environ returns the environment of the interpreted programs: the one of
+// the process, with the variables of configEnv. Unrestricted programs access
+// the process environment instead, which is left unmodified.
+func environ(useSyscall, useUnrestricted, useUnsafe bool) []string {
+	return append(os.Environ(), configEnv(useSyscall, useUnrestricted, useUnsafe)...)
+}
+
+// configEnv returns the variables of the configuration, and the YAEGI_*
+// variables of the selected symbols, so that a nested yaegi command started
+// by a program includes the same symbols.
+func configEnv(useSyscall, useUnrestricted, useUnsafe bool) []string {
+	keys := make([]string, 0, len(cfg.Env))
+	for k := range cfg.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	env := make([]string, 0, len(keys)+3)
+	for _, k := range keys {
+		env = append(env, k+"="+cfg.Env[k])
+	}
+	if useSyscall {
+		env = append(env, "YAEGI_SYSCALL=1")
+	}
+	if useUnrestricted {
+		env = append(env, "YAEGI_UNRESTRICTED=1")
+	}
+	if useUnsafe {
+		env = append(env, "YAEGI_UNSAFE=1")
+	}
+	return env
+}
+
+// setMemoryLimit applies the memory limit of the configuration to the process.
+func setMemoryLimit() {
+	if cfg.memory > 0 {
+		debug.SetMemoryLimit(cfg.memory)
+	}
+}
+
+// setTimeout makes the process exit with an error when the timeout of the
+// configuration expires.
+func setTimeout(cmd string) {
+	if cfg.timeout > 0 {
+		time.AfterFunc(cfg.timeout, func() {
+			fmt.Fprintf(os.Stderr, "%s: timeout of %v exceeded (%s)\n", cmd, cfg.timeout, cfg.file)
+			os.Exit(1)
+		})
+	}
+}
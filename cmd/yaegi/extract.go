@@ -6,6 +6,7 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"go/build"
 	"io"
 	"os"
 	"path/filepath"
@@ -29,6 +30,8 @@ func extractCmd(arg []string) error {
 	eflag.StringVar(&tag, "tag", "", "comma separated list of build tags to be added to the created package")
 	eflag.Usage = func() {
 		fmt.Println("Usage: yaegi extract [options] packages...")
+		fmt.Println("The packages are located in the gopath and their files selected by the tags")
+		fmt.Println("of the configuration file, as for the other commands.")
 		fmt.Println("Options:")
 		eflag.PrintDefaults()
 	}
@@ -36,6 +39,8 @@ func extractCmd(arg []string) error {
 	if err := eflag.Parse(arg); err != nil {
 		return err
 	}
+	setMemoryLimit()
+	setTimeout(Extract)
 
 	args := eflag.Args()
 	if len(args) == 0 {
@@ -74,7 +79,11 @@ func extractCmd(arg []string) error {
 
 	for _, pkgIdent := range args {
 		var buf bytes.Buffer
-		importPath, err := ext.Extract(pkgIdent, name, &buf)
+		ident, importPath := pkgIdent, name
+		if dir, ok := gopathPackage(wd, pkgIdent); ok {
+			ident, importPath = dir, pkgIdent
+		}
+		importPath, err := ext.Extract(ident, importPath, &buf)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			continue
@@ -99,6 +108,27 @@ func extractCmd(arg []string) error {
 	return nil
 }
 
+// gopathPackage returns the directory of the package of import path
+// pkgIdent in the gopath of the configuration, relative to wd, so that it is
+// located as by the interpreter, whether modules are enabled or not.
+func gopathPackage(wd, pkgIdent string) (string, bool) {
+	if cfg.GoPath == "" || build.IsLocalImport(pkgIdent) || filepath.IsAbs(pkgIdent) {
+		return "", false
+	}
+	dir := filepath.Join(cfg.GoPath, "src", filepath.FromSlash(pkgIdent))
+	if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+		return "", false
+	}
+	rel, err := filepath.Rel(wd, dir)
+	if err != nil {
+		return "", false
+	}
+	if !build.IsLocalImport(filepath.ToSlash(rel)) {
+		rel = "." + string(filepath.Separator) + rel
+	}
+	return rel, true
+}
+
 // genLicense generates the correct LICENSE header text from the provided
 // path to a LICENSE file.
 func genLicense(fname string) (string, error) {
@@ -10,11 +10,14 @@ Usage:
 
 The commands are:
 
+    build       generate a Go program embedding a script and its source imports
     extract     generate a wrapper file from a source package
     help        print usage information
+    lsp         run a language server for editors
     run         execute a Go program from source
     test        execute test functions in a Go package
     version     print version
+    vet         check Go files or packages for compile errors, without running them
 
 Use "yaegi help <command>" for more information about a command.
 
@@ -29,11 +32,15 @@ func help(arg []string) error {
 	}
 
 	switch cmd {
+	case Build:
+		return buildCmd([]string{"-h"})
 	case Extract:
 		return extractCmd([]string{"-h"})
 	case Help, "", "-h", "--help":
 		fmt.Print(usage)
 		return nil
+	case LSP:
+		return lspCmd([]string{"-h"})
 	case Run:
 		return run([]string{"-h"})
 	case Test:
@@ -41,6 +48,8 @@ func help(arg []string) error {
 	case Version:
 		fmt.Println("Usage: yaegi version")
 		return nil
+	case Vet:
+		return vet([]string{"-h"})
 	default:
 		return fmt.Errorf("help: invalid yaegi command: %v", cmd)
 	}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/build"
+	"os"
+	"strings"
+
+	"github.com/traefik/yaegi/interp"
+	"github.com/traefik/yaegi/lsp"
+	"github.com/traefik/yaegi/stdlib"
+	"github.com/traefik/yaegi/stdlib/syscall"
+	"github.com/traefik/yaegi/stdlib/unrestricted"
+	"github.com/traefik/yaegi/stdlib/unsafe"
+)
+
+func lspCmd(arg []string) error {
+	var tags string
+
+	// The following flags are initialized from environment and configuration file.
+	useSyscall, useUnrestricted, useUnsafe := symbolChoices()
+
+	lflag := flag.NewFlagSet("lsp", flag.ContinueOnError)
+	lflag.BoolVar(&useSyscall, "syscall", useSyscall, "include syscall symbols")
+	lflag.BoolVar(&useUnrestricted, "unrestricted", useUnrestricted, "include unrestricted symbols")
+	lflag.StringVar(&tags, "tags", defaultTags(), "set a list of build tags")
+	lflag.BoolVar(&useUnsafe, "unsafe", useUnsafe, "include unsafe symbols")
+	lflag.Usage = func() {
+		fmt.Println("Usage: yaegi lsp [options]")
+		fmt.Println("Run a Language Server Protocol server on standard input and output,")
+		fmt.Println("providing diagnostics, hover, go to definition and completion for")
+		fmt.Println("interpreted Go code, including scripts.")
+		fmt.Println("Options:")
+		lflag.PrintDefaults()
+	}
+	if err := lflag.Parse(arg); err != nil {
+		return err
+	}
+
+	env := environ(useSyscall, useUnrestricted, useUnsafe)
+	exports := []interp.Exports{stdlib.Symbols, interp.Symbols}
+	if useSyscall {
+		exports = append(exports, syscall.Symbols)
+	}
+	if useUnsafe {
+		exports = append(exports, unsafe.Symbols)
+	}
+	if useUnrestricted {
+		exports = append(exports, unrestricted.Symbols)
+	}
+
+	s := &lsp.Server{
+		Options: interp.Options{
+			GoPath:       build.Default.GOPATH,
+			BuildTags:    strings.Split(tags, ","),
+			Env:          env,
+			Unrestricted: useUnrestricted,
+		},
+		Exports: exports,
+	}
+	return s.Serve(os.Stdin, os.Stdout)
+}
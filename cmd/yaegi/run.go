@@ -5,10 +5,11 @@ import (
 	"fmt"
 	"go/build"
 	"os"
+	"path/filepath"
 	"reflect"
-	"strconv"
 	"strings"
 
+	"github.com/traefik/yaegi/internal/editor"
 	"github.com/traefik/yaegi/interp"
 	"github.com/traefik/yaegi/stdlib"
 	"github.com/traefik/yaegi/stdlib/syscall"
@@ -21,21 +22,25 @@ func run(arg []string) error {
 	var noAutoImport bool
 	var tags string
 	var cmd string
+	var uses listFlag
 	var err error
 
-	// The following flags are initialized from environment.
-	useSyscall, _ := strconv.ParseBool(os.Getenv("YAEGI_SYSCALL"))
-	useUnrestricted, _ := strconv.ParseBool(os.Getenv("YAEGI_UNRESTRICTED"))
-	useUnsafe, _ := strconv.ParseBool(os.Getenv("YAEGI_UNSAFE"))
+	// The following flags are initialized from environment and configuration file.
+	useSyscall, useUnrestricted, useUnsafe := symbolChoices()
+	historyFile, ok := os.LookupEnv("YAEGI_HISTORY")
+	if home, err := os.UserHomeDir(); !ok && err == nil {
+		historyFile = filepath.Join(home, ".yaegi_history")
+	}
 
 	rflag := flag.NewFlagSet("run", flag.ContinueOnError)
 	rflag.BoolVar(&interactive, "i", false, "start an interactive REPL")
 	rflag.BoolVar(&useSyscall, "syscall", useSyscall, "include syscall symbols")
 	rflag.BoolVar(&useUnrestricted, "unrestricted", useUnrestricted, "include unrestricted symbols")
-	rflag.StringVar(&tags, "tags", "", "set a list of build tags")
+	rflag.StringVar(&tags, "tags", defaultTags(), "set a list of build tags")
 	rflag.BoolVar(&useUnsafe, "unsafe", useUnsafe, "include unsafe symbols")
 	rflag.BoolVar(&noAutoImport, "noautoimport", false, "do not auto import pre-compiled packages. Import names that would result in collisions (e.g. rand from crypto/rand and rand from math/rand) are automatically renamed (crypto_rand and math_rand)")
 	rflag.StringVar(&cmd, "e", "", "set the command to be executed (instead of script or/and shell)")
+	rflag.Var(&uses, "use", "use the symbols of a package generated by yaegi extract, given by import path or directory (repeatable)")
 	rflag.Usage = func() {
 		fmt.Println("Usage: yaegi run [options] [path] [args]")
 		fmt.Println("Options:")
@@ -46,11 +51,30 @@ func run(arg []string) error {
 	}
 	args := rflag.Args()
 
+	if uses = append(cfg.Use, uses...); len(uses) > 0 && !useBuilt {
+		// Run the command line in a yaegi command built with the symbols.
+		bin, err := useCommand(uses)
+		if err != nil {
+			return err
+		}
+		return runUse(bin, append(append([]string{Run}, withoutFlag(arg[:len(arg)-len(args)], "use")...), args...))
+	}
+	setMemoryLimit()
+	setTimeout(Run)
+
+	env := environ(useSyscall, useUnrestricted, useUnsafe)
 	i := interp.New(interp.Options{
 		GoPath:       build.Default.GOPATH,
 		BuildTags:    strings.Split(tags, ","),
-		Env:          os.Environ(),
+		Env:          env,
 		Unrestricted: useUnrestricted,
+		NewLineEditor: func(complete func(string) (string, []string)) interp.LineEditor {
+			// Avoid returning a nil *editor.Editor in a non nil interface.
+			if e := editor.New(os.Stdin, os.Stdout, historyFile, complete); e != nil {
+				return e
+			}
+			return nil
+		},
 	})
 	if err := i.Use(stdlib.Symbols); err != nil {
 		return err
@@ -58,12 +82,13 @@ func run(arg []string) error {
 	if err := i.Use(interp.Symbols); err != nil {
 		return err
 	}
-	if useSyscall {
-		if err := i.Use(syscall.Symbols); err != nil {
+	for _, exports := range useExports {
+		if err := i.Use(exports); err != nil {
 			return err
 		}
-		// Using a environment var allows a nested interpreter to import the syscall package.
-		if err := os.Setenv("YAEGI_SYSCALL", "1"); err != nil {
+	}
+	if useSyscall {
+		if err := i.Use(syscall.Symbols); err != nil {
 			return err
 		}
 	}
@@ -71,18 +96,12 @@ func run(arg []string) error {
 		if err := i.Use(unsafe.Symbols); err != nil {
 			return err
 		}
-		if err := os.Setenv("YAEGI_UNSAFE", "1"); err != nil {
-			return err
-		}
 	}
 	if useUnrestricted {
 		// Use of unrestricted symbols should always follow stdlib and syscall symbols, to update them.
 		if err := i.Use(unrestricted.Symbols); err != nil {
 			return err
 		}
-		if err := os.Setenv("YAEGI_UNRESTRICTED", "1"); err != nil {
-			return err
-		}
 	}
 
 	if cmd != "" {
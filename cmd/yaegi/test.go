@@ -8,7 +8,6 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
-	"strconv"
 	"strings"
 	"testing"
 
@@ -34,10 +33,8 @@ func test(arg []string) (err error) {
 		verbose   bool
 	)
 
-	// The following flags are initialized from environment.
-	useSyscall, _ := strconv.ParseBool(os.Getenv("YAEGI_SYSCALL"))
-	useUnrestricted, _ := strconv.ParseBool(os.Getenv("YAEGI_UNRESTRICTED"))
-	useUnsafe, _ := strconv.ParseBool(os.Getenv("YAEGI_UNSAFE"))
+	// The following flags are initialized from environment and configuration file.
+	useSyscall, useUnrestricted, useUnsafe := symbolChoices()
 
 	tflag := flag.NewFlagSet("test", flag.ContinueOnError)
 	tflag.StringVar(&bench, "bench", "", "Run only those benchmarks matching a regular expression.")
@@ -48,8 +45,8 @@ func test(arg []string) (err error) {
 	tflag.BoolVar(&failfast, "failfast", false, "Do not start new tests after the first test failure.")
 	tflag.StringVar(&run, "run", "", "Run only those tests matching a regular expression.")
 	tflag.BoolVar(&short, "short", false, "Tell long-running tests to shorten their run time.")
-	tflag.StringVar(&tags, "tags", "", "Set a list of build tags.")
-	tflag.StringVar(&timeout, "timeout", "", "If a test binary runs longer than duration d, panic.")
+	tflag.StringVar(&tags, "tags", defaultTags(), "Set a list of build tags.")
+	tflag.StringVar(&timeout, "timeout", cfg.Limits.Timeout, "If a test binary runs longer than duration d, panic.")
 	tflag.BoolVar(&useUnrestricted, "unrestricted", useUnrestricted, "Include unrestricted symbols.")
 	tflag.BoolVar(&useUnsafe, "unsafe", useUnsafe, "Include usafe symbols.")
 	tflag.BoolVar(&useSyscall, "syscall", useSyscall, "Include syscall symbols.")
@@ -116,10 +113,12 @@ func test(arg []string) (err error) {
 		return err
 	}
 
+	setMemoryLimit()
+	env := environ(useSyscall, useUnrestricted, useUnsafe)
 	i := interp.New(interp.Options{
 		GoPath:       build.Default.GOPATH,
 		BuildTags:    strings.Split(tags, ","),
-		Env:          os.Environ(),
+		Env:          env,
 		Unrestricted: useUnrestricted,
 	})
 	if err := i.Use(stdlib.Symbols); err != nil {
@@ -132,26 +131,16 @@ func test(arg []string) (err error) {
 		if err := i.Use(syscall.Symbols); err != nil {
 			return err
 		}
-		// Using a environment var allows a nested interpreter to import the syscall package.
-		if err := os.Setenv("YAEGI_SYSCALL", "1"); err != nil {
-			return err
-		}
 	}
 	if useUnrestricted {
 		if err := i.Use(unrestricted.Symbols); err != nil {
 			return err
 		}
-		if err := os.Setenv("YAEGI_UNRESTRICTED", "1"); err != nil {
-			return err
-		}
 	}
 	if useUnsafe {
 		if err := i.Use(unsafe.Symbols); err != nil {
 			return err
 		}
-		if err := os.Setenv("YAEGI_UNSAFE", "1"); err != nil {
-			return err
-		}
 	}
 	if err = i.EvalTest(path); err != nil {
 		return err
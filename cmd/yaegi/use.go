@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/traefik/yaegi/interp"
+)
+
+// cmdSources are the sources of the yaegi command, from which a custom
+// command including additional symbols is built, see useCommand.
+//
+//go:embed *.go
+var cmdSources embed.FS
+
+const yaegiModule = "github.com/traefik/yaegi"
+
+// useBuilt is true in a custom yaegi command built with the symbols
+// packages given by -use. The useExports are their symbols.
+var (
+	useBuilt   bool
+	useExports []interp.Exports
+)
+
+// listFlag is a flag which can be repeated, or set to a comma separated list of values.
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *listFlag) Set(s string) error {
+	for _, v := range strings.Split(s, ",") {
+		if v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
+
+// withoutFlag returns the flags in arg, without the flag name and its value.
+func withoutFlag(arg []string, name string) []string {
+	var res []string
+	for i := 0; i < len(arg); i++ {
+		a := strings.TrimPrefix(strings.TrimPrefix(arg[i], "-"), "-")
+		switch {
+		case a == name:
+			i++ // skip value
+		case strings.HasPrefix(a, name+"="):
+		default:
+			res = append(res, arg[i])
+		}
+	}
+	return res
+}
+
+// goModule is a module, as described by go list.
+type goModule struct {
+	Path    string
+	Version string
+	Dir     string
+	Replace *goModule
+}
+
+// goPackage is a package, as described by go list.
+type goPackage struct {
+	ImportPath string
+	Name       string
+	Module     *goModule
+	Error      *struct{ Err string }
+}
+
+// useCommand returns the path of a yaegi command including the symbols of
+// the packages uses, as generated by yaegi extract, building it if needed.
+// The packages are given by import path or directory, as to go list, and
+// must be part of a module.
+func useCommand(uses []string) (string, error) {
+	if _, err := exec.LookPath("go"); err != nil {
+		return "", fmt.Errorf("the go command is required to use symbol packages: %w", err)
+	}
+	pkgs, err := listPackages(uses)
+	if err != nil {
+		return "", err
+	}
+	mods := map[string]*goModule{}
+	var imports []string
+	for _, p := range pkgs {
+		if p.Error != nil {
+			return "", errors.New(p.Error.Err)
+		}
+		if p.Module == nil {
+			return "", fmt.Errorf("package %s is not part of a module", p.ImportPath)
+		}
+		if p.Name == "main" {
+			return "", fmt.Errorf("package %s is a command", p.ImportPath)
+		}
+		mods[p.Module.Path] = p.Module
+		imports = append(imports, p.ImportPath)
+	}
+	if mods[yaegiModule] == nil {
+		m, err := yaegiModuleInfo()
+		if err != nil {
+			return "", err
+		}
+		mods[yaegiModule] = m
+	}
+
+	// Generate the module of the custom command, named after its content.
+	files := map[string][]byte{"use.gen.go": useSource(imports), "go.mod": useGoMod(mods)}
+	err = fs.WalkDir(cmdSources, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || strings.HasSuffix(path, "_test.go") {
+			return err
+		}
+		files[path], err = cmdSources.ReadFile(path)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	h := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(h, "%s %d\n", name, len(files[name]))
+		h.Write(files[name])
+	}
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(cache, "yaegi", "use", hex.EncodeToString(h.Sum(nil))[:16])
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	for _, name := range names {
+		if name == "go.mod" {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				continue // keep the go.mod completed by go build
+			}
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), files[name], 0o644); err != nil {
+			return "", err
+		}
+	}
+
+	// Build the command, or reuse it from the go build cache.
+	bin := filepath.Join(dir, "yaegi")
+	if runtime.GOOS == "windows" {
+		bin += ".exe"
+	}
+	cmd := exec.Command("go", "build", "-mod=mod", "-o", bin, ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("building yaegi with %s: %w\n%s", strings.Join(uses, ", "), err, out)
+	}
+	return bin, nil
+}
+
+// listPackages returns the description by go list of the packages.
+func listPackages(patterns []string) ([]goPackage, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("go", append([]string{"list", "-e", "-json"}, patterns...)...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list: %w\n%s", err, &stderr)
+	}
+	var pkgs []goPackage
+	for dec := json.NewDecoder(bytes.NewReader(out)); ; {
+		var p goPackage
+		if err := dec.Decode(&p); errors.Is(err, io.EOF) {
+			return pkgs, nil
+		} else if err != nil {
+			return nil, err
+		}
+		pkgs = append(pkgs, p)
+	}
+}
+
+// yaegiModuleInfo returns the yaegi module to build a custom command: the one
+// required in the working directory, or else the version of this command.
+func yaegiModuleInfo() (*goModule, error) {
+	if out, err := exec.Command("go", "list", "-m", "-json", yaegiModule).Output(); err == nil {
+		var m goModule
+		if err := json.Unmarshal(out, &m); err == nil && m.Dir != "" {
+			return &m, nil
+		}
+	}
+	if v := yaegiVersion(); v != "" {
+		return &goModule{Path: yaegiModule, Version: v}, nil
+	}
+	return nil, fmt.Errorf("unknown version of %s: require it in the current module", yaegiModule)
+}
+
+// useSource returns the source registering the symbols of the imported packages.
+func useSource(imports []string) []byte {
+	var b bytes.Buffer
+	b.WriteString("// Code generated by \"yaegi run -use\"; DO NOT EDIT.\n\npackage main\n\nimport (\n")
+	for i, p := range imports {
+		fmt.Fprintf(&b, "\tuse%d %q\n", i, p)
+	}
+	b.WriteString(")\n\nfunc init() {\n\tuseBuilt = true\n")
+	for i := range imports {
+		fmt.Fprintf(&b, "\tuseExports = append(useExports, use%d.Symbols)\n", i)
+	}
+	b.WriteString("}\n")
+	return b.Bytes()
+}
+
+// useGoMod returns the go.mod file of the custom command, requiring the
+// modules. Modules without version, or replaced, are used from their directory.
+func useGoMod(mods map[string]*goModule) []byte {
+	paths := make([]string, 0, len(mods))
+	for p := range mods {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	var b bytes.Buffer
+	b.WriteString("module yaegi\n\ngo 1.22\n")
+	var replace []string
+	for _, p := range paths {
+		m := mods[p]
+		if m.Version != "" && m.Replace == nil {
+			fmt.Fprintf(&b, "\nrequire %s %s\n", p, m.Version)
+			continue
+		}
+		fmt.Fprintf(&b, "\nrequire %s v0.0.0-00010101000000-000000000000\n", p)
+		replace = append(replace, fmt.Sprintf("replace %s => %s\n", p, m.Dir))
+	}
+	if len(replace) > 0 {
+		b.WriteString("\n" + strings.Join(replace, ""))
+	}
+	return b.Bytes()
+}
+
+// runUse runs the command line arg in the custom yaegi command bin, and
+// exits with its status.
+func runUse(bin string, arg []string) error {
+	cmd := exec.Command(bin, arg...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+
+	// Interrupts are handled by the command.
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt)
+	defer signal.Stop(c)
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.ExitCode())
+	}
+	if err != nil {
+		return err
+	}
+	os.Exit(0)
+	return nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go/build"
+	"os"
+	"strings"
+
+	"github.com/traefik/yaegi/interp"
+	"github.com/traefik/yaegi/stdlib"
+	"github.com/traefik/yaegi/stdlib/syscall"
+	"github.com/traefik/yaegi/stdlib/unrestricted"
+	"github.com/traefik/yaegi/stdlib/unsafe"
+)
+
+func vet(arg []string) error {
+	var tags, goos, goarch, goVersion string
+
+	// The following flags are initialized from environment and configuration file.
+	useSyscall, useUnrestricted, useUnsafe := symbolChoices()
+
+	vflag := flag.NewFlagSet("vet", flag.ContinueOnError)
+	vflag.BoolVar(&useSyscall, "syscall", useSyscall, "include syscall symbols")
+	vflag.BoolVar(&useUnrestricted, "unrestricted", useUnrestricted, "include unrestricted symbols")
+	vflag.StringVar(&tags, "tags", defaultTags(), "set a list of build tags")
+	vflag.StringVar(&goos, "goos", "", "set the target operating system, instead of the host one")
+	vflag.StringVar(&goarch, "goarch", "", "set the target architecture, instead of the host one")
+	vflag.StringVar(&goVersion, "go", "", "set the Go language version, such as go1.21, instead of the toolchain one")
+	vflag.BoolVar(&useUnsafe, "unsafe", useUnsafe, "include unsafe symbols")
+	vflag.Usage = func() {
+		fmt.Println("Usage: yaegi vet [options] [path ...]")
+		fmt.Println("Check that Go files or packages compile, without running them.")
+		fmt.Println("Errors are printed on standard error, and the exit status is 1 if any.")
+		fmt.Println("Options:")
+		vflag.PrintDefaults()
+	}
+	if err := vflag.Parse(arg); err != nil {
+		return err
+	}
+	paths := vflag.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	env := environ(useSyscall, useUnrestricted, useUnsafe)
+	var count int
+	for _, path := range paths {
+		if path == "." {
+			// The current directory must be given in the relative import form.
+			path = "./"
+		}
+
+		// Each path is checked in a fresh interpreter, as it would be run.
+		i := interp.New(interp.Options{
+			GoPath:       build.Default.GOPATH,
+			BuildTags:    strings.Split(tags, ","),
+			GOOS:         goos,
+			GOARCH:       goarch,
+			GoVersion:    goVersion,
+			Env:          env,
+			Unrestricted: useUnrestricted,
+		})
+		if err := i.Use(stdlib.Symbols); err != nil {
+			return err
+		}
+		if err := i.Use(interp.Symbols); err != nil {
+			return err
+		}
+		if useSyscall {
+			if err := i.Use(syscall.Symbols); err != nil {
+				return err
+			}
+		}
+		if useUnsafe {
+			if err := i.Use(unsafe.Symbols); err != nil {
+				return err
+			}
+		}
+		if useUnrestricted {
+			if err := i.Use(unrestricted.Symbols); err != nil {
+				return err
+			}
+		}
+		if isScript(path) {
+			// Scripts are interpreted in REPL mode, with auto imports.
+			i.ImportUsed()
+		}
+
+		err := i.Check(path)
+		var diags interp.Diagnostics
+		if !errors.As(err, &diags) {
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		for _, d := range diags {
+			fmt.Fprintln(os.Stderr, d)
+		}
+		count += len(diags)
+	}
+
+	if count > 0 {
+		return fmt.Errorf("%d error(s) found", count)
+	}
+	return nil
+}
+
+// isScript returns true if path is an executable Go script file,
+// starting with "#!".
+func isScript(path string) bool {
+	if !isFile(path) {
+		return false
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	b := make([]byte, 2)
+	n, _ := f.Read(b)
+	return string(b[:n]) == "#!"
+}
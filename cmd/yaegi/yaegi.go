@@ -45,6 +45,28 @@ an explicit import is still necessary).
 Note that the source packages are always interpreted in file mode,
 even if imported from REPL.
 
+When the input and output are a terminal, the REPL provides line
+edition with the usual Emacs key bindings, a history of the lines
+entered, browsed with the up and down arrows and kept across sessions,
+and completion with the tab key of package names, symbols in scope,
+and package members, fields and methods in selector expressions.
+
+The result of each expression is printed with its type, pretty-printed
+with field names for composite values, or as the signature of functions.
+
+Lines starting with ':' are REPL commands, to inspect the session
+(:type, :doc, :vars, :imports), to print results in Go syntax
+(:format go) or to manage the session (:load, :reset, :save to write
+the declarations entered so far to a Go file). The list of commands
+is given by :help.
+
+The build command generates a Go program embedding a script or package
+and the source packages it imports, with the same choice of symbols, to be
+built as a single binary which does not need the sources at run time:
+
+	$ yaegi build -unrestricted -o hello ./hello.go
+	$ cd hello && go mod tidy && go build
+
 The following extract is a valid executable script:
 
 	#!/usr/bin/env yaegi
@@ -70,8 +92,37 @@ Options:
 	   the interpretation.
 	-unsafe
 	  include unsafe symbols.
+	-use path
+	   use the symbols of a package generated by yaegi extract, given by
+	   import path or directory in a module. A yaegi command including them
+	   is built with the go command, cached, and runs the command line.
+
+Configuration file:
+  The commands are configured by a yaegi.json file, searched in the working
+  directory and its parents, for example:
+
+	{
+	  "gopath": "vendor/gopath",
+	  "tags": ["prod"],
+	  "syscall": false,
+	  "unsafe": false,
+	  "unrestricted": false,
+	  "env": {"APP_MODE": "prod"},
+	  "use": ["./symbols"],
+	  "limits": {"timeout": "30s", "memory": "512MiB"}
+	}
+
+  The gopath and the use directories are relative to the directory of the file. The gopath
+  and the tags locate and select the source files of packages, including the
+  ones of extract. The env variables are added to the environment of the
+  interpreted programs, but not to the one of the process, which unrestricted
+  programs access instead. The timeout limits the duration of run and extract,
+  and is the default -timeout of test. The memory limit is a soft limit, as
+  GOMEMLIMIT. Flags and environment variables take precedence over the file.
 
 Environment variables:
+  YAEGI_CONFIG=file
+    Use this configuration file instead of searching yaegi.json, or none if "off".
   YAEGI_SYSCALL=1
     Include syscall symbols (same as -syscall flag).
   YAEGI_UNRESTRICTED=1
@@ -81,6 +132,9 @@ Environment variables:
   YAEGI_PROMPT=1
     Force enable the printing of the REPL prompt and the result of last instruction,
     even if stdin is not a terminal.
+  YAEGI_HISTORY=file
+    File where the REPL history is kept, $HOME/.yaegi_history by default.
+    If empty, the history is not persisted.
   YAEGI_AST_DOT=1
     Generate and display graphviz dot of AST with dotty(1)
   YAEGI_CFG_DOT=1
@@ -104,11 +158,14 @@ import (
 )
 
 const (
+	Build   = "build"
 	Extract = "extract"
 	Help    = "help"
+	LSP     = "lsp"
 	Run     = "run"
 	Test    = "test"
 	Version = "version"
+	Vet     = "vet"
 )
 
 var version = "devel" // This may be overwritten at build time.
@@ -124,17 +181,29 @@ func main() {
 		cmd = os.Args[1]
 	}
 
+	if cfg, err = loadConfig(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	cfg.apply()
+
 	switch cmd {
+	case Build:
+		err = buildCmd(os.Args[2:])
 	case Extract:
 		err = extractCmd(os.Args[2:])
 	case Help, "-h", "--help":
 		err = help(os.Args[2:])
+	case LSP:
+		err = lspCmd(os.Args[2:])
 	case Run:
 		err = run(os.Args[2:])
 	case Test:
 		err = test(os.Args[2:])
 	case Version:
 		fmt.Println(version)
+	case Vet:
+		err = vet(os.Args[2:])
 	default:
 		// If no command is given, fallback to default "run" command.
 		// This allows scripts starting with "#!/usr/bin/env yaegi",
@@ -132,3 +132,269 @@ func raceDetectorSupported(goos, goarch string) bool {
 		return false
 	}
 }
+
+func TestYaegiBuild(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping build of the generated program in short mode")
+	}
+	root, err := filepath.Abs("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "src")
+	files := map[string]string{
+		"main.go":    "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n\n\t\"./lib\"\n)\n\nfunc main() { fmt.Println(lib.Hello(), os.Args[1:]) }\n",
+		"lib/lib.go": "package lib\n\nimport \"os\"\n\nfunc init() {\n\tif os.Getenv(\"HELLO_RUN\") == \"\" {\n\t\tpanic(\"init executed\")\n\t}\n}\n\nfunc Hello() string { return \"hello\" }\n",
+		"unused.go":  "package main\n",
+	}
+	for name, content := range files {
+		name = filepath.Join(src, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := filepath.Join(tmp, "hello")
+	if err := buildCmd([]string{"-o", out, filepath.Join(src, "main.go")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(out, bundleDir, srcDir, "lib", "lib.go")); err != nil {
+		t.Fatalf("imported package not bundled: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(out, bundleDir, srcDir, "unused.go")); err == nil {
+		t.Fatal("unused file bundled")
+	}
+	if b, err := os.ReadFile(filepath.Join(out, "main.go")); err != nil || bytes.Contains(b, []byte(tmp)) {
+		t.Fatalf("build location embedded in the generated program: %v", err)
+	}
+
+	// Build the generated program with the yaegi module of this tree, and
+	// run it without the sources.
+	gomod := "module hello\n\ngo 1.22\n\nrequire github.com/traefik/yaegi v0.0.0\n\nreplace github.com/traefik/yaegi => " + root + "\n"
+	if err := os.WriteFile(filepath.Join(out, "go.mod"), []byte(gomod), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	bin := filepath.Join(tmp, "hello.bin")
+	build := exec.Command("go", "build", "-mod=mod", "-o", bin, ".")
+	build.Dir = out
+	if b, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("failed to build generated program: %v: %s", err, b)
+	}
+	if err := os.RemoveAll(src); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(bin, "a", "b")
+	cmd.Env = append(os.Environ(), "HELLO_RUN=1")
+	b, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("failed to run generated program: %v: %s", err, b)
+	}
+	if got, want := string(b), "hello [a b]\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, configFile)
+	const content = `{
+	"gopath": "gopath",
+	"tags": ["a", "b"],
+	"syscall": true,
+	"env": {"FOO": "bar"},
+	"limits": {"timeout": "2m", "memory": "64MiB"}
+}`
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("YAEGI_CONFIG", file)
+	c, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "gopath"); c.GoPath != want {
+		t.Errorf("got gopath %q, want %q", c.GoPath, want)
+	}
+	if got := strings.Join(c.Tags, ","); got != "a,b" {
+		t.Errorf("got tags %q, want %q", got, "a,b")
+	}
+	if !c.Syscall || c.Unsafe || c.Unrestricted {
+		t.Errorf("got symbols syscall %v, unsafe %v, unrestricted %v", c.Syscall, c.Unsafe, c.Unrestricted)
+	}
+	if c.Env["FOO"] != "bar" {
+		t.Errorf("got env %v", c.Env)
+	}
+	if c.timeout != 2*time.Minute || c.memory != 64<<20 {
+		t.Errorf("got timeout %v, memory %d", c.timeout, c.memory)
+	}
+
+	t.Setenv("YAEGI_CONFIG", "off")
+	if c, err = loadConfig(); err != nil || c.file != "" {
+		t.Errorf("got config %q, error %v, want none", c.file, err)
+	}
+
+	if err := os.WriteFile(file, []byte(`{"tag": ["a"]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("YAEGI_CONFIG", file)
+	if _, err = loadConfig(); err == nil || !strings.Contains(err.Error(), `unknown field "tag"`) {
+		t.Errorf("got error %v, want unknown field", err)
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		s    string
+		size int64
+		err  bool
+	}{
+		{s: "1024", size: 1024},
+		{s: "10B", size: 10},
+		{s: "2KiB", size: 2 << 10},
+		{s: "512MiB", size: 512 << 20},
+		{s: "1GiB", size: 1 << 30},
+		{s: "1TiB", size: 1 << 40},
+		{s: "1MB", err: true},
+		{s: "-1", err: true},
+		{s: "", err: true},
+	}
+	for _, test := range tests {
+		size, err := parseSize(test.s)
+		if (err != nil) != test.err || size != test.size {
+			t.Errorf("parseSize(%q) = %d, %v, want %d, error %v", test.s, size, err, test.size, test.err)
+		}
+	}
+}
+
+func TestWithoutFlag(t *testing.T) {
+	arg := []string{"-i", "-use", "./a", "-tags", "x", "--use=b,c", "-use", "d"}
+	got := strings.Join(withoutFlag(arg, "use"), " ")
+	if want := "-i -tags x"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestYaegiRunUse(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping build of a custom yaegi command in short mode")
+	}
+	root, err := filepath.Abs("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmp := t.TempDir()
+	yaegi := filepath.Join(tmp, "yaegi")
+	if out, err := exec.Command("go", "build", "-o", yaegi, ".").CombinedOutput(); err != nil {
+		t.Fatalf("failed to build yaegi command: %v: %s", err, out)
+	}
+
+	// A module with a host package, and its symbols as extracted by yaegi extract.
+	src := filepath.Join(tmp, "src")
+	files := map[string]string{
+		"go.mod":             "module example.com/host\n\ngo 1.22\n\nrequire github.com/traefik/yaegi v0.0.0\n\nreplace github.com/traefik/yaegi => " + root + "\n",
+		"api/api.go":         "package api\n\nfunc Hello(s string) string { return \"hello \" + s }\n",
+		"symbols/symbols.go": "package symbols\n\nimport (\n\t\"reflect\"\n\n\t\"example.com/host/api\"\n)\n\nvar Symbols = map[string]map[string]reflect.Value{}\n\nfunc init() {\n\tSymbols[\"example.com/host/api/api\"] = map[string]reflect.Value{\"Hello\": reflect.ValueOf(api.Hello)}\n}\n",
+		"main.go.txt":        "package main\n\nimport (\n\t\"fmt\"\n\n\t\"example.com/host/api\"\n)\n\nfunc main() { fmt.Println(api.Hello(\"world\")) }\n",
+	}
+	for name, content := range files {
+		name = filepath.Join(src, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	env := []string{"XDG_CACHE_HOME=" + filepath.Join(tmp, "cache"), "YAEGI_CONFIG=off"}
+	for _, k := range []string{"GOCACHE", "GOMODCACHE", "GOPATH"} {
+		out, err := exec.Command("go", "env", k).Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		env = append(env, k+"="+strings.TrimSpace(string(out)))
+	}
+	for _, test := range []struct{ args, want string }{
+		{"run -use ./symbols main.go.txt", "hello world\n"},
+		{"run -use=./symbols -e api.Hello(\"e\")", "hello e\n"},
+	} {
+		cmd := exec.Command(yaegi, strings.Fields(test.args)...)
+		cmd.Dir = src
+		cmd.Env = append(os.Environ(), env...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("%s: %v: %s", test.args, err, out)
+		}
+		if string(out) != test.want {
+			t.Errorf("%s: got %q, want %q", test.args, out, test.want)
+		}
+	}
+}
+
+func TestYaegiConfig(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping build of yaegi command in short mode")
+	}
+	tmp := t.TempDir()
+	yaegi := filepath.Join(tmp, "yaegi")
+	if out, err := exec.Command("go", "build", "-o", yaegi, ".").CombinedOutput(); err != nil {
+		t.Fatalf("failed to build yaegi command: %v: %s", err, out)
+	}
+
+	// A project whose configuration sets the gopath, the build tags, the
+	// environment of scripts and the syscall symbols.
+	dir := filepath.Join(tmp, "project")
+	files := map[string]string{
+		configFile:                `{"gopath": "gopath", "tags": ["foo"], "syscall": true, "env": {"FOO": "bar"}}`,
+		"gopath/src/lib/lib.go":   "//go:build foo\n\npackage lib\n\nfunc Hello() string { return \"hello\" }\n",
+		"gopath/src/lib/other.go": "//go:build !foo\n\npackage lib\n\nfunc Other() string { return \"other\" }\n",
+		"main.go":                 "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n\n\t\"lib\"\n)\n\nfunc main() { fmt.Println(lib.Hello(), os.Getenv(\"FOO\"), os.Getenv(\"YAEGI_SYSCALL\")) }\n",
+	}
+	for name, content := range files {
+		name = filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	env := []string{"XDG_CACHE_HOME=" + filepath.Join(tmp, "cache"), "FOO=", "YAEGI_CONFIG=", "YAEGI_SYSCALL="}
+	for _, k := range []string{"GOCACHE", "GOMODCACHE"} {
+		out, err := exec.Command("go", "env", k).Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		env = append(env, k+"="+strings.TrimSpace(string(out)))
+	}
+	yaegiCmd := func(args ...string) string {
+		cmd := exec.Command(yaegi, args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), env...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("%s: %v: %s", strings.Join(args, " "), err, out)
+		}
+		return string(out)
+	}
+
+	if got, want := yaegiCmd("run", "main.go"), "hello bar 1\n"; got != want {
+		t.Errorf("run: got %q, want %q", got, want)
+	}
+
+	// The symbols of lib are extracted from the files selected by the tags.
+	yaegiCmd("extract", "-name", "symbols", "lib")
+	b, err := os.ReadFile(filepath.Join(dir, "lib.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(b); !strings.Contains(s, `"Hello"`) || strings.Contains(s, `"Other"`) {
+		t.Errorf("extract: unexpected symbols:\n%s", s)
+	}
+}
@@ -17,12 +17,18 @@ import (
 	"go/constant"
 	"go/token"
 	"reflect"
+	"unsafe"
 )
 
 // Arithmetic operators
 {{range $name, $op := .Arithmetic}}
 func {{$name}}(n *node) {
 	next := getExec(n.tnext)
+	{{- if not $op.Shift}}
+	if {{$name}}Unboxed(n, next) {
+		return
+	}
+	{{- end}}
 	typ := n.typ.concrete().TypeOf()
 	isInterface := n.typ.TypeOf().Kind() == reflect.Interface
 	dest := genValueOutput(n, typ)
@@ -287,6 +293,11 @@ func {{$name}}Const(n *node) {
 {{range $name, $op := .Arithmetic}}
 func {{$name}}Assign(n *node) {
 	next := getExec(n.tnext)
+	{{- if not $op.Shift}}
+	if {{$name}}AssignUnboxed(n, next) {
+		return
+	}
+	{{- end}}
 	typ := n.typ.TypeOf()
 	c0, c1 := n.child[0], n.child[1]
 	setMap := isMapEntry(c0)
@@ -438,6 +449,9 @@ func {{$name}}Assign(n *node) {
 {{range $name, $op := .IncDec}}
 func {{$name}}(n *node) {
 	next := getExec(n.tnext)
+	if {{$name}}Unboxed(n, next) {
+		return
+	}
 	typ := n.typ.TypeOf()
 	c0 := n.child[0]
 	setMap := isMapEntry(c0)
@@ -531,6 +545,9 @@ func {{$name}}Const(n *node) {
 {{range $name, $op := .Comparison}}
 func {{$name}}(n *node) {
 	tnext := getExec(n.tnext)
+	if {{$name}}Unboxed(n, tnext) {
+		return
+	}
 	dest := genValueOutput(n, reflect.TypeOf(true))
 	typ := n.typ.concrete().TypeOf()
 	isInterface := n.typ.TypeOf().Kind() == reflect.Interface
@@ -1134,6 +1151,118 @@ func {{$name}}(n *node) {
 	}
 }
 {{end}}
+// Operators on unboxed values, see unbox.go.
+{{range $name, $op := .Arithmetic}}{{if not $op.Shift}}
+func {{$name}}Unboxed(n *node, next bltn) bool {
+	d, i0, i1, t, ok := unboxedOperands(n, n, n.child[0], n.child[1])
+	if !ok || t != n.typ.TypeOf() {
+		return false
+	}
+	switch t.Kind() {
+	{{- range $t := $.Unboxed}}{{if or (not $t.Float) $op.Float}}
+	case reflect.{{$t.Kind}}:
+		if i1 < 0 {
+			j := {{$t.Name}}({{$t.Conv}}(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*{{$t.Name}})(unsafe.Pointer(&f.num[d])) = *(*{{$t.Name}})(unsafe.Pointer(&f.num[i0])) {{$op.Name}} j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*{{$t.Name}})(unsafe.Pointer(&f.num[d])) = *(*{{$t.Name}})(unsafe.Pointer(&f.num[i0])) {{$op.Name}} *(*{{$t.Name}})(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	{{- end}}{{end}}
+	}
+	return false
+}
+
+func {{$name}}AssignUnboxed(n *node, next bltn) bool {
+	d, _, i1, t, ok := unboxedOperands(n, n.child[0], n.child[0], n.child[1])
+	if !ok {
+		return false
+	}
+	switch t.Kind() {
+	{{- range $t := $.Unboxed}}{{if or (not $t.Float) $op.Float}}
+	case reflect.{{$t.Kind}}:
+		if i1 < 0 {
+			j := {{$t.Name}}({{$t.Conv}}(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*{{$t.Name}})(unsafe.Pointer(&f.num[d])) {{$op.Name}}= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*{{$t.Name}})(unsafe.Pointer(&f.num[d])) {{$op.Name}}= *(*{{$t.Name}})(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	{{- end}}{{end}}
+	}
+	return false
+}
+{{end}}{{end}}
+{{- range $name, $op := .IncDec}}
+func {{$name}}Unboxed(n *node, next bltn) bool {
+	d, t, ok := unboxedIndex(n.child[0])
+	if !ok {
+		return false
+	}
+	switch t.Kind() {
+	{{- range $t := $.Unboxed}}
+	case reflect.{{$t.Kind}}:
+		n.exec = func(f *frame) bltn {
+			*(*{{$t.Name}})(unsafe.Pointer(&f.num[d])){{$op.Name}}{{$op.Name}}
+			return next
+		}
+		return true
+	{{- end}}
+	}
+	return false
+}
+{{end}}
+{{- range $name, $op := .Comparison}}
+func {{$name}}Unboxed(n *node, tnext bltn) bool {
+	d, i0, i1, t, ok := unboxedOperands(n, n, n.child[0], n.child[1])
+	if !ok {
+		return false
+	}
+	var fnext bltn
+	if n.fnext != nil {
+		fnext = getExec(n.fnext)
+	}
+	switch t.Kind() {
+	{{- range $t := $.Unboxed}}
+	case reflect.{{$t.Kind}}:
+		if i1 < 0 {
+			j := {{$t.Name}}({{$t.Conv}}(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*{{$t.Name}})(unsafe.Pointer(&f.num[i0])) {{$op.Name}} j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*{{$t.Name}})(unsafe.Pointer(&f.num[i0])) {{$op.Name}} *(*{{$t.Name}})(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	{{- end}}
+	}
+	return false
+}
+{{end}}
 `
 
 // Op define operator name and properties.
@@ -1147,6 +1276,14 @@ type Op struct {
 	Int     bool   // true if operator applies to int only
 }
 
+// Unboxed defines a type of values which can be stored unboxed.
+type Unboxed struct {
+	Name  string // Go type name
+	Kind  string // reflect.Kind name
+	Conv  string // function to get a constant value of the type
+	Float bool   // true if type is floating point
+}
+
 func main() {
 	base := template.New("genop")
 	base.Funcs(template.FuncMap{
@@ -1197,6 +1334,17 @@ func main() {
 			"lowerEqual":   {Name: "<=", Complex: false},
 			"notEqual":     {Name: "!=", Complex: true},
 		},
+		"Unboxed": []Unboxed{
+			{"int", "Int", "vInt", false},
+			{"int32", "Int32", "vInt", false},
+			{"int64", "Int64", "vInt", false},
+			{"uint", "Uint", "vUint", false},
+			{"uint8", "Uint8", "vUint", false},
+			{"uint32", "Uint32", "vUint", false},
+			{"uint64", "Uint64", "vUint", false},
+			{"float32", "Float32", "vFloat", true},
+			{"float64", "Float64", "vFloat", true},
+		},
 		"Unary": map[string]Op{
 			"not":    {Name: "!", Float: false, Bool: true},
 			"neg":    {Name: "-", Float: true, Bool: false},
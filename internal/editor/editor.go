@@ -0,0 +1,304 @@
+package editor
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/traefik/yaegi/interp"
+)
+
+// maxHistory is the maximum number of lines kept in the history.
+const maxHistory = 1000
+
+// Editor is a minimal line editor, with history and completion, used by the
+// REPL when its input and output are a terminal.
+type Editor struct {
+	in       *bufio.Reader
+	out      io.Writer
+	raw      func() (func(), error)               // sets the terminal in raw mode, returns a function to restore it
+	complete func(line string) (string, []string) // returns the word to complete at end of line, and its candidates
+	histFile string                               // file where the history is persisted, if not empty
+
+	history []string
+	hist    int    // index in history of the line being edited
+	saved   string // line being edited, saved while browsing the history
+
+	prompt string
+	buf    []rune // line being edited
+	pos    int    // cursor position in buf
+}
+
+// New returns a line editor reading from in and writing to out, or nil if
+// in or out is not a terminal. The lines entered are appended to histFile,
+// if not empty, and complete is called with the line before the cursor to
+// complete it on the tab key.
+func New(in, out *os.File, histFile string, complete func(line string) (word string, candidates []string)) *Editor {
+	if !isTerminal(in.Fd()) || !isTerminal(out.Fd()) {
+		return nil
+	}
+	e := &Editor{
+		in:       bufio.NewReader(in),
+		out:      out,
+		raw:      func() (func(), error) { return makeRaw(in.Fd()) },
+		complete: complete,
+		histFile: histFile,
+	}
+	e.loadHistory()
+	return e
+}
+
+// loadHistory reads the last lines of the history file.
+func (e *Editor) loadHistory() {
+	if e.histFile == "" {
+		return
+	}
+	b, err := os.ReadFile(e.histFile)
+	if err != nil {
+		return
+	}
+	for _, l := range strings.Split(string(b), "\n") {
+		if l != "" {
+			e.history = append(e.history, l)
+		}
+	}
+	if n := len(e.history); n > maxHistory {
+		e.history = e.history[n-maxHistory:]
+	}
+}
+
+// addHistory appends a non empty line to the history, and to the history file.
+func (e *Editor) addHistory(line string) {
+	if strings.TrimSpace(line) == "" || len(e.history) > 0 && e.history[len(e.history)-1] == line {
+		return
+	}
+	e.history = append(e.history, line)
+	if n := len(e.history); n > maxHistory {
+		e.history = e.history[n-maxHistory:]
+	}
+	if e.histFile == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(e.histFile), 0o700); err != nil {
+		return
+	}
+	f, err := os.OpenFile(e.histFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(f, line)
+	f.Close()
+}
+
+func ctrl(c byte) rune { return rune(c & 0x1f) }
+
+// ReadLine displays the prompt and returns the line entered by the user.
+// It returns io.EOF on Ctrl-D on an empty line, and interp.ErrInterrupt on Ctrl-C.
+func (e *Editor) ReadLine(prompt string) (string, error) {
+	if e.raw != nil {
+		restore, err := e.raw()
+		if err != nil {
+			return "", err
+		}
+		defer restore()
+	}
+	e.prompt, e.buf, e.pos = prompt, e.buf[:0], 0
+	e.hist, e.saved = len(e.history), ""
+	e.refresh()
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			if errors.Is(err, io.EOF) && len(e.buf) > 0 {
+				e.write("\r\n")
+				return string(e.buf), nil
+			}
+			return "", err
+		}
+		switch r {
+		case '\r', '\n':
+			e.write("\r\n")
+			line := string(e.buf)
+			e.addHistory(line)
+			return line, nil
+		case ctrl('C'):
+			e.write("^C\r\n")
+			return "", interp.ErrInterrupt
+		case ctrl('D'):
+			if len(e.buf) == 0 {
+				e.write("\r\n")
+				return "", io.EOF
+			}
+			e.delete(e.pos, e.pos+1)
+		case ctrl('A'):
+			e.pos = 0
+		case ctrl('E'):
+			e.pos = len(e.buf)
+		case ctrl('B'):
+			e.move(-1)
+		case ctrl('F'):
+			e.move(1)
+		case ctrl('H'), 127:
+			e.delete(e.pos-1, e.pos)
+		case ctrl('K'):
+			e.delete(e.pos, len(e.buf))
+		case ctrl('U'):
+			e.delete(0, e.pos)
+		case ctrl('W'):
+			e.delete(e.wordStart(), e.pos)
+		case ctrl('L'):
+			e.write("\x1b[H\x1b[2J")
+		case ctrl('P'):
+			e.recall(e.hist - 1)
+		case ctrl('N'):
+			e.recall(e.hist + 1)
+		case '\t':
+			e.completion()
+		case 27:
+			e.escape()
+		default:
+			if unicode.IsPrint(r) {
+				e.insert([]rune{r})
+			}
+		}
+		e.refresh()
+	}
+}
+
+// escape handles the escape sequences sent by the cursor and edition keys.
+func (e *Editor) escape() {
+	r, _, err := e.in.ReadRune()
+	if err != nil || r != '[' && r != 'O' {
+		return
+	}
+	var param []rune
+	for {
+		if r, _, err = e.in.ReadRune(); err != nil {
+			return
+		}
+		if r < '0' || r > '9' && r != ';' {
+			break
+		}
+		param = append(param, r)
+	}
+	switch r {
+	case 'A':
+		e.recall(e.hist - 1)
+	case 'B':
+		e.recall(e.hist + 1)
+	case 'C':
+		e.move(1)
+	case 'D':
+		e.move(-1)
+	case 'H':
+		e.pos = 0
+	case 'F':
+		e.pos = len(e.buf)
+	case '~':
+		switch string(param) {
+		case "1", "7":
+			e.pos = 0
+		case "4", "8":
+			e.pos = len(e.buf)
+		case "3":
+			e.delete(e.pos, e.pos+1)
+		}
+	}
+}
+
+func (e *Editor) write(s string) { fmt.Fprint(e.out, s) }
+
+// refresh redraws the line being edited, and places the cursor.
+func (e *Editor) refresh() {
+	s := "\r" + e.prompt + string(e.buf) + "\x1b[K"
+	if n := len(e.buf) - e.pos; n > 0 {
+		s += fmt.Sprintf("\x1b[%dD", n)
+	}
+	e.write(s)
+}
+
+func (e *Editor) move(n int) {
+	if p := e.pos + n; p >= 0 && p <= len(e.buf) {
+		e.pos = p
+	}
+}
+
+func (e *Editor) insert(r []rune) {
+	e.buf = append(e.buf[:e.pos], append(r, e.buf[e.pos:]...)...)
+	e.pos += len(r)
+}
+
+// delete removes the runes between indexes i and j of the line.
+func (e *Editor) delete(i, j int) {
+	if i < 0 || j > len(e.buf) || i >= j {
+		return
+	}
+	e.buf = append(e.buf[:i], e.buf[j:]...)
+	if e.pos > j {
+		e.pos -= j - i
+	} else if e.pos > i {
+		e.pos = i
+	}
+}
+
+// wordStart returns the start of the word preceding the cursor.
+func (e *Editor) wordStart() int {
+	i := e.pos
+	for i > 0 && e.buf[i-1] == ' ' {
+		i--
+	}
+	for i > 0 && e.buf[i-1] != ' ' {
+		i--
+	}
+	return i
+}
+
+// recall replaces the line being edited by the history entry at index i.
+// The index past the last entry designates the line being edited.
+func (e *Editor) recall(i int) {
+	if i < 0 || i > len(e.history) || i == e.hist {
+		return
+	}
+	if e.hist == len(e.history) {
+		e.saved = string(e.buf)
+	}
+	e.hist = i
+	if i == len(e.history) {
+		e.buf = []rune(e.saved)
+	} else {
+		e.buf = []rune(e.history[i])
+	}
+	e.pos = len(e.buf)
+}
+
+// completion completes the word before the cursor with the longest prefix
+// common to all candidates, or lists the candidates if there is none.
+func (e *Editor) completion() {
+	if e.complete == nil {
+		return
+	}
+	word, cands := e.complete(string(e.buf[:e.pos]))
+	if len(cands) == 0 {
+		return
+	}
+	prefix := cands[0]
+	for _, c := range cands[1:] {
+		for !strings.HasPrefix(c, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	if len(prefix) > len(word) && strings.HasPrefix(prefix, word) {
+		e.insert([]rune(prefix[len(word):]))
+		return
+	}
+	if len(cands) > 1 {
+		e.write("\r\n" + strings.Join(cands, "  ") + "\r\n")
+	}
+}
@@ -0,0 +1,67 @@
+package editor
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/traefik/yaegi/interp"
+)
+
+func TestEditor(t *testing.T) {
+	histFile := filepath.Join(t.TempDir(), "history")
+	if err := os.WriteFile(histFile, []byte("a := 1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	input := strings.Join([]string{
+		"abc\x1b[D\x1b[DX\r",   // cursor moves and insertion
+		"\x1b[A\r",             // previous line
+		"\x1b[A\x1b[A\r",       // line from the history file
+		"hello world\x17\r",    // delete word
+		"xy\x02\x0b\r",         // kill to end of line
+		"fmt\x01// \x1b[3~\r",  // insertion and deletion at start of line
+		"tot\t\r",              // completion
+		"\x1b[A\x1b[A\x1b[B\r", // browse history back and forth
+		"\x03",                 // interrupt
+		"\x04",                 // end of input
+	}, "")
+	complete := func(line string) (string, []string) {
+		word := line[strings.LastIndex(line, " ")+1:]
+		if strings.HasPrefix("total", word) {
+			return word, []string{"total"}
+		}
+		return word, nil
+	}
+	var out strings.Builder
+	e := &Editor{in: bufio.NewReader(strings.NewReader(input)), out: &out, complete: complete, histFile: histFile}
+	e.loadHistory()
+
+	want := []string{"aXbc", "aXbc", "a := 1", "hello ", "x", "// mt", "total", "total"}
+	for _, w := range want {
+		line, err := e.ReadLine("> ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line != w {
+			t.Errorf("got %q, want %q", line, w)
+		}
+	}
+	if _, err := e.ReadLine("> "); !errors.Is(err, interp.ErrInterrupt) {
+		t.Errorf("got error %v, want %v", err, interp.ErrInterrupt)
+	}
+	if _, err := e.ReadLine("> "); !errors.Is(err, io.EOF) {
+		t.Errorf("got error %v, want %v", err, io.EOF)
+	}
+
+	b, err := os.ReadFile(histFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), "a := 1\naXbc\na := 1\nhello \nx\n// mt\ntotal\n"; got != want {
+		t.Errorf("got history %q, want %q", got, want)
+	}
+}
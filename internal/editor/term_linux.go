@@ -0,0 +1,8 @@
+package editor
+
+import "syscall"
+
+const (
+	ioctlReadTermios  = syscall.TCGETS
+	ioctlWriteTermios = syscall.TCSETS
+)
@@ -0,0 +1,11 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+
+package editor
+
+import "errors"
+
+// isTerminal returns false, as the line editor is not
+// supported on this platform.
+func isTerminal(fd uintptr) bool { return false }
+
+func makeRaw(fd uintptr) (func(), error) { return nil, errors.New("raw terminal mode not supported") }
@@ -16,17 +16,32 @@ var DummyType = reflect.TypeOf(dummy{})
 
 // The following type sizes must match their original definition in Go src/internal/abi/type.go.
 type abiType struct {
-	_ uintptr
-	_ uintptr
-	_ uint32
-	_ uint8
-	_ uint8
-	_ uint8
-	_ uint8
-	_ uintptr
-	_ uintptr
-	_ int32
-	_ int32
+	_         uintptr
+	_         uintptr
+	_         uint32
+	TFlag     uint8
+	_         uint8
+	_         uint8
+	_         uint8
+	_         uintptr
+	_         uintptr
+	Str       int32
+	PtrToThis int32
+}
+
+// Type flags, from Go src/internal/abi/type.go.
+const (
+	tflagUncommon  = 1 << 0
+	tflagExtraStar = 1 << 1
+	tflagNamed     = 1 << 2
+)
+
+type abiUncommonType struct {
+	PkgPath int32
+	Mcount  uint16
+	Xcount  uint16
+	Moff    uint32
+	_       uint32
 }
 
 type abiName struct {
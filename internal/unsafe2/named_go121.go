@@ -0,0 +1,132 @@
+//go:build go1.21 && !go1.28
+// +build go1.21,!go1.28
+
+package unsafe2
+
+import (
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// The runtime type layouts used by NamedOf have been checked for the Go
+// versions selected by the build constraints above. For other versions,
+// NamedOf returns its argument unchanged, see named_other.go.
+
+// addReflectOff registers a pointer in the runtime reflection lookup map,
+// and returns an offset usable as a name or type offset.
+//
+//go:linkname addReflectOff reflect.addReflectOff
+func addReflectOff(ptr unsafe.Pointer) int32
+
+// The following kind specific types must match their original definition in
+// Go src/internal/abi/type.go.
+
+type abiPtrType struct { // Also the layout of slice types.
+	abiType
+	Elem *abiType
+}
+
+type abiArrayType struct {
+	abiType
+	Elem  *abiType
+	Slice *abiType
+	Len   uintptr
+}
+
+type abiChanType struct {
+	abiType
+	Elem *abiType
+	Dir  int
+}
+
+// uncommonType is the kind specific type K of a named type, followed by its
+// uncommon type, as in Go src/internal/abi/type.go.
+type uncommonType[K any] struct {
+	k K
+	u abiUncommonType
+}
+
+// newUncommon returns a copy of the kind specific type t, followed by an
+// uncommon type.
+func newUncommon[K any](t *abiType) (*abiType, *abiUncommonType) {
+	n := &uncommonType[K]{k: *(*K)(unsafe.Pointer(t))}
+	return (*abiType)(unsafe.Pointer(n)), &n.u
+}
+
+type namedKey struct {
+	typ           reflect.Type
+	pkgPath, name string
+}
+
+var namedCache sync.Map // map[namedKey]reflect.Type
+
+// NamedOf returns a copy of the type t, with the qualified name name
+// (i.e. "main.T") and defined in the package pkgPath, so reflect and fmt report
+// it as such, like for compiled types. The copy has no methods, and is
+// convertible to t. For struct types, the fields of the returned type are
+// shared with t, so SetFieldType on t also applies to the named copy.
+//
+// Boolean, numeric, string, pointer, slice, array, channel and struct types
+// are named. Map, function and interface types, whose runtime layout depends
+// on the Go version, are returned unchanged. This is very unsafe.
+func NamedOf(t reflect.Type, pkgPath, name string) reflect.Type {
+	if name == "" || t.Kind() == reflect.Struct && t.NumMethod() > 0 {
+		return t
+	}
+	key := namedKey{t, pkgPath, name}
+	if nt, ok := namedCache.Load(key); ok {
+		return nt.(reflect.Type)
+	}
+
+	var ntyp *abiType
+	var u *abiUncommonType
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128, reflect.String:
+		ntyp, u = newUncommon[abiType](unpackType(t))
+	case reflect.Ptr, reflect.Slice:
+		ntyp, u = newUncommon[abiPtrType](unpackType(t))
+	case reflect.Array:
+		ntyp, u = newUncommon[abiArrayType](unpackType(t))
+	case reflect.Chan:
+		ntyp, u = newUncommon[abiChanType](unpackType(t))
+	case reflect.Struct:
+		ntyp, u = newUncommon[abiStructType](unpackType(t))
+	default:
+		return t
+	}
+	ntyp.TFlag = ntyp.TFlag&^tflagExtraStar | tflagUncommon | tflagNamed
+	ntyp.PtrToThis = 0
+	ntyp.Str = addReflectOff(unsafe.Pointer(newName(name)))
+	*u = abiUncommonType{Moff: uint32(unsafe.Sizeof(abiUncommonType{}))}
+	if pkgPath != "" {
+		u.PkgPath = addReflectOff(unsafe.Pointer(newName(pkgPath)))
+	}
+
+	// The type memory is referenced from the heap by the cached type.
+	var v interface{} = struct{}{}
+	eface := (*emptyInterface)(unsafe.Pointer(&v))
+	eface.typ = ntyp
+	nt, _ := namedCache.LoadOrStore(key, reflect.TypeOf(v))
+	return nt.(reflect.Type)
+}
+
+// newName returns an encoded type name, as in Go src/internal/abi/type.go.
+func newName(s string) *byte {
+	var l [10]byte
+	n := 0
+	for v := len(s); ; v >>= 7 {
+		l[n] = byte(v & 0x7f)
+		n++
+		if v < 0x80 {
+			break
+		}
+		l[n-1] |= 0x80
+	}
+	b := make([]byte, 1+n+len(s))
+	copy(b[1:], l[:n])
+	copy(b[1+n:], s)
+	return &b[0]
+}
@@ -0,0 +1,10 @@
+//go:build !go1.21 || go1.28
+// +build !go1.21 go1.28
+
+package unsafe2
+
+import "reflect"
+
+// NamedOf returns t unchanged, as naming runtime created types is only
+// supported for the Go versions whose runtime type layouts are known.
+func NamedOf(t reflect.Type, pkgPath, name string) reflect.Type { return t }
@@ -1,6 +1,7 @@
 package unsafe2_test
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
 
@@ -31,3 +32,83 @@ func TestSwapFieldType(t *testing.T) {
 		t.Fatalf("unexpected field type: want %s; got %s", ntyp, typ.Field(1).Type)
 	}
 }
+
+func TestNamedOf(t *testing.T) {
+	typ := reflect.StructOf([]reflect.StructField{{Name: "A", Type: reflect.TypeOf(0)}})
+	ntyp := unsafe2.NamedOf(typ, "example.com/foo", "foo.T")
+	if ntyp == typ {
+		t.Skip("naming types is not supported by this Go version")
+	}
+
+	if ntyp.Name() != "T" || ntyp.PkgPath() != "example.com/foo" || ntyp.String() != "foo.T" {
+		t.Fatalf("unexpected named type: %s, %s, %s", ntyp.Name(), ntyp.PkgPath(), ntyp.String())
+	}
+	if typ.Name() != "" {
+		t.Fatalf("unexpected original type name: %s", typ.Name())
+	}
+	if unsafe2.NamedOf(typ, "example.com/foo", "foo.T") != ntyp {
+		t.Fatal("named type is not unique")
+	}
+
+	v := reflect.New(ntyp).Elem()
+	v.Field(0).SetInt(3)
+	if s := fmt.Sprintf("%T %+v %v", v.Interface(), v.Interface(), reflect.PtrTo(ntyp)); s != "foo.T {A:3} *foo.T" {
+		t.Fatalf("unexpected formatting: %s", s)
+	}
+	if !v.Type().ConvertibleTo(typ) {
+		t.Fatal("named type should be convertible to its underlying type")
+	}
+}
+
+func TestNamedOfKinds(t *testing.T) {
+	if typ := reflect.TypeOf(0.0); unsafe2.NamedOf(typ, "example.com/foo", "foo.Celsius") == typ {
+		t.Skip("naming types is not supported by this Go version")
+	}
+	celsius := unsafe2.NamedOf(reflect.TypeOf(0.0), "example.com/foo", "foo.Celsius")
+
+	tests := []struct {
+		typ   reflect.Type
+		value interface{}
+		want  string
+	}{
+		{typ: reflect.TypeOf(0.0), value: 21.5, want: "foo.N 21.5"},
+		{typ: reflect.TypeOf(""), value: "a", want: "foo.N a"},
+		{typ: reflect.TypeOf(false), value: true, want: "foo.N true"},
+		{typ: reflect.TypeOf(uint8(0)), value: uint8(3), want: "foo.N 3"},
+		{typ: reflect.TypeOf([]int{}), value: []int{1, 2}, want: "foo.N [1 2]"},
+		{typ: reflect.TypeOf([2]string{}), value: [2]string{"a", "b"}, want: "foo.N [a b]"},
+		{typ: reflect.TypeOf(new(int)), value: (*int)(nil), want: "foo.N <nil>"},
+		{typ: reflect.TypeOf(make(chan int)), value: (chan int)(nil), want: "foo.N <nil>"},
+		{typ: reflect.SliceOf(celsius), value: nil, want: "foo.N []"},
+	}
+	for _, test := range tests {
+		ntyp := unsafe2.NamedOf(test.typ, "example.com/foo", "foo.N")
+		if ntyp.Name() != "N" || ntyp.PkgPath() != "example.com/foo" || ntyp.Kind() != test.typ.Kind() {
+			t.Errorf("%s: unexpected named type: %s, %s, %s", test.typ, ntyp.Name(), ntyp.PkgPath(), ntyp.Kind())
+			continue
+		}
+		v := reflect.New(ntyp).Elem()
+		if test.value != nil {
+			v.Set(reflect.ValueOf(test.value).Convert(ntyp))
+		}
+		if s := fmt.Sprintf("%T %v", v.Interface(), v.Interface()); s != test.want {
+			t.Errorf("%s: got %q, want %q", test.typ, s, test.want)
+		}
+		// As for compiled types, a named type is assignable to an unnamed one with
+		// the same underlying type.
+		if !ntyp.ConvertibleTo(test.typ) || ntyp.AssignableTo(test.typ) != (test.typ.Name() == "") {
+			t.Errorf("%s: unexpected convertibility or assignability of named type", test.typ)
+		}
+	}
+
+	if s := reflect.SliceOf(celsius).String(); s != "[]foo.Celsius" {
+		t.Errorf("unexpected element type: %s", s)
+	}
+
+	// Map and function types are returned unchanged.
+	for _, typ := range []reflect.Type{reflect.TypeOf(map[string]int{}), reflect.TypeOf(func() {})} {
+		if unsafe2.NamedOf(typ, "example.com/foo", "foo.N") != typ {
+			t.Errorf("%s: unexpected named type", typ)
+		}
+	}
+}
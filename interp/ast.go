@@ -3,6 +3,7 @@ package interp
 import (
 	"fmt"
 	"go/ast"
+	"go/build"
 	"go/constant"
 	"go/parser"
 	"go/scanner"
@@ -366,7 +367,20 @@ func wrapInMain(src string) string {
 }
 
 func (interp *Interpreter) parse(src, name string, inc bool) (node ast.Node, err error) {
-	mode := parser.DeclarationErrors
+	return interp.parseWith(&interp.context, src, name, inc)
+}
+
+// parseWith parses src as parse, evaluating the build constraints in ctx.
+func (interp *Interpreter) parseWith(ctx *build.Context, src, name string, inc bool) (node ast.Node, err error) {
+	if interp.opt.err != nil {
+		return nil, interp.opt.err
+	}
+
+	// Comments are parsed to retain the documentation of declarations.
+	mode := parser.DeclarationErrors | parser.ParseComments
+	if interp.check != nil {
+		mode |= parser.AllErrors
+	}
 
 	// Allow incremental parsing of declarations or statements, by inserting
 	// them in a pseudo file package or function. Those statements or
@@ -384,15 +398,25 @@ func (interp *Interpreter) parse(src, name string, inc bool) (node ast.Node, err
 			inFunc = true
 			src = wrapInMain(src)
 		}
-		// Parse comments in REPL mode, to allow tag setting.
-		mode |= parser.ParseComments
 	}
 
-	if ok, err := interp.buildOk(&interp.context, name, src); !ok || err != nil {
+	if ok, err := interp.buildOk(ctx, name, src); !ok || err != nil {
 		return nil, err // skip source not matching build constraints
 	}
 
-	f, err := parser.ParseFile(interp.fset, name, src, mode)
+	interp.sources.set(name, src)
+
+	var f *ast.File
+	var p *parsedFile
+	if !inc {
+		p = interp.parsed.take(name, src)
+	}
+	if p != nil {
+		// File already parsed by preload.
+		f, err = p.file, p.err
+	} else {
+		f, err = parser.ParseFile(interp.fset, name, src, mode)
+	}
 	if err != nil {
 		// only retry if we're on an expression/statement about a func
 		if !inc || tok != token.FUNC {
@@ -410,6 +434,7 @@ func (interp *Interpreter) parse(src, name string, inc bool) (node ast.Node, err
 		if err != nil {
 			return nil, initialError
 		}
+		interp.sources.set(name, src)
 	}
 
 	if inFunc {
@@ -417,7 +442,10 @@ func (interp *Interpreter) parse(src, name string, inc bool) (node ast.Node, err
 		return f.Decls[0].(*ast.FuncDecl).Body, nil
 	}
 
-	setYaegiTags(&interp.context, f.Comments)
+	if inc {
+		// Allow tag setting in REPL mode.
+		setYaegiTags(&interp.context, f.Comments)
+	}
 	return f, nil
 }
 
@@ -435,6 +463,17 @@ func (interp *Interpreter) ast(f ast.Node) (string, *node, error) {
 	var st nodestack
 	pkgName := "main"
 
+	// Doc comments of declarations, by position, set to the nodes once created.
+	docs := map[token.Pos]string{}
+	setDoc := func(pos token.Pos, doc *ast.CommentGroup) {
+		if doc == nil {
+			return
+		}
+		if text := doc.Text(); text != "" {
+			docs[pos] = text
+		}
+	}
+
 	addChild := func(root **node, anc astNode, pos token.Pos, kind nkind, act action) *node {
 		var i interface{}
 		nindex := atomic.AddInt64(&interp.nindex, 1)
@@ -675,6 +714,11 @@ func (interp *Interpreter) ast(f ast.Node) (string, *node, error) {
 			st.push(addChild(&root, anc, pos, exprStmt, aNop), nod)
 
 		case *ast.Field:
+			if a.Doc != nil {
+				setDoc(pos, a.Doc)
+			} else {
+				setDoc(pos, a.Comment)
+			}
 			st.push(addChild(&root, anc, pos, fieldExpr, aNop), nod)
 
 		case *ast.FieldList:
@@ -708,6 +752,7 @@ func (interp *Interpreter) ast(f ast.Node) (string, *node, error) {
 			st.push(addChild(&root, anc, pos, kind, aNop), nod)
 
 		case *ast.FuncDecl:
+			setDoc(pos, a.Doc)
 			n := addChild(&root, anc, pos, funcDecl, aNop)
 			n.val = n
 			if a.Recv == nil {
@@ -743,6 +788,10 @@ func (interp *Interpreter) ast(f ast.Node) (string, *node, error) {
 			case token.VAR:
 				kind = varDecl
 			}
+			if !a.Lparen.IsValid() && len(a.Specs) == 1 {
+				// The declaration documents its single spec.
+				setDoc(a.Specs[0].Pos(), a.Doc)
+			}
 			st.push(addChild(&root, anc, pos, kind, aNop), nod)
 
 		case *ast.GoStmt:
@@ -864,6 +913,7 @@ func (interp *Interpreter) ast(f ast.Node) (string, *node, error) {
 			st.push(addChild(&root, anc, pos, typeAssertExpr, aTypeAssert), nod)
 
 		case *ast.TypeSpec:
+			setDoc(pos, a.Doc)
 			if a.Assign.IsValid() {
 				st.push(addChild(&root, anc, pos, typeSpecAssign, aNop), nod)
 				break
@@ -899,6 +949,7 @@ func (interp *Interpreter) ast(f ast.Node) (string, *node, error) {
 			st.push(addChild(&root, anc, pos, kind, act), nod)
 
 		case *ast.ValueSpec:
+			setDoc(pos, a.Doc)
 			kind := valueSpec
 			act := aNop
 			switch {
@@ -929,12 +980,23 @@ func (interp *Interpreter) ast(f ast.Node) (string, *node, error) {
 			st.push(n, nod)
 
 		default:
-			err = astError(fmt.Errorf("ast: %T not implemented, line %s", a, interp.fset.Position(pos)))
+			err = astError(&codeError{fmt.Errorf("ast: %T not implemented, line %s", a, interp.fset.Position(pos)), ErrUnsupported})
 			return false
 		}
 		return true
 	})
 
+	if len(docs) > 0 && root != nil {
+		root.Walk(func(n *node) bool {
+			// The declaration is the first node at its position.
+			if d, ok := docs[n.pos]; ok {
+				n.doc = d
+				delete(docs, n.pos)
+			}
+			return true
+		}, nil)
+	}
+
 	interp.roots = append(interp.roots, root)
 	return pkgName, root, err
 }
@@ -1,8 +1,10 @@
 package interp
 
 import (
+	"fmt"
 	"go/ast"
 	"go/build"
+	"go/build/constraint"
 	"go/parser"
 	"path"
 	"path/filepath"
@@ -19,11 +21,33 @@ func (interp *Interpreter) buildOk(ctx *build.Context, name, src string) (bool,
 	if err != nil {
 		return false, err
 	}
+
+	// A //go:build line takes precedence over // +build lines.
+	var expr constraint.Expr
 	for _, g := range f.Comments {
-		// in file, evaluate the AND of multiple line build constraints
-		for _, line := range strings.Split(strings.TrimSpace(g.Text()), "\n") {
-			if !buildLineOk(ctx, line) {
-				return false, nil
+		for _, c := range g.List {
+			if c.Pos() > f.Package || !constraint.IsGoBuild(c.Text) {
+				continue
+			}
+			if expr != nil {
+				return false, fmt.Errorf("%s: multiple //go:build comments", interp.fset.Position(c.Pos()))
+			}
+			if expr, err = constraint.Parse(c.Text); err != nil {
+				return false, fmt.Errorf("%s: %w", interp.fset.Position(c.Pos()), err)
+			}
+		}
+	}
+	if expr != nil {
+		if !expr.Eval(func(tag string) bool { return buildTagOk(ctx, tag) }) {
+			return false, nil
+		}
+	} else {
+		for _, g := range f.Comments {
+			// in file, evaluate the AND of multiple line build constraints
+			for _, line := range strings.Split(strings.TrimSpace(g.Text()), "\n") {
+				if !buildLineOk(ctx, line) {
+					return false, nil
+				}
 			}
 		}
 	}
@@ -72,6 +96,10 @@ func buildTagOk(ctx *build.Context, s string) (r bool) {
 		r = true
 	case s == ctx.GOARCH:
 		r = true
+	case s == "linux" && ctx.GOOS == "android", s == "darwin" && ctx.GOOS == "ios", s == "solaris" && ctx.GOOS == "illumos":
+		r = true
+	case s == "unix":
+		r = unixOS[ctx.GOOS]
 	case len(s) > 4 && s[:4] == "go1.":
 		if n, err := strconv.Atoi(s[4:]); err != nil {
 			r = false
@@ -113,20 +141,42 @@ func contains(tags []string, tag string) bool {
 	return false
 }
 
-// goMinorVersion returns the go minor version number.
+// goMinorVersion returns the go minor version number of the language
+// version of the build context, or 0 if it is unknown.
 func goMinorVersion(ctx *build.Context) int {
-	current := ctx.ReleaseTags[len(ctx.ReleaseTags)-1]
-
-	v := strings.Split(current, ".")
-	if len(v) < 2 {
-		panic("unsupported Go version: " + current)
+	if len(ctx.ReleaseTags) == 0 {
+		return 0
 	}
+	m, _ := parseGoVersion(ctx.ReleaseTags[len(ctx.ReleaseTags)-1])
+	return m
+}
 
-	m, err := strconv.Atoi(v[1])
+// parseGoVersion returns the minor version number of a Go 1 version, in the
+// forms "go1.22", "1.22", "go1.22.3" or "go1.23rc1".
+func parseGoVersion(v string) (int, error) {
+	major, minor, ok := strings.Cut(strings.TrimPrefix(v, "go"), ".")
+	if !ok || major != "1" {
+		return 0, fmt.Errorf("invalid Go version: %q", v)
+	}
+	i := 0
+	for i < len(minor) && '0' <= minor[i] && minor[i] <= '9' {
+		i++
+	}
+	m, err := strconv.Atoi(minor[:i])
 	if err != nil {
-		panic("unsupported Go version: " + current)
+		return 0, fmt.Errorf("invalid Go version: %q", v)
 	}
-	return m
+	return m, nil
+}
+
+// releaseTags returns the release tags of the Go 1 minor version m,
+// as in build.Context.
+func releaseTags(m int) []string {
+	tags := make([]string, m)
+	for i := range tags {
+		tags[i] = "go1." + strconv.Itoa(i+1)
+	}
+	return tags
 }
 
 // skipFile returns true if file should be skipped.
@@ -186,6 +236,22 @@ var knownOs = map[string]bool{
 	"windows":   true,
 }
 
+// unixOS is the set of operating systems satisfying the "unix" build tag.
+var unixOS = map[string]bool{
+	"aix":       true,
+	"android":   true,
+	"darwin":    true,
+	"dragonfly": true,
+	"freebsd":   true,
+	"hurd":      true,
+	"illumos":   true,
+	"ios":       true,
+	"linux":     true,
+	"netbsd":    true,
+	"openbsd":   true,
+	"solaris":   true,
+}
+
 var knownArch = map[string]bool{
 	"386":      true,
 	"amd64":    true,
@@ -201,3 +267,83 @@ var knownArch = map[string]bool{
 	"s390x":    true,
 	"wasm":     true,
 }
+
+// setTarget sets the operating system, architecture and Go version of the
+// build context, if not empty.
+func (o *opt) setTarget(goos, goarch, version string) error {
+	if goos != "" {
+		if !knownOs[goos] {
+			return fmt.Errorf("unsupported GOOS: %q", goos)
+		}
+		o.context.GOOS = goos
+	}
+	if goarch != "" {
+		if !knownArch[goarch] {
+			return fmt.Errorf("unsupported GOARCH: %q", goarch)
+		}
+		o.context.GOARCH = goarch
+	}
+	if version != "" {
+		m, err := parseGoVersion(version)
+		if err != nil {
+			return err
+		}
+		o.context.ReleaseTags = releaseTags(m)
+		o.lang, o.langSet = m, true
+	}
+	return nil
+}
+
+// defaultLang is the minor version number of the Go language version of the
+// packages not part of a module, if not set in Options.
+const defaultLang = 22
+
+// langVersion is the Go language version of a source package in a module.
+type langVersion struct {
+	minor int    // minor version number
+	file  string // go.mod file, or source file if set by its build constraint
+	build bool   // set by the //go:build line of file
+}
+
+// langMinor returns the minor version number of the Go language version of
+// the package importPath: the one of the go directive of its module, or the
+// one of the interpreter if it is not part of a module.
+func (interp *Interpreter) langMinor(importPath string) int {
+	if lv, ok := interp.langOf(importPath); ok {
+		return lv.minor
+	}
+	return interp.lang
+}
+
+// langOf returns the language version of the package importPath, if part
+// of a module. The language versions are updated during the compilation of
+// the package, which may be concurrent with the one of other packages.
+func (interp *Interpreter) langOf(importPath string) (langVersion, bool) {
+	interp.universe.mu.Lock()
+	defer interp.universe.mu.Unlock()
+	lv, ok := interp.langs[importPath]
+	return lv, ok
+}
+
+// requireLang returns an error at node n if the language version of the
+// package importPath is older than go1.m, which is required by what. The
+// error tells where the language version comes from.
+func (interp *Interpreter) requireLang(n *node, importPath string, m int, what string) error {
+	v := interp.langMinor(importPath)
+	if v >= m {
+		return nil
+	}
+	var from string
+	lv, ok := interp.langOf(importPath)
+	switch {
+	case ok && lv.build:
+		from = fmt.Sprintf("-lang was set to go1.%d by the //go:build line of %s", v, lv.file)
+	case ok:
+		from = fmt.Sprintf("-lang was set to go1.%d; check %s", v, lv.file)
+	case interp.langSet:
+		from = fmt.Sprintf("GoVersion was set to go1.%d in Options", v)
+	default:
+		from = fmt.Sprintf("the default language version is go1.%d; set GoVersion in Options", v)
+	}
+	return n.cfgErrorCodef(ErrUnsupported, "%s requires go1.%d or later (%s)", what, m, from)
+}
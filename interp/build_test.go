@@ -37,6 +37,17 @@ func TestBuildTag(t *testing.T) {
 		{"// +build foo", true},
 		{"// +build !foo", false},
 		{"// +build bar", false},
+		{"//go:build linux", true},
+		{"//go:build windows", false},
+		{"//go:build linux && amd64", true},
+		{"//go:build linux && !amd64", false},
+		{"//go:build (windows || linux) && go1.11", true},
+		{"//go:build go1.12", false},
+		{"//go:build unix", true},
+		{"//go:build foo && !bar", true},
+		{"//go:build windows\n// +build linux", false},
+		{"//go:build linux\n// +build windows", true},
+		{"// Doc.\n\n//go:build !linux", false},
 	}
 
 	i := New(Options{})
@@ -102,6 +113,16 @@ func Test_goMinorVersion(t *testing.T) {
 			}},
 			expected: 12,
 		},
+		{
+			desc:     "no release tags",
+			context:  build.Context{},
+			expected: 0,
+		},
+		{
+			desc:     "invalid",
+			context:  build.Context{ReleaseTags: []string{"devel"}},
+			expected: 0,
+		},
 		{
 			desc: "devel/beta/rc",
 			context: build.Context{ReleaseTags: []string{
@@ -122,3 +143,43 @@ func Test_goMinorVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestParseGoVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		minor   int
+		err     bool
+	}{
+		{version: "go1.22", minor: 22},
+		{version: "1.21", minor: 21},
+		{version: "go1.21.3", minor: 21},
+		{version: "go1.23rc1", minor: 23},
+		{version: "go1", err: true},
+		{version: "go2.1", err: true},
+		{version: "devel", err: true},
+		{version: "go1.x", err: true},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.version, func(t *testing.T) {
+			minor, err := parseGoVersion(test.version)
+			if (err != nil) != test.err {
+				t.Fatalf("got error %v, want error %v", err, test.err)
+			}
+			if minor != test.minor {
+				t.Errorf("got %v, want %v", minor, test.minor)
+			}
+		})
+	}
+}
+
+func TestDefaultLang(t *testing.T) {
+	// The language version does not follow the Go toolchain by default.
+	if m := New(Options{}).langMinor(mainID); m != defaultLang {
+		t.Errorf("got go1.%d, want go1.%d", m, defaultLang)
+	}
+	if m := New(Options{GoVersion: "go1.21"}).langMinor(mainID); m != 21 {
+		t.Errorf("got go1.%d, want go1.21", m)
+	}
+}
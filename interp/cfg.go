@@ -15,6 +15,7 @@ import (
 type cfgError struct {
 	*node
 	error
+	code ErrorCode
 }
 
 func (c *cfgError) Error() string { return c.error.Error() }
@@ -61,15 +62,29 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 		sc = interp.initScopePkg(importPath, pkgName)
 	}
 	check := typecheck{scope: sc}
+	assigned := assignCount{}
 	var initNodes []*node
 	var err error
 
 	baseName := filepath.Base(interp.fset.Position(root.pos).Filename)
 
+	// In check mode, errors are reported and the processing resumes at the
+	// next top level declaration, or statement in REPL mode, in the scope
+	// saved at its entry.
+	resume := interp.check != nil && (root.kind == fileStmt || root.kind == blockStmt && root.anc == nil)
+	var declScope *scope
+
 	root.Walk(func(n *node) bool {
 		// Pre-order processing
 		if err != nil {
-			return false
+			if !resume || n.anc != root {
+				return false
+			}
+			interp.report(err)
+			err, sc = nil, declScope
+		}
+		if n.anc == root {
+			declScope = sc
 		}
 		if n.scope == nil {
 			n.scope = sc
@@ -132,7 +147,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 				// RangeStmt. The following workaround is less elegant but ok.
 				c := n.anc.child[1]
 				if c != nil && c.typ != nil && isSendChan(c.typ) {
-					err = c.cfgErrorf("invalid operation: range %s receive from send-only channel", c.ident)
+					err = c.cfgErrorCodef(ErrInvalidOperation, "invalid operation: range %s receive from send-only channel", c.ident)
 					return false
 				}
 
@@ -166,7 +181,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 						switch typ.Kind() {
 						case reflect.Map:
 							n.anc.gen = rangeMap
-							ityp := valueTOf(reflect.TypeOf((*reflect.MapIter)(nil)))
+							ityp := valueTOf(interp.mapIterType())
 							sc.add(ityp)
 							ktyp = valueTOf(typ.Key())
 							vtyp = valueTOf(typ.Elem())
@@ -182,7 +197,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 						}
 					case mapT:
 						n.anc.gen = rangeMap
-						ityp := valueTOf(reflect.TypeOf((*reflect.MapIter)(nil)))
+						ityp := valueTOf(interp.mapIterType())
 						sc.add(ityp)
 						ktyp = o.typ.key
 						vtyp = o.typ.val
@@ -204,6 +219,9 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 						ktyp = sc.getType("int")
 						vtyp = o.typ.val
 					case intT:
+						if err = interp.requireLang(o, importPath, 22, "range over int"); err != nil {
+							return false
+						}
 						n.anc.gen = rangeInt
 						sc.add(sc.getType("int"))
 						ktyp = sc.getType("int")
@@ -229,7 +247,9 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 			n.val = nil
 			sc = sc.pushBloc()
 
-			if n.anc != nil && n.anc.kind == rangeStmt {
+			// Loop variables are defined per iteration since go1.22.
+			perIteration := interp.langMinor(importPath) >= 22
+			if n.anc != nil && n.anc.kind == rangeStmt && perIteration {
 				lk := n.child[0]
 				if rangek != nil {
 					lk.ident = rangek.ident
@@ -249,7 +269,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 					lv.gen = loopVarVal
 				}
 			}
-			if n.anc != nil && n.anc.kind == forStmt7 {
+			if n.anc != nil && n.anc.kind == forStmt7 && perIteration {
 				lv := n.child[0]
 				init := n.anc.child[0]
 				if init.kind == defineStmt && len(init.child) >= 2 && init.child[0].kind == identExpr {
@@ -292,7 +312,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 			label := n.child[0].ident
 			if sym, _, ok := sc.lookup(label); ok {
 				if sym.kind != labelSym {
-					err = n.child[0].cfgErrorf("label %s not defined", label)
+					err = n.child[0].cfgErrorCodef(ErrUndefined, "label %s not defined", label)
 					break
 				}
 				n.sym = sym
@@ -315,7 +335,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 					case n.child[0].ident == nilIdent:
 						typ = sc.getType("interface{}")
 					case !n.child[0].isType(sc):
-						err = n.cfgErrorf("%s is not a type", n.child[0].ident)
+						err = n.cfgErrorCodef(ErrTypeMismatch, "%s is not a type", n.child[0].ident)
 					default:
 						typ, err = nodeType(interp, sc, n.child[0])
 					}
@@ -345,7 +365,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 					return false
 				}
 				if !isChan(typ) {
-					err = n.cfgErrorf("invalid operation: receive from non-chan type")
+					err = n.cfgErrorCodef(ErrInvalidOperation, "invalid operation: receive from non-chan type")
 					return false
 				}
 				elem := chanElement(typ)
@@ -380,7 +400,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 					// A child indexExpr or indexListExpr is used for type parameters,
 					// it indicates an instanciated generic.
 					if n.child[0].kind != indexExpr && n.child[0].kind != indexListExpr {
-						err = n.cfgErrorf("undefined type")
+						err = n.cfgErrorCodef(ErrUndefined, "undefined type")
 						return false
 					}
 					t0, err1 := nodeType(interp, sc, n.child[0].child[0])
@@ -388,7 +408,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 						return false
 					}
 					if t0.cat != genericT {
-						err = n.cfgErrorf("undefined type")
+						err = n.cfgErrorCodef(ErrUndefined, "undefined type")
 						return false
 					}
 					// We have a composite literal of generic type, instantiate it.
@@ -441,7 +461,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 			// Propagate type to children, to handle implicit types
 			for _, c := range child {
 				if isBlank(c) {
-					err = n.cfgErrorf("cannot use _ as value")
+					err = n.cfgErrorCodef(ErrInvalidOperation, "cannot use _ as value")
 					return false
 				}
 				switch c.kind {
@@ -474,9 +494,17 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 			fallthrough
 
 		case funcDecl:
-			// Do not allow function declarations without body.
+			// Do not allow function declarations without body, unless
+			// implemented by the host.
 			if len(n.child) < 4 {
-				err = n.cfgErrorf("missing function body")
+				if _, ok := interp.asm[importPath][n.child[1].ident]; ok && !isMethod(n) {
+					return false
+				}
+				if importPath != mainID {
+					err = n.cfgErrorCodef(ErrUnsupported, "missing function body: assembly is not supported, %s can be provided in Exports[%q]", n.child[1].ident, importPath+"/"+asmName)
+				} else {
+					err = n.cfgErrorf("missing function body")
+				}
 				return false
 			}
 			n.val = n
@@ -549,7 +577,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 					// This may happen when instantiating generic methods.
 					s2, _, ok := sc.lookup(typ.id())
 					if !ok {
-						err = n.cfgErrorf("type not found: %s", typ.id())
+						err = n.cfgErrorCodef(ErrUndefined, "type not found: %s", typ.id())
 						break
 					}
 					typ = s2.typ
@@ -659,7 +687,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 		switch n.kind {
 		case addressExpr:
 			if isBlank(n.child[0]) {
-				err = n.cfgErrorf("cannot use _ as value")
+				err = n.cfgErrorCodef(ErrInvalidOperation, "cannot use _ as value")
 				break
 			}
 			wireChild(n)
@@ -695,7 +723,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 			// on the right is assigned to the nth operand on the left, so the number of
 			// nodes on the left and right sides must be equal
 			if n.nright > 1 && n.nright != n.nleft {
-				err = n.cfgErrorf("cannot assign %d values to %d variables", n.nright, n.nleft)
+				err = n.cfgErrorCodef(ErrInvalidOperation, "cannot assign %d values to %d variables", n.nright, n.nleft)
 				return
 			}
 
@@ -707,11 +735,11 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 				var level int
 
 				if dest.rval.IsValid() && !dest.rval.CanSet() && isConstType(dest.typ) {
-					err = n.cfgErrorf("cannot assign to %s (%s constant)", dest.rval, dest.typ.str)
+					err = n.cfgErrorCodef(ErrInvalidOperation, "cannot assign to %s (%s constant)", dest.rval, dest.typ.str)
 					break
 				}
 				if isBlank(src) {
-					err = n.cfgErrorf("cannot use _ as value")
+					err = n.cfgErrorCodef(ErrInvalidOperation, "cannot use _ as value")
 					break
 				}
 				if n.kind == defineStmt || (n.kind == assignStmt && dest.ident == "_") {
@@ -858,6 +886,8 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 					} else {
 						sc.iota++
 					}
+				} else if updateSym {
+					interp.propagateConst(sc, assigned, n, dest, src, sym)
 				}
 			}
 
@@ -884,7 +914,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 					l--
 				}
 				if r := lc.child[0].typ.numOut(); r != l {
-					err = n.cfgErrorf("assignment mismatch: %d variables but %s returns %d values", l, lc.child[0].name(), r)
+					err = n.cfgErrorCodef(ErrTypeMismatch, "assignment mismatch: %d variables but %s returns %d values", l, lc.child[0].name(), r)
 				}
 				if isBinCall(lc, sc) {
 					n.gen = nop
@@ -959,7 +989,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 					break
 				}
 			}
-			if c0.rval.IsValid() && c1.rval.IsValid() && (!isInterface(n.typ)) && constOp[n.action] != nil {
+			if c0.rval.IsValid() && c1.rval.IsValid() && (!isInterface(n.typ)) && constOp[n.action] != nil && !isFoldedShift(n) {
 				n.typ.TypeOf()       // Force compute of reflection type.
 				constOp[n.action](n) // Compute a constant result now rather than during exec.
 			}
@@ -990,7 +1020,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 
 		case indexExpr:
 			if isBlank(n.child[0]) {
-				err = n.cfgErrorf("cannot use _ as value")
+				err = n.cfgErrorCodef(ErrInvalidOperation, "cannot use _ as value")
 				break
 			}
 			wireChild(n)
@@ -1042,7 +1072,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 				name := t.id() + "[" + n.child[1].typ.id() + "]"
 				sym, _, ok := sc.lookup(name)
 				if !ok {
-					err = n.cfgErrorf("type not found: %s", name)
+					err = n.cfgErrorCodef(ErrUndefined, "type not found: %s", name)
 					return
 				}
 				n.gen = nop
@@ -1082,10 +1112,10 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 					l = typ2.Len()
 					n.gen = getIndexArray
 				} else {
-					err = n.cfgErrorf("type %v does not support indexing", typ)
+					err = n.cfgErrorCodef(ErrInvalidOperation, "type %v does not support indexing", typ)
 				}
 			default:
-				err = n.cfgErrorf("type is not an array, slice, string or map: %v", t.id())
+				err = n.cfgErrorCodef(ErrInvalidOperation, "type is not an array, slice, string or map: %v", t.id())
 			}
 
 			err = check.index(n.child[1], l)
@@ -1116,7 +1146,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 
 		case sendStmt:
 			if !isChan(n.child[0].typ) {
-				err = n.cfgErrorf("invalid operation: cannot send to non-channel %s", n.child[0].typ.id())
+				err = n.cfgErrorCodef(ErrInvalidOperation, "invalid operation: cannot send to non-channel %s", n.child[0].typ.id())
 				break
 			}
 			fallthrough
@@ -1137,7 +1167,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 				break
 			}
 			if !n.hasAnc(n.sym.node) {
-				err = n.cfgErrorf("invalid break label %s", n.child[0].ident)
+				err = n.cfgErrorCodef(ErrInvalidOperation, "invalid break label %s", n.child[0].ident)
 				break
 			}
 			n.tnext = n.sym.node
@@ -1148,7 +1178,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 				break
 			}
 			if !n.hasAnc(n.sym.node) {
-				err = n.cfgErrorf("invalid continue label %s", n.child[0].ident)
+				err = n.cfgErrorCodef(ErrInvalidOperation, "invalid continue label %s", n.child[0].ident)
 				break
 			}
 			n.tnext = n.sym.node.child[1].lastChild().start
@@ -1173,7 +1203,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 		case callExpr:
 			for _, c := range n.child {
 				if isBlank(c) {
-					err = n.cfgErrorf("cannot use _ as value")
+					err = n.cfgErrorCodef(ErrInvalidOperation, "cannot use _ as value")
 					return
 				}
 			}
@@ -1251,7 +1281,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 					case reflect.Array, reflect.Chan:
 						capConst(n)
 					default:
-						err = n.cfgErrorf("cap argument is not an array or channel")
+						err = n.cfgErrorCodef(ErrInvalidOperation, "cap argument is not an array or channel")
 					}
 					n.findex = notInFrame
 					n.gen = nop
@@ -1264,7 +1294,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 					case reflect.Array, reflect.Chan, reflect.String:
 						lenConst(n)
 					default:
-						err = n.cfgErrorf("len argument is not an array, channel or string")
+						err = n.cfgErrorCodef(ErrInvalidOperation, "len argument is not an array, channel or string")
 					}
 					n.findex = notInFrame
 					n.gen = nop
@@ -1280,11 +1310,11 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 				c1 := n.child[1]
 				switch len(n.child) {
 				case 1:
-					err = n.cfgErrorf("missing argument in conversion to %s", c0.typ.id())
+					err = n.cfgErrorCodef(ErrInvalidOperation, "missing argument in conversion to %s", c0.typ.id())
 				case 2:
 					err = check.conversion(c1, c0.typ)
 				default:
-					err = n.cfgErrorf("too many arguments in conversion to %s", c0.typ.id())
+					err = n.cfgErrorCodef(ErrInvalidOperation, "too many arguments in conversion to %s", c0.typ.id())
 				}
 				if err != nil {
 					break
@@ -1295,7 +1325,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 				case isInterface(c0.typ) && !c1.isNil():
 					// Convert to interface: just check that all required methods are defined by concrete type.
 					if !c1.typ.implements(c0.typ) {
-						err = n.cfgErrorf("type %v does not implement interface %v", c1.typ.id(), c0.typ.id())
+						err = n.cfgErrorCodef(ErrTypeMismatch, "type %v does not implement interface %v", c1.typ.id(), c0.typ.id())
 					}
 					// Convert type to interface while keeping a reference to the original concrete type.
 					// besides type, the node value remains preserved.
@@ -1408,6 +1438,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 				} else {
 					n.findex = notInFrame
 				}
+				interp.inlineCall(n, sc, importPath, pkgName)
 			}
 
 		case caseBody:
@@ -1481,7 +1512,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 
 		case fallthroughtStmt:
 			if n.anc.kind != caseBody {
-				err = n.cfgErrorf("fallthrough statement out of place")
+				err = n.cfgErrorCodef(ErrInvalidOperation, "fallthrough statement out of place")
 			}
 
 		case fileStmt:
@@ -1505,7 +1536,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 		case forStmt2: // for cond {}
 			cond, body := n.child[0], n.child[1]
 			if !isBool(cond.typ) {
-				err = cond.cfgErrorf("non-bool used as for condition")
+				err = cond.cfgErrorCodef(ErrTypeMismatch, "non-bool used as for condition")
 			}
 			if cond.rval.IsValid() {
 				// Condition is known at compile time, bypass test.
@@ -1524,7 +1555,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 		case forStmt3: // for init; cond; {}
 			init, cond, body := n.child[0], n.child[1], n.child[2]
 			if !isBool(cond.typ) {
-				err = cond.cfgErrorf("non-bool used as for condition")
+				err = cond.cfgErrorCodef(ErrTypeMismatch, "non-bool used as for condition")
 			}
 			n.start = init.start
 			if cond.rval.IsValid() {
@@ -1553,7 +1584,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 		case forStmt5: // for ; cond; post {}
 			cond, post, body := n.child[0], n.child[1], n.child[2]
 			if !isBool(cond.typ) {
-				err = cond.cfgErrorf("non-bool used as for condition")
+				err = cond.cfgErrorCodef(ErrTypeMismatch, "non-bool used as for condition")
 			}
 			if cond.rval.IsValid() {
 				// Condition is known at compile time, bypass test.
@@ -1581,7 +1612,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 		case forStmt7: // for init; cond; post {}
 			init, cond, post, body := n.child[0], n.child[1], n.child[2], n.child[3]
 			if !isBool(cond.typ) {
-				err = cond.cfgErrorf("non-bool used as for condition")
+				err = cond.cfgErrorCodef(ErrTypeMismatch, "non-bool used as for condition")
 			}
 			n.start = init.start
 			body.start = body.child[0] // loopvar
@@ -1645,7 +1676,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 				// retry with the filename, in case ident is a package name.
 				sym, level, found = sc.lookup(filepath.Join(n.ident, baseName))
 				if !found {
-					err = n.cfgErrorf("undefined: %s", n.ident)
+					err = n.cfgErrorCodef(ErrUndefined, "undefined: %s", n.ident)
 					break
 				}
 			}
@@ -1668,7 +1699,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 					n.rval = sym.rval
 				case sym.kind == bltnSym:
 					if n.anc.kind != callExpr {
-						err = n.cfgErrorf("use of builtin %s not in function call", n.ident)
+						err = n.cfgErrorCodef(ErrInvalidOperation, "use of builtin %s not in function call", n.ident)
 					}
 				}
 			}
@@ -1679,7 +1710,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 		case ifStmt0: // if cond {}
 			cond, tbody := n.child[0], n.child[1]
 			if !isBool(cond.typ) {
-				err = cond.cfgErrorf("non-bool used as if condition")
+				err = cond.cfgErrorCodef(ErrTypeMismatch, "non-bool used as if condition")
 			}
 			if cond.rval.IsValid() {
 				// Condition is known at compile time, bypass test.
@@ -1697,7 +1728,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 		case ifStmt1: // if cond {} else {}
 			cond, tbody, fbody := n.child[0], n.child[1], n.child[2]
 			if !isBool(cond.typ) {
-				err = cond.cfgErrorf("non-bool used as if condition")
+				err = cond.cfgErrorCodef(ErrTypeMismatch, "non-bool used as if condition")
 			}
 			if cond.rval.IsValid() {
 				// Condition is known at compile time, bypass test and the useless branch.
@@ -1718,7 +1749,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 		case ifStmt2: // if init; cond {}
 			init, cond, tbody := n.child[0], n.child[1], n.child[2]
 			if !isBool(cond.typ) {
-				err = cond.cfgErrorf("non-bool used as if condition")
+				err = cond.cfgErrorCodef(ErrTypeMismatch, "non-bool used as if condition")
 			}
 			n.start = init.start
 			if cond.rval.IsValid() {
@@ -1739,7 +1770,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 		case ifStmt3: // if init; cond {} else {}
 			init, cond, tbody, fbody := n.child[0], n.child[1], n.child[2], n.child[3]
 			if !isBool(cond.typ) {
-				err = cond.cfgErrorf("non-bool used as if condition")
+				err = cond.cfgErrorCodef(ErrTypeMismatch, "non-bool used as if condition")
 			}
 			n.start = init.start
 			if cond.rval.IsValid() {
@@ -1760,14 +1791,14 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 
 		case keyValueExpr:
 			if isBlank(n.child[1]) {
-				err = n.cfgErrorf("cannot use _ as value")
+				err = n.cfgErrorCodef(ErrInvalidOperation, "cannot use _ as value")
 				break
 			}
 			wireChild(n)
 
 		case landExpr:
 			if isBlank(n.child[0]) || isBlank(n.child[1]) {
-				err = n.cfgErrorf("cannot use _ as value")
+				err = n.cfgErrorCodef(ErrInvalidOperation, "cannot use _ as value")
 				break
 			}
 			n.start = n.child[0].start
@@ -1779,10 +1810,11 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 			if n.start.action == aNop {
 				n.start.gen = branch
 			}
+			interp.foldLogical(n)
 
 		case lorExpr:
 			if isBlank(n.child[0]) || isBlank(n.child[1]) {
-				err = n.cfgErrorf("cannot use _ as value")
+				err = n.cfgErrorCodef(ErrInvalidOperation, "cannot use _ as value")
 				break
 			}
 			n.start = n.child[0].start
@@ -1794,6 +1826,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 			if n.start.action == aNop {
 				n.start.gen = branch
 			}
+			interp.foldLogical(n)
 
 		case parenExpr:
 			wireChild(n)
@@ -1828,12 +1861,12 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 
 		case returnStmt:
 			if len(n.child) > sc.def.typ.numOut() {
-				err = n.cfgErrorf("too many arguments to return")
+				err = n.cfgErrorCodef(ErrInvalidOperation, "too many arguments to return")
 				break
 			}
 			for _, c := range n.child {
 				if isBlank(c) {
-					err = n.cfgErrorf("cannot use _ as value")
+					err = n.cfgErrorCodef(ErrInvalidOperation, "cannot use _ as value")
 					return
 				}
 			}
@@ -1844,7 +1877,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 					nret = n.child[0].child[0].typ.numOut()
 				}
 				if nret < sc.def.typ.numOut() {
-					err = n.cfgErrorf("not enough arguments to return")
+					err = n.cfgErrorCodef(ErrInvalidOperation, "not enough arguments to return")
 					break
 				}
 			}
@@ -1860,7 +1893,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 				// TODO(mpl): move any of that code to typecheck?
 				c.typ.node = c
 				if !c.typ.assignableTo(typ) {
-					err = c.cfgErrorf("cannot use %v (type %v) as type %v in return argument", c.ident, c.typ.cat, typ.cat)
+					err = c.cfgErrorCodef(ErrTypeMismatch, "cannot use %v (type %v) as type %v in return argument", c.ident, c.typ.cat, typ.cat)
 					return
 				}
 				if c.typ.cat == nilT {
@@ -1874,7 +1907,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 			n.typ = n.child[0].typ
 			n.recv = n.child[0].recv
 			if n.typ == nil {
-				err = n.cfgErrorf("undefined type")
+				err = n.cfgErrorCodef(ErrUndefined, "undefined type")
 				break
 			}
 			switch {
@@ -1896,7 +1929,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 					n.action = aGetSym
 					n.gen = nop
 				} else {
-					err = n.cfgErrorf("package %s \"%s\" has no symbol %s", n.child[0].ident, pkg, name)
+					err = n.cfgErrorCodef(ErrUndefined, "package %s \"%s\" has no symbol %s", n.child[0].ident, pkg, name)
 				}
 			case n.typ.cat == srcPkgT:
 				pkg, name := n.child[0].sym.typ.path, n.child[1].ident
@@ -1914,7 +1947,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 					n.recv = sym.recv
 					n.rval = sym.rval
 				} else {
-					err = n.cfgErrorf("undefined selector: %s.%s", pkg, name)
+					err = n.cfgErrorCodef(ErrUndefined, "undefined selector: %s.%s", pkg, name)
 				}
 			case isStruct(n.typ) || isInterfaceSrc(n.typ):
 				// Find a matching field.
@@ -2036,7 +2069,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 
 		case starExpr:
 			if isBlank(n.child[0]) {
-				err = n.cfgErrorf("cannot use _ as value")
+				err = n.cfgErrorCodef(ErrInvalidOperation, "cannot use _ as value")
 				break
 			}
 			switch {
@@ -2077,7 +2110,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 				for _, t := range c.child[:len(c.child)-1] {
 					tid := t.typ.id()
 					if usedCase[tid] {
-						err = c.cfgErrorf("duplicate case %s in type switch", t.ident)
+						err = c.cfgErrorCodef(ErrRedeclared, "duplicate case %s in type switch", t.ident)
 						return
 					}
 					usedCase[tid] = true
@@ -2107,7 +2140,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 
 					if i < l-1 && len(body.child) > 0 && body.lastChild().kind == fallthroughtStmt {
 						if n.kind == typeSwitch {
-							err = body.lastChild().cfgErrorf("cannot fallthrough in type switch")
+							err = body.lastChild().cfgErrorCodef(ErrInvalidOperation, "cannot fallthrough in type switch")
 						}
 						if len(clauses[i+1].child) == 0 {
 							body.tnext = n // Fallthrough to next with empty body, just exit.
@@ -2192,7 +2225,7 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 			wireChild(n)
 			c0, c1 := n.child[0], n.child[1]
 			if isBlank(c0) || isBlank(c1) {
-				err = n.cfgErrorf("cannot use _ as value")
+				err = n.cfgErrorCodef(ErrInvalidOperation, "cannot use _ as value")
 				break
 			}
 			if c1.typ == nil {
@@ -2299,6 +2332,10 @@ func (interp *Interpreter) cfg(root *node, sc *scope, importPath, pkgName string
 		}
 	})
 
+	if err != nil && resume {
+		interp.report(err)
+		err = nil
+	}
 	if sc != interp.universe {
 		sc.pop()
 	}
@@ -2348,7 +2385,7 @@ func compDefineX(sc *scope, n *node) error {
 			l--
 		}
 		if len(types) != l {
-			return n.cfgErrorf("assignment mismatch: %d variables but %s returns %d values", l, src.child[0].name(), len(types))
+			return n.cfgErrorCodef(ErrTypeMismatch, "assignment mismatch: %d variables but %s returns %d values", l, src.child[0].name(), len(types))
 		}
 		if isBinCall(src, sc) {
 			n.gen = nop
@@ -2379,7 +2416,7 @@ func compDefineX(sc *scope, n *node) error {
 		}
 
 	default:
-		return n.cfgErrorf("unsupported assign expression")
+		return n.cfgErrorCodef(ErrUnsupported, "unsupported assign expression")
 	}
 
 	// Handle redeclarations: find out new symbols vs existing ones.
@@ -2391,7 +2428,7 @@ func compDefineX(sc *scope, n *node) error {
 			continue
 		}
 		if _, found := symIsNew[id]; found {
-			return n.cfgErrorf("%s repeated on left side of :=", id)
+			return n.cfgErrorCodef(ErrInvalidOperation, "%s repeated on left side of :=", id)
 		}
 		// A new symbol doesn't exist in current scope. Upper scopes are not
 		// taken into accout here, as a new symbol can shadow an existing one.
@@ -2443,13 +2480,18 @@ func childPos(n *node) int {
 }
 
 func (n *node) cfgErrorf(format string, a ...interface{}) *cfgError {
+	return n.cfgErrorCodef(ErrOther, format, a...)
+}
+
+// cfgErrorCodef returns a compile error at node n, of category code.
+func (n *node) cfgErrorCodef(code ErrorCode, format string, a ...interface{}) *cfgError {
 	pos := n.interp.fset.Position(n.pos)
 	posString := n.interp.fset.Position(n.pos).String()
 	if pos.Filename == DefaultSourceName {
 		posString = strings.TrimPrefix(posString, DefaultSourceName+":")
 	}
 	a = append([]interface{}{posString}, a...)
-	return &cfgError{n, fmt.Errorf("%s: "+format, a...)}
+	return &cfgError{n, fmt.Errorf("%s: "+format, a...), code}
 }
 
 func genRun(nod *node) error {
@@ -2465,9 +2507,14 @@ func genRun(nod *node) error {
 		case funcType:
 			if len(n.anc.child) == 4 {
 				// function body entry point
+				setUnboxed(n.anc)
 				setExec(n.anc.child[3].start)
 			}
 			// continue in function body as there may be inner function definitions
+		case funcDecl:
+			if n.interp.bytecode && n.interp.debugger == nil && n.vm == nil {
+				compileVM(n)
+			}
 		case constDecl, varDecl:
 			setExec(n.start)
 			return false
@@ -2541,7 +2588,7 @@ func genGlobalVarDecl(nodes []*node, sc *scope) (*node, error) {
 	}
 
 	if len(revisit) > 0 {
-		return nil, revisit[0].cfgErrorf("variable definition loop")
+		return nil, revisit[0].cfgErrorCodef(ErrDefinitionLoop, "variable definition loop")
 	}
 	wireChild(varNode)
 	return varNode, nil
@@ -3013,7 +3060,7 @@ func compositeGenerator(n *node, typ *itype, rtyp reflect.Type) (gen bltnGenerat
 		case reflect.Slice, reflect.Array:
 			gen = compositeBinSlice
 		default:
-			log.Panic(n.cfgErrorf("compositeGenerator not implemented for type kind: %s", k))
+			log.Panic(n.cfgErrorCodef(ErrUnsupported, "compositeGenerator not implemented for type kind: %s", k))
 		}
 	}
 	return gen
@@ -3042,7 +3089,7 @@ func matchSelectorMethod(sc *scope, n *node) (err error) {
 				n.gen = getPtrIndexSeq
 				break
 			}
-			err = n.cfgErrorf("undefined method: %s", name)
+			err = n.cfgErrorCodef(ErrUndefined, "undefined method: %s", name)
 		case n.typ.TypeOf().Kind() == reflect.Struct:
 			if field, ok := n.typ.rtype.FieldByName(name); ok {
 				n.typ = valueTOf(field.Type)
@@ -3062,7 +3109,7 @@ func matchSelectorMethod(sc *scope, n *node) (err error) {
 				n.action = aGetMethod
 				break
 			}
-			err = n.cfgErrorf("undefined method: %s", name)
+			err = n.cfgErrorCodef(ErrUndefined, "undefined method: %s", name)
 		}
 		return err
 	}
@@ -3086,7 +3133,7 @@ func matchSelectorMethod(sc *scope, n *node) (err error) {
 			n.val = field.Index
 			n.gen = getPtrIndexSeq
 		} else {
-			err = n.cfgErrorf("undefined selector: %s", name)
+			err = n.cfgErrorCodef(ErrUndefined, "undefined selector: %s", name)
 		}
 		return err
 	}
@@ -3134,7 +3181,7 @@ func matchSelectorMethod(sc *scope, n *node) (err error) {
 		return nil
 	}
 
-	return n.cfgErrorf("undefined selector: %s", name)
+	return n.cfgErrorCodef(ErrUndefined, "undefined selector: %s", name)
 }
 
 // arrayTypeLen returns the node's array length. If the expression is an
@@ -3164,10 +3211,10 @@ func arrayTypeLen(n *node, sc *scope) (int, error) {
 				// Key is defined by a symbol which must be a constant integer.
 				sym, _, ok := sc.lookup(c0.ident)
 				if !ok {
-					return 0, c0.cfgErrorf("undefined: %s", c0.ident)
+					return 0, c0.cfgErrorCodef(ErrUndefined, "undefined: %s", c0.ident)
 				}
 				if sym.kind != constSym {
-					return 0, c0.cfgErrorf("non-constant array bound %q", c0.ident)
+					return 0, c0.cfgErrorCodef(ErrInvalidOperation, "non-constant array bound %q", c0.ident)
 				}
 				r = int(vInt(sym.rval))
 			} else {
@@ -3177,7 +3224,7 @@ func arrayTypeLen(n *node, sc *scope) (int, error) {
 				}
 				cv, ok := c0.rval.Interface().(constant.Value)
 				if !ok {
-					return 0, c0.cfgErrorf("non-constant expression")
+					return 0, c0.cfgErrorCodef(ErrInvalidOperation, "non-constant expression")
 				}
 				r = constToInt(cv)
 			}
@@ -0,0 +1,94 @@
+package interp
+
+import (
+	"reflect"
+	"runtime"
+	"unsafe"
+)
+
+// cgoPath is the import path of the virtual C package, which the host can
+// provide to the interpreted code as Exports{"C/C": symbols}.
+const cgoPath = "C"
+
+// fixC completes the symbols of the virtual C package p, registered by the
+// host, with the basic C types and the helpers provided by cgo, unless
+// already defined. The C types are the Go types of the same size, so the
+// host functions can use Go types for their parameters. The memory of
+// C.CString, C.CBytes and C.malloc is allocated by Go, and C.free does nothing.
+func fixC(p map[string]reflect.Value) {
+	long, ulong := reflect.ValueOf((*int64)(nil)), reflect.ValueOf((*uint64)(nil))
+	if unsafe.Sizeof(uintptr(0)) == 4 || runtime.GOOS == "windows" {
+		long, ulong = reflect.ValueOf((*int32)(nil)), reflect.ValueOf((*uint32)(nil))
+	}
+	for k, v := range map[string]reflect.Value{
+		"char":      reflect.ValueOf((*int8)(nil)),
+		"schar":     reflect.ValueOf((*int8)(nil)),
+		"uchar":     reflect.ValueOf((*uint8)(nil)),
+		"short":     reflect.ValueOf((*int16)(nil)),
+		"ushort":    reflect.ValueOf((*uint16)(nil)),
+		"int":       reflect.ValueOf((*int32)(nil)),
+		"uint":      reflect.ValueOf((*uint32)(nil)),
+		"long":      long,
+		"ulong":     ulong,
+		"longlong":  reflect.ValueOf((*int64)(nil)),
+		"ulonglong": reflect.ValueOf((*uint64)(nil)),
+		"float":     reflect.ValueOf((*float32)(nil)),
+		"double":    reflect.ValueOf((*float64)(nil)),
+		"size_t":    reflect.ValueOf((*uintptr)(nil)),
+		"uintptr_t": reflect.ValueOf((*uintptr)(nil)),
+
+		"CString":   reflect.ValueOf(cString),
+		"CBytes":    reflect.ValueOf(cBytes),
+		"GoString":  reflect.ValueOf(goString),
+		"GoStringN": reflect.ValueOf(goStringN),
+		"GoBytes":   reflect.ValueOf(goBytes),
+		"malloc":    reflect.ValueOf(func(n uintptr) unsafe.Pointer { return unsafe.Pointer(&make([]byte, max(n, 1))[0]) }),
+		"free":      reflect.ValueOf(func(unsafe.Pointer) {}),
+	} {
+		if _, ok := p[k]; !ok {
+			p[k] = v
+		}
+	}
+}
+
+// cString returns a copy of s terminated by a null byte, as C.CString.
+func cString(s string) *int8 {
+	b := make([]byte, len(s)+1)
+	copy(b, s)
+	return (*int8)(unsafe.Pointer(&b[0]))
+}
+
+// cBytes returns a copy of b, as C.CBytes.
+func cBytes(b []byte) unsafe.Pointer {
+	c := make([]byte, max(len(b), 1))
+	copy(c, b)
+	return unsafe.Pointer(&c[0])
+}
+
+// goString returns the string of the null terminated bytes at p, as C.GoString.
+func goString(p *int8) string {
+	if p == nil {
+		return ""
+	}
+	n := 0
+	for *(*int8)(unsafe.Add(unsafe.Pointer(p), n)) != 0 {
+		n++
+	}
+	return string(unsafe.Slice((*byte)(unsafe.Pointer(p)), n))
+}
+
+// goStringN returns the string of the n bytes at p, as C.GoStringN.
+func goStringN(p *int8, n int32) string {
+	if p == nil || n <= 0 {
+		return ""
+	}
+	return string(unsafe.Slice((*byte)(unsafe.Pointer(p)), n))
+}
+
+// goBytes returns a copy of the n bytes at p, as C.GoBytes.
+func goBytes(p unsafe.Pointer, n int32) []byte {
+	if p == nil || n <= 0 {
+		return []byte{}
+	}
+	return append([]byte{}, unsafe.Slice((*byte)(p), n)...)
+}
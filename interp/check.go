@@ -0,0 +1,195 @@
+package interp
+
+import (
+	"errors"
+	"fmt"
+	"go/scanner"
+	"io/fs"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Diagnostics is the list of errors found by Check, sorted by position.
+type Diagnostics []*CompileError
+
+func (d Diagnostics) Error() string {
+	s := make([]string, len(d))
+	for i, e := range d {
+		s[i] = e.Error()
+	}
+	return strings.Join(s, "\n")
+}
+
+// checker collects the diagnostics of a compilation in check mode.
+type checker struct {
+	diags Diagnostics
+}
+
+// Check parses and compiles the Go file or package located at path, as
+// EvalPath does, including the source packages it imports, but executes
+// nothing: neither package initializations nor main.
+//
+// The code is compiled in a separate interpreter, with the options and the
+// packages imported by interp, but not its main package, so interp is left
+// unchanged, and may be used concurrently. The code checked last can be
+// queried with IdentAt and Completions.
+//
+// An executable script file, starting with "#!", is compiled in REPL mode.
+//
+// Instead of stopping at the first error, the compilation resumes at the
+// next file, or at the next top level declaration in a file, so all the
+// errors found are returned as Diagnostics, or nil if the code is correct.
+// Other errors, such as an unreadable path, are returned as is.
+func (interp *Interpreter) Check(path string) (err error) {
+	ci := interp.checker()
+	c := ci.check
+	defer func() {
+		if r := recover(); r != nil {
+			c.add(ci, fmt.Errorf("%v", r))
+			err = nil
+		}
+		if err == nil {
+			err = c.result()
+		}
+		interp.mutex.Lock()
+		interp.checked = ci
+		interp.mutex.Unlock()
+	}()
+
+	if !isFile(ci.opt.filesystem, path) {
+		if _, err = ci.importSrc(mainID, path, NoTest); err != nil && !ci.report(err) {
+			return err
+		}
+		return nil
+	}
+
+	b, err := fs.ReadFile(ci.filesystem, path)
+	if err != nil {
+		return err
+	}
+	src, inc := string(b), false
+	if strings.HasPrefix(src, "#!") {
+		// An executable script is compiled in REPL mode, as by yaegi run.
+		src, inc = strings.Replace(src, "#!", "//", 1), true
+	}
+	if _, err = ci.compileSrc(src, path, inc); err != nil {
+		ci.report(err)
+	}
+	return nil
+}
+
+// checker returns a new interpreter in check mode, with the options, the
+// binary packages and the source packages imported by interp.
+func (interp *Interpreter) checker() *Interpreter {
+	interp.mutex.RLock()
+	binPkg := make(Exports, len(interp.binPkg))
+	for k, v := range interp.binPkg {
+		binPkg[k] = v
+	}
+	scopes := map[string]*scope{}
+	for k, v := range interp.scopes {
+		if k != mainID {
+			scopes[k] = v
+		}
+	}
+	srcPkg := imports{}
+	for k, v := range interp.srcPkg {
+		if k != mainID {
+			srcPkg[k] = v
+		}
+	}
+	pkgNames := map[string]string{}
+	for k, v := range interp.pkgNames {
+		pkgNames[k] = v
+	}
+	langs := map[string]langVersion{}
+	for k, v := range interp.langs {
+		langs[k] = v
+	}
+	ci := &Interpreter{
+		name:     interp.name,
+		opt:      interp.opt,
+		fset:     interp.fset,
+		binPkg:   binPkg,
+		asm:      interp.asm,
+		rdir:     map[string]bool{},
+		mapTypes: interp.mapTypes,
+		frame:    newFrame(nil, 0, 0),
+		universe: interp.universe,
+		scopes:   scopes,
+		srcPkg:   srcPkg,
+		pkgNames: pkgNames,
+		langs:    langs,
+		done:     make(chan struct{}),
+		generic:  map[string]*node{},
+		hooks:    interp.hooks,
+		sources:  interp.sources,
+		check:    &checker{},
+	}
+	interp.mutex.RUnlock()
+	ci.frame.interp = ci
+	ci.compiling = &sync.Mutex{}
+	// Optimizations are disabled, as they rewrite the code which can then
+	// be queried with IdentAt.
+	ci.noRun, ci.opt.noOpt = true, true
+	return ci
+}
+
+// lastChecked returns the interpreter of the last call to Check, or nil.
+func (interp *Interpreter) lastChecked() *Interpreter {
+	interp.mutex.RLock()
+	defer interp.mutex.RUnlock()
+	return interp.checked
+}
+
+// report records err as a diagnostic and returns true in check mode,
+// or returns false otherwise.
+func (interp *Interpreter) report(err error) bool {
+	if interp.check == nil {
+		return false
+	}
+	interp.check.add(interp, err)
+	return true
+}
+
+// add records the diagnostics of compilation error err.
+func (c *checker) add(interp *Interpreter, err error) {
+	var el scanner.ErrorList
+	if errors.As(err, &el) {
+		// Keep only the first syntax error of each line, as follow-up
+		// errors on the same line are mostly noise.
+		el.RemoveMultiples()
+		for _, e := range el {
+			c.diags = append(c.diags, interp.syntaxError(e))
+		}
+		return
+	}
+	c.diags = append(c.diags, interp.compileErrors(err)...)
+}
+
+// result returns the diagnostics sorted by position and without duplicates,
+// or nil if there are none.
+func (c *checker) result() error {
+	if len(c.diags) == 0 {
+		return nil
+	}
+	sort.SliceStable(c.diags, func(i, j int) bool {
+		a, b := c.diags[i].Pos, c.diags[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	res := Diagnostics{}
+	for i, d := range c.diags {
+		if i > 0 && d.Pos == c.diags[i-1].Pos && d.Msg == c.diags[i-1].Msg {
+			continue
+		}
+		res = append(res, d)
+	}
+	return res
+}
@@ -0,0 +1,114 @@
+package interp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCheck(t *testing.T) {
+	fsys := fstest.MapFS{
+		"_pkg/src/guthib.com/p/a.go": {Data: []byte(`package p
+
+import "guthib.com/q"
+
+var initialized = setup()
+
+func setup() bool { panic("executed") }
+
+func A() int { return "a" }
+
+func B() int { return undefinedB }
+
+func C() int { return q.V + 1 }
+`)},
+		"_pkg/src/guthib.com/p/b.go": {Data: []byte(`package p
+
+func D() {}
+
+func E() int { return 1 + }
+`)},
+		"_pkg/src/guthib.com/q/q.go": {Data: []byte(`package q
+
+var V = undefinedV
+
+func init() { panic("executed") }
+`)},
+		"_pkg/src/guthib.com/ok/ok.go": {Data: []byte(`package ok
+
+func init() { panic("executed") }
+
+func F() int { return 1 }
+`)},
+		"main.go": {Data: []byte(`package main
+
+func main() { panic("executed") }
+
+func f() string { return 1 }
+`)},
+	}
+	newInterp := func() *Interpreter {
+		return New(Options{GoPath: "./_pkg", SourcecodeFilesystem: fsys})
+	}
+
+	if err := newInterp().Check("guthib.com/ok"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err := newInterp().Check("guthib.com/p")
+	var diags Diagnostics
+	if !errors.As(err, &diags) {
+		t.Fatalf("got %v, want diagnostics", err)
+	}
+	want := []string{
+		"_pkg/src/guthib.com/p/a.go:9:23: cannot use",
+		"_pkg/src/guthib.com/p/a.go:11:23: undefined: undefinedB",
+		"_pkg/src/guthib.com/p/a.go:13:23: undefined selector: guthib.com/q.V",
+		"_pkg/src/guthib.com/p/b.go:5:27: expected operand",
+		"_pkg/src/guthib.com/q/q.go:3:5: constant definition loop",
+	}
+	if len(diags) != len(want) {
+		t.Fatalf("got %d diagnostics, want %d:\n%v", len(diags), len(want), err)
+	}
+	codes := []ErrorCode{ErrTypeMismatch, ErrUndefined, ErrUndefined, ErrSyntax, ErrDefinitionLoop}
+	for i, d := range diags {
+		if !strings.HasPrefix(d.Error(), want[i]) {
+			t.Errorf("got %q, want prefix %q", d.Error(), want[i])
+		}
+		if d.Code != codes[i] {
+			t.Errorf("%s: got code %v, want %v", d.Pos, d.Code, codes[i])
+		}
+	}
+
+	err = newInterp().Check("main.go")
+	if !errors.As(err, &diags) || len(diags) != 1 || diags[0].Pos.Line != 5 {
+		t.Errorf("unexpected result: %v", err)
+	}
+}
+
+func TestCheckLeavesInterpreter(t *testing.T) {
+	fsys := fstest.MapFS{
+		"_pkg/src/guthib.com/foo/foo.go": {Data: []byte(`package foo
+
+var V int
+
+func init() { V = 42 }
+`)},
+	}
+	i := New(Options{GoPath: "./_pkg", SourcecodeFilesystem: fsys})
+	if err := i.Check("guthib.com/foo"); err != nil {
+		t.Fatal(err)
+	}
+	// The package checked is not registered, and is initialized on import.
+	if _, err := i.Eval(`import "guthib.com/foo"`); err != nil {
+		t.Fatal(err)
+	}
+	v, err := i.Eval("foo.V")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := v.Interface(); got != 42 {
+		t.Errorf("got %v, want 42", got)
+	}
+}
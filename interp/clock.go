@@ -0,0 +1,208 @@
+package interp
+
+import (
+	"go/token"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is the virtual clock used by the interpreted code in place of the
+// system clock when the interpreter is deterministic, see Options.Deterministic.
+//
+// The clock only moves when advanced by the host, or when all the goroutines
+// of the interpreter are blocked, in which case it jumps to the expiration of
+// the next timer.
+type Clock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*clockTimer // active timers, by expiration, then by order of start
+	sched  *scheduler
+}
+
+// clockTimer is a timer of the virtual clock.
+type clockTimer struct {
+	clock  *Clock
+	when   time.Time
+	period time.Duration // for tickers
+	c      chan time.Time
+	f      func()
+}
+
+// Now returns the current time of the clock.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d, firing in order the timers expiring.
+// The functions of the expired timers started by AfterFunc are run once
+// the interpreted goroutines already running are blocked or done.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	end := c.now.Add(d)
+	for len(c.timers) > 0 && !c.timers[0].when.After(end) {
+		c.fire()
+	}
+	c.now = end
+	c.mu.Unlock()
+
+	c.sched.progress()
+	select {
+	case c.sched.wake <- struct{}{}:
+	default:
+	}
+}
+
+// next advances the clock to the next timer, and fires it. It returns false
+// if there is no timer.
+func (c *Clock) next() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.timers) == 0 {
+		return false
+	}
+	for when := c.timers[0].when; len(c.timers) > 0 && c.timers[0].when.Equal(when); {
+		c.fire()
+	}
+	c.sched.progress()
+	return true
+}
+
+// fire fires the first timer, with c.mu locked.
+func (c *Clock) fire() {
+	t := c.timers[0]
+	c.timers = c.timers[1:]
+	if t.when.After(c.now) {
+		c.now = t.when
+	}
+	if t.c != nil {
+		select {
+		case t.c <- c.now:
+		default: // drop the tick, as time.Ticker
+		}
+	}
+	if t.f != nil {
+		f := t.f
+		_, exit := c.sched.goroutines.start(nil, "time.AfterFunc", token.NoPos)
+		c.sched.spawn(func() {
+			defer exit()
+			f()
+		})
+	}
+	if t.period > 0 {
+		t.when = t.when.Add(t.period)
+		c.add(t)
+	}
+}
+
+// add adds the active timer t, with c.mu locked.
+func (c *Clock) add(t *clockTimer) {
+	i := sort.Search(len(c.timers), func(i int) bool { return c.timers[i].when.After(t.when) })
+	c.timers = append(c.timers, nil)
+	copy(c.timers[i+1:], c.timers[i:])
+	c.timers[i] = t
+}
+
+// remove removes the timer t, with c.mu locked. It returns true if t was active.
+func (c *Clock) remove(t *clockTimer) bool {
+	for i, x := range c.timers {
+		if x == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// newTimer returns a timer expiring after d, then every period if not zero,
+// sending the time on its channel, or else running f.
+func (c *Clock) newTimer(d, period time.Duration, f func()) *clockTimer {
+	t := &clockTimer{clock: c, period: period, f: f}
+	if f == nil {
+		t.c = make(chan time.Time, 1)
+	}
+	c.mu.Lock()
+	t.when = c.now.Add(d)
+	c.add(t)
+	c.mu.Unlock()
+	return t
+}
+
+// reset stops t, discarding a pending value, and restarts it to expire after d
+// if d is not negative. It returns true if t was active.
+func (t *clockTimer) reset(d time.Duration) bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	active := c.remove(t)
+	if t.c != nil {
+		select {
+		case <-t.c:
+		default:
+		}
+	}
+	if d >= 0 {
+		t.when = c.now.Add(d)
+		c.add(t)
+	}
+	return active
+}
+
+// Timer replaces time.Timer in deterministic interpreters.
+type Timer struct {
+	C <-chan time.Time
+	t *clockTimer
+}
+
+// Stop prevents the timer from firing, as time.Timer.Stop.
+func (t *Timer) Stop() bool { return t.t.reset(-1) }
+
+// Reset changes the timer to expire after d, as time.Timer.Reset.
+func (t *Timer) Reset(d time.Duration) bool {
+	if d < 0 {
+		d = 0
+	}
+	return t.t.reset(d)
+}
+
+// Ticker replaces time.Ticker in deterministic interpreters.
+type Ticker struct {
+	C <-chan time.Time
+	t *clockTimer
+}
+
+// Stop turns off the ticker, as time.Ticker.Stop.
+func (t *Ticker) Stop() { t.t.reset(-1) }
+
+// Reset stops the ticker and resets its period to d, as time.Ticker.Reset.
+func (t *Ticker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("non-positive interval for Ticker.Reset")
+	}
+	t.t.clock.mu.Lock()
+	t.t.period = d
+	t.t.clock.mu.Unlock()
+	t.t.reset(d)
+}
+
+// NewTimer returns a timer of the clock, as time.NewTimer.
+func (c *Clock) NewTimer(d time.Duration) *Timer {
+	t := c.newTimer(d, 0, nil)
+	return &Timer{C: t.c, t: t}
+}
+
+// AfterFunc runs f in a goroutine after the duration d, as time.AfterFunc.
+func (c *Clock) AfterFunc(d time.Duration, f func()) *Timer {
+	return &Timer{t: c.newTimer(d, 0, f)}
+}
+
+// NewTicker returns a ticker of the clock, as time.NewTicker.
+func (c *Clock) NewTicker(d time.Duration) *Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	t := c.newTimer(d, d, nil)
+	return &Ticker{C: t.c, t: t}
+}
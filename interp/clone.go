@@ -0,0 +1,491 @@
+package interp
+
+import (
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// A clone receives a snapshot of the global frame of its interpreter: the
+// global values are copied deeply, preserving the sharing of memory between
+// them, so that modifications made by the clone and by the original are not
+// observed by the other. No interpreted code is executed again.
+//
+// Values of binary types are copied too, unless they hold channels,
+// functions or unsafe pointers, which may refer to resources of the host
+// program, or are referenced by the variables of binary packages, as
+// io.EOF or time.Local: those are shared.
+//
+// Function values are closures bound to the frame where they were created.
+// They are recorded in a funcTable, with their generator, so that a clone
+// can create them again, bound to its copy of the frames. Before go1.24,
+// which allows to record them without retaining them, only the ones
+// created during the initialization of the global state are.
+
+// closure is the generator of a function value, and the frame it is bound to.
+type closure struct {
+	gen func(*frame) reflect.Value
+	f   *frame
+}
+
+// funcKey returns the identity of the function value v, which is the
+// address of its closure.
+func funcKey(v reflect.Value) unsafe.Pointer {
+	p := reflect.New(v.Type())
+	p.Elem().Set(v)
+	return *(*unsafe.Pointer)(p.UnsafePointer())
+}
+
+// recordFunc records the function value v generated by gen in frame f, if
+// recordAll is set or if created during the initialization of the global
+// state.
+func recordFunc(f *frame, v reflect.Value, gen func(*frame) reflect.Value) {
+	interp := f.root.interp
+	if interp == nil || !recordAll && atomic.LoadInt32(&interp.funcs.init) == 0 {
+		return
+	}
+	interp.funcs.add(v, closure{gen: gen, f: f})
+}
+
+// runInit runs n on the global frame, as part of the global state
+// initialization.
+func (interp *Interpreter) runInit(n *node, local bool) {
+	if n == nil {
+		return
+	}
+	atomic.AddInt32(&interp.funcs.init, 1)
+	defer atomic.AddInt32(&interp.funcs.init, -1)
+	if local {
+		interp.run(n, interp.frame)
+	} else {
+		interp.run(n, nil)
+	}
+}
+
+// Clone returns a new interpreter which shares with interp the compiled
+// code, the imported binary and source packages, and the options, but with
+// its own global variables.
+//
+// The global variables of the clone are a copy of the ones of interp at the
+// time of the call. The copy is deep: the memory referenced by pointers,
+// slices, maps and interfaces is copied too, so the state of the clone is
+// independent from interp and other clones. The init functions and the
+// statements executed by interp are not executed again. It allows, for
+// example, to run each request of a server on a fresh instance of preloaded
+// plugins without parsing and compiling them again.
+//
+// Values of types defined by binary packages are copied deeply too, except
+// the ones holding channels, functions or unsafe pointers, for example
+// *os.File, *log.Logger or sync.Map, and the memory referenced by the
+// variables of binary packages, as io.EOF or time.Local: they are shared by
+// assignment, as the resources of the host program they may refer to.
+// Channels are copied with their capacity, buffered values and closed state,
+// except closed channels still holding values, which are shared. Function
+// values of binary packages, as method values, are shared. With a Go
+// toolchain older than go1.24, the function values created after the
+// initialization of the global state (for example by the main function, or
+// by a function called later) are shared too, and still refer to the
+// globals of interp. Clone must not be called while interp runs code, or
+// has goroutines running or blocked.
+//
+// Compilation is serialized between interp and its clones, as they share
+// the symbols: code compiled after cloning, for example by Eval, becomes
+// visible to all of them, while executing it only affects the interpreter
+// it runs in. The debugger is not supported in a clone. In deterministic
+// mode, the clone shares the clock, the random number generators and the
+// scheduler of goroutines of interp.
+func (interp *Interpreter) Clone() (c *Interpreter, err error) {
+	interp.compiling.Lock()
+	defer interp.compiling.Unlock()
+
+	interp.mutex.RLock()
+	c = &Interpreter{
+		nindex:     atomic.LoadInt64(&interp.nindex),
+		name:       interp.name,
+		opt:        interp.opt,
+		cancelChan: interp.cancelChan,
+		fset:       interp.fset,
+		binPkg:     interp.binPkg,
+		asm:        interp.asm,
+		rdir:       map[string]bool{},
+		mapTypes:   interp.mapTypes,
+		frame:      newFrame(nil, 0, 0),
+		universe:   interp.universe,
+		scopes:     interp.scopes,
+		srcPkg:     interp.srcPkg,
+		pkgNames:   interp.pkgNames,
+		langs:      interp.langs,
+		done:       make(chan struct{}),
+		roots:      interp.roots,
+		generic:    interp.generic,
+		hooks:      interp.hooks,
+		compiling:  interp.compiling,
+		sched:      interp.sched,
+		sources:    interp.sources,
+	}
+	interp.mutex.RUnlock()
+	c.frame.interp = c
+
+	defer func() {
+		if r := recover(); r != nil {
+			var pc [64]uintptr // 64 frames should be enough.
+			n := runtime.Callers(1, pc[:])
+			err = Panic{Value: r, Callers: pc[:n], Stack: debug.Stack()}
+		}
+	}()
+
+	s := &snapshot{
+		src:    interp,
+		dst:    c,
+		frames: map[*frame]*frame{interp.frame: c.frame},
+		values: map[valueKey]reflect.Value{},
+		filled: map[unsafe.Pointer]bool{},
+		shared: sharedMemory(interp.binPkg),
+	}
+	interp.frame.mutex.RLock()
+	data, num := interp.frame.data, interp.frame.num
+	interp.frame.mutex.RUnlock()
+	c.frame.data, c.frame.num = s.alloc(data, num)
+	s.fill(c.frame.data, data)
+
+	c.frame.mutex.Lock()
+	c.resizeFrame()
+	c.frame.mutex.Unlock()
+	return c, nil
+}
+
+// snapshot holds the state of the copy of the global frame of src to dst.
+type snapshot struct {
+	src, dst *Interpreter
+	frames   map[*frame]*frame
+	values   map[valueKey]reflect.Value // copies of pointers, slices, maps and functions
+	filled   map[unsafe.Pointer]bool    // frame values already copied
+	shared   map[unsafe.Pointer]bool    // memory referenced by binary package variables
+}
+
+// sharedMemory returns the memory referenced by the variables of the binary
+// packages, and the variables themselves, which must not be copied.
+func sharedMemory(binPkg Exports) map[unsafe.Pointer]bool {
+	m := map[unsafe.Pointer]bool{}
+	for _, pkg := range binPkg {
+		for _, v := range pkg {
+			if !v.CanAddr() {
+				// Not a variable.
+				continue
+			}
+			m[unsafe.Pointer(v.UnsafeAddr())] = true
+			if v.Kind() == reflect.Interface {
+				v = v.Elem()
+			}
+			switch v.Kind() {
+			case reflect.Ptr, reflect.Map, reflect.Chan:
+				if !v.IsNil() {
+					m[v.UnsafePointer()] = true
+				}
+			}
+		}
+	}
+	return m
+}
+
+// valueKey identifies a value referencing memory, for the copy to preserve
+// the sharing of this memory. Frame values are registered as pointers.
+type valueKey struct {
+	p        unsafe.Pointer
+	t        reflect.Type
+	len, cap int
+}
+
+// alloc returns new frame values for a copy of the frame values data and
+// their unboxed storage num. The frame values already copied are reused.
+func (s *snapshot) alloc(data []reflect.Value, num []uint64) ([]reflect.Value, []uint64) {
+	var nnum []uint64
+	if num != nil {
+		nnum = make([]uint64, len(num))
+	}
+	ndata := make([]reflect.Value, len(data))
+	for i, v := range data {
+		switch {
+		case !v.IsValid():
+		case !v.CanAddr():
+			// Value rebound in frame, as function values.
+			ndata[i] = reflect.New(v.Type()).Elem()
+		default:
+			k := valueKey{p: unsafe.Pointer(v.UnsafeAddr()), t: reflect.PtrTo(v.Type())}
+			p, ok := s.values[k]
+			if !ok {
+				if i < len(num) && k.p == unsafe.Pointer(&num[i]) {
+					p = reflect.NewAt(v.Type(), unsafe.Pointer(&nnum[i]))
+				} else {
+					p = reflect.New(v.Type())
+				}
+				s.values[k] = p
+			}
+			ndata[i] = p.Elem()
+		}
+	}
+	return ndata, nnum
+}
+
+// fill copies the frame values data to the new frame values ndata returned
+// by alloc, except the ones already copied.
+func (s *snapshot) fill(ndata, data []reflect.Value) {
+	for i, v := range data {
+		if !v.IsValid() {
+			continue
+		}
+		if v.CanAddr() {
+			// Frame values may be shared between frames, see frame.clone.
+			p := unsafe.Pointer(v.UnsafeAddr())
+			if s.filled[p] {
+				continue
+			}
+			s.filled[p] = true
+		}
+		s.copy(ndata[i], v)
+	}
+}
+
+// frame returns the copy of frame f.
+func (s *snapshot) frame(f *frame) *frame {
+	if nf, ok := s.frames[f]; ok {
+		return nf
+	}
+	nf := &frame{root: s.dst.frame, id: s.dst.frame.runid(), done: s.dst.frame.done}
+	s.frames[f] = nf
+	if f.anc != nil {
+		nf.anc = s.frame(f.anc)
+	}
+	f.mutex.RLock()
+	data, num := f.data, f.num
+	f.mutex.RUnlock()
+	nf.data, nf.num = s.alloc(data, num)
+	s.fill(nf.data, data)
+	return nf
+}
+
+// copy sets the settable value dst to a deep copy of src.
+func (s *snapshot) copy(dst, src reflect.Value) {
+	t := src.Type()
+	if t == valueInterfaceType {
+		vi := src.Interface().(valueInterface)
+		if vi.value.IsValid() {
+			v := reflect.New(vi.value.Type()).Elem()
+			s.copy(v, vi.value)
+			vi.value = v
+		}
+		dst.Set(reflect.ValueOf(vi))
+		return
+	}
+	if t.Kind() == reflect.Func {
+		if !src.IsNil() {
+			dst.Set(s.function(src))
+		}
+		return
+	}
+	if s.binary(t) && !plain(t) {
+		// Value of a binary type which may refer to host resources, shared.
+		dst.Set(src)
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() || s.shared[src.UnsafePointer()] || s.binary(t.Elem()) && !plain(t.Elem()) {
+			dst.Set(src)
+			return
+		}
+		k := valueKey{p: src.UnsafePointer(), t: t}
+		p, ok := s.values[k]
+		if !ok {
+			p = reflect.New(t.Elem())
+			s.values[k] = p
+			s.copy(p.Elem(), src.Elem())
+		}
+		dst.Set(p)
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		e := src.Elem()
+		v := reflect.New(e.Type()).Elem()
+		s.copy(v, e)
+		dst.Set(v)
+	case reflect.Struct:
+		if !src.CanAddr() {
+			v := reflect.New(t).Elem()
+			v.Set(src)
+			src = v
+		}
+		for i := 0; i < t.NumField(); i++ {
+			s.copy(field(dst, i), field(src, i))
+		}
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			s.copy(dst.Index(i), src.Index(i))
+		}
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		k := valueKey{p: src.UnsafePointer(), t: t, len: src.Len(), cap: src.Cap()}
+		v, ok := s.values[k]
+		if !ok {
+			v = reflect.MakeSlice(t, src.Len(), src.Cap())
+			s.values[k] = v
+			for i := 0; i < src.Len(); i++ {
+				s.copy(v.Index(i), src.Index(i))
+			}
+		}
+		dst.Set(v)
+	case reflect.Chan:
+		if src.IsNil() || s.shared[src.UnsafePointer()] {
+			dst.Set(src)
+			return
+		}
+		k := valueKey{p: src.UnsafePointer(), t: t}
+		v, ok := s.values[k]
+		if !ok {
+			v = s.channel(src)
+			s.values[k] = v
+		}
+		dst.Set(v)
+	case reflect.Map:
+		if src.IsNil() || s.shared[src.UnsafePointer()] {
+			dst.Set(src)
+			return
+		}
+		k := valueKey{p: src.UnsafePointer(), t: t}
+		v, ok := s.values[k]
+		if !ok {
+			v = reflect.MakeMapWithSize(t, src.Len())
+			s.values[k] = v
+			for it := src.MapRange(); it.Next(); {
+				mk, mv := reflect.New(t.Key()).Elem(), reflect.New(t.Elem()).Elem()
+				s.copy(mk, it.Key())
+				s.copy(mv, it.Value())
+				v.SetMapIndex(mk, mv)
+			}
+		}
+		dst.Set(v)
+	default:
+		dst.Set(src)
+	}
+}
+
+// channel returns the copy of the channel c, with the same capacity,
+// buffered values and closed state. The buffered values are received from c
+// and sent back in order. A closed channel holding values is returned as is,
+// as its values can not be read without consuming them.
+func (s *snapshot) channel(c reflect.Value) reflect.Value {
+	t := c.Type()
+	// Access c as a bidirectional channel, to receive and send whatever its direction.
+	bt := reflect.ChanOf(reflect.BothDir, t.Elem())
+	p := reflect.New(t)
+	p.Elem().Set(c)
+	b := reflect.NewAt(bt, p.UnsafePointer()).Elem()
+
+	n := b.Len()
+	closed := (*hchan)(b.UnsafePointer()).closed != 0
+	if closed && n > 0 {
+		return c
+	}
+	nc := reflect.MakeChan(bt, b.Cap())
+	for ; n > 0; n-- {
+		v, _ := b.TryRecv()
+		b.Send(v)
+		nv := reflect.New(t.Elem()).Elem()
+		s.copy(nv, v)
+		nc.Send(nv)
+	}
+	if closed {
+		nc.Close()
+	}
+	return nc.Convert(t)
+}
+
+// hchan is the header of a channel in the runtime, up to its closed flag.
+type hchan struct {
+	qcount   uint
+	dataqsiz uint
+	buf      unsafe.Pointer
+	elemsize uint16
+	closed   uint32
+}
+
+// function returns the copy of the function value v, bound to the copy of
+// its frame if recorded, or v itself.
+func (s *snapshot) function(v reflect.Value) reflect.Value {
+	k := valueKey{p: funcKey(v), t: v.Type()}
+	if c, ok := s.values[k]; ok {
+		return c
+	}
+	c, ok := s.src.funcs.get(k.p)
+	if !ok || c.f.root != s.src.frame {
+		s.values[k] = v
+		return v
+	}
+	f := s.frame(c.f)
+	nv := c.gen(f)
+	if nv.Type() != v.Type() {
+		nv = nv.Convert(v.Type())
+	}
+	s.dst.funcs.add(nv, closure{gen: c.gen, f: f})
+	s.values[k] = nv
+	return nv
+}
+
+// binary returns true if t is a named type defined by a binary package,
+// instead of by the interpreted code.
+func (s *snapshot) binary(t reflect.Type) bool {
+	p := t.PkgPath()
+	return p != "" && p != mainID && s.src.srcPkg[p] == nil
+}
+
+// plainTypes caches the results of plain, indexed by reflect.Type.
+var plainTypes sync.Map
+
+// plain returns true if the values of type t only hold memory which can be
+// copied, and no channels, functions, unsafe pointers or uintptr, which may
+// refer to resources of the host program. Interfaces are plain, as their
+// dynamic values are checked when copied.
+func plain(t reflect.Type) bool {
+	if p, ok := plainTypes.Load(t); ok {
+		return p.(bool)
+	}
+	p := plainType(t, map[reflect.Type]bool{})
+	plainTypes.Store(t, p)
+	return p
+}
+
+func plainType(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return true
+	}
+	seen[t] = true
+	switch t.Kind() {
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer, reflect.Uintptr:
+		return false
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return plainType(t.Elem(), seen)
+	case reflect.Map:
+		return plainType(t.Key(), seen) && plainType(t.Elem(), seen)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if !plainType(t.Field(i).Type, seen) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// field returns the field i of the addressable struct v, settable even if
+// not exported.
+func field(v reflect.Value, i int) reflect.Value {
+	f := v.Field(i)
+	return reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem()
+}
@@ -0,0 +1,38 @@
+//go:build !go1.24
+
+package interp
+
+import (
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// recordAll is false as the function values can not be recorded without
+// retaining them: only the ones created during the initialization of the
+// global state are.
+const recordAll = false
+
+// funcTable records the function values created during the initialization
+// of the global state.
+type funcTable struct {
+	sync.Mutex
+	init  int32                      // number of running initializations, accessed atomically
+	funcs map[unsafe.Pointer]closure // closures indexed by funcKey
+}
+
+func (t *funcTable) add(v reflect.Value, c closure) {
+	t.Lock()
+	if t.funcs == nil {
+		t.funcs = map[unsafe.Pointer]closure{}
+	}
+	t.funcs[funcKey(v)] = c
+	t.Unlock()
+}
+
+func (t *funcTable) get(key unsafe.Pointer) (closure, bool) {
+	t.Lock()
+	defer t.Unlock()
+	c, ok := t.funcs[key]
+	return c, ok
+}
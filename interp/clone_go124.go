@@ -0,0 +1,68 @@
+//go:build go1.24
+
+package interp
+
+import (
+	"reflect"
+	"runtime"
+	"sync"
+	"unsafe"
+	"weak"
+)
+
+// recordAll is true as the function values created after the
+// initialization of the global state can be recorded without retaining
+// them.
+const recordAll = true
+
+// funcTable records the function values created by the interpreted code.
+// The entries hold weak pointers to the function values and their frames,
+// and are removed once the function values are garbage collected.
+type funcTable struct {
+	sync.Mutex
+	init  int32               // number of running initializations, accessed atomically
+	funcs map[uintptr]funcRef // closures indexed by funcKey
+}
+
+// funcRef is a closure, with weak pointers to its function value and frame.
+type funcRef struct {
+	v   weak.Pointer[byte]
+	gen func(*frame) reflect.Value
+	f   weak.Pointer[frame]
+}
+
+func (t *funcTable) add(v reflect.Value, c closure) {
+	p := (*byte)(funcKey(v))
+	k := uintptr(unsafe.Pointer(p))
+	t.Lock()
+	if t.funcs == nil {
+		t.funcs = map[uintptr]funcRef{}
+	}
+	t.funcs[k] = funcRef{v: weak.Make(p), gen: c.gen, f: weak.Make(c.f)}
+	t.Unlock()
+	runtime.AddCleanup(p, t.remove, k)
+}
+
+// remove deletes the entry at key k, unless its address was reused by a
+// function value recorded since.
+func (t *funcTable) remove(k uintptr) {
+	t.Lock()
+	if r, ok := t.funcs[k]; ok && r.v.Value() == nil {
+		delete(t.funcs, k)
+	}
+	t.Unlock()
+}
+
+func (t *funcTable) get(key unsafe.Pointer) (closure, bool) {
+	t.Lock()
+	defer t.Unlock()
+	r, ok := t.funcs[uintptr(key)]
+	if !ok || r.v.Value() != (*byte)(key) {
+		return closure{}, false
+	}
+	f := r.f.Value()
+	if f == nil {
+		return closure{}, false
+	}
+	return closure{gen: r.gen, f: f}, true
+}
@@ -0,0 +1,125 @@
+package interp
+
+import (
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// completeLine returns the identifier at the end of line, possibly empty, and
+// the names it may be completed with in the REPL, sorted. The candidates are
+// the members of a package, or the fields and methods of a value, for a
+// selector, and the symbols of the REPL scope and the package names otherwise.
+// At the start of a meta-command, the candidates are the command names.
+func (interp *Interpreter) completeLine(line string) (word string, res []string) {
+	if strings.HasPrefix(line, ":") && !strings.Contains(line, " ") {
+		for _, c := range replCommands {
+			if strings.HasPrefix(c.name, line[1:]) {
+				res = append(res, c.name)
+			}
+		}
+		return line[1:], res
+	}
+
+	start := len(line)
+	for start > 0 {
+		r, size := utf8.DecodeLastRuneInString(line[:start])
+		if r != '.' && r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			break
+		}
+		start -= size
+	}
+	sel := strings.Split(line[start:], ".")
+	word = sel[len(sel)-1]
+	if r, _ := utf8.DecodeRuneInString(sel[0]); unicode.IsDigit(r) {
+		return word, nil
+	}
+
+	interp.compiling.Lock()
+	defer interp.compiling.Unlock()
+	interp.mutex.RLock()
+	defer interp.mutex.RUnlock()
+	defer func() {
+		if recover() != nil {
+			res = nil
+		}
+	}()
+
+	seen := map[string]bool{}
+	add := func(name, kind, typ string) {
+		if seen[name] || name == "_" || !strings.HasPrefix(name, word) {
+			return
+		}
+		seen[name] = true
+		res = append(res, name)
+	}
+
+	sc := interp.scopes[mainID]
+	if sc == nil {
+		sc = interp.universe
+	}
+	if len(sel) == 1 {
+		for ; sc != nil; sc = sc.anc {
+			for name, sym := range sc.sym {
+				if i := strings.Index(name, "/"); i >= 0 {
+					name = name[:i]
+				}
+				add(name, symKindName(sym.kind), "")
+			}
+		}
+		for p := range interp.binPkg {
+			if p != "" {
+				add(path.Base(p), "package", "")
+			}
+		}
+		for p := range interp.srcPkg {
+			if name, ok := interp.pkgNames[p]; ok {
+				add(name, "package", "")
+			}
+		}
+		sort.Strings(res)
+		return word, res
+	}
+
+	sym, _, ok := sc.lookup(sel[0])
+	if !ok {
+		sym, _, ok = sc.lookup(key2name(sel[0]))
+	}
+	if !ok || sym.typ == nil {
+		return word, nil
+	}
+	t := sym.typ
+	for _, name := range sel[1 : len(sel)-1] {
+		if t = memberType(t, name); t == nil {
+			return word, nil
+		}
+	}
+	interp.memberCompletions(&node{typ: t}, add)
+	sort.Strings(res)
+	return word, res
+}
+
+// memberType returns the type of the field name of a value of type t,
+// or nil if there is none.
+func memberType(t *itype, name string) *itype {
+	if t.cat == valueT && t.rtype != nil {
+		rt := t.rtype
+		if rt.Kind() == reflect.Ptr {
+			rt = rt.Elem()
+		}
+		if rt.Kind() != reflect.Struct {
+			return nil
+		}
+		if f, ok := rt.FieldByName(name); ok {
+			return valueTOf(f.Type)
+		}
+		return nil
+	}
+	if ti := t.lookupField(name); len(ti) > 0 {
+		return t.fieldSeq(ti)
+	}
+	return nil
+}
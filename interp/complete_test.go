@@ -0,0 +1,49 @@
+package interp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompleteLine(t *testing.T) {
+	i := New(Options{})
+	if err := i.Use(Exports{"guthib.com/app/app": {
+		"Greet":    reflect.ValueOf(func(string) string { return "" }),
+		"Greeting": reflect.ValueOf((*string)(nil)),
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	i.ImportUsed()
+	if _, err := i.Eval(`
+type T struct {
+	Name string
+	in   struct{ Count int }
+}
+
+func (T) Get() int { return 1 }
+
+var v T
+var total int
+`); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		line, word string
+		want       []string
+	}{
+		{"to", "to", []string{"total"}},
+		{"x := v.", "", []string{"Get", "Name", "in"}},
+		{"v.in.C", "C", []string{"Count"}},
+		{"app.Gr", "Gr", []string{"Greet", "Greeting"}},
+		{"ap", "ap", []string{"app", "append"}},
+		{"1.5", "5", nil},
+		{"undefinedX.", "", nil},
+	}
+	for _, test := range tests {
+		word, got := i.completeLine(test.line)
+		if word != test.word || !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%q: got %q %q, want %q %q", test.line, word, got, test.word, test.want)
+		}
+	}
+}
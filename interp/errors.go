@@ -0,0 +1,205 @@
+package interp
+
+import (
+	"errors"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"strings"
+	"sync"
+)
+
+// ErrorCode is the category of a CompileError. Codes are stable: new ones
+// may be added, but existing ones are never renumbered.
+type ErrorCode int
+
+// Compile error codes.
+const (
+	ErrOther            ErrorCode = iota // not otherwise categorized
+	ErrSyntax                            // invalid Go syntax
+	ErrUndefined                         // undefined identifier, selector, field, method, type or label
+	ErrRedeclared                        // symbol already declared in the same scope
+	ErrTypeMismatch                      // incompatible types in assignment, conversion, assertion or call
+	ErrInvalidOperation                  // invalid operator, operand or argument
+	ErrDefinitionLoop                    // constant, variable or type defined in terms of itself
+	ErrUnsupported                       // Go feature not supported by the interpreter
+	ErrImport                            // package not found, invalid import or import cycle
+)
+
+var errorCodeNames = [...]string{
+	ErrOther:            "other",
+	ErrSyntax:           "syntax",
+	ErrUndefined:        "undefined",
+	ErrRedeclared:       "redeclared",
+	ErrTypeMismatch:     "type mismatch",
+	ErrInvalidOperation: "invalid operation",
+	ErrDefinitionLoop:   "definition loop",
+	ErrUnsupported:      "unsupported",
+	ErrImport:           "import",
+}
+
+func (c ErrorCode) String() string {
+	if c < 0 || int(c) >= len(errorCodeNames) {
+		return "other"
+	}
+	return errorCodeNames[c]
+}
+
+// A CompileError is an error detected while parsing or compiling Go source,
+// as returned by Compile, CompilePath, CompileAST, Eval and EvalPath.
+//
+// The original error is available through errors.As, and provides the
+// message returned by Error. Syntax errors are of code ErrSyntax, and wrap
+// the scanner.ErrorList of go/parser.
+type CompileError struct {
+	Pos     token.Position // start of the offending code, invalid if unknown
+	End     token.Position // end of the offending code, same as Pos if unknown
+	Code    ErrorCode
+	Msg     string // error message, without position
+	Snippet string // source line at Pos, if available
+
+	err error
+}
+
+func (e *CompileError) Error() string {
+	if e.err != nil {
+		return e.err.Error()
+	}
+	if e.Pos.IsValid() {
+		return e.Pos.String() + ": " + e.Msg
+	}
+	return e.Msg
+}
+
+func (e *CompileError) Unwrap() error { return e.err }
+
+// sourceMap holds the source texts parsed by interpreters, for the error
+// snippets. It is shared by clones.
+type sourceMap struct {
+	sync.Mutex
+	src map[string]string // source texts, indexed by file name
+}
+
+func (s *sourceMap) set(name, src string) {
+	s.Lock()
+	s.src[name] = src
+	s.Unlock()
+}
+
+// line returns the text of the source line at position pos, or "".
+func (s *sourceMap) line(pos token.Position) string {
+	s.Lock()
+	src := s.src[pos.Filename]
+	s.Unlock()
+	if !pos.IsValid() || pos.Offset < 0 || pos.Offset > len(src) {
+		return ""
+	}
+	start := strings.LastIndexByte(src[:pos.Offset], '\n') + 1
+	end := strings.IndexByte(src[pos.Offset:], '\n')
+	if end < 0 {
+		return src[start:]
+	}
+	return src[start : pos.Offset+end]
+}
+
+// compileError returns err as a *CompileError, or nil if err is nil.
+// For several errors, as in a scanner.ErrorList, the first one is described.
+func (interp *Interpreter) compileError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var ce *CompileError
+	if errors.As(err, &ce) {
+		return err
+	}
+	ce = interp.compileErrors(err)[0]
+	ce.err = err
+	return ce
+}
+
+// compileErrors returns the list of compile errors contained in err.
+func (interp *Interpreter) compileErrors(err error) []*CompileError {
+	var (
+		el  scanner.ErrorList
+		se  *scanner.Error
+		cfe *cfgError
+		dl  Diagnostics
+		ce  *CompileError
+	)
+	switch {
+	case errors.As(err, &el) && len(el) > 0:
+		res := make([]*CompileError, len(el))
+		for i, e := range el {
+			res[i] = interp.syntaxError(e)
+		}
+		return res
+	case errors.As(err, &se):
+		return []*CompileError{interp.syntaxError(se)}
+	case errors.As(err, &dl) && len(dl) > 0:
+		return dl
+	case errors.As(err, &ce):
+		return []*CompileError{ce}
+	case errors.As(err, &cfe) && cfe.node != nil:
+		pos := interp.fset.Position(cfe.pos)
+		msg := cfe.Error()
+		// Remove the position prefix set by cfgErrorf.
+		for _, p := range []string{pos.String() + ": ", strings.TrimPrefix(pos.String(), DefaultSourceName+":") + ": "} {
+			msg = strings.TrimPrefix(msg, p)
+		}
+		return []*CompileError{{
+			Pos:     pos,
+			End:     interp.fset.Position(nodeEnd(cfe.node)),
+			Code:    cfe.code,
+			Msg:     msg,
+			Snippet: interp.sources.line(pos),
+			err:     cfe,
+		}}
+	}
+	code := ErrOther
+	var cde *codeError
+	if errors.As(err, &cde) {
+		code = cde.code
+	}
+	return []*CompileError{{Code: code, Msg: err.Error(), err: err}}
+}
+
+func (interp *Interpreter) syntaxError(e *scanner.Error) *CompileError {
+	return &CompileError{
+		Pos:     e.Pos,
+		End:     e.Pos,
+		Code:    ErrSyntax,
+		Msg:     e.Msg,
+		Snippet: interp.sources.line(e.Pos),
+		err:     e,
+	}
+}
+
+// nodeEnd returns the end position of the last identifier or literal in
+// the subtree of n, or the position of n if there is none.
+func nodeEnd(n *node) token.Pos {
+	end := n.pos
+	n.Walk(func(c *node) bool {
+		p := c.pos
+		if c.kind == identExpr || c.kind == basicLit {
+			p += token.Pos(len(c.ident))
+		}
+		if p > end {
+			end = p
+		}
+		return true
+	}, nil)
+	return end
+}
+
+// codeError is a compile error of category code, without position.
+type codeError struct {
+	error
+	code ErrorCode
+}
+
+func (e *codeError) Unwrap() error { return e.error }
+
+// importErrorf returns an error locating or reading a source package.
+func importErrorf(format string, a ...interface{}) error {
+	return &codeError{fmt.Errorf(format, a...), ErrImport}
+}
@@ -36,3 +36,28 @@ func Example_eval() {
 	// Output:
 	// 4
 }
+
+// Typed access to interpreted functions.
+func ExampleFunc() {
+	i := interp.New(interp.Options{})
+
+	_, err := i.Eval("func f(i int) int { return 2 * i }")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// The signature is checked, so a mismatch is an error instead of a panic.
+	if _, err := interp.Func[func(string) int](i, "f"); err != nil {
+		fmt.Println(err)
+	}
+
+	f, err := interp.Func[func(int) int](i, "main.f")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(f(2))
+
+	// Output:
+	// f: cannot use func(int) int as func(string) int
+	// 4
+}
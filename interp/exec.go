@@ -0,0 +1,356 @@
+package interp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Command is a command started by the interpreted code, to be run by a
+// CommandRunner.
+type Command struct {
+	Path string   // path or name of the command, as given to exec.Command
+	Args []string // command line arguments, including the command as Args[0]
+	Env  []string // environment, in the form "key=value"
+	Dir  string   // working directory, or empty for the current one
+
+	// Standard input, output and error of the command. They are not nil.
+	Stdin          io.Reader
+	Stdout, Stderr io.Writer
+}
+
+// CommandRunner runs the commands of the interpreted code, see Options.Exec.
+type CommandRunner interface {
+	// Run runs cmd until it completes, or ctx is done. It returns nil if the
+	// command succeeds, an *ExitError or an *exec.ExitError if it exits with
+	// a non zero status, or any other error if it can not be run.
+	Run(ctx context.Context, cmd *Command) error
+}
+
+// CommandRunnerFunc is an adapter to use a function as a CommandRunner.
+type CommandRunnerFunc func(ctx context.Context, cmd *Command) error
+
+// Run calls f(ctx, cmd).
+func (f CommandRunnerFunc) Run(ctx context.Context, cmd *Command) error { return f(ctx, cmd) }
+
+// ErrCommandDenied is the error which may be returned by a CommandRunner
+// refusing to run a command.
+var ErrCommandDenied = errors.New("command denied")
+
+// HostRunner is a CommandRunner running the commands as processes of the
+// host, using package os/exec.
+var HostRunner CommandRunner = CommandRunnerFunc(func(ctx context.Context, cmd *Command) error {
+	c := exec.CommandContext(ctx, cmd.Path, cmd.Args[1:]...)
+	c.Args = cmd.Args
+	c.Env = cmd.Env
+	c.Dir = cmd.Dir
+	c.Stdin, c.Stdout, c.Stderr = cmd.Stdin, cmd.Stdout, cmd.Stderr
+	return c.Run()
+})
+
+// ExitError replaces exec.ExitError in the os/exec package of the interpreted
+// code when Options.Exec is set. It reports an unsuccessful exit by a command.
+type ExitError struct {
+	// Code is the exit code of the command, or -1 if it was terminated by a signal.
+	Code int
+
+	// ProcessState is the state of the process, if the command was run as
+	// a process of the host, or nil.
+	ProcessState *os.ProcessState
+
+	// Stderr holds the standard error output of the command, if it was not
+	// otherwise collected by Cmd.Output.
+	Stderr []byte
+}
+
+func (e *ExitError) Error() string {
+	if e.ProcessState != nil {
+		return e.ProcessState.String()
+	}
+	return "exit status " + strconv.Itoa(e.Code)
+}
+
+// ExitCode returns the exit code of the command, as exec.ExitError.ExitCode.
+func (e *ExitError) ExitCode() int { return e.Code }
+
+// Exited reports whether the command has exited, instead of being terminated
+// by a signal.
+func (e *ExitError) Exited() bool {
+	if e.ProcessState != nil {
+		return e.ProcessState.Exited()
+	}
+	return e.Code >= 0
+}
+
+// Success reports whether the command exited successfully.
+func (e *ExitError) Success() bool { return e.Code == 0 }
+
+// Cmd replaces exec.Cmd in the os/exec package of the interpreted code when
+// Options.Exec is set. The command is run by the CommandRunner of the
+// interpreter. The Process and ProcessState fields of exec.Cmd are not provided.
+type Cmd struct {
+	Path   string
+	Args   []string
+	Env    []string
+	Dir    string
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// Err holds an error from the creation of the command, returned by Start.
+	Err error
+
+	ctx     context.Context
+	runner  CommandRunner
+	environ func() []string // environment of the interpreter
+
+	closers []io.Closer // pipe ends to close after the command completes
+	done    chan struct{}
+	err     error // error of the runner
+	waited  bool
+}
+
+// String returns a human-readable description of c, as exec.Cmd.String.
+func (c *Cmd) String() string {
+	b := new(strings.Builder)
+	b.WriteString(c.Path)
+	if len(c.Args) > 1 {
+		for _, a := range c.Args[1:] {
+			b.WriteByte(' ')
+			b.WriteString(a)
+		}
+	}
+	return b.String()
+}
+
+// Environ returns the environment of the command, which is the environment
+// of the interpreter if c.Env is nil.
+func (c *Cmd) Environ() []string {
+	if c.Env != nil {
+		return append([]string{}, c.Env...)
+	}
+	if c.environ == nil {
+		return nil
+	}
+	return c.environ()
+}
+
+// Run starts the command and waits for it to complete, as exec.Cmd.Run.
+func (c *Cmd) Run() error {
+	if err := c.Start(); err != nil {
+		return err
+	}
+	return c.Wait()
+}
+
+// Start starts the command but does not wait for it to complete, as exec.Cmd.Start.
+func (c *Cmd) Start() error {
+	if c.Err != nil {
+		return c.Err
+	}
+	if c.done != nil {
+		return errors.New("exec: already started")
+	}
+	if c.runner == nil {
+		// Not created by Command or CommandContext.
+		return &exec.Error{Name: c.Path, Err: ErrCommandDenied}
+	}
+	if c.ctx != nil {
+		if err := c.ctx.Err(); err != nil {
+			return err
+		}
+	}
+	cmd := &Command{
+		Path:   c.Path,
+		Args:   append([]string{}, c.Args...),
+		Env:    c.Environ(),
+		Dir:    c.Dir,
+		Stdin:  c.Stdin,
+		Stdout: c.Stdout,
+		Stderr: c.Stderr,
+	}
+	if cmd.Stdin == nil {
+		cmd.Stdin = strings.NewReader("")
+	}
+	if cmd.Stdout == nil {
+		cmd.Stdout = io.Discard
+	}
+	if cmd.Stderr == nil {
+		cmd.Stderr = io.Discard
+	}
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	c.done = make(chan struct{})
+	go func() {
+		defer close(c.done)
+		c.err = c.runner.Run(ctx, cmd)
+		for _, cl := range c.closers {
+			cl.Close()
+		}
+	}()
+	return nil
+}
+
+// Wait waits for the started command to complete, as exec.Cmd.Wait.
+func (c *Cmd) Wait() error {
+	if c.done == nil {
+		return errors.New("exec: not started")
+	}
+	if c.waited {
+		return errors.New("exec: Wait was already called")
+	}
+	c.waited = true
+	<-c.done
+
+	var ee *exec.ExitError
+	if errors.As(c.err, &ee) {
+		return &ExitError{Code: ee.ExitCode(), ProcessState: ee.ProcessState, Stderr: ee.Stderr}
+	}
+	return c.err
+}
+
+// Output runs the command and returns its standard output, as exec.Cmd.Output.
+func (c *Cmd) Output() ([]byte, error) {
+	if c.Stdout != nil {
+		return nil, errors.New("exec: Stdout already set")
+	}
+	var stdout bytes.Buffer
+	c.Stdout = &stdout
+	var stderr *bytes.Buffer
+	if c.Stderr == nil {
+		stderr = &bytes.Buffer{}
+		c.Stderr = stderr
+	}
+	err := c.Run()
+	if ee := (*ExitError)(nil); stderr != nil && errors.As(err, &ee) && ee.Stderr == nil {
+		ee.Stderr = stderr.Bytes()
+	}
+	return stdout.Bytes(), err
+}
+
+// CombinedOutput runs the command and returns its combined standard output
+// and standard error, as exec.Cmd.CombinedOutput.
+func (c *Cmd) CombinedOutput() ([]byte, error) {
+	if c.Stdout != nil {
+		return nil, errors.New("exec: Stdout already set")
+	}
+	if c.Stderr != nil {
+		return nil, errors.New("exec: Stderr already set")
+	}
+	var b lockedBuffer
+	c.Stdout = &b
+	c.Stderr = &b
+	err := c.Run()
+	return b.buf.Bytes(), err
+}
+
+// StdinPipe returns a pipe connected to the standard input of the command,
+// as exec.Cmd.StdinPipe.
+func (c *Cmd) StdinPipe() (io.WriteCloser, error) {
+	if c.Stdin != nil {
+		return nil, errors.New("exec: Stdin already set")
+	}
+	if c.done != nil {
+		return nil, errors.New("exec: StdinPipe after process started")
+	}
+	r, w := io.Pipe()
+	c.Stdin = r
+	c.closers = append(c.closers, r)
+	return w, nil
+}
+
+// StdoutPipe returns a pipe connected to the standard output of the command,
+// as exec.Cmd.StdoutPipe.
+func (c *Cmd) StdoutPipe() (io.ReadCloser, error) {
+	if c.Stdout != nil {
+		return nil, errors.New("exec: Stdout already set")
+	}
+	if c.done != nil {
+		return nil, errors.New("exec: StdoutPipe after process started")
+	}
+	r, w := io.Pipe()
+	c.Stdout = w
+	c.closers = append(c.closers, w)
+	return r, nil
+}
+
+// StderrPipe returns a pipe connected to the standard error of the command,
+// as exec.Cmd.StderrPipe.
+func (c *Cmd) StderrPipe() (io.ReadCloser, error) {
+	if c.Stderr != nil {
+		return nil, errors.New("exec: Stderr already set")
+	}
+	if c.done != nil {
+		return nil, errors.New("exec: StderrPipe after process started")
+	}
+	r, w := io.Pipe()
+	c.Stderr = w
+	c.closers = append(c.closers, w)
+	return r, nil
+}
+
+// lockedBuffer is a buffer safe for concurrent writes.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// fixExec defines the os/exec package of the interpreted code, to run the
+// commands with the CommandRunner assigned to the interpreter.
+func fixExec(interp *Interpreter) {
+	environ := func() []string {
+		if interp.unrestricted {
+			return os.Environ()
+		}
+		a := make([]string, 0, len(interp.env))
+		for k, v := range interp.env {
+			a = append(a, k+"="+v)
+		}
+		sort.Strings(a)
+		return a
+	}
+	command := func(ctx context.Context, name string, arg ...string) *Cmd {
+		return &Cmd{
+			Path:    name,
+			Args:    append([]string{name}, arg...),
+			ctx:     ctx,
+			runner:  interp.runner,
+			environ: environ,
+		}
+	}
+
+	interp.binPkg["os/exec"] = map[string]reflect.Value{
+		"Command": reflect.ValueOf(func(name string, arg ...string) *Cmd { return command(nil, name, arg...) }),
+		"CommandContext": reflect.ValueOf(func(ctx context.Context, name string, arg ...string) *Cmd {
+			if ctx == nil {
+				panic("nil Context")
+			}
+			return command(ctx, name, arg...)
+		}),
+		// The commands are resolved by the runner.
+		"LookPath":     reflect.ValueOf(func(file string) (string, error) { return file, nil }),
+		"ErrDot":       reflect.ValueOf(&exec.ErrDot).Elem(),
+		"ErrNotFound":  reflect.ValueOf(&exec.ErrNotFound).Elem(),
+		"ErrWaitDelay": reflect.ValueOf(&exec.ErrWaitDelay).Elem(),
+		"Cmd":          reflect.ValueOf((*Cmd)(nil)),
+		"Error":        reflect.ValueOf((*exec.Error)(nil)),
+		"ExitError":    reflect.ValueOf((*ExitError)(nil)),
+	}
+	interp.pkgNames["os/exec"] = "exec"
+}
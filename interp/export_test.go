@@ -15,3 +15,14 @@ func (interp *Interpreter) Scopes() map[string]map[string]struct{} {
 func (interp *Interpreter) Packages() map[string]string {
 	return interp.pkgNames
 }
+
+// Lowered returns true if the function name of package main is lowered to
+// bytecode, and is executed as such.
+func (interp *Interpreter) Lowered(name string) bool {
+	sc := interp.scopes[mainID]
+	if sc == nil || sc.sym[name] == nil || sc.sym[name].node == nil {
+		return false
+	}
+	fn := sc.sym[name].node.vm
+	return fn != nil && fn.done
+}
@@ -64,7 +64,7 @@ func genAST(sc *scope, root *node, types []*itype) (*node, bool, error) {
 					l := len(c.child) - 1
 					for _, cc := range c.child[:l] {
 						if pindex >= len(types) {
-							return nil, cc.cfgErrorf("undefined type for %s", cc.ident)
+							return nil, cc.cfgErrorCodef(ErrUndefined, "undefined type for %s", cc.ident)
 						}
 						t, err := nodeType(c.interp, sc, c.child[l])
 						if err != nil {
@@ -96,7 +96,7 @@ func genAST(sc *scope, root *node, types []*itype) (*node, bool, error) {
 					rtname = rtn.child[0].ident + "["
 					for _, cc := range rtn.child[1:] {
 						if pindex >= len(types) {
-							return nil, cc.cfgErrorf("undefined type for %s", cc.ident)
+							return nil, cc.cfgErrorCodef(ErrUndefined, "undefined type for %s", cc.ident)
 						}
 						it := types[pindex]
 						typeParam[cc.ident] = copyNode(cc, cc.anc, false)
@@ -117,7 +117,7 @@ func genAST(sc *scope, root *node, types []*itype) (*node, bool, error) {
 					l := len(c.child) - 1
 					for _, cc := range c.child[:l] {
 						if pindex >= len(types) {
-							return nil, cc.cfgErrorf("undefined type for %s", cc.ident)
+							return nil, cc.cfgErrorCodef(ErrUndefined, "undefined type for %s", cc.ident)
 						}
 						it := types[pindex]
 						t, err := nodeType(c.interp, sc, c.child[l])
@@ -149,7 +149,11 @@ func genAST(sc *scope, root *node, types []*itype) (*node, bool, error) {
 		return nod, nil
 	}
 
-	if nod, found := root.interp.generic[sname]; found {
+	mu := root.interp.universe.mu
+	mu.Lock()
+	nod, found := root.interp.generic[sname]
+	mu.Unlock()
+	if found {
 		return nod, true, nil
 	}
 
@@ -157,7 +161,9 @@ func genAST(sc *scope, root *node, types []*itype) (*node, bool, error) {
 	if err != nil {
 		return nil, false, err
 	}
+	mu.Lock()
 	root.interp.generic[sname] = r
+	mu.Unlock()
 	r.param = append(r.param, types...)
 	if tname != "" {
 		for _, nod := range fixNodes {
@@ -315,5 +321,5 @@ func checkConstraint(it, ct *itype) error {
 			return nil
 		}
 	}
-	return it.node.cfgErrorf("%s does not implement %s", it.id(), ct.id())
+	return it.node.cfgErrorCodef(ErrTypeMismatch, "%s does not implement %s", it.id(), ct.id())
 }
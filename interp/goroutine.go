@@ -0,0 +1,258 @@
+package interp
+
+import (
+	"context"
+	"fmt"
+	"go/token"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Goroutine describes a goroutine started by the interpreter.
+type Goroutine struct {
+	// ID is the goroutine sequence number in the interpreter, starting at 1.
+	ID uint64
+
+	// Func is the name of the function run by the goroutine, if known.
+	Func string
+
+	// Blocked is true if the goroutine runs a channel operation, a select
+	// statement or a call to a binary function, where it may be blocked. The
+	// position of this operation is then the first one of Stack. Note that a
+	// goroutine blocked in a call to a binary function can not be interrupted,
+	// neither by the cancellation of the context of EvalWithContext nor by
+	// Shutdown.
+	Blocked bool
+
+	// Stack holds the positions in the interpreted code of the operation
+	// where the goroutine is blocked, if any, followed by the positions of the
+	// calls which created its frame and the frames it descends from, down to
+	// the go statement which started it, then the calls in progress in the
+	// goroutine which ran it, and so on. A function literal descends from
+	// the frame where it was evaluated, instead of its caller. If the
+	// goroutine is not blocked, Stack starts at its go statement. It is
+	// empty for the goroutines started by EvalWithContext and similar
+	// functions to run the code, when they are not blocked.
+	Stack []token.Position
+}
+
+func (g Goroutine) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "goroutine %d", g.ID)
+	if g.Func != "" {
+		sb.WriteString(" [" + g.Func + "]")
+	}
+	for i, p := range g.Stack {
+		if i == 0 && g.Blocked {
+			sb.WriteString("\n\tblocked at " + p.String())
+			continue
+		}
+		sb.WriteString("\n\tat " + p.String())
+	}
+	return sb.String()
+}
+
+// LeakError is returned by Wait and Shutdown when goroutines started by the
+// interpreter are still running once their context is done.
+type LeakError struct {
+	// Err is the error of the context.
+	Err error
+
+	// Goroutines are the goroutines still running, in order of creation.
+	Goroutines []Goroutine
+}
+
+func (e LeakError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%v: %d goroutine(s) still running", e.Err, len(e.Goroutines))
+	for _, g := range e.Goroutines {
+		sb.WriteString("\n" + g.String())
+	}
+	return sb.String()
+}
+
+func (e LeakError) Unwrap() error { return e.Err }
+
+// routine is the record of a running goroutine.
+type routine struct {
+	id  uint64
+	fun string
+	pos token.Pos             // position of the go statement
+	f   *frame                // frame running the go statement
+	at  int64                 // position of the blocking operation in progress, accessed atomically
+	atf atomic.Pointer[frame] // frame running the blocking operation in progress
+}
+
+// site is the position and frame of a blocking operation.
+type site struct {
+	pos token.Pos
+	f   *frame
+}
+
+// block records that g runs in frame f the blocking operation at position
+// pos, and returns the site to restore with unblock once it completes. g may
+// be nil.
+func (g *routine) block(f *frame, pos token.Pos) site {
+	if g == nil {
+		return site{}
+	}
+	s := site{f: g.atf.Swap(f)}
+	s.pos = token.Pos(atomic.SwapInt64(&g.at, int64(pos)))
+	return s
+}
+
+// unblock restores the site s returned by block. g may be nil.
+func (g *routine) unblock(s site) {
+	if g == nil {
+		return
+	}
+	atomic.StoreInt64(&g.at, int64(s.pos))
+	g.atf.Store(s.f)
+}
+
+// goroutines tracks the goroutines started by an interpreter.
+type goroutines struct {
+	sync.Mutex
+	seq  uint64
+	live map[*routine]bool
+	idle chan struct{} // closed when no goroutine is running
+}
+
+// start records a new goroutine, started in frame f at position pos to run
+// fun. The returned function must be called when the goroutine exits.
+func (gs *goroutines) start(f *frame, fun string, pos token.Pos) (*routine, func()) {
+	gs.Lock()
+	defer gs.Unlock()
+	if len(gs.live) == 0 {
+		gs.live = map[*routine]bool{}
+		gs.idle = make(chan struct{})
+	}
+	gs.seq++
+	g := &routine{id: gs.seq, fun: fun, pos: pos, f: f}
+	gs.live[g] = true
+	return g, func() {
+		gs.Lock()
+		defer gs.Unlock()
+		delete(gs.live, g)
+		if len(gs.live) == 0 {
+			close(gs.idle)
+		}
+	}
+}
+
+// goRun runs fn in a new goroutine started from frame f by the go statement
+// at node n, tracked by the interpreter owning f. In deterministic mode, the
+// goroutine runs under the scheduler of the interpreter, unless bin is true:
+// the goroutines calling binary functions can not be scheduled.
+func goRun(n *node, f *frame, fun string, bin bool, fn func(g *routine)) {
+	var pos token.Pos
+	if n != nil {
+		pos = n.pos
+	}
+	interp := n.interp
+	if f != nil && f.root.interp != nil {
+		interp = f.root.interp
+	}
+	g, exit := interp.goroutines.start(f, fun, pos)
+	if s := interp.sched; s != nil && !bin {
+		s.spawn(func() {
+			defer exit()
+			fn(g)
+		})
+		return
+	}
+	go func() {
+		defer exit()
+		fn(g)
+	}()
+}
+
+// funcName returns the name of the interpreted function def.
+func funcName(def *node) string {
+	if len(def.child) < 4 {
+		return ""
+	}
+	return panicFunc(def.child[3].scope)
+}
+
+// binFuncName returns the name of the binary function v.
+func binFuncName(v reflect.Value) string {
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return ""
+	}
+	if fn := runtime.FuncForPC(v.Pointer()); fn != nil {
+		return fn.Name()
+	}
+	return ""
+}
+
+// Goroutines returns the goroutines started by the interpreter and still
+// running, in order of creation.
+func (interp *Interpreter) Goroutines() []Goroutine {
+	interp.goroutines.Lock()
+	defer interp.goroutines.Unlock()
+
+	res := make([]Goroutine, 0, len(interp.goroutines.live))
+	for g := range interp.goroutines.live {
+		r := Goroutine{ID: g.id, Func: g.fun}
+		pos, f := g.pos, g.f
+		if at := token.Pos(atomic.LoadInt64(&g.at)); at.IsValid() {
+			r.Blocked = true
+			pos, f = at, g.atf.Load()
+		}
+		if pos.IsValid() {
+			r.Stack = append(r.Stack, interp.fset.Position(pos))
+		}
+		for ; f != nil; f = f.anc {
+			if f.pos.IsValid() {
+				r.Stack = append(r.Stack, interp.fset.Position(f.pos))
+			}
+		}
+		res = append(res, r)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res
+}
+
+// Wait waits for the termination of all goroutines started by the interpreter,
+// including the ones started by the interpreted code. If ctx is done before,
+// Wait returns a LeakError describing the goroutines still running.
+func (interp *Interpreter) Wait(ctx context.Context) error {
+	for {
+		interp.goroutines.Lock()
+		idle := interp.goroutines.idle
+		n := len(interp.goroutines.live)
+		interp.goroutines.Unlock()
+
+		if n == 0 {
+			return nil
+		}
+		select {
+		case <-idle:
+			// New goroutines may have been started since.
+			continue
+		case <-ctx.Done():
+		}
+		if gs := interp.Goroutines(); len(gs) > 0 {
+			return LeakError{Err: ctx.Err(), Goroutines: gs}
+		}
+		return nil
+	}
+}
+
+// Shutdown cancels the running interpreted code, as the cancellation of the
+// context of EvalWithContext, then waits for the termination of all the
+// goroutines started by the interpreter, as Wait.
+//
+// Interpreted goroutines stop at their next statement, or at their current
+// channel operation when running under EvalWithContext or similar functions.
+// Calls to binary packages can not be interrupted: goroutines blocked in them
+// are reported as leaked if they do not return before ctx is done.
+func (interp *Interpreter) Shutdown(ctx context.Context) error {
+	interp.stop()
+	return interp.Wait(ctx)
+}
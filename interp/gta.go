@@ -63,7 +63,7 @@ func (interp *Interpreter) gta(root *node, rpath, importPath, pkgName string) ([
 			for i := 0; i < n.nleft; i++ {
 				dest, src := n.child[i], n.child[sbase+i]
 				if isBlank(src) {
-					err = n.cfgErrorf("cannot use _ as value")
+					err = n.cfgErrorCodef(ErrInvalidOperation, "cannot use _ as value")
 				}
 				val := src.rval
 				if n.anc.kind == constDecl {
@@ -91,7 +91,7 @@ func (interp *Interpreter) gta(root *node, rpath, importPath, pkgName string) ([
 					return false
 				}
 				if typ.cat == nilT {
-					err = n.cfgErrorf("use of untyped nil")
+					err = n.cfgErrorCodef(ErrInvalidOperation, "use of untyped nil")
 					return false
 				}
 				if typ.isBinMethod {
@@ -136,17 +136,25 @@ func (interp *Interpreter) gta(root *node, rpath, importPath, pkgName string) ([
 				// redeclaration error
 				if sym.typ.node != nil && sym.typ.node.anc != nil {
 					prevDecl := n.interp.fset.Position(sym.typ.node.anc.pos)
-					err = n.cfgErrorf("%s redeclared in this block\n\tprevious declaration at %v", c.ident, prevDecl)
+					err = n.cfgErrorCodef(ErrRedeclared, "%s redeclared in this block\n\tprevious declaration at %v", c.ident, prevDecl)
 					return false
 				}
-				err = n.cfgErrorf("%s redeclared in this block", c.ident)
+				err = n.cfgErrorCodef(ErrRedeclared, "%s redeclared in this block", c.ident)
 				return false
 			}
 
 		case funcDecl:
+			if len(n.child[2].child[0].child) > 0 {
+				if err = interp.requireLang(n.child[2].child[0], importPath, 18, "generic function"); err != nil {
+					return false
+				}
+			}
 			if n.typ, err = nodeType(interp, sc, n.child[2]); err != nil {
 				return false
 			}
+			if !interp.opt.noOpt {
+				n.inline = newInlineBody(n, sc.pkgID)
+			}
 			genericMethod := false
 			ident := n.child[1].ident
 			switch {
@@ -175,12 +183,12 @@ func (interp *Interpreter) gta(root *node, rpath, importPath, pkgName string) ([
 				}
 				sym, _, found := sc.lookup(typName)
 				if !found {
-					n.meta = n.cfgErrorf("undefined: %s", typName)
+					n.meta = n.cfgErrorCodef(ErrUndefined, "undefined: %s", typName)
 					revisit = append(revisit, n)
 					return false
 				}
 				if sym.typ.path != pkgName {
-					err = n.cfgErrorf("cannot define new methods on non-local type %s", baseType(sym.typ).id())
+					err = n.cfgErrorCodef(ErrInvalidOperation, "cannot define new methods on non-local type %s", baseType(sym.typ).id())
 					return false
 				}
 				rcvrtype = sym.typ
@@ -206,9 +214,20 @@ func (interp *Interpreter) gta(root *node, rpath, importPath, pkgName string) ([
 				asImportName := filepath.Join(ident, baseName)
 				if _, exists := sc.sym[asImportName]; exists {
 					// redeclaration error
-					err = n.cfgErrorf("%s redeclared in this block", ident)
+					err = n.cfgErrorCodef(ErrRedeclared, "%s redeclared in this block", ident)
 					return false
 				}
+				if len(n.child) < 4 {
+					// A function without body, implemented in assembly, can be provided by the host.
+					if v, ok := interp.asm[importPath][ident]; ok {
+						if n.typ.isComplete() && n.typ.TypeOf() != v.Type() {
+							err = n.cfgErrorCodef(ErrTypeMismatch, "cannot use %s as implementation of %s %s", v.Type(), ident, n.typ.id())
+							return false
+						}
+						sc.sym[ident] = &symbol{kind: binSym, typ: valueTOf(v.Type(), withScope(sc)), rval: v, node: n}
+						return false
+					}
+				}
 				// Add a function symbol in the package name space except for init
 				sc.sym[ident] = &symbol{kind: funcSym, typ: n.typ, node: n, index: -1}
 			}
@@ -267,11 +286,10 @@ func (interp *Interpreter) gta(root *node, rpath, importPath, pkgName string) ([
 					}
 
 					// redeclaration error. Not caught by the parser.
-					err = n.cfgErrorf("%s redeclared in this block", name)
+					err = n.cfgErrorCodef(ErrRedeclared, "%s redeclared in this block", name)
 					return false
 				}
 			} else if pkgName, err = interp.importSrc(rpath, ipath, NoTest); err == nil {
-				sc.types = interp.universe.types
 				switch name {
 				case "_": // no import of symbols
 				case ".": // import symbols in current namespace
@@ -294,20 +312,23 @@ func (interp *Interpreter) gta(root *node, rpath, importPath, pkgName string) ([
 					}
 
 					// redeclaration error
-					err = n.cfgErrorf("%s redeclared as imported package name", name)
+					err = n.cfgErrorCodef(ErrRedeclared, "%s redeclared as imported package name", name)
 					return false
 				}
 			} else {
-				err = n.cfgErrorf("import %q error: %v", ipath, err)
+				err = n.cfgErrorCodef(ErrImport, "import %q error: %v", ipath, err)
 			}
 
 		case typeSpec, typeSpecAssign:
 			if isBlank(n.child[0]) {
-				err = n.cfgErrorf("cannot use _ as value")
+				err = n.cfgErrorCodef(ErrInvalidOperation, "cannot use _ as value")
 				return false
 			}
 			typeName := n.child[0].ident
 			if len(n.child) > 2 {
+				if err = interp.requireLang(n.child[1], importPath, 18, "generic type"); err != nil {
+					return false
+				}
 				// Handle a generic type: skip definition as parameter is not instantiated yet.
 				n.typ = genericOf(nil, typeName, pkgName, withNode(n.child[0]), withScope(sc))
 				if _, exists := sc.sym[typeName]; !exists {
@@ -348,7 +369,7 @@ func (interp *Interpreter) gta(root *node, rpath, importPath, pkgName string) ([
 			asImportName := filepath.Join(typeName, baseName)
 			if _, exists := sc.sym[asImportName]; exists {
 				// redeclaration error
-				err = n.cfgErrorf("%s redeclared in this block", typeName)
+				err = n.cfgErrorCodef(ErrRedeclared, "%s redeclared in this block", typeName)
 				return false
 			}
 			sym, exists := sc.sym[typeName]
@@ -387,6 +408,15 @@ func baseType(t *itype) *itype {
 	}
 }
 
+// derefType returns the type t with pointer indirections removed. Unlike
+// baseType, the name of a defined type is preserved.
+func derefType(t *itype) *itype {
+	for t.cat == ptrT {
+		t = t.val
+	}
+	return t
+}
+
 // gtaRetry (re)applies gta until all global constants and types are defined.
 func (interp *Interpreter) gtaRetry(nodes []*node, importPath, pkgName string) error {
 	revisit := []*node{}
@@ -419,7 +449,7 @@ func (interp *Interpreter) gtaRetry(nodes []*node, importPath, pkgName string) e
 				return err
 			}
 		}
-		return n.cfgErrorf("constant definition loop")
+		return n.cfgErrorCodef(ErrDefinitionLoop, "constant definition loop")
 	}
 	return nil
 }
@@ -456,7 +486,7 @@ func definedType(typ *itype) error {
 			return err
 		}
 	case nilT:
-		return typ.node.cfgErrorf("undefined: %s", typ.node.ident)
+		return typ.node.cfgErrorCodef(ErrUndefined, "undefined: %s", typ.node.ident)
 	}
 	return nil
 }
@@ -0,0 +1,381 @@
+package interp
+
+import (
+	"fmt"
+	"go/constant"
+	"go/token"
+	"reflect"
+	"sort"
+)
+
+// PackageInfo describes the declarations at package level of an interpreted
+// package, exported or not. Declarations are listed in source order.
+type PackageInfo struct {
+	Path   string // import path, or package name for the main package
+	Name   string // package name
+	Consts []ValueInfo
+	Vars   []ValueInfo
+	Funcs  []FuncInfo
+	Types  []TypeInfo
+}
+
+// DeclInfo holds the attributes common to all declarations.
+type DeclInfo struct {
+	Name string
+	Pos  token.Position
+	Doc  string // doc comment, without comment markers
+}
+
+// ValueInfo describes a constant or a variable.
+type ValueInfo struct {
+	DeclInfo
+	Type  string
+	Value string // exact value, for constants only
+}
+
+// ParamInfo describes a function parameter or result.
+type ParamInfo struct {
+	Name string // empty if the parameter is not named
+	Type string
+}
+
+// FuncInfo describes a function or a method.
+type FuncInfo struct {
+	DeclInfo
+	Recv     string // receiver type, for methods only
+	Params   []ParamInfo
+	Results  []ParamInfo
+	Variadic bool // the last parameter is variadic, and its type is a slice
+}
+
+// FieldInfo describes a struct field.
+type FieldInfo struct {
+	DeclInfo
+	Type     string
+	Tag      string
+	Embedded bool
+}
+
+// TypeInfo describes a declared type.
+type TypeInfo struct {
+	DeclInfo
+	Kind       string // kind of the underlying type, as reflect.Kind.String()
+	Alias      bool
+	Underlying string
+	Fields     []FieldInfo // struct fields
+	Methods    []FuncInfo  // declared methods, or interface methods
+}
+
+// SourcePackages returns the import paths of the source packages known by the
+// interpreter, in lexical order. The main package is listed by its name.
+func (interp *Interpreter) SourcePackages() []string {
+	interp.mutex.RLock()
+	defer interp.mutex.RUnlock()
+
+	var res []string
+	for path := range interp.srcPkg {
+		if interp.scopes[path] != nil {
+			res = append(res, path)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Package returns the description of the declarations of the source package
+// of the given import path, or name for the main package, as compiled so far.
+func (interp *Interpreter) Package(path string) (*PackageInfo, error) {
+	interp.compiling.Lock()
+	defer interp.compiling.Unlock()
+	interp.mutex.RLock()
+	defer interp.mutex.RUnlock()
+
+	syms, ok := interp.srcPkg[path]
+	sc := interp.scopes[path]
+	if !ok || sc == nil {
+		return nil, fmt.Errorf("package %q not found", path)
+	}
+
+	pi := &PackageInfo{Path: path, Name: sc.pkgName}
+	for name, s := range syms {
+		if name == "_" || s.node == nil && s.kind != typeSym {
+			continue
+		}
+		switch s.kind {
+		case constSym, varSym:
+			vi := ValueInfo{DeclInfo: interp.declInfo(name, s.node), Type: typeString(s.typ)}
+			if s.kind == varSym {
+				pi.Vars = append(pi.Vars, vi)
+				break
+			}
+			vi.Value = constString(s.rval)
+			pi.Consts = append(pi.Consts, vi)
+		case funcSym:
+			pi.Funcs = append(pi.Funcs, interp.funcInfo(s.node))
+		case typeSym:
+			if n := typeSpecNode(s); n != nil {
+				pi.Types = append(pi.Types, interp.typeInfo(name, n, s.typ))
+			}
+		}
+	}
+
+	sortDecls(pi.Consts, func(v ValueInfo) DeclInfo { return v.DeclInfo })
+	sortDecls(pi.Vars, func(v ValueInfo) DeclInfo { return v.DeclInfo })
+	sortDecls(pi.Funcs, func(f FuncInfo) DeclInfo { return f.DeclInfo })
+	sortDecls(pi.Types, func(t TypeInfo) DeclInfo { return t.DeclInfo })
+	return pi, nil
+}
+
+// declInfo returns the position and doc comment of the declaration of name
+// at node n, with the position of the name itself if found.
+func (interp *Interpreter) declInfo(name string, n *node) DeclInfo {
+	pos := n.pos
+	for _, c := range n.child {
+		if c.kind == identExpr && c.ident == name {
+			pos = c.pos
+			break
+		}
+	}
+	return DeclInfo{Name: name, Pos: interp.fset.Position(pos), Doc: n.doc}
+}
+
+// funcInfo returns the description of the function or method declared by n.
+func (interp *Interpreter) funcInfo(n *node) FuncInfo {
+	name := n.child[1].ident
+	fi := FuncInfo{DeclInfo: interp.declInfo(name, n)}
+	fi.Pos = interp.fset.Position(n.child[1].pos)
+
+	if isMethod(n) {
+		fi.Recv = typeString(n.child[0].child[0].lastChild().typ)
+	}
+
+	// Parameter names are in the AST, and types in the function type.
+	ft := n.child[2]
+	if n.typ == nil || len(ft.child) < 2 {
+		return fi
+	}
+	names := paramNames(ft.child[1])
+	for i, t := range n.typ.arg {
+		p := ParamInfo{Type: typeString(t)}
+		if i < len(names) {
+			p.Name = names[i]
+		}
+		if t.cat == variadicT {
+			fi.Variadic = true
+			p.Type = "..." + typeString(t.val)
+		}
+		fi.Params = append(fi.Params, p)
+	}
+	if len(ft.child) > 2 {
+		names = paramNames(ft.child[2])
+	} else {
+		names = nil
+	}
+	for i, t := range n.typ.ret {
+		p := ParamInfo{Type: typeString(t)}
+		if i < len(names) {
+			p.Name = names[i]
+		}
+		fi.Results = append(fi.Results, p)
+	}
+	return fi
+}
+
+// paramNames returns the names of the parameters of field list n, in order,
+// with empty names for unnamed parameters.
+func paramNames(n *node) (names []string) {
+	for _, f := range n.child {
+		if len(f.child) == 1 {
+			names = append(names, "")
+			continue
+		}
+		for _, c := range f.child[:len(f.child)-1] {
+			names = append(names, c.ident)
+		}
+	}
+	return names
+}
+
+// typeSpecNode returns the type specification node of type symbol s, or nil.
+func typeSpecNode(s *symbol) *node {
+	n := s.node
+	if n == nil && s.typ != nil {
+		n = s.typ.node
+	}
+	for n != nil && n.kind != typeSpec && n.kind != typeSpecAssign {
+		n = n.anc
+	}
+	return n
+}
+
+// typeInfo returns the description of type t, declared by the type
+// specification n.
+func (interp *Interpreter) typeInfo(name string, n *node, t *itype) TypeInfo {
+	ti := TypeInfo{DeclInfo: interp.declInfo(name, n), Alias: n.kind == typeSpecAssign}
+	if t == nil || t.cat == genericT {
+		return ti
+	}
+
+	u := t
+	for u.cat == linkedT && u.val != nil {
+		u = u.val
+	}
+	ti.Underlying = underlyingString(u)
+	switch u.cat {
+	case structT:
+		ti.Kind = reflect.Struct.String()
+	case interfaceT:
+		ti.Kind = reflect.Interface.String()
+	default:
+		if rt := t.TypeOf(); rt != nil {
+			ti.Kind = rt.Kind().String()
+		}
+	}
+
+	// Field and interface method positions and docs are found in the AST.
+	var decls []*node
+	if len(n.child) > 1 {
+		if tn := n.child[1]; (tn.kind == structType || tn.kind == interfaceType) && len(tn.child) > 0 {
+			decls = tn.child[0].child
+		}
+	}
+	var fields []FieldInfo
+	for _, f := range decls {
+		l := len(f.child)
+		if l > 1 && f.child[l-1].kind == basicLit {
+			l-- // Skip field tag.
+		}
+		if l == 1 {
+			fields = append(fields, FieldInfo{DeclInfo: interp.declInfo("", f), Embedded: true})
+			continue
+		}
+		for _, c := range f.child[:l-1] {
+			fi := FieldInfo{DeclInfo: interp.declInfo(c.ident, f)}
+			fi.Pos = interp.fset.Position(c.pos)
+			fields = append(fields, fi)
+		}
+	}
+
+	switch u.cat {
+	case structT:
+		for i, f := range u.field {
+			fi := FieldInfo{DeclInfo: DeclInfo{Name: f.name}}
+			if i < len(fields) {
+				fi = fields[i]
+				fi.Name = f.name
+			}
+			fi.Type, fi.Tag, fi.Embedded = typeString(f.typ), f.tag, f.embed
+			ti.Fields = append(ti.Fields, fi)
+		}
+	case interfaceT:
+		for _, f := range u.field {
+			if f.embed || f.typ == nil || f.typ.cat != funcT {
+				continue
+			}
+			mi := FuncInfo{DeclInfo: DeclInfo{Name: f.name}}
+			for _, d := range fields {
+				if d.Name == f.name {
+					mi.DeclInfo = d.DeclInfo
+				}
+			}
+			for _, a := range f.typ.arg {
+				mi.Params = append(mi.Params, ParamInfo{Type: typeString(a)})
+			}
+			for _, r := range f.typ.ret {
+				mi.Results = append(mi.Results, ParamInfo{Type: typeString(r)})
+			}
+			ti.Methods = append(ti.Methods, mi)
+		}
+	}
+
+	// Methods are attached to the type, and for pointer receivers also to
+	// the pointer type. Each one is listed once.
+	seen := map[*node]bool{}
+	for _, m := range t.method {
+		if !seen[m] && m.kind == funcDecl {
+			seen[m] = true
+			ti.Methods = append(ti.Methods, interp.funcInfo(m))
+		}
+	}
+	if t.ptr != nil {
+		for _, m := range t.ptr.method {
+			if !seen[m] && m.kind == funcDecl {
+				seen[m] = true
+				ti.Methods = append(ti.Methods, interp.funcInfo(m))
+			}
+		}
+	}
+	if u.cat != interfaceT {
+		sortDecls(ti.Methods, func(f FuncInfo) DeclInfo { return f.DeclInfo })
+	}
+	return ti
+}
+
+// sortDecls sorts declarations by source position, then by name.
+func sortDecls[T any](decls []T, info func(T) DeclInfo) {
+	sort.SliceStable(decls, func(i, j int) bool {
+		a, b := info(decls[i]), info(decls[j])
+		if a.Pos.Filename != b.Pos.Filename {
+			return a.Pos.Filename < b.Pos.Filename
+		}
+		if a.Pos.Offset != b.Pos.Offset {
+			return a.Pos.Offset < b.Pos.Offset
+		}
+		return a.Name < b.Name
+	})
+}
+
+// typeString returns the Go representation of type t.
+func typeString(t *itype) string {
+	if t == nil {
+		return ""
+	}
+	if s := t.id(); s != "" {
+		return s
+	}
+	if rt := t.TypeOf(); rt != nil {
+		return rt.String()
+	}
+	return t.cat.String()
+}
+
+// underlyingString returns the Go representation of type t, ignoring its name.
+func underlyingString(t *itype) string {
+	switch t.cat {
+	case arrayT:
+		return arrayOf(t.val, t.length).str
+	case chanT:
+		return chanOf(t.val, chanSendRecv).str
+	case chanRecvT:
+		return chanOf(t.val, chanRecv).str
+	case chanSendT:
+		return chanOf(t.val, chanSend).str
+	case funcT:
+		return funcOf(t.arg, t.ret).str
+	case interfaceT:
+		return interfaceOf(nil, t.field, nil, nil).str
+	case mapT:
+		return mapOf(t.key, t.val).str
+	case ptrT:
+		return ptrOf(t.val).str
+	case sliceT:
+		return sliceOf(t.val).str
+	case structT:
+		return structOf(nil, t.field).str
+	}
+	return typeString(t)
+}
+
+// constString returns the exact representation of constant value v.
+func constString(v reflect.Value) string {
+	if !v.IsValid() {
+		return ""
+	}
+	if c, ok := v.Interface().(constant.Value); ok {
+		return c.ExactString()
+	}
+	if v.Kind() == reflect.String {
+		return fmt.Sprintf("%q", v.String())
+	}
+	return fmt.Sprint(v)
+}
@@ -21,6 +21,7 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // Interpreter node structure for AST and CFG.
@@ -53,6 +54,10 @@ type node struct {
 	ident      string         // set if node is a var or func
 	redeclared bool           // set if node is a redeclared variable (CFG)
 	meta       interface{}    // meta stores meta information between gta runs, like errors
+	vm         *vmFunc        // function lowered to bytecode (funcDecl), or nil
+	unbox      []bool         // frame slots stored unboxed (funcDecl), or nil
+	inline     *inlineBody    // body of an inlinable function (funcDecl), or nil
+	doc        string         // doc comment (declarations)
 }
 
 func (n *node) shouldBreak() bool {
@@ -110,9 +115,13 @@ type frame struct {
 
 	debug *frameDebugData
 
-	root *frame          // global space
-	anc  *frame          // ancestor frame (caller space)
-	data []reflect.Value // values
+	interp *Interpreter    // interpreter owning the global space (root frame only)
+	g      *routine        // tracked goroutine running the frame, if any
+	root   *frame          // global space
+	anc    *frame          // ancestor frame (caller space)
+	pos    token.Pos       // position of the call which created the frame, if any
+	data   []reflect.Value // values
+	num    []uint64        // unboxed storage of scalar values, see unbox.go
 
 	mutex     sync.RWMutex
 	deferred  [][]reflect.Value  // defer stack
@@ -131,6 +140,7 @@ func newFrame(anc *frame, length int, id uint64) *frame {
 	} else {
 		f.done = anc.done
 		f.root = anc.root
+		f.g = anc.g
 	}
 	return f
 }
@@ -142,21 +152,29 @@ func (f *frame) clone() *frame {
 	defer f.mutex.RUnlock()
 	nf := &frame{
 		anc:       f.anc,
+		pos:       f.pos,
 		root:      f.root,
 		deferred:  f.deferred,
 		recovered: f.recovered,
 		id:        f.runid(),
 		done:      f.done,
 		debug:     f.debug,
+		g:         f.g,
 	}
 	nf.data = make([]reflect.Value, len(f.data))
 	copy(nf.data, f.data)
+	nf.num = f.num
 	return nf
 }
 
 // Exports stores the map of binary packages per package path.
 // The package path is the path joined from the import path and the package name
 // as specified in source files by the "package" statement.
+// Symbols registered under the "C/C" path form a virtual C package, which
+// interpreted code can import as "C" in place of cgo. Symbols registered
+// under the "<import path>/_asm" path provide the implementations of the
+// functions declared without body, usually in assembly, by the source
+// package of the import path.
 type Exports map[string]map[string]reflect.Value
 
 // imports stores the map of source packages per package path.
@@ -164,6 +182,9 @@ type imports map[string]map[string]*symbol
 
 // opt stores interpreter options.
 type opt struct {
+	// newEditor returns the line editor of the REPL, see Options.NewLineEditor.
+	newEditor func(complete func(string) (string, []string)) LineEditor
+
 	// dotCmd is the command to process the dot graph produced when astDot and/or
 	// cfgDot is enabled. It defaults to 'dot -Tdot -o <filename>.dot'.
 	dotCmd       string
@@ -174,12 +195,19 @@ type opt struct {
 	args         []string          // cmdline args
 	env          map[string]string // environment of interpreter, entries in form of "key=value"
 	filesystem   fs.FS             // filesystem containing sources
+	err          error             // invalid option, reported at compilation
 	astDot       bool              // display AST graph (debug)
 	cfgDot       bool              // display CFG graph (debug)
 	noRun        bool              // compile, but do not run
 	fastChan     bool              // disable cancellable chan operations
 	specialStdio bool              // allows os.Stdin, os.Stdout, os.Stderr to not be file descriptors
 	unrestricted bool              // allow use of non-sandboxed symbols
+	bytecode     bool              // lower eligible functions to register bytecode
+	network      Network           // network of the interpreted code, or nil for the host one
+	runner       CommandRunner     // runner of the os/exec commands of the interpreted code, or nil
+	noOpt        bool              // disable CFG optimisations (debug)
+	lang         int               // Go language minor version of the packages not part of a module
+	langSet      bool              // lang is set in Options, for all the packages
 }
 
 // Interpreter contains global resources and state.
@@ -201,21 +229,33 @@ type Interpreter struct {
 	cancelChan bool                             // enables cancellable chan operations
 	fset       *token.FileSet                   // fileset to locate node in source code
 	binPkg     Exports                          // binary packages used in interpreter, indexed by path
+	asm        Exports                          // implementations of assembly functions of source packages, indexed by path
 	rdir       map[string]bool                  // for src import cycle detection
+	parsed     srcCache                         // source files parsed ahead of import, see preload
 	mapTypes   map[reflect.Value][]reflect.Type // special interfaces mapping for wrappers
 
 	mutex    sync.RWMutex
-	frame    *frame            // program data storage during execution
-	universe *scope            // interpreter global level scope
-	scopes   map[string]*scope // package level scopes, indexed by import path
-	srcPkg   imports           // source packages used in interpreter, indexed by path
-	pkgNames map[string]string // package names, indexed by import path
-	done     chan struct{}     // for cancellation of channel operations
+	frame    *frame                 // program data storage during execution
+	universe *scope                 // interpreter global level scope
+	scopes   map[string]*scope      // package level scopes, indexed by import path
+	srcPkg   imports                // source packages used in interpreter, indexed by path
+	pkgNames map[string]string      // package names, indexed by import path
+	langs    map[string]langVersion // Go language versions of source packages in modules, by import path
+	done     chan struct{}          // for cancellation of channel operations
 	roots    []*node
 	generic  map[string]*node
 
 	hooks *hooks // symbol hooks
 
+	funcs     funcTable   // function values created during initialization, see Clone
+	compiling *sync.Mutex // serializes compilations among clones sharing code
+
+	goroutines goroutines   // goroutines started by the interpreter
+	sched      *scheduler   // scheduler of goroutines in deterministic mode, or nil
+	sources    *sourceMap   // parsed source texts, for compile errors
+	check      *checker     // diagnostics of Check, or nil
+	checked    *Interpreter // interpreter of the last Check, for queries, or nil
+
 	debugger *Debugger
 }
 
@@ -223,6 +263,7 @@ const (
 	mainID     = "main"
 	selfPrefix = "github.com/traefik/yaegi"
 	selfPath   = selfPrefix + "/interp/interp"
+	asmName    = "_asm" // package name of the Exports of assembly function implementations
 	// DefaultSourceName is the name used by default when the name of the input
 	// source file has not been specified for an Eval.
 	// TODO(mpl): something even more special as a name?
@@ -298,6 +339,26 @@ type Options struct {
 	// BuildTags sets build constraints for the interpreter.
 	BuildTags []string
 
+	// GOOS and GOARCH set the target operating system and architecture used
+	// to select source files and evaluate build constraints. They default
+	// to the host ones. They do not change the behavior of the binary
+	// symbols, such as runtime.GOOS, which remain those of the host.
+	GOOS, GOARCH string
+
+	// GoVersion sets the Go language version, such as "go1.21", used to
+	// evaluate build constraints and to select version dependent semantics,
+	// such as per-iteration loop variables, introduced in go1.22.
+	// If set, it applies to all the source packages, instead of the go
+	// directive of the go.mod file of their module.
+	// By default, build constraints are evaluated for the version of the Go
+	// toolchain building the interpreter, and packages use the version of
+	// the go.mod file of their module, or go1.22 if they are not part of
+	// one. Thus, a script located under a module with an older go directive
+	// gets the semantics of that version, such as loop variables shared by
+	// all the iterations before go1.22. As with the go command, from go1.21
+	// a //go:build line of a file can raise the version of its package.
+	GoVersion string
+
 	// Standard input, output and error streams.
 	// They default to os.Stdin, os.Stdout and os.Stderr respectively.
 	Stdin          io.Reader
@@ -317,6 +378,63 @@ type Options struct {
 
 	// Unrestricted allows to run non sandboxed stdlib symbols such as os/exec and environment
 	Unrestricted bool
+
+	// Bytecode enables an execution tier where functions operating only on
+	// booleans, numbers, strings and slices of those are lowered to a register
+	// bytecode with unboxed values. Other functions are executed as usual.
+	// It can also be enabled by setting the YAEGI_BYTECODE environment variable.
+	Bytecode bool
+
+	// NewLineEditor, if not nil, is called by REPL with a function returning
+	// the word at the end of a line and the names it may be completed with.
+	// It returns the editor reading the lines of the REPL, or nil to read
+	// them from the standard input of the interpreter.
+	NewLineEditor func(complete func(line string) (word string, candidates []string)) LineEditor
+
+	// Deterministic makes the execution of interpreted programs reproducible.
+	// The time package uses a virtual clock, returned by Interpreter.Clock,
+	// the top level functions of math/rand and math/rand/v2 use generators
+	// seeded with Seed, range statements iterate over maps in the order of
+	// their keys, and the goroutines of the interpreter run one at a time,
+	// switching only when blocked (on channel operations, select statements,
+	// sleeps, the locks of package sync and runtime.Gosched), in a
+	// reproducible order.
+	//
+	// The goroutines started by binary packages, and the calls to binary
+	// functions which block, such as I/O, are not made deterministic. A
+	// goroutine which never blocks prevents the other ones from running.
+	Deterministic bool
+
+	// Seed is the seed of the random number generators in deterministic mode.
+	Seed int64
+
+	// StartTime is the initial time of the virtual clock in deterministic
+	// mode. It defaults to 2009-11-10 23:00:00 UTC.
+	StartTime time.Time
+
+	// Network, if not nil, provides the connections of the interpreted code
+	// in place of the host network, allowing to filter, redirect or deny them.
+	// It is used by the dial, listen and lookup functions of packages net,
+	// crypto/tls, net/rpc, net/rpc/jsonrpc, net/smtp and net/textproto,
+	// net.DefaultResolver, http.DefaultTransport, http.DefaultClient and the
+	// functions of net/http using them, http.ListenAndServe, the servers of
+	// net/http/httptest and httputil.NewSingleHostReverseProxy. Packet
+	// connections and log/syslog are denied, and httptest.NewUnstartedServer
+	// is not available. The net.Dialer, net.ListenConfig, net.Resolver,
+	// tls.Dialer, http.Transport, http.Client, http.Server and
+	// httputil.ReverseProxy types are replaced by Dialer, ListenConfig,
+	// Resolver, TLSDialer, HTTPTransport, HTTPClient, HTTPServer and
+	// ReverseProxy, which deny the connections that would use the host
+	// network.
+	// See DenyNetwork and MemNetwork.
+	Network Network
+
+	// Exec, if not nil, provides a restricted os/exec package to the
+	// interpreted code, where the commands created by exec.Command and
+	// exec.CommandContext are run by Exec, allowing to filter, rewrite or
+	// emulate them. The exec.Cmd and exec.ExitError types are replaced by
+	// Cmd and ExitError. See HostRunner.
+	Exec CommandRunner
 }
 
 // New returns a new interpreter.
@@ -328,13 +446,18 @@ func New(options Options) *Interpreter {
 		universe: initUniverse(),
 		scopes:   map[string]*scope{},
 		binPkg:   Exports{"": map[string]reflect.Value{"_error": reflect.ValueOf((*_error)(nil))}},
+		asm:      Exports{},
 		mapTypes: map[reflect.Value][]reflect.Type{},
 		srcPkg:   imports{},
 		pkgNames: map[string]string{},
+		langs:    map[string]langVersion{},
 		rdir:     map[string]bool{},
 		hooks:    &hooks{},
 		generic:  map[string]*node{},
+		sources:  &sourceMap{src: map[string]string{}},
 	}
+	i.frame.interp = &i
+	i.compiling = &sync.Mutex{}
 
 	if i.opt.stdin = options.Stdin; i.opt.stdin == nil {
 		i.opt.stdin = os.Stdin
@@ -370,10 +493,13 @@ func New(options Options) *Interpreter {
 		i.opt.filesystem = options.SourcecodeFilesystem
 	}
 
+	i.opt.newEditor = options.NewLineEditor
 	i.opt.context.GOPATH = options.GoPath
 	if len(options.BuildTags) > 0 {
 		i.opt.context.BuildTags = options.BuildTags
 	}
+	i.opt.lang = defaultLang
+	i.opt.err = i.opt.setTarget(options.GOOS, options.GOARCH, options.GoVersion)
 
 	// astDot activates AST graph display for the interpreter
 	i.opt.astDot, _ = strconv.ParseBool(os.Getenv("YAEGI_AST_DOT"))
@@ -396,9 +522,34 @@ func New(options Options) *Interpreter {
 	// even if they are not file descriptors.
 	i.opt.specialStdio, _ = strconv.ParseBool(os.Getenv("YAEGI_SPECIAL_STDIO"))
 
+	// noOpt disables the inlining and constant propagation performed by the CFG builder
+	i.opt.noOpt, _ = strconv.ParseBool(os.Getenv("YAEGI_NO_OPT"))
+
+	// bytecode enables the register bytecode execution tier for eligible functions.
+	if i.opt.bytecode = options.Bytecode; !i.opt.bytecode {
+		i.opt.bytecode, _ = strconv.ParseBool(os.Getenv("YAEGI_BYTECODE"))
+	}
+
+	i.opt.network = options.Network
+	i.opt.runner = options.Exec
+
+	if options.Deterministic {
+		i.sched = newScheduler(options.Seed, options.StartTime)
+		i.sched.goroutines = &i.goroutines
+	}
+
 	return &i
 }
 
+// Clock returns the virtual clock of the interpreter in deterministic mode,
+// or nil, see Options.Deterministic.
+func (interp *Interpreter) Clock() *Clock {
+	if interp.sched == nil {
+		return nil
+	}
+	return interp.sched.clock
+}
+
 const (
 	bltnAlignof  = "unsafe.Alignof"
 	bltnAppend   = "append"
@@ -421,7 +572,7 @@ const (
 )
 
 func initUniverse() *scope {
-	sc := &scope{global: true, sym: map[string]*symbol{
+	sc := &scope{global: true, mu: &sync.Mutex{}, sym: map[string]*symbol{
 		// predefined Go types
 		"any":         {kind: typeSym, typ: &itype{cat: interfaceT, str: "any"}},
 		"bool":        {kind: typeSym, typ: &itype{cat: boolT, name: "bool", str: "bool"}},
@@ -477,14 +628,17 @@ func initUniverse() *scope {
 
 // resizeFrame resizes the global frame of interpreter.
 func (interp *Interpreter) resizeFrame() {
-	l := len(interp.universe.types)
+	interp.universe.mu.Lock()
+	types := interp.universe.types
+	interp.universe.mu.Unlock()
+	l := len(types)
 	b := len(interp.frame.data)
 	if l-b <= 0 {
 		return
 	}
 	data := make([]reflect.Value, l)
 	copy(data, interp.frame.data)
-	for j, t := range interp.universe.types[b:] {
+	for j, t := range types[b:] {
 		data[b+j] = reflect.New(t).Elem()
 	}
 	interp.frame.data = data
@@ -502,7 +656,7 @@ func (interp *Interpreter) Eval(src string) (res reflect.Value, err error) {
 func (interp *Interpreter) EvalPath(path string) (res reflect.Value, err error) {
 	if !isFile(interp.opt.filesystem, path) {
 		_, err := interp.importSrc(mainID, path, NoTest)
-		return res, err
+		return res, interp.compileError(err)
 	}
 
 	b, err := fs.ReadFile(interp.filesystem, path)
@@ -522,7 +676,9 @@ func (interp *Interpreter) EvalPathWithContext(ctx context.Context, path string)
 	interp.mutex.Unlock()
 
 	done := make(chan struct{})
+	_, exit := interp.goroutines.start(nil, "", token.NoPos)
 	go func() {
+		defer exit()
 		defer close(done)
 		res, err = interp.EvalPath(path)
 	}()
@@ -542,7 +698,7 @@ func (interp *Interpreter) EvalPathWithContext(ctx context.Context, path string)
 // executed. Test functions can be retrieved using the Symbol() method.
 func (interp *Interpreter) EvalTest(path string) error {
 	_, err := interp.importSrc(mainID, path, Test)
-	return err
+	return interp.compileError(err)
 }
 
 func isFile(filesystem fs.FS, path string) bool {
@@ -551,22 +707,37 @@ func isFile(filesystem fs.FS, path string) bool {
 }
 
 func (interp *Interpreter) eval(src, name string, inc bool) (res reflect.Value, err error) {
+	res, _, err = interp.evalType(src, name, inc)
+	return res, err
+}
+
+// evalType is as eval, and also returns the interpreted type of the result.
+func (interp *Interpreter) evalType(src, name string, inc bool) (res reflect.Value, t *itype, err error) {
 	prog, err := interp.compileSrc(src, name, inc)
 	if err != nil {
-		return res, err
+		return res, nil, err
 	}
 
 	if interp.noRun {
-		return res, err
+		return res, nil, err
 	}
 
-	return interp.Execute(prog)
+	res, err = interp.Execute(prog)
+	return res, prog.root.typ, err
 }
 
 // EvalWithContext evaluates Go code represented as a string. It returns
 // a map on current interpreted package exported symbols.
 func (interp *Interpreter) EvalWithContext(ctx context.Context, src string) (reflect.Value, error) {
+	v, _, err := interp.evalWithContext(ctx, src)
+	return v, err
+}
+
+// evalWithContext is as EvalWithContext, and also returns the interpreted
+// type of the result.
+func (interp *Interpreter) evalWithContext(ctx context.Context, src string) (reflect.Value, *itype, error) {
 	var v reflect.Value
+	var t *itype
 	var err error
 
 	interp.mutex.Lock()
@@ -575,7 +746,9 @@ func (interp *Interpreter) EvalWithContext(ctx context.Context, src string) (ref
 	interp.mutex.Unlock()
 
 	done := make(chan struct{})
+	_, exit := interp.goroutines.start(nil, "", token.NoPos)
 	go func() {
+		defer exit()
 		defer func() {
 			if r := recover(); r != nil {
 				var pc [64]uintptr
@@ -584,24 +757,32 @@ func (interp *Interpreter) EvalWithContext(ctx context.Context, src string) (ref
 			}
 			close(done)
 		}()
-		v, err = interp.Eval(src)
+		v, t, err = interp.evalType(src, "", true)
 	}()
 
 	select {
 	case <-ctx.Done():
 		interp.stop()
-		return reflect.Value{}, ctx.Err()
+		return reflect.Value{}, nil, ctx.Err()
 	case <-done:
 	}
-	return v, err
+	return v, t, err
 }
 
 // stop sends a semaphore to all running frames and closes the chan
-// operation short circuit channel. stop may only be called once per
-// invocation of EvalWithContext.
+// operation short circuit channel, if not already done.
 func (interp *Interpreter) stop() {
+	interp.mutex.Lock()
+	defer interp.mutex.Unlock()
 	atomic.AddUint64(&interp.id, 1)
-	close(interp.done)
+	if interp.done == nil {
+		return
+	}
+	select {
+	case <-interp.done:
+	default:
+		close(interp.done)
+	}
 }
 
 func (interp *Interpreter) runid() uint64 { return atomic.LoadUint64(&interp.id) }
@@ -658,6 +839,18 @@ func fixKey(k string) string {
 	return k
 }
 
+// LineEditor reads the lines entered in the REPL, see Options.NewLineEditor.
+type LineEditor interface {
+	// ReadLine displays the prompt and returns the line entered. It returns
+	// io.EOF at the end of input, and an error matching ErrInterrupt to
+	// discard the lines of an incomplete statement.
+	ReadLine(prompt string) (string, error)
+}
+
+// ErrInterrupt is returned by a LineEditor when the edition of a line is
+// interrupted, typically by Ctrl-C.
+var ErrInterrupt = errors.New("interrupt")
+
 // REPL performs a Read-Eval-Print-Loop on input reader.
 // Results are printed to the output writer of the Interpreter, provided as option
 // at creation time. Errors are printed to the similarly defined errors writer.
@@ -665,35 +858,53 @@ func fixKey(k string) string {
 func (interp *Interpreter) REPL() (reflect.Value, error) {
 	in, out, errs := interp.stdin, interp.stdout, interp.stderr
 	ctx, cancel := context.WithCancel(context.Background())
-	end := make(chan struct{})     // channel to terminate the REPL
-	sig := make(chan os.Signal, 1) // channel to trap interrupt signal (Ctrl-C)
-	lines := make(chan string)     // channel to read REPL input lines
-	prompt := getPrompt(in, out)   // prompt activated on tty like IO stream
-	s := bufio.NewScanner(in)      // read input stream line by line
-	var v reflect.Value            // result value from eval
-	var err error                  // error from eval
-	src := ""                      // source string to evaluate
+	end := make(chan struct{})                   // channel to terminate the REPL
+	sig := make(chan os.Signal, 1)               // channel to trap interrupt signal (Ctrl-C)
+	lines := make(chan string)                   // channel to read REPL input lines
+	session := &replSession{}                    // state for meta-commands
+	prompt := getPrompt(in, out, session.result) // prompt activated on tty like IO stream
+	var ed LineEditor                            // line editor, if any
+	var v reflect.Value                          // result value from eval
+	var t *itype                                 // interpreted type of the result
+	var err error                                // error from eval
+	src := ""                                    // source string to evaluate
 
 	signal.Notify(sig, os.Interrupt)
 	defer signal.Stop(sig)
-	prompt(v)
 
-	go func() {
+	if interp.opt.newEditor != nil {
+		ed = interp.opt.newEditor(interp.completeLine)
+	}
+	if ed != nil {
+		// Lines are read synchronously, the editor displays the prompt.
 		defer close(end)
-		for s.Scan() {
-			lines <- s.Text()
-		}
-		if e := s.Err(); e != nil {
-			fmt.Fprintln(errs, e)
+		prompt = func(v reflect.Value, t *itype) {
+			if s := session.result(v, t); s != "" {
+				fmt.Fprintln(out, ":", s)
+			}
 		}
-	}()
+	} else {
+		prompt(v, t)
+		s := bufio.NewScanner(in) // read input stream line by line
+		go func() {
+			defer close(end)
+			for s.Scan() {
+				lines <- s.Text()
+			}
+			if e := s.Err(); e != nil {
+				fmt.Fprintln(errs, e)
+			}
+		}()
+	}
 
 	go func() {
 		for {
 			select {
 			case <-sig:
 				cancel()
-				lines <- ""
+				if ed == nil {
+					lines <- ""
+				}
 			case <-end:
 				return
 			}
@@ -703,26 +914,55 @@ func (interp *Interpreter) REPL() (reflect.Value, error) {
 	for {
 		var line string
 
-		select {
-		case <-end:
-			cancel()
-			return v, err
-		case line = <-lines:
-			src += line + "\n"
+		if ed != nil {
+			p := "> "
+			if src != "" {
+				p = ""
+			}
+			l, e := ed.ReadLine(p)
+			if errors.Is(e, ErrInterrupt) {
+				src = ""
+				continue
+			}
+			if e != nil {
+				if !errors.Is(e, io.EOF) {
+					fmt.Fprintln(errs, e)
+				}
+				cancel()
+				return v, err
+			}
+			line = l
+		} else {
+			select {
+			case <-end:
+				cancel()
+				return v, err
+			case line = <-lines:
+			}
 		}
-
-		v, err = interp.EvalWithContext(ctx, src)
-		if err != nil {
-			switch e := err.(type) {
-			case scanner.ErrorList:
-				if len(e) > 0 && ignoreScannerError(e[0], line) {
+		if src == "" && strings.HasPrefix(strings.TrimSpace(line), ":") {
+			if e := interp.replCommand(ctx, session, strings.TrimSpace(line)); e != nil {
+				fmt.Fprintln(errs, e)
+			}
+			prompt(reflect.Value{}, nil)
+			continue
+		}
+		src += line + "\n"
+
+		v, t, err = interp.evalWithContext(ctx, src)
+		if err == nil {
+			session.src = append(session.src, src)
+		} else {
+			var el scanner.ErrorList
+			if errors.As(err, &el) && len(el) > 0 {
+				if ignoreScannerError(el[0], line) {
 					continue
 				}
-				fmt.Fprintln(errs, strings.TrimPrefix(e[0].Error(), DefaultSourceName+":"))
-			case Panic:
+				fmt.Fprintln(errs, strings.TrimPrefix(el[0].Error(), DefaultSourceName+":"))
+			} else if e, ok := err.(Panic); ok {
 				fmt.Fprintln(errs, e.Value)
 				fmt.Fprintln(errs, string(e.Stack))
-			default:
+			} else {
 				fmt.Fprintln(errs, err)
 			}
 		}
@@ -730,32 +970,32 @@ func (interp *Interpreter) REPL() (reflect.Value, error) {
 			ctx, cancel = context.WithCancel(context.Background())
 		}
 		src = ""
-		prompt(v)
+		prompt(v, t)
 	}
 }
 
-func doPrompt(out io.Writer) func(v reflect.Value) {
-	return func(v reflect.Value) {
-		if v.IsValid() {
-			fmt.Fprintln(out, ":", v)
+func doPrompt(out io.Writer, result func(reflect.Value, *itype) string) func(reflect.Value, *itype) {
+	return func(v reflect.Value, t *itype) {
+		if s := result(v, t); s != "" {
+			fmt.Fprintln(out, ":", s)
 		}
 		fmt.Fprint(out, "> ")
 	}
 }
 
 // getPrompt returns a function which prints a prompt only if input is a terminal.
-func getPrompt(in io.Reader, out io.Writer) func(reflect.Value) {
+func getPrompt(in io.Reader, out io.Writer, result func(reflect.Value, *itype) string) func(reflect.Value, *itype) {
 	forcePrompt, _ := strconv.ParseBool(os.Getenv("YAEGI_PROMPT"))
 	if forcePrompt {
-		return doPrompt(out)
+		return doPrompt(out, result)
 	}
 	s, ok := in.(interface{ Stat() (os.FileInfo, error) })
 	if !ok {
-		return func(reflect.Value) {}
+		return func(reflect.Value, *itype) {}
 	}
 	stat, err := s.Stat()
 	if err == nil && stat.Mode()&os.ModeCharDevice != 0 {
-		return doPrompt(out)
+		return doPrompt(out, result)
 	}
-	return func(reflect.Value) {}
+	return func(reflect.Value, *itype) {}
 }
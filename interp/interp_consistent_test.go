@@ -24,12 +24,7 @@ func TestInterpConsistencyBuild(t *testing.T) {
 	if testing.Short() {
 		t.Skip("short mode")
 	}
-	dir := filepath.Join("..", "_test", "tmp")
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		if err := os.Mkdir(dir, 0o700); err != nil {
-			t.Fatal(err)
-		}
-	}
+	dir := t.TempDir()
 
 	baseDir := filepath.Join("..", "_test")
 	files, err := os.ReadDir(baseDir)
@@ -4,13 +4,19 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"go/build"
 	"go/parser"
+	"go/scanner"
+	"go/token"
 	"io"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"os/exec"
+	"path"
 	"path/filepath"
 	"reflect"
 	"runtime"
@@ -18,10 +24,12 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/traefik/yaegi/interp"
 	"github.com/traefik/yaegi/stdlib"
+	"github.com/traefik/yaegi/stdlib/unsafe"
 )
 
 func init() { log.SetFlags(log.Lshortfile) }
@@ -1643,12 +1651,12 @@ func TestREPLCommands(t *testing.T) {
 			`reflect.TypeOf(crypto_rand.Int)`,
 		}
 		output := []string{
-			`1`,
-			`2`,
-			`3`,
-			`1.5`,
-			`func() int`,
-			`func(io.Reader, *big.Int) (*big.Int, error)`,
+			`int = 1`,
+			`int = 2`,
+			`int = 3`,
+			`float64 = 1.5`,
+			`reflect.Type = func() int`,
+			`reflect.Type = func(io.Reader, *big.Int) (*big.Int, error)`,
 		}
 
 		go func() {
@@ -1930,3 +1938,1404 @@ func TestIssue1623(t *testing.T) {
 		{desc: `pkg.S = "bar"`, src: `pkg.S = "bar"; pkg.S`, res: "bar"},
 	})
 }
+
+func TestClone(t *testing.T) {
+	i := interp.New(interp.Options{})
+	if err := i.Use(stdlib.Symbols); err != nil {
+		t.Fatal(err)
+	}
+	_, err := i.Eval(`
+import "strconv"
+
+var (
+	count int
+	seen  = map[string]int{}
+	next  = counter()
+)
+
+func counter() func() int {
+	n := 0
+	return func() int { n++; return n }
+}
+
+func init() { count = 10 }
+
+func Add(s string) string {
+	count++
+	seen[s]++
+	return s + strconv.Itoa(count) + strconv.Itoa(seen[s]) + strconv.Itoa(next())
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := i.Eval(`Add("a")`); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for k := 0; k < 4; k++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c, err := i.Clone()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			v, err := c.Eval("Add")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			add := v.Interface().(func(string) string)
+			for _, want := range []string{"a1222", "a1333", "b1414"} {
+				if got := add(want[:1]); got != want {
+					t.Errorf("got %q, want %q", got, want)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	res, err := i.Eval(`Add("a")`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := res.String(); got != "a1222" {
+		t.Errorf("got %q, want %q", got, "a1222")
+	}
+}
+
+func TestCloneSnapshot(t *testing.T) {
+	var out bytes.Buffer
+	i := interp.New(interp.Options{Stdout: &out})
+	if err := i.Use(stdlib.Symbols); err != nil {
+		t.Fatal(err)
+	}
+	_, err := i.Eval(`
+import (
+	"fmt"
+	"net/http"
+)
+
+type T struct {
+	n int
+	s []int
+	m map[string]*int
+}
+
+func (t *T) Inc() { t.n++ }
+
+var (
+	x   = 1
+	px  = &x
+	t0  = &T{m: map[string]*int{}}
+	inc = func() int { x++; return x }
+	ops = map[string]func() int{"inc": inc, "get": get}
+	h   = http.HandlerFunc(func(http.ResponseWriter, *http.Request) { x += 100 })
+	any interface{} = []int{1, 2}
+)
+
+func get() int { return x }
+
+func init() {
+	fmt.Println("init")
+	t0.m["x"] = px
+	t0.s = append(t0.s, 1)
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := i.Eval(`fmt.Println("stmt")`); err != nil {
+		t.Fatal(err)
+	}
+
+	const state = `fmt.Sprint(x, ops["get"](), *t0.m["x"], t0.n, t0.s, any)`
+	for k := 0; k < 2; k++ {
+		c, err := i.Clone()
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := c.Eval(`*px = 10; inc(); ops["inc"](); h(nil, nil); t0.Inc(); t0.s[0] = 5; any.([]int)[0] = 9; ` + state)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := res.String(), "112 112 112 1 [5] [9 2]"; got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+
+	res, err := i.Eval(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := res.String(), "1 1 1 0 [1] [1 2]"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := out.String(), "init\nstmt\n"; got != want {
+		t.Errorf("got output %q, want %q", got, want)
+	}
+}
+
+func TestCloneIsolation(t *testing.T) {
+	i := interp.New(interp.Options{})
+	if err := i.Use(stdlib.Symbols); err != nil {
+		t.Fatal(err)
+	}
+	_, err := i.Eval(`
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var (
+	buf  = &bytes.Buffer{}
+	sb   strings.Builder
+	c    = make(chan int, 4)
+	done = make(chan bool)
+	eof  = io.EOF
+	next func() int
+)
+
+func init() {
+	buf.WriteString("init;")
+	c <- 1
+	close(done)
+}
+
+func counter() func() int {
+	n := 0
+	return func() int { n++; return n }
+}
+
+func Write(s string) {
+	buf.WriteString(s + ";")
+	sb.WriteString(s)
+	c <- next()
+}
+
+func State() string {
+	var s []string
+	for len(c) > 0 {
+		s = append(s, strconv.Itoa(<-c))
+	}
+	_, open := <-done
+	return buf.String() + " " + sb.String() + " " + strings.Join(s, ",") + " " + strconv.FormatBool(open) + " " + strconv.FormatBool(eof == io.EOF)
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The counter is created after the initialization of the global state.
+	if _, err := i.Eval("next = counter(); next()"); err != nil {
+		t.Fatal(err)
+	}
+
+	clones := make([]*interp.Interpreter, 2)
+	for k := range clones {
+		c, err := i.Clone()
+		if err != nil {
+			t.Fatal(err)
+		}
+		clones[k] = c
+	}
+	for k, c := range clones {
+		for n := 0; n <= k; n++ {
+			if _, err := c.Eval(fmt.Sprintf("Write(%q)", fmt.Sprint("c", k))); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	for k, want := range []string{
+		"init;c0; c0 1,2 false true",
+		"init;c1;c1; c1c1 1,2,3 false true",
+	} {
+		res, err := clones[k].Eval("State()")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := res.String(); got != want {
+			t.Errorf("clone %d: got %q, want %q", k, got, want)
+		}
+	}
+
+	res, err := i.Eval("State()")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := res.String(), "init;  1 false true"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	i := interp.New(interp.Options{})
+	if err := i.Use(stdlib.Symbols); err != nil {
+		t.Fatal(err)
+	}
+	_, err := i.EvalWithContext(context.Background(), `
+import "time"
+
+func spin() { for {} }
+
+func block(c chan int) { wait(c) }
+func wait(c chan int)  { <-c }
+var ready = make(chan bool)
+
+func sleep() { ready <- true; time.Sleep(time.Hour) }
+
+func start() {
+	go spin()
+	go block(make(chan int))
+	go sleep()
+	<-ready
+}
+
+func init() {
+	done := make(chan bool)
+	go func() { done <- true }()
+	<-done
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := i.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := i.EvalWithContext(context.Background(), "start()"); err != nil {
+		t.Fatal(err)
+	}
+	gs := i.Goroutines()
+	if n := len(gs); n != 3 {
+		t.Fatalf("got %d goroutines, want 3", n)
+	}
+	for deadline := time.Now().Add(time.Second); !gs[1].Blocked && time.Now().Before(deadline); gs = i.Goroutines() {
+		time.Sleep(time.Millisecond)
+	}
+	if g := gs[1]; g.Func != "main.block" || !g.Blocked || stackLines(g) != "7 6 14 1" {
+		t.Errorf("unexpected blocked goroutine: %v", g)
+	}
+	if g := gs[0]; g.Func != "main.spin" || g.Blocked || stackLines(g) != "13 1" {
+		t.Errorf("unexpected running goroutine: %v", g)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err = i.Shutdown(ctx)
+	var leak interp.LeakError
+	if !errors.As(err, &leak) {
+		t.Fatalf("got %v, want a LeakError", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want a deadline exceeded error", leak.Err)
+	}
+	if len(leak.Goroutines) != 1 {
+		t.Fatalf("got %v, want 1 leaked goroutine", err)
+	}
+	g := leak.Goroutines[0]
+	if g.Func != "main.sleep" || !g.Blocked || stackLines(g) != "10 15 1" {
+		t.Errorf("unexpected leaked goroutine: %v", g)
+	}
+}
+
+// stackLines returns the line numbers of the stack of g.
+func stackLines(g interp.Goroutine) string {
+	lines := make([]string, len(g.Stack))
+	for i, p := range g.Stack {
+		lines[i] = strconv.Itoa(p.Line)
+	}
+	return strings.Join(lines, " ")
+}
+
+func TestLookup(t *testing.T) {
+	i := interp.New(interp.Options{})
+	if err := i.Use(stdlib.Symbols); err != nil {
+		t.Fatal(err)
+	}
+	_, err := i.Eval(`
+import "strings"
+
+type Base struct{ n int }
+
+func (b *Base) Add(d int) int { b.n += d; return b.n }
+
+type T struct {
+	Base
+	name string
+}
+
+func (t T) Name(upper bool) string {
+	if upper {
+		return strings.ToUpper(t.name)
+	}
+	return t.name
+}
+
+var (
+	Count = 3
+	V     = &T{name: "foo"}
+)
+
+func Hello(s string) string { return "hello " + s }
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hello, err := interp.Func[func(string) string](i, "Hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hello("bob"); got != "hello bob" {
+		t.Errorf("got %q", got)
+	}
+
+	upper, err := interp.Func[func(string) string](i, "strings.ToUpper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := upper("bob"); got != "BOB" {
+		t.Errorf("got %q", got)
+	}
+
+	count, err := interp.Var[int](i, "main.Count")
+	if err != nil {
+		t.Fatal(err)
+	}
+	*count++
+	if res, err := i.Eval("Count"); err != nil || res.Int() != 4 {
+		t.Errorf("got %v, %v", res, err)
+	}
+
+	v, err := i.Eval("V")
+	if err != nil {
+		t.Fatal(err)
+	}
+	name, err := interp.Method[func(bool) string](i, v, "Name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := name(true); got != "FOO" {
+		t.Errorf("got %q", got)
+	}
+	add, err := interp.Method[func(int) int](i, v, "Add")
+	if err != nil {
+		t.Fatal(err)
+	}
+	add(2)
+	if got := add(3); got != 5 {
+		t.Errorf("got %d, want 5", got)
+	}
+	if res, err := i.Eval("V.Add(0)"); err != nil || res.Int() != 5 {
+		t.Errorf("got %v, %v", res, err)
+	}
+
+	for _, test := range []struct {
+		name string
+		fn   func() error
+		err  string
+	}{
+		{"Hello", func() error { _, err := interp.Func[func(int) string](i, "Hello"); return err }, "Hello: cannot use func(string) string as func(int) string"},
+		{"Count", func() error { _, err := interp.Func[func()](i, "Count"); return err }, "Count: symbol is a variable, not a function"},
+		{"Count", func() error { _, err := interp.Var[string](i, "Count"); return err }, "Count: cannot use int as string"},
+		{"Hello", func() error { _, err := interp.Var[int](i, "Hello"); return err }, "Hello: symbol is a function, not a variable"},
+		{"Nope", func() error { _, err := interp.Func[func()](i, "foo.Nope"); return err }, "foo.Nope: symbol not found"},
+		{"Name", func() error { _, err := interp.Method[func() string](i, v, "Name"); return err }, "Name: cannot use func(bool) string as func() string"},
+		{"Sub", func() error { _, err := interp.Method[func() string](i, v, "Sub"); return err }, "Sub: no method Sub for type"},
+	} {
+		if err := test.fn(); err == nil || !strings.HasPrefix(err.Error(), test.err) {
+			t.Errorf("%s: got error %v, want %q", test.name, err, test.err)
+		}
+	}
+}
+
+func TestPackageInfo(t *testing.T) {
+	i := interp.New(interp.Options{})
+	_, err := i.Eval(`package main
+
+// Max is the maximum.
+const Max = 10
+
+var x, y = 1, "s"
+
+// Point is a point.
+type Point struct {
+	// X is the abscissa.
+	X, Y int ` + "`json:\"x\"`" + `
+	name string
+}
+
+// Move moves p.
+func (p *Point) Move(dx, dy int) { p.X += dx }
+
+type Shape interface {
+	Area() float64
+}
+
+// Sum sums.
+func Sum(base int, vals ...int) (total int) { return }
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := i.Package("nope"); err == nil {
+		t.Error("expected an error for an unknown package")
+	}
+	p, err := i.Package("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, c := range p.Consts {
+		got = append(got, fmt.Sprintf("const %s %s = %s // %d %q", c.Name, c.Type, c.Value, c.Pos.Line, c.Doc))
+	}
+	for _, v := range p.Vars {
+		got = append(got, fmt.Sprintf("var %s %s // %d", v.Name, v.Type, v.Pos.Line))
+	}
+	for _, ty := range p.Types {
+		got = append(got, fmt.Sprintf("type %s %s %s // %d %q", ty.Name, ty.Kind, ty.Underlying, ty.Pos.Line, ty.Doc))
+		for _, f := range ty.Fields {
+			got = append(got, fmt.Sprintf("\tfield %s %s %q // %d %q", f.Name, f.Type, f.Tag, f.Pos.Line, f.Doc))
+		}
+		for _, m := range ty.Methods {
+			got = append(got, fmt.Sprintf("\tmethod (%s) %s%v%v // %d %q", m.Recv, m.Name, m.Params, m.Results, m.Pos.Line, m.Doc))
+		}
+	}
+	for _, f := range p.Funcs {
+		got = append(got, fmt.Sprintf("func %s%v%v %v // %d %q", f.Name, f.Params, f.Results, f.Variadic, f.Pos.Line, f.Doc))
+	}
+	want := []string{
+		`const Max untyped int = 10 // 4 "Max is the maximum.\n"`,
+		`var x int // 6`,
+		`var y string // 6`,
+		`type Point struct struct { X int; Y int; name string} // 9 "Point is a point.\n"`,
+		`	field X int "json:\"x\"" // 11 "X is the abscissa.\n"`,
+		`	field Y int "json:\"x\"" // 11 "X is the abscissa.\n"`,
+		`	field name string "" // 12 ""`,
+		`	method (*main.Point) Move[{dx int} {dy int}][] // 16 "Move moves p.\n"`,
+		`type Shape interface interface { Area() float64} // 18 ""`,
+		`	method () Area[][{ float64}] // 19 ""`,
+		`func Sum[{base int} {vals ...int}][{total int}] true // 23 "Sum sums.\n"`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestCompileError(t *testing.T) {
+	tests := []struct {
+		desc, src    string
+		code         interp.ErrorCode
+		pos, end     string // line:column
+		snippet, err string
+	}{
+		{
+			desc:    "undefined",
+			src:     "func f() int { return 1 + undefinedX }",
+			code:    interp.ErrUndefined,
+			pos:     "1:40",
+			end:     "1:50",
+			snippet: "package main;func f() int { return 1 + undefinedX }",
+			err:     "1:40: undefined: undefinedX",
+		},
+		{
+			desc:    "type mismatch",
+			src:     "var a int\nvar b string = a",
+			code:    interp.ErrTypeMismatch,
+			pos:     "2:16",
+			end:     "2:17",
+			snippet: "var b string = a",
+			err:     "2:16: cannot use type int as type string in assignment",
+		},
+		{
+			desc: "import",
+			src:  `import "guthib.com/nowhere"`,
+			code: interp.ErrImport,
+			pos:  "1:21",
+			end:  "1:41",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			i := interp.New(interp.Options{GoPath: "./_pkg"})
+			_, err := i.Eval(test.src)
+			var ce *interp.CompileError
+			if !errors.As(err, &ce) {
+				t.Fatalf("got %v, want a CompileError", err)
+			}
+			if ce.Code != test.code {
+				t.Errorf("got code %v, want %v", ce.Code, test.code)
+			}
+			if got := fmt.Sprintf("%s:%d:%d", ce.Pos.Filename, ce.Pos.Line, ce.Pos.Column); got != interp.DefaultSourceName+":"+test.pos {
+				t.Errorf("got position %s, want %s", got, test.pos)
+			}
+			if got := fmt.Sprintf("%d:%d", ce.End.Line, ce.End.Column); got != test.end {
+				t.Errorf("got end position %s, want %s", got, test.end)
+			}
+			if test.snippet != "" && ce.Snippet != test.snippet {
+				t.Errorf("got snippet %q, want %q", ce.Snippet, test.snippet)
+			}
+			if test.err != "" && err.Error() != test.err {
+				t.Errorf("got error %q, want %q", err, test.err)
+			}
+		})
+	}
+
+	// Syntax errors wrap the errors of go/parser.
+	_, err := interp.New(interp.Options{}).Eval("func (")
+	var ce *interp.CompileError
+	if !errors.As(err, &ce) || ce.Code != interp.ErrSyntax {
+		t.Errorf("got %#v, want a syntax CompileError", err)
+	}
+	if !errors.As(err, &scanner.ErrorList{}) {
+		t.Errorf("got %T, want a wrapped scanner.ErrorList", err)
+	}
+
+	_, err = interp.New(interp.Options{GoPath: "./_pkg"}).EvalPath("guthib.com/nowhere")
+	if !errors.As(err, &ce) || ce.Code != interp.ErrImport || ce.Pos.IsValid() {
+		t.Errorf("got %#v, want an import CompileError without position", err)
+	}
+}
+
+func TestIdentAt(t *testing.T) {
+	src := `package main
+
+import "strings"
+
+// T is a test type.
+type T struct {
+	// Name is the name.
+	Name string
+	n    int
+}
+
+// Get returns n.
+func (t *T) Get() int { return t.n }
+
+func main() {
+	v := &T{Name: "x"}
+	s := strings.ToUpper(v.Name)
+	println(s, v.Get())
+}
+`
+	i := interp.New(interp.Options{SourcecodeFilesystem: fstest.MapFS{"main.go": {Data: []byte(src)}}})
+	if err := i.Use(stdlib.Symbols); err != nil {
+		t.Fatal(err)
+	}
+	if err := i.Check("main.go"); err != nil {
+		t.Fatal(err)
+	}
+	pos := func(line, col int) token.Position {
+		return token.Position{Filename: "main.go", Line: line, Column: col}
+	}
+
+	tests := []struct {
+		line, col int
+		want      string
+	}{
+		{16, 2, "variable v *main.T at 16:2: "},
+		{17, 7, "package strings strings at -: "},
+		{17, 15, "function ToUpper func(string) string at -: "},
+		{17, 25, "field Name string at 8:2: Name is the name.\n"},
+		{18, 10, "variable s string at 17:2: "},
+		{18, 15, "method Get func() int at 13:13: Get returns n.\n"},
+		{13, 10, "type T struct { Name string; n int} at 6:6: T is a test type.\n"},
+		{1, 1, "<nil>"},
+	}
+	for _, test := range tests {
+		id := i.IdentAt(pos(test.line, test.col))
+		got := "<nil>"
+		if id != nil {
+			decl := "-"
+			if id.Decl.Pos.IsValid() {
+				decl = fmt.Sprintf("%d:%d", id.Decl.Pos.Line, id.Decl.Pos.Column)
+			}
+			got = fmt.Sprintf("%s %s %s at %s: %s", id.Kind, id.Name, id.Type, decl, id.Decl.Doc)
+		}
+		if got != test.want {
+			t.Errorf("%d:%d: got %q, want %q", test.line, test.col, got, test.want)
+		}
+	}
+
+	var names []string
+	for _, c := range i.Completions(pos(18, 15)) {
+		names = append(names, c.Kind+" "+c.Name)
+	}
+	if got, want := strings.Join(names, ", "), "method Get, field Name, field n"; got != want {
+		t.Errorf("got completions %q, want %q", got, want)
+	}
+	names = nil
+	for _, c := range i.Completions(pos(18, 4)) {
+		names = append(names, c.Name)
+	}
+	if got, want := strings.Join(names, ", "), "print, println"; got != want {
+		t.Errorf("got completions %q, want %q", got, want)
+	}
+}
+
+func TestEvalTarget(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main/main.go": {Data: []byte(`package main
+
+import "fmt"
+
+func main() {
+	var fs []func() int
+	for i := 0; i < 2; i++ {
+		fs = append(fs, func() int { return i })
+	}
+	fmt.Println(target, version, fs[0](), fs[1]())
+}
+`)},
+		"main/target_linux.go":        {Data: []byte("package main\n\nconst target = \"linux\"\n")},
+		"main/target_windows.go":      {Data: []byte("package main\n\nconst target = \"windows\"\n")},
+		"main/target_darwin_arm64.go": {Data: []byte("package main\n\nconst target = \"darwin/arm64\"\n")},
+		"main/version_new.go":         {Data: []byte("//go:build go1.22\n\npackage main\n\nconst version = \"go1.22+\"\n")},
+		"main/version_old.go":         {Data: []byte("//go:build !go1.22\n\npackage main\n\nconst version = \"go1.21-\"\n")},
+	}
+
+	tests := []struct {
+		desc string
+		opt  interp.Options
+		res  string
+		err  string
+	}{
+		{desc: "linux", opt: interp.Options{GOOS: "linux", GOARCH: "amd64", GoVersion: "go1.22"}, res: "linux go1.22+ 0 1\n"},
+		{desc: "windows", opt: interp.Options{GOOS: "windows", GOARCH: "amd64", GoVersion: "go1.23.1"}, res: "windows go1.22+ 0 1\n"},
+		{desc: "darwin arm64", opt: interp.Options{GOOS: "darwin", GOARCH: "arm64", GoVersion: "1.24"}, res: "darwin/arm64 go1.22+ 0 1\n"},
+		{desc: "go1.21", opt: interp.Options{GOOS: "linux", GOARCH: "amd64", GoVersion: "go1.21"}, res: "linux go1.21- 2 2\n"},
+		{desc: "invalid GOOS", opt: interp.Options{GOOS: "foo"}, err: `unsupported GOOS: "foo"`},
+		{desc: "invalid GOARCH", opt: interp.Options{GOARCH: "foo"}, err: `unsupported GOARCH: "foo"`},
+		{desc: "invalid version", opt: interp.Options{GoVersion: "go2"}, err: `invalid Go version: "go2"`},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			var stdout bytes.Buffer
+			test.opt.Stdout = &stdout
+			test.opt.SourcecodeFilesystem = fsys
+			i := interp.New(test.opt)
+			err := i.Use(stdlib.Symbols)
+			if err == nil {
+				_, err = i.EvalPath("./main")
+			}
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("got error %v, want %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := stdout.String(); got != test.res {
+				t.Errorf("got %q, want %q", got, test.res)
+			}
+		})
+	}
+}
+
+func TestEvalModuleGoVersion(t *testing.T) {
+	const loop = `package main
+
+import "fmt"
+
+func main() {
+	var fs []func() int
+	for i := 0; i < 2; i++ {
+		fs = append(fs, func() int { return i })
+	}
+	fmt.Println(fs[0](), fs[1]())
+}
+`
+	tests := []struct {
+		desc    string
+		gomod   string
+		version string
+		src     string
+		res     string
+		err     string
+	}{
+		{desc: "no module", src: loop, res: "0 1\n"},
+		{desc: "explicit version", gomod: "module foo\n\ngo 1.21\n", version: "go1.22", src: loop, res: "0 1\n"},
+		{desc: "go1.22", gomod: "module foo\n\ngo 1.22\n", src: loop, res: "0 1\n"},
+		{desc: "go1.21", gomod: "module foo\n\ngo 1.21 // old\n", src: loop, res: "2 2\n"},
+		{desc: "no go directive", gomod: "module foo\n", src: loop, res: "0 1\n"},
+		{desc: "build constraint", gomod: "module foo\n\ngo 1.21\n", src: "//go:build go1.22\n\n" + loop, res: "0 1\n"},
+		{desc: "old build constraint", gomod: "module foo\n\ngo 1.21\n", src: "//go:build go1.21\n\n" + loop, res: "2 2\n"},
+		{
+			desc:    "old explicit version",
+			version: "go1.21",
+			src:     "package main\n\nfunc main() {\n\tfor i := range 3 {\n\t\tprintln(i)\n\t}\n}\n",
+			err:     "main/main.go:4:17: range over int requires go1.22 or later (GoVersion was set to go1.21 in Options)",
+		},
+		{
+			desc:  "range over int",
+			gomod: "module foo\n\ngo 1.21.0\n",
+			src:   "package main\n\nfunc main() {\n\tfor i := range 3 {\n\t\tprintln(i)\n\t}\n}\n",
+			err:   "main/main.go:4:17: range over int requires go1.22 or later (-lang was set to go1.21; check go.mod)",
+		},
+		{
+			desc:  "generic function",
+			gomod: "module foo\n\ngo 1.17\n",
+			src:   "package main\n\nfunc f[T any](t T) T { return t }\n\nfunc main() {}\n",
+			err:   "main/main.go:3:7: generic function requires go1.18 or later (-lang was set to go1.17; check go.mod)",
+		},
+		{
+			desc:  "generic type",
+			gomod: "module foo\n\ngo 1.17\n",
+			src:   "package main\n\ntype T[E any] []E\n\nfunc main() {}\n",
+			err:   "main/main.go:3:7: generic type requires go1.18 or later (-lang was set to go1.17; check go.mod)",
+		},
+		{desc: "newer", gomod: "module foo\n\ngo 1.99\n", src: loop, err: "go.mod requires go >= 1.99"},
+		{desc: "invalid", gomod: "module foo\n\ngo one\n", src: loop, err: `go.mod: invalid Go version: "one"`},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			fsys := fstest.MapFS{"main/main.go": {Data: []byte(test.src)}}
+			if test.gomod != "" {
+				fsys["go.mod"] = &fstest.MapFile{Data: []byte(test.gomod)}
+			}
+			var stdout bytes.Buffer
+			i := interp.New(interp.Options{Stdout: &stdout, SourcecodeFilesystem: fsys, GoVersion: test.version})
+			if err := i.Use(stdlib.Symbols); err != nil {
+				t.Fatal(err)
+			}
+			_, err := i.EvalPath("./main")
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("got error %v, want %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := stdout.String(); got != test.res {
+				t.Errorf("got %q, want %q", got, test.res)
+			}
+		})
+	}
+}
+
+func TestEvalDeterministic(t *testing.T) {
+	src := `package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+func main() {
+	start := time.Now()
+	m := map[string]int{"a": 1, "b": 2, "c": 3, "d": 4, "e": 5}
+	for k, v := range m {
+		fmt.Print(k, v, " ")
+	}
+	fmt.Println(rand.Intn(1000000))
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	c := make(chan int)
+	var res []int
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			time.Sleep(time.Duration(5-i) * time.Second)
+			mu.Lock()
+			res = append(res, i)
+			mu.Unlock()
+			c <- i
+		}()
+	}
+	go func() { wg.Wait(); close(c) }()
+	for v := range c {
+		fmt.Print(v, " ")
+	}
+	fmt.Println(res, time.Since(start))
+
+	a, b := make(chan int, 10), make(chan int, 10)
+	for i := 0; i < 10; i++ {
+		a <- i
+		b <- i
+	}
+	for i := 0; i < 10; i++ {
+		select {
+		case v := <-a:
+			fmt.Print("a", v)
+		case v := <-b:
+			fmt.Print("b", v)
+		}
+	}
+	fmt.Println()
+}
+`
+	run := func(seed int64) string {
+		var stdout bytes.Buffer
+		i := interp.New(interp.Options{Deterministic: true, Seed: seed, Stdout: &stdout})
+		if err := i.Use(stdlib.Symbols); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := i.Eval(src); err != nil {
+			t.Fatal(err)
+		}
+		return stdout.String()
+	}
+
+	start := time.Now()
+	res := run(1)
+	if !strings.HasPrefix(res, "a1 b2 c3 d4 e5 ") || !strings.Contains(res, "\n4 3 2 1 0 [4 3 2 1 0] 5s\n") {
+		t.Errorf("unexpected output %q", res)
+	}
+	if d := time.Since(start); d > 4*time.Second {
+		t.Errorf("virtual sleeps took %v", d)
+	}
+	for n := 0; n < 5; n++ {
+		if r := run(1); r != res {
+			t.Fatalf("got %q, want %q", r, res)
+		}
+	}
+	if r := run(2); r == res {
+		t.Errorf("same output %q with a different seed", r)
+	}
+}
+
+func TestEvalDeterministicClock(t *testing.T) {
+	var stdout bytes.Buffer
+	i := interp.New(interp.Options{Deterministic: true, Stdout: &stdout, StartTime: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)})
+	if err := i.Use(stdlib.Symbols); err != nil {
+		t.Fatal(err)
+	}
+	eval := func(src string) {
+		t.Helper()
+		if _, err := i.Eval(src); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	eval(`import ("fmt"; "time")`)
+	eval(`t := time.NewTimer(time.Second)`)
+	eval(`time.AfterFunc(2*time.Second, func() { fmt.Println("fired at", time.Now().Format(time.TimeOnly)) })`)
+	eval(`func ready() bool { select { case <-t.C: return true; default: return false } }`)
+	eval(`fmt.Println(ready())`)
+	i.Clock().Advance(time.Second)
+	eval(`fmt.Println(ready())`)
+	i.Clock().Advance(time.Second)
+	if err := i.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	eval(`fmt.Println(time.Now())`)
+
+	want := "false\ntrue\nfired at 00:00:02\n2024-01-01 00:00:02 +0000 UTC\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := i.Clock().Now(); !got.Equal(time.Date(2024, 1, 1, 0, 0, 2, 0, time.UTC)) {
+		t.Errorf("got clock time %v", got)
+	}
+	if interp.New(interp.Options{}).Clock() != nil {
+		t.Error("unexpected clock when not deterministic")
+	}
+}
+
+func TestEvalDeterministicMethod(t *testing.T) {
+	i := interp.New(interp.Options{Deterministic: true})
+	if err := i.Use(stdlib.Symbols); err != nil {
+		t.Fatal(err)
+	}
+	v, err := i.Eval(`package main
+
+import (
+	"fmt"
+	"time"
+)
+
+type Sleeper struct{ n int }
+
+func (s *Sleeper) Order() string {
+	c := make(chan int)
+	for i := 0; i < s.n; i++ {
+		go func() {
+			time.Sleep(time.Duration(s.n-i) * time.Second)
+			c <- i
+		}()
+	}
+	res := ""
+	for i := 0; i < s.n; i++ {
+		res += fmt.Sprint(<-c)
+	}
+
+	// A goroutine does not run until the method blocks or returns.
+	done := false
+	go func() { done = true }()
+	for i := 0; i < 100000; i++ {
+		_ = i
+	}
+	return fmt.Sprint(res, " ", done)
+}
+
+var S = &Sleeper{n: 5}
+
+func main() {}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err = i.Eval("S"); err != nil {
+		t.Fatal(err)
+	}
+	order, err := interp.Method[func() string](i, v, "Order")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The method runs under the scheduler, with virtual sleeps.
+	start := time.Now()
+	for n := 0; n < 10; n++ {
+		if got, want := order(), "43210 false"; got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	}
+	if d := time.Since(start); d > 4*time.Second {
+		t.Errorf("virtual sleeps took %v", d)
+	}
+}
+
+func TestEvalDeterministicCancel(t *testing.T) {
+	i := interp.New(interp.Options{Deterministic: true})
+	if err := i.Use(stdlib.Symbols); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := i.EvalWithContext(ctx, `
+c := make(chan int)
+go func() { <-c }()
+<-c
+`)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v", err)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := i.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEvalNetworkDenied(t *testing.T) {
+	i := interp.New(interp.Options{Network: interp.DenyNetwork})
+	if err := i.Use(stdlib.Symbols); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := i.Eval(`import ("context"; "crypto/tls"; "net"; "net/http"); var err error`); err != nil {
+		t.Fatal(err)
+	}
+	for _, src := range []string{
+		`net.Dial("tcp", "example.com:80")`,
+		`net.Listen("tcp", ":8080")`,
+		`net.ListenPacket("udp", ":53")`,
+		`http.Get("http://example.com")`,
+		`tls.Dial("tcp", "example.com:443", nil)`,
+		`net.LookupHost("example.com")`,
+		// Zero values of the types which would use the host network.
+		`(&net.Dialer{}).Dial("tcp", "example.com:80")`,
+		`(&net.ListenConfig{}).Listen(context.Background(), "tcp", ":8080")`,
+		`(&net.Resolver{}).LookupHost(context.Background(), "example.com")`,
+		`(&tls.Dialer{}).Dial("tcp", "example.com:443")`,
+		`(&http.Client{}).Get("http://example.com")`,
+		`(&http.Client{Transport: &http.Transport{}}).Get("http://example.com")`,
+		`0, (&http.Server{Addr: ":8080"}).ListenAndServe()`,
+	} {
+		res, err := i.Eval(`_, err = ` + src + `; err`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The lookup errors do not wrap the errors of the name server connections.
+		if err, _ := res.Interface().(error); err == nil || !errors.Is(err, interp.ErrNetworkDenied) && !strings.Contains(err.Error(), interp.ErrNetworkDenied.Error()) {
+			t.Errorf("%s: got error %v", src, err)
+		}
+	}
+}
+
+// hostListener returns the address of a host listener, and a function
+// returning the number of connections it has accepted.
+func hostListener(t *testing.T) (string, func() int) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	// The connections are closed as soon as accepted, so that the
+	// interpreted code does not wait for a response.
+	remotes := make(chan string, 16)
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				close(remotes)
+				return
+			}
+			remotes <- c.RemoteAddr().String()
+			c.Close()
+		}
+	}()
+	accepts := func() int {
+		// The connections are accepted in order: the ones accepted before
+		// the sentinel were dialed by the interpreted code.
+		sentinel, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer sentinel.Close()
+		n := 0
+		for r := range remotes {
+			if r == sentinel.LocalAddr().String() {
+				break
+			}
+			n++
+		}
+		return n
+	}
+	return l.Addr().String(), accepts
+}
+
+func TestEvalNetworkDeniedHost(t *testing.T) {
+	tests := []struct {
+		pkg string
+		src string // the source of an error, the address of the host listener is %[1]q
+	}{
+		{"net/http", `func() error { _, err := (&http.Transport{}).RoundTrip(httptest.NewRequest("GET", "http://"+%[1]q, nil)); return err }()`},
+		{"net/http", `func() error { _, err := http.DefaultTransport.RoundTrip(httptest.NewRequest("GET", "http://"+%[1]q, nil)); return err }()`},
+		{"net/http", `func() error { _, err := http.DefaultTransport.(*http.Transport).Clone().RoundTrip(httptest.NewRequest("GET", "http://"+%[1]q, nil)); return err }()`},
+		{"net/http/httputil", `func() (err error) {
+	u, _ := url.Parse("http://" + %[1]q)
+	p := httputil.NewSingleHostReverseProxy(u)
+	p.ErrorHandler = func(w http.ResponseWriter, r *http.Request, e error) { err = e }
+	p.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	return err
+}()`},
+		{"net/http/httputil", `func() (err error) {
+	u, _ := url.Parse("http://" + %[1]q)
+	p := &httputil.ReverseProxy{
+		Rewrite:      func(r *httputil.ProxyRequest) { r.SetURL(u) },
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, e error) { err = e },
+	}
+	p.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	return err
+}()`},
+		{"net/rpc", `func() error { _, err := rpc.Dial("tcp", %[1]q); return err }()`},
+		{"net/rpc", `func() error { _, err := rpc.DialHTTP("tcp", %[1]q); return err }()`},
+		{"net/rpc/jsonrpc", `func() error { _, err := jsonrpc.Dial("tcp", %[1]q); return err }()`},
+		{"net/smtp", `func() error { _, err := smtp.Dial(%[1]q); return err }()`},
+		{"net/smtp", `smtp.SendMail(%[1]q, nil, "a@example.com", []string{"b@example.com"}, []byte("hi"))`},
+		{"net/textproto", `func() error { _, err := textproto.Dial("tcp", %[1]q); return err }()`},
+		{"log/syslog", `func() error { _, err := syslog.Dial("tcp", %[1]q, syslog.LOG_INFO, "yaegi"); return err }()`},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.pkg, func(t *testing.T) {
+			if _, ok := stdlib.Symbols[test.pkg+"/"+path.Base(test.pkg)]; !ok {
+				t.Skip("package not available")
+			}
+			addr, accepts := hostListener(t)
+			i := interp.New(interp.Options{Network: interp.DenyNetwork})
+			if err := i.Use(stdlib.Symbols); err != nil {
+				t.Fatal(err)
+			}
+			imports := `import ("net/http"; "net/http/httptest"; "net/url"; "` + test.pkg + `")`
+			if test.pkg == "net/http" {
+				imports = `import ("net/http"; "net/http/httptest")`
+			}
+			if _, err := i.Eval(imports + "\nvar _, _ = http.Get, httptest.NewRecorder\nvar err error"); err != nil {
+				t.Fatal(err)
+			}
+			res, err := i.Eval("err = " + fmt.Sprintf(test.src, addr) + "; err")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err, _ := res.Interface().(error); err == nil || !strings.Contains(err.Error(), interp.ErrNetworkDenied.Error()) {
+				t.Errorf("got error %v", err)
+			}
+			if n := accepts(); n != 0 {
+				t.Errorf("the host listener accepted %d connections", n)
+			}
+		})
+	}
+}
+
+func TestEvalMemNetwork(t *testing.T) {
+	network := interp.NewMemNetwork()
+	i := interp.New(interp.Options{Network: network})
+	if err := i.Use(stdlib.Symbols); err != nil {
+		t.Fatal(err)
+	}
+	res, err := i.Eval(`package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+)
+
+func main() {}
+
+func Get() string {
+	l, err := net.Listen("tcp", ":8080")
+	if err != nil {
+		return err.Error()
+	}
+	defer l.Close()
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, "hello ", r.Host) })
+	go http.Serve(l, h)
+
+	resp, err := http.Get("http://example.com:8080/")
+	if err != nil {
+		return err.Error()
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	return string(b)
+}
+
+func Test() string {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, "test") }))
+	defer s.Close()
+	resp, err := s.Client().Get(s.URL)
+	if err != nil {
+		return err.Error()
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	return string(b)
+}
+
+func Hi() string {
+	l, err := net.Listen("tcp", ":8081")
+	if err != nil {
+		return err.Error()
+	}
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, "hi") })}
+	defer srv.Close()
+	go srv.Serve(l)
+
+	resp, err := (&http.Client{Transport: http.DefaultTransport}).Get("http://localhost:8081/")
+	if err != nil {
+		return err.Error()
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	return string(b)
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err = i.Eval("Get()")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.String(); s != "hello example.com:8080" {
+		t.Fatalf("got %q", s)
+	}
+
+	// The httptest servers listen on the in-memory network, and their
+	// clients dial it.
+	res, err = i.Eval("Test()")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.String(); s != "test" {
+		t.Fatalf("got %q", s)
+	}
+
+	// A server and a client created by the interpreted code use the
+	// listeners and transport of the in-memory network.
+	res, err = i.Eval("Hi()")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.String(); s != "hi" {
+		t.Fatalf("got %q", s)
+	}
+
+	// Nothing listens on the port in the in-memory network.
+	res, err = i.Eval(`_, err := http.DefaultClient.Get("http://example.com:9090/"); err`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err, _ := res.Interface().(error); err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Fatalf("got error %v", err)
+	}
+}
+
+func TestEvalExec(t *testing.T) {
+	runner := interp.CommandRunnerFunc(func(ctx context.Context, cmd *interp.Command) error {
+		switch cmd.Path {
+		case "echo":
+			fmt.Fprintln(cmd.Stdout, strings.Join(cmd.Args[1:], " "))
+			return nil
+		case "fail":
+			fmt.Fprint(cmd.Stderr, "failed")
+			return &interp.ExitError{Code: 2}
+		case "sh":
+			if runtime.GOOS == "windows" {
+				break
+			}
+			return interp.HostRunner.Run(ctx, cmd)
+		}
+		return &exec.Error{Name: cmd.Path, Err: interp.ErrCommandDenied}
+	})
+	i := interp.New(interp.Options{Exec: runner})
+	if err := i.Use(stdlib.Symbols); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := i.Eval(`import ("errors"; "fmt"; "io"; "os/exec"); var ee *exec.ExitError`); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct{ src, res string }{
+		{src: `b, _ := exec.Command("echo", "hello", "world").Output(); string(b)`, res: "hello world\n"},
+		{src: `c := exec.Command("echo", "piped"); r, _ := c.StdoutPipe(); c.Start(); b, _ = io.ReadAll(r); c.Wait(); string(b)`, res: "piped\n"},
+		{src: `_, err := exec.Command("fail").Output(); errors.As(err, &ee); fmt.Sprint(ee.ExitCode(), " ", string(ee.Stderr))`, res: "2 failed"},
+		{src: `err = exec.Command("rm", "-rf", "/").Run(); err.Error()`, res: `exec: "rm": command denied`},
+	}
+	for _, test := range tests {
+		res, err := i.Eval(test.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := fmt.Sprint(res); s != test.res {
+			t.Errorf("%s: got %q, want %q", test.src, s, test.res)
+		}
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	res, err := i.Eval(`err = exec.Command("sh", "-c", "exit 3").Run(); errors.As(err, &ee); ee.ExitCode()`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Interface() != 3 {
+		t.Errorf("got exit code %v", res)
+	}
+}
+
+func TestEvalVirtualC(t *testing.T) {
+	i := interp.New(interp.Options{})
+	if err := i.Use(stdlib.Symbols); err != nil {
+		t.Fatal(err)
+	}
+	if err := i.Use(unsafe.Symbols); err != nil {
+		t.Fatal(err)
+	}
+	if err := i.Use(interp.Exports{"C/C": {
+		"add":     reflect.ValueOf(func(a, b int32) int32 { return a + b }),
+		"VERSION": reflect.ValueOf(3),
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	res, err := i.Eval(`package main
+
+/*
+#include <stdlib.h>
+
+static int add(int a, int b) { return a + b; }
+*/
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+func main() {}
+
+func Run() string {
+	cs := C.CString("hello")
+	defer C.free(unsafe.Pointer(cs))
+	var n C.int = C.add(C.int(C.VERSION), 4)
+	return fmt.Sprint(C.GoString(cs), " ", C.GoStringN(cs, 4), " ", n, " ", C.GoBytes(unsafe.Pointer(cs), 2))
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res, err = i.Eval("Run()"); err != nil {
+		t.Fatal(err)
+	}
+	if s := res.String(); s != "hello hell 7 [104 101]" {
+		t.Fatalf("got %q", s)
+	}
+}
+
+func TestEvalAsm(t *testing.T) {
+	files := fstest.MapFS{
+		"main/main.go":           {Data: []byte("package main\n\nimport (\n\t\"fmt\"\n\n\t\"asmpkg\"\n)\n\nfunc main() { fmt.Println(asmpkg.Add(1, 2)) }\n")},
+		"src/asmpkg/add.go":      {Data: []byte("package asmpkg\n\nfunc Add(a, b int) int { return add(a, b) }\n")},
+		"src/asmpkg/add_asm.go":  {Data: []byte("//go:build amd64 && !purego\n\npackage asmpkg\n\n//go:noescape\nfunc add(a, b int) int\n")},
+		"src/asmpkg/add_amd64.s": {Data: []byte("TEXT ·add(SB),4,$0-24\n\tRET\n")},
+	}
+	purego := fstest.MapFS{"src/asmpkg/add_purego.go": {Data: []byte("//go:build !amd64 || purego\n\npackage asmpkg\n\nfunc add(a, b int) int { return a + b }\n")}}
+	for k, v := range files {
+		purego[k] = v
+	}
+
+	tests := []struct {
+		desc string
+		fsys fstest.MapFS
+		asm  interp.Exports
+		res  string
+		err  string
+	}{
+		{desc: "purego", fsys: purego, res: "3\n"},
+		{desc: "host", fsys: files, asm: interp.Exports{"asmpkg/_asm": {"add": reflect.ValueOf(func(a, b int) int { return 10*a + b })}}, res: "12\n"},
+		{desc: "bad host", fsys: files, asm: interp.Exports{"asmpkg/_asm": {"add": reflect.ValueOf(func(a, b int32) int32 { return a + b })}}, err: "cannot use func(int32, int32) int32 as implementation of add"},
+		{desc: "missing", fsys: files, err: `missing function body: assembly is not supported, add can be provided in Exports["asmpkg/_asm"]`},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			var stdout bytes.Buffer
+			i := interp.New(interp.Options{GoPath: ".", GOARCH: "amd64", Stdout: &stdout, SourcecodeFilesystem: test.fsys})
+			if err := i.Use(stdlib.Symbols); err != nil {
+				t.Fatal(err)
+			}
+			if err := i.Use(test.asm); err != nil {
+				t.Fatal(err)
+			}
+			_, err := i.EvalPath("./main")
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("got error %v, want %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := stdout.String(); got != test.res {
+				t.Errorf("got %q, want %q", got, test.res)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package interp
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Func returns the function of the given name, defined in the interpreter,
+// as a value of type T, which must be a function type.
+//
+// The name is either unqualified, for the main package, or qualified by an
+// import path or a package name, as in "github.com/foo/bar.Fn" or "bar.Fn".
+// Source and binary packages are searched, in this order.
+//
+// An error is returned if the symbol is not found, is not a function, or if
+// its signature does not match T, instead of panicking at conversion.
+func Func[T any](interp *Interpreter, name string) (fn T, err error) {
+	want := reflect.TypeOf(&fn).Elem()
+	if want.Kind() != reflect.Func {
+		return fn, fmt.Errorf("%s: %v is not a function type", name, want)
+	}
+
+	v, t, err := interp.lookupValue(name, funcSym)
+	if err != nil {
+		return fn, err
+	}
+	if err = checkType(name, t, v.Type(), want); err != nil {
+		return fn, err
+	}
+	return v.Convert(want).Interface().(T), nil
+}
+
+// Var returns a pointer to the global variable of the given name, defined in
+// the interpreter, which must be of type T. The name is resolved as in Func.
+//
+// The returned pointer allows to read and modify the variable while the
+// interpreted code runs, with the same synchronization constraints as in Go.
+func Var[T any](interp *Interpreter, name string) (*T, error) {
+	want := reflect.TypeOf((*T)(nil)).Elem()
+
+	v, t, err := interp.lookupValue(name, varSym)
+	if err != nil {
+		return nil, err
+	}
+	if v.Type() != want {
+		return nil, mismatchError(name, t, want)
+	}
+	if !v.CanAddr() {
+		return nil, fmt.Errorf("%s: variable is not addressable", name)
+	}
+	return v.Addr().Interface().(*T), nil
+}
+
+// Method returns the method of the given name of recv, a value of a type
+// defined in the interpreter, as obtained from Eval, as a function of type T
+// bound to recv. Methods promoted from embedded fields are supported.
+//
+// If the method has a pointer receiver, recv must be a pointer or addressable.
+// The signature of the method, without the receiver, must match T.
+func Method[T any](interp *Interpreter, recv reflect.Value, name string) (fn T, err error) {
+	want := reflect.TypeOf(&fn).Elem()
+	if want.Kind() != reflect.Func {
+		return fn, fmt.Errorf("%s: %v is not a function type", name, want)
+	}
+	if !recv.IsValid() {
+		return fn, fmt.Errorf("%s: invalid receiver", name)
+	}
+
+	interp.compiling.Lock()
+	defer interp.compiling.Unlock()
+
+	var rtyp *itype
+	if vi, ok := recv.Interface().(valueInterface); ok && vi.node != nil {
+		rtyp, recv = vi.node.typ, vi.value
+	} else if rtyp = interp.srcTypeOf(recv.Type()); rtyp == nil {
+		// Not an interpreted type: use the methods known to reflect.
+		m := recv.MethodByName(name)
+		if !m.IsValid() {
+			return fn, fmt.Errorf("%s: no method %s for type %v", name, name, recv.Type())
+		}
+		if err = checkType(name, nil, m.Type(), want); err != nil {
+			return fn, err
+		}
+		return m.Convert(want).Interface().(T), nil
+	}
+
+	def, index := rtyp.lookupMethod(name)
+	if def == nil {
+		return fn, fmt.Errorf("%s: no method %s for type %s", name, name, rtyp)
+	}
+	if err = checkType(name, def.typ, def.typ.TypeOf(), want); err != nil {
+		return fn, err
+	}
+
+	// Locate the receiver, possibly in an embedded field.
+	for _, i := range index {
+		if recv.Kind() == reflect.Ptr {
+			recv = recv.Elem()
+		}
+		recv = recv.Field(i)
+	}
+	ptrRecv := def.types[len(def.typ.ret)].Kind() == reflect.Ptr
+	switch {
+	case ptrRecv && recv.Kind() != reflect.Ptr:
+		if !recv.CanAddr() {
+			return fn, fmt.Errorf("%s: method %s has a pointer receiver, but value is not addressable", name, name)
+		}
+		recv = recv.Addr()
+	case !ptrRecv && recv.Kind() == reflect.Ptr:
+		recv = recv.Elem()
+	}
+
+	f := makeFuncWrapper(def, def, def.typ.TypeOf(), interp.frame, func() reflect.Value { return recv })
+	return f.Convert(want).Interface().(T), nil
+}
+
+// lookupValue returns the value and the interpreted type, if any, of the
+// global symbol name, which must be of the given kind.
+func (interp *Interpreter) lookupValue(name string, kind sKind) (reflect.Value, *itype, error) {
+	interp.compiling.Lock()
+	defer interp.compiling.Unlock()
+	interp.mutex.RLock()
+	defer interp.mutex.RUnlock()
+
+	path, ident := mainID, name
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		path, ident = name[:i], name[i+1:]
+	}
+
+	syms, ok := interp.srcPkg[path]
+	if !ok {
+		// Resolve a package name into its import path.
+		for p, n := range interp.pkgNames {
+			if n == path && interp.srcPkg[p] != nil {
+				syms, ok = interp.srcPkg[p], true
+				break
+			}
+		}
+	}
+	if s := syms[ident]; ok && s != nil {
+		switch {
+		case s.kind != kind:
+			return reflect.Value{}, nil, fmt.Errorf("%s: symbol is a %s, not a %s", name, symKindName(s.kind), symKindName(kind))
+		case kind == funcSym:
+			return genFunctionWrapper(s.node)(interp.frame), s.typ, nil
+		default:
+			return interp.frame.data[s.index], s.typ, nil
+		}
+	}
+
+	bsyms, ok := interp.binPkg[path]
+	if !ok {
+		for p, n := range interp.pkgNames {
+			if n == path && interp.binPkg[p] != nil {
+				bsyms, ok = interp.binPkg[p], true
+				break
+			}
+		}
+	}
+	if v, found := bsyms[ident]; ok && found {
+		k := v.Kind()
+		switch {
+		case kind == funcSym && k != reflect.Func:
+			return reflect.Value{}, nil, fmt.Errorf("%s: symbol is not a function", name)
+		case kind == varSym && (k != reflect.Ptr || v.IsNil()):
+			return reflect.Value{}, nil, fmt.Errorf("%s: symbol is not a variable", name)
+		case kind == varSym:
+			// Binary variables are exported as pointers.
+			return v.Elem(), nil, nil
+		}
+		return v, nil, nil
+	}
+
+	return reflect.Value{}, nil, fmt.Errorf("%s: symbol not found", name)
+}
+
+// srcTypeOf returns the interpreted type, declared at global level, of
+// reflect type rt or of its element if rt is a pointer, or nil.
+func (interp *Interpreter) srcTypeOf(rt reflect.Type) *itype {
+	interp.mutex.RLock()
+	defer interp.mutex.RUnlock()
+
+	for _, syms := range interp.srcPkg {
+		for _, s := range syms {
+			if s.kind != typeSym || s.typ == nil || s.typ.cat == nilT {
+				continue
+			}
+			t := s.typ.TypeOf()
+			if t == rt || rt.Kind() == reflect.Ptr && t == rt.Elem() {
+				return s.typ
+			}
+		}
+	}
+	return nil
+}
+
+// checkType returns an error if the function type got, of interpreted type t
+// if not nil, can not be converted to the wanted function type.
+func checkType(name string, t *itype, got, want reflect.Type) error {
+	if got == want || got.Kind() == reflect.Func && got.ConvertibleTo(want) {
+		return nil
+	}
+	if t == nil {
+		return fmt.Errorf("%s: cannot use %v as %v", name, got, want)
+	}
+	return mismatchError(name, t, want)
+}
+
+func mismatchError(name string, t *itype, want reflect.Type) error {
+	return fmt.Errorf("%s: cannot use %s as %v", name, t, want)
+}
+
+// symKindName returns a user readable name for the symbol kind k.
+func symKindName(k sKind) string {
+	switch k {
+	case constSym:
+		return "constant"
+	case funcSym:
+		return "function"
+	case typeSym:
+		return "type"
+	case varSym:
+		return "variable"
+	case pkgSym:
+		return "package"
+	case bltnSym:
+		return "builtin"
+	case binSym:
+		return "value"
+	}
+	return strings.TrimSuffix(k.String(), "Sym")
+}
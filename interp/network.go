@@ -0,0 +1,1090 @@
+package interp
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/fcgi"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/netip"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"net/smtp"
+	"net/textproto"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Network provides the network connections of the interpreted code in place
+// of the host network, see Options.Network.
+type Network interface {
+	// DialContext connects to the address on the named network, as
+	// net.Dialer.DialContext.
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+
+	// Listen announces on the local network address, as net.ListenConfig.Listen.
+	Listen(ctx context.Context, network, address string) (net.Listener, error)
+}
+
+// ErrNetworkDenied is the error of the network operations denied to the
+// interpreted code.
+var ErrNetworkDenied = errors.New("network access denied")
+
+// DenyNetwork is a Network denying all the connections.
+var DenyNetwork Network = denyNetwork{}
+
+type denyNetwork struct{}
+
+func (denyNetwork) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return nil, &net.OpError{Op: "dial", Net: network, Err: ErrNetworkDenied}
+}
+
+func (denyNetwork) Listen(ctx context.Context, network, address string) (net.Listener, error) {
+	return nil, &net.OpError{Op: "listen", Net: network, Err: ErrNetworkDenied}
+}
+
+// MemNetwork is an in-memory Network, where the connections are made of
+// net.Pipe, to test or connect interpreted programs without using the host
+// network. A same MemNetwork can be shared by several interpreters.
+//
+// A listener accepts the connections dialed to its address, or to any host
+// if its host is empty or unspecified, as ":8080" or "0.0.0.0:8080". A
+// listener on port 0 is assigned a free port.
+type MemNetwork struct {
+	mu        sync.Mutex
+	listeners map[string]*memListener // by network family and address
+	port      int                     // last assigned port
+}
+
+// NewMemNetwork returns an empty in-memory network.
+func NewMemNetwork() *MemNetwork {
+	return &MemNetwork{listeners: map[string]*memListener{}, port: 49151}
+}
+
+// DialContext connects to the listener of the address, as net.Dialer.DialContext.
+func (m *MemNetwork) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	opErr := func(err error) error {
+		return &net.OpError{Op: "dial", Net: network, Addr: memAddr{network, address}, Err: err}
+	}
+	keys := []string{memKey(network, address)}
+	if !isUnix(network) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, opErr(err)
+		}
+		keys = []string{memKey(network, net.JoinHostPort(host, port)), memKey(network, net.JoinHostPort("", port))}
+	}
+	m.mu.Lock()
+	var l *memListener
+	var ok bool
+	for _, k := range keys {
+		if l, ok = m.listeners[k]; ok {
+			break
+		}
+	}
+	m.mu.Unlock()
+	if !ok {
+		return nil, opErr(errors.New("connection refused"))
+	}
+
+	var err error
+	c, s := net.Pipe()
+	select {
+	case l.conns <- s:
+		return c, nil
+	case <-l.done:
+		err = errors.New("connection refused")
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	c.Close()
+	s.Close()
+	return nil, opErr(err)
+}
+
+// Listen announces on the address, as net.ListenConfig.Listen.
+func (m *MemNetwork) Listen(ctx context.Context, network, address string) (net.Listener, error) {
+	opErr := func(err error) error {
+		return &net.OpError{Op: "listen", Net: network, Addr: memAddr{network, address}, Err: err}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !isUnix(network) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, opErr(err)
+		}
+		if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+			host = ""
+		}
+		if port == "0" || port == "" {
+			for {
+				m.port++
+				port = strconv.Itoa(m.port)
+				if _, ok := m.listeners[memKey(network, net.JoinHostPort(host, port))]; !ok {
+					break
+				}
+			}
+		}
+		address = net.JoinHostPort(host, port)
+	}
+	key := memKey(network, address)
+	if _, ok := m.listeners[key]; ok {
+		return nil, opErr(errors.New("address already in use"))
+	}
+	l := &memListener{
+		network: m,
+		key:     key,
+		addr:    memAddr{network, address},
+		conns:   make(chan net.Conn),
+		done:    make(chan struct{}),
+	}
+	m.listeners[key] = l
+	return l, nil
+}
+
+// memKey returns the key of a listener on address for the network, where
+// the networks of a same family, such as "tcp", "tcp4" and "tcp6", are the same.
+func memKey(network, address string) string {
+	switch network {
+	case "tcp4", "tcp6":
+		network = "tcp"
+	case "udp4", "udp6":
+		network = "udp"
+	}
+	return network + " " + address
+}
+
+// isUnix returns true if network is a unix domain socket network.
+func isUnix(network string) bool {
+	return network == "unix" || network == "unixpacket" || network == "unixgram"
+}
+
+// memListener is a listener of a MemNetwork.
+type memListener struct {
+	network *MemNetwork
+	key     string
+	addr    memAddr
+	conns   chan net.Conn
+	done    chan struct{}
+	once    sync.Once
+}
+
+func (l *memListener) Accept() (net.Conn, error) {
+	select {
+	case c := <-l.conns:
+		return c, nil
+	case <-l.done:
+		return nil, &net.OpError{Op: "accept", Net: l.addr.network, Addr: l.addr, Err: net.ErrClosed}
+	}
+}
+
+func (l *memListener) Close() error {
+	l.once.Do(func() {
+		l.network.mu.Lock()
+		delete(l.network.listeners, l.key)
+		l.network.mu.Unlock()
+		close(l.done)
+	})
+	return nil
+}
+
+func (l *memListener) Addr() net.Addr { return l.addr }
+
+// memAddr is the address of a memListener.
+type memAddr struct{ network, address string }
+
+func (a memAddr) Network() string { return a.network }
+func (a memAddr) String() string  { return a.address }
+
+// fixNetwork redefines the stdlib symbols which dial, listen or resolve on the
+// network to use the Network assigned to the interpreter. The types whose
+// values would use the host network are replaced by Dialer, ListenConfig,
+// Resolver, TLSDialer, HTTPTransport, HTTPClient, HTTPServer and ReverseProxy.
+func fixNetwork(interp *Interpreter) {
+	nw := interp.network
+	bg := context.Background()
+
+	// replace sets the symbol name of package p to the function f, and
+	// updates mapTypes as well.
+	replace := func(p map[string]reflect.Value, name string, f interface{}) {
+		v := reflect.ValueOf(f)
+		if t, ok := interp.mapTypes[p[name]]; ok {
+			interp.mapTypes[v] = t
+		}
+		p[name] = v
+	}
+
+	dialTimeout := func(network, address string, timeout time.Duration) (net.Conn, error) {
+		ctx := bg
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		return nw.DialContext(ctx, network, address)
+	}
+	dial := func(network, address string) (net.Conn, error) { return dialTimeout(network, address, 0) }
+	listen := func(network, address string) (net.Listener, error) { return nw.Listen(bg, network, address) }
+
+	if p := interp.binPkg["net"]; p != nil {
+		replace(p, "Dial", dial)
+		replace(p, "DialTimeout", dialTimeout)
+		replace(p, "Listen", listen)
+
+		replace(p, "DialTCP", func(network string, laddr, raddr *net.TCPAddr) (*net.TCPConn, error) {
+			return dialAs[*net.TCPConn](dial, network, raddr)
+		})
+		replace(p, "DialUDP", func(network string, laddr, raddr *net.UDPAddr) (*net.UDPConn, error) {
+			return dialAs[*net.UDPConn](dial, network, raddr)
+		})
+		replace(p, "DialIP", func(network string, laddr, raddr *net.IPAddr) (*net.IPConn, error) {
+			return dialAs[*net.IPConn](dial, network, raddr)
+		})
+		replace(p, "DialUnix", func(network string, laddr, raddr *net.UnixAddr) (*net.UnixConn, error) {
+			return dialAs[*net.UnixConn](dial, network, raddr)
+		})
+		replace(p, "ListenTCP", func(network string, laddr *net.TCPAddr) (*net.TCPListener, error) {
+			address := ":0"
+			if laddr != nil {
+				address = laddr.String()
+			}
+			return listenAs[*net.TCPListener](listen, network, address)
+		})
+		replace(p, "ListenUnix", func(network string, laddr *net.UnixAddr) (*net.UnixListener, error) {
+			address := ""
+			if laddr != nil {
+				address = laddr.String()
+			}
+			return listenAs[*net.UnixListener](listen, network, address)
+		})
+
+		// Packet connections and connections from files are not provided by Network.
+		denied := func(op, network string) error { return &net.OpError{Op: op, Net: network, Err: ErrNetworkDenied} }
+		replace(p, "ListenPacket", func(network, address string) (net.PacketConn, error) {
+			return nil, denied("listen", network)
+		})
+		replace(p, "ListenUDP", func(network string, laddr *net.UDPAddr) (*net.UDPConn, error) {
+			return nil, denied("listen", network)
+		})
+		replace(p, "ListenIP", func(network string, laddr *net.IPAddr) (*net.IPConn, error) {
+			return nil, denied("listen", network)
+		})
+		replace(p, "ListenUnixgram", func(network string, laddr *net.UnixAddr) (*net.UnixConn, error) {
+			return nil, denied("listen", network)
+		})
+		replace(p, "ListenMulticastUDP", func(network string, ifi *net.Interface, gaddr *net.UDPAddr) (*net.UDPConn, error) {
+			return nil, denied("listen", network)
+		})
+		replace(p, "FileConn", func(f *os.File) (net.Conn, error) { return nil, denied("file", "") })
+		replace(p, "FileListener", func(f *os.File) (net.Listener, error) { return nil, denied("file", "") })
+		replace(p, "FilePacketConn", func(f *os.File) (net.PacketConn, error) { return nil, denied("file", "") })
+
+		// The values of these types created by the interpreted code are not
+		// bound to the Network, see Dialer, ListenConfig and Resolver.
+		p["Dialer"] = reflect.ValueOf((*Dialer)(nil))
+		p["ListenConfig"] = reflect.ValueOf((*ListenConfig)(nil))
+		p["Resolver"] = reflect.ValueOf((*Resolver)(nil))
+
+		resolver := &Resolver{network: nw}
+		p["DefaultResolver"] = reflect.ValueOf(&resolver).Elem()
+		replace(p, "LookupHost", func(host string) ([]string, error) { return resolver.LookupHost(bg, host) })
+		replace(p, "LookupIP", func(host string) ([]net.IP, error) { return resolver.LookupIP(bg, "ip", host) })
+		replace(p, "LookupAddr", func(addr string) ([]string, error) { return resolver.LookupAddr(bg, addr) })
+		replace(p, "LookupCNAME", func(host string) (string, error) { return resolver.LookupCNAME(bg, host) })
+		replace(p, "LookupMX", func(name string) ([]*net.MX, error) { return resolver.LookupMX(bg, name) })
+		replace(p, "LookupNS", func(name string) ([]*net.NS, error) { return resolver.LookupNS(bg, name) })
+		replace(p, "LookupTXT", func(name string) ([]string, error) { return resolver.LookupTXT(bg, name) })
+		replace(p, "LookupSRV", func(service, proto, name string) (string, []*net.SRV, error) {
+			return resolver.LookupSRV(bg, service, proto, name)
+		})
+		replace(p, "LookupPort", func(network, service string) (int, error) { return resolver.LookupPort(bg, network, service) })
+	}
+
+	// The defaults of http.DefaultTransport, without the proxy of the host
+	// environment.
+	var rt http.RoundTripper = &HTTPTransport{
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		dial:                  nw.DialContext,
+	}
+
+	if p := interp.binPkg["net/http"]; p != nil {
+		client := &HTTPClient{transport: &rt}
+
+		p["DefaultTransport"] = reflect.ValueOf(&rt).Elem()
+		p["DefaultClient"] = reflect.ValueOf(&client).Elem()
+		p["Transport"] = reflect.ValueOf((*HTTPTransport)(nil))
+		p["Client"] = reflect.ValueOf((*HTTPClient)(nil))
+		p["Server"] = reflect.ValueOf((*HTTPServer)(nil))
+		replace(p, "Get", func(url string) (*http.Response, error) { return client.Get(url) })
+		replace(p, "Head", func(url string) (*http.Response, error) { return client.Head(url) })
+		replace(p, "Post", client.Post)
+		replace(p, "PostForm", func(url string, data url.Values) (*http.Response, error) { return client.PostForm(url, data) })
+
+		serverListen := func(addr string) (net.Listener, error) {
+			if addr == "" {
+				addr = ":http"
+			}
+			return listen("tcp", addr)
+		}
+		replace(p, "ListenAndServe", func(addr string, handler http.Handler) error {
+			l, err := serverListen(addr)
+			if err != nil {
+				return err
+			}
+			return (&http.Server{Addr: addr, Handler: handler}).Serve(l)
+		})
+		replace(p, "ListenAndServeTLS", func(addr, certFile, keyFile string, handler http.Handler) error {
+			if addr == "" {
+				addr = ":https"
+			}
+			l, err := serverListen(addr)
+			if err != nil {
+				return err
+			}
+			return (&http.Server{Addr: addr, Handler: handler}).ServeTLS(l, certFile, keyFile)
+		})
+	}
+
+	if p := interp.binPkg["net/http/httputil"]; p != nil {
+		p["ReverseProxy"] = reflect.ValueOf((*ReverseProxy)(nil))
+		replace(p, "NewSingleHostReverseProxy", func(target *url.URL) *ReverseProxy {
+			rp := httputil.NewSingleHostReverseProxy(target)
+			return &ReverseProxy{Director: rp.Director, transport: &rt}
+		})
+	}
+
+	if p := interp.binPkg["net/http/httptest"]; p != nil {
+		// The servers listen on the Network, and their clients dial it. The
+		// clients of the servers started by the interpreted code would dial
+		// the host network.
+		newServer := func(handler http.Handler, start func(*httptest.Server)) *httptest.Server {
+			l, err := listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				panic("httptest: failed to listen on a port: " + err.Error())
+			}
+			s := &httptest.Server{Listener: l, Config: &http.Server{Handler: handler}}
+			start(s)
+			s.Client().Transport.(*http.Transport).DialContext = nw.DialContext
+			return s
+		}
+		replace(p, "NewServer", func(handler http.Handler) *httptest.Server {
+			return newServer(handler, (*httptest.Server).Start)
+		})
+		replace(p, "NewTLSServer", func(handler http.Handler) *httptest.Server {
+			return newServer(handler, (*httptest.Server).StartTLS)
+		})
+		delete(p, "NewUnstartedServer")
+	}
+
+	if p := interp.binPkg["net/http/fcgi"]; p != nil {
+		replace(p, "Serve", func(l net.Listener, handler http.Handler) error {
+			if l == nil {
+				// The standard input of the host process is not served.
+				return deniedError("listen", "fcgi")
+			}
+			return fcgi.Serve(l, handler)
+		})
+	}
+
+	if p := interp.binPkg["net/rpc"]; p != nil {
+		dialHTTP := func(network, address, path string) (*rpc.Client, error) {
+			conn, err := dial(network, address)
+			if err != nil {
+				return nil, err
+			}
+			// The handshake of rpc.DialHTTPPath.
+			io.WriteString(conn, "CONNECT "+path+" HTTP/1.0\n\n")
+			resp, err := http.ReadResponse(bufio.NewReader(conn), &http.Request{Method: "CONNECT"})
+			if err == nil && resp.Status == "200 Connected to Go RPC" {
+				return rpc.NewClient(conn), nil
+			}
+			if err == nil {
+				err = errors.New("unexpected HTTP response: " + resp.Status)
+			}
+			conn.Close()
+			return nil, &net.OpError{Op: "dial-http", Net: network + " " + address, Err: err}
+		}
+		replace(p, "Dial", func(network, address string) (*rpc.Client, error) {
+			conn, err := dial(network, address)
+			if err != nil {
+				return nil, err
+			}
+			return rpc.NewClient(conn), nil
+		})
+		replace(p, "DialHTTP", func(network, address string) (*rpc.Client, error) {
+			return dialHTTP(network, address, rpc.DefaultRPCPath)
+		})
+		replace(p, "DialHTTPPath", dialHTTP)
+	}
+
+	if p := interp.binPkg["net/rpc/jsonrpc"]; p != nil {
+		replace(p, "Dial", func(network, address string) (*rpc.Client, error) {
+			conn, err := dial(network, address)
+			if err != nil {
+				return nil, err
+			}
+			return jsonrpc.NewClient(conn), nil
+		})
+	}
+
+	if p := interp.binPkg["net/textproto"]; p != nil {
+		replace(p, "Dial", func(network, addr string) (*textproto.Conn, error) {
+			conn, err := dial(network, addr)
+			if err != nil {
+				return nil, err
+			}
+			return textproto.NewConn(conn), nil
+		})
+	}
+
+	if p := interp.binPkg["net/smtp"]; p != nil {
+		smtpDial := func(addr string) (*smtp.Client, error) {
+			conn, err := dial("tcp", addr)
+			if err != nil {
+				return nil, err
+			}
+			host, _, _ := net.SplitHostPort(addr)
+			return smtp.NewClient(conn, host)
+		}
+		replace(p, "Dial", smtpDial)
+		replace(p, "SendMail", func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+			return sendMail(smtpDial, addr, a, from, to, msg)
+		})
+	}
+
+	if p := interp.binPkg["log/syslog"]; p != nil {
+		// The writers connect to the host syslog daemon by default, and can
+		// not be made from a connection.
+		for _, name := range []string{"Dial", "New", "NewLogger"} {
+			if v, ok := p[name]; ok {
+				replace(p, name, deniedFunc(v.Type(), "dial", "syslog").Interface())
+			}
+		}
+	}
+
+	if p := interp.binPkg["crypto/tls"]; p != nil {
+		dialTLS := func(timeout time.Duration, network, addr string, config *tls.Config) (*tls.Conn, error) {
+			ctx := bg
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+			c, err := nw.DialContext(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			if config == nil {
+				config = &tls.Config{}
+			}
+			if config.ServerName == "" {
+				config = config.Clone()
+				if host, _, err := net.SplitHostPort(addr); err == nil {
+					config.ServerName = host
+				} else {
+					config.ServerName = addr
+				}
+			}
+			conn := tls.Client(c, config)
+			if err := conn.HandshakeContext(ctx); err != nil {
+				c.Close()
+				return nil, err
+			}
+			return conn, nil
+		}
+		replace(p, "Dial", func(network, addr string, config *tls.Config) (*tls.Conn, error) {
+			return dialTLS(0, network, addr, config)
+		})
+		replace(p, "DialWithDialer", func(dialer *Dialer, network, addr string, config *tls.Config) (*tls.Conn, error) {
+			return dialTLS(dialer.Timeout, network, addr, config)
+		})
+		p["Dialer"] = reflect.ValueOf((*TLSDialer)(nil))
+		replace(p, "Listen", func(network, laddr string, config *tls.Config) (net.Listener, error) {
+			if config == nil || len(config.Certificates) == 0 && config.GetCertificate == nil && config.GetConfigForClient == nil {
+				return nil, errors.New("tls: neither Certificates, GetCertificate, nor GetConfigForClient set in Config")
+			}
+			l, err := listen(network, laddr)
+			if err != nil {
+				return nil, err
+			}
+			return tls.NewListener(l, config), nil
+		})
+	}
+}
+
+// dialAs dials raddr and returns the connection if of type T, as expected
+// by the address specific dial functions of package net.
+func dialAs[T net.Conn](dial func(network, address string) (net.Conn, error), network string, raddr net.Addr) (T, error) {
+	var zero T
+	if reflect.ValueOf(raddr).IsNil() {
+		return zero, &net.OpError{Op: "dial", Net: network, Err: errors.New("missing address")}
+	}
+	c, err := dial(network, raddr.String())
+	if err != nil {
+		return zero, err
+	}
+	t, ok := c.(T)
+	if !ok {
+		c.Close()
+		return zero, &net.OpError{Op: "dial", Net: network, Addr: raddr, Err: ErrNetworkDenied}
+	}
+	return t, nil
+}
+
+// listenAs listens on address and returns the listener if of type T, as
+// expected by the address specific listen functions of package net.
+func listenAs[T net.Listener](listen func(network, address string) (net.Listener, error), network, address string) (T, error) {
+	var zero T
+	l, err := listen(network, address)
+	if err != nil {
+		return zero, err
+	}
+	t, ok := l.(T)
+	if !ok {
+		l.Close()
+		return zero, &net.OpError{Op: "listen", Net: network, Err: ErrNetworkDenied}
+	}
+	return t, nil
+}
+
+// deniedError returns the error of a denied network operation.
+func deniedError(op, network string) error {
+	return &net.OpError{Op: op, Net: network, Err: ErrNetworkDenied}
+}
+
+// deniedFunc returns a function of type t, whose last result is an error,
+// returning zero values and an error wrapping ErrNetworkDenied.
+func deniedFunc(t reflect.Type, op, network string) reflect.Value {
+	return reflect.MakeFunc(t, func([]reflect.Value) []reflect.Value {
+		out := make([]reflect.Value, t.NumOut())
+		for i := range out {
+			out[i] = reflect.Zero(t.Out(i))
+		}
+		out[len(out)-1] = reflect.ValueOf(deniedError(op, network))
+		return out
+	})
+}
+
+// sendMail is smtp.SendMail, where the client is returned by dial.
+func sendMail(dial func(addr string) (*smtp.Client, error), addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+	for _, line := range append([]string{from}, to...) {
+		if strings.ContainsAny(line, "\n\r") {
+			return errors.New("smtp: A line must not contain CR or LF")
+		}
+	}
+	c, err := dial(addr)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if err = c.Hello("localhost"); err != nil {
+		return err
+	}
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		host, _, _ := net.SplitHostPort(addr)
+		if err = c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if a != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("smtp: server doesn't support AUTH")
+		}
+		if err = c.Auth(a); err != nil {
+			return err
+		}
+	}
+	if err = c.Mail(from); err != nil {
+		return err
+	}
+	for _, addr := range to {
+		if err = c.Rcpt(addr); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(msg); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// Dialer replaces net.Dialer in the net package of the interpreted code when
+// Options.Network is set, as the Dialer values created by the interpreted
+// code are not bound to the Network. Its connections are denied: the
+// interpreted code dials with the functions of package net instead.
+type Dialer struct {
+	Timeout        time.Duration
+	Deadline       time.Time
+	LocalAddr      net.Addr
+	DualStack      bool
+	FallbackDelay  time.Duration
+	KeepAlive      time.Duration
+	Resolver       *Resolver
+	Cancel         <-chan struct{}
+	Control        func(network, address string, c syscall.RawConn) error
+	ControlContext func(ctx context.Context, network, address string, c syscall.RawConn) error
+}
+
+// Dial returns an error wrapping ErrNetworkDenied.
+func (d *Dialer) Dial(network, address string) (net.Conn, error) {
+	return nil, deniedError("dial", network)
+}
+
+// DialContext returns an error wrapping ErrNetworkDenied.
+func (d *Dialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return nil, deniedError("dial", network)
+}
+
+// ListenConfig replaces net.ListenConfig in the net package of the
+// interpreted code when Options.Network is set. Its listeners are denied:
+// the interpreted code listens with the functions of package net instead.
+type ListenConfig struct {
+	Control   func(network, address string, c syscall.RawConn) error
+	KeepAlive time.Duration
+}
+
+// Listen returns an error wrapping ErrNetworkDenied.
+func (lc *ListenConfig) Listen(ctx context.Context, network, address string) (net.Listener, error) {
+	return nil, deniedError("listen", network)
+}
+
+// ListenPacket returns an error wrapping ErrNetworkDenied.
+func (lc *ListenConfig) ListenPacket(ctx context.Context, network, address string) (net.PacketConn, error) {
+	return nil, deniedError("listen", network)
+}
+
+// TLSDialer replaces tls.Dialer in the crypto/tls package of the interpreted
+// code when Options.Network is set. Its connections are denied: the
+// interpreted code dials with the functions of package crypto/tls instead.
+type TLSDialer struct {
+	NetDialer *Dialer
+	Config    *tls.Config
+}
+
+// Dial returns an error wrapping ErrNetworkDenied.
+func (d *TLSDialer) Dial(network, addr string) (net.Conn, error) {
+	return nil, deniedError("dial", network)
+}
+
+// DialContext returns an error wrapping ErrNetworkDenied.
+func (d *TLSDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	return nil, deniedError("dial", network)
+}
+
+// Resolver replaces net.Resolver in the net package of the interpreted code
+// when Options.Network is set. The lookups are made by the Go resolver,
+// dialing the name servers with Dial if set, else with the Network for
+// net.DefaultResolver. They are denied for the other Resolver values.
+type Resolver struct {
+	PreferGo     bool
+	StrictErrors bool
+	Dial         func(ctx context.Context, network, address string) (net.Conn, error)
+
+	network Network // network of net.DefaultResolver, or nil
+}
+
+// resolver returns the host resolver making the lookups of r.
+func (r *Resolver) resolver() *net.Resolver {
+	dial := r.Dial
+	switch {
+	case dial != nil:
+	case r.network != nil:
+		dial = r.network.DialContext
+	default:
+		dial = func(ctx context.Context, network, address string) (net.Conn, error) {
+			return nil, deniedError("dial", network)
+		}
+	}
+	return &net.Resolver{PreferGo: true, StrictErrors: r.StrictErrors, Dial: dial}
+}
+
+// LookupHost looks up the addresses of host, as net.Resolver.LookupHost.
+func (r *Resolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return r.resolver().LookupHost(ctx, host)
+}
+
+// LookupIP looks up the IP addresses of host, as net.Resolver.LookupIP.
+func (r *Resolver) LookupIP(ctx context.Context, network, host string) ([]net.IP, error) {
+	return r.resolver().LookupIP(ctx, network, host)
+}
+
+// LookupIPAddr looks up the IP addresses of host, as net.Resolver.LookupIPAddr.
+func (r *Resolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	return r.resolver().LookupIPAddr(ctx, host)
+}
+
+// LookupNetIP looks up the IP addresses of host, as net.Resolver.LookupNetIP.
+func (r *Resolver) LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	return r.resolver().LookupNetIP(ctx, network, host)
+}
+
+// LookupAddr looks up the names of addr, as net.Resolver.LookupAddr.
+func (r *Resolver) LookupAddr(ctx context.Context, addr string) ([]string, error) {
+	return r.resolver().LookupAddr(ctx, addr)
+}
+
+// LookupCNAME looks up the canonical name of host, as net.Resolver.LookupCNAME.
+func (r *Resolver) LookupCNAME(ctx context.Context, host string) (string, error) {
+	return r.resolver().LookupCNAME(ctx, host)
+}
+
+// LookupMX looks up the MX records of name, as net.Resolver.LookupMX.
+func (r *Resolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	return r.resolver().LookupMX(ctx, name)
+}
+
+// LookupNS looks up the NS records of name, as net.Resolver.LookupNS.
+func (r *Resolver) LookupNS(ctx context.Context, name string) ([]*net.NS, error) {
+	return r.resolver().LookupNS(ctx, name)
+}
+
+// LookupTXT looks up the TXT records of name, as net.Resolver.LookupTXT.
+func (r *Resolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	return r.resolver().LookupTXT(ctx, name)
+}
+
+// LookupSRV looks up the SRV records of a service, as net.Resolver.LookupSRV.
+func (r *Resolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	return r.resolver().LookupSRV(ctx, service, proto, name)
+}
+
+// LookupPort looks up the port of a service, as net.Resolver.LookupPort.
+func (r *Resolver) LookupPort(ctx context.Context, network, service string) (int, error) {
+	return r.resolver().LookupPort(ctx, network, service)
+}
+
+// HTTPClient replaces http.Client in the net/http package of the interpreted
+// code when Options.Network is set. Its requests are sent with Transport if
+// set, else with http.DefaultTransport for http.DefaultClient, and denied for
+// the other HTTPClient values. The requests of a host http.Transport without
+// DialContext, obtained from a binary package, are denied as well, as it
+// would dial the host network.
+type HTTPClient struct {
+	Transport     http.RoundTripper
+	CheckRedirect func(req *http.Request, via []*http.Request) error
+	Jar           http.CookieJar
+	Timeout       time.Duration
+
+	transport *http.RoundTripper // http.DefaultTransport of the interpreter, for http.DefaultClient
+}
+
+// client returns the host client sending the requests of c.
+func (c *HTTPClient) client() *http.Client {
+	t := c.Transport
+	if t == nil && c.transport != nil {
+		t = *c.transport
+	}
+	if t == nil || hostTransport(t) {
+		t = deniedTransport{}
+	}
+	return &http.Client{Transport: t, CheckRedirect: c.CheckRedirect, Jar: c.Jar, Timeout: c.Timeout}
+}
+
+// Do sends an HTTP request, as http.Client.Do.
+func (c *HTTPClient) Do(req *http.Request) (*http.Response, error) { return c.client().Do(req) }
+
+// Get issues a GET to the URL, as http.Client.Get.
+func (c *HTTPClient) Get(url string) (*http.Response, error) { return c.client().Get(url) }
+
+// Head issues a HEAD to the URL, as http.Client.Head.
+func (c *HTTPClient) Head(url string) (*http.Response, error) { return c.client().Head(url) }
+
+// Post issues a POST to the URL, as http.Client.Post.
+func (c *HTTPClient) Post(url, contentType string, body io.Reader) (*http.Response, error) {
+	return c.client().Post(url, contentType, body)
+}
+
+// PostForm issues a POST to the URL with data as body, as http.Client.PostForm.
+func (c *HTTPClient) PostForm(url string, data url.Values) (*http.Response, error) {
+	return c.client().PostForm(url, data)
+}
+
+// CloseIdleConnections closes the idle connections of the transport, as
+// http.Client.CloseIdleConnections.
+func (c *HTTPClient) CloseIdleConnections() { c.client().CloseIdleConnections() }
+
+// HTTPTransport replaces http.Transport in the net/http package of the
+// interpreted code when Options.Network is set. Its connections are made with
+// DialContext or Dial if set, else with the Network for http.DefaultTransport
+// and its clones, and denied for the other HTTPTransport values. The fields
+// are read at the first request.
+type HTTPTransport struct {
+	Proxy                  func(*http.Request) (*url.URL, error)
+	OnProxyConnectResponse func(ctx context.Context, proxyURL *url.URL, connectReq *http.Request, connectRes *http.Response) error
+	DialContext            func(ctx context.Context, network, addr string) (net.Conn, error)
+	Dial                   func(network, addr string) (net.Conn, error)
+	DialTLSContext         func(ctx context.Context, network, addr string) (net.Conn, error)
+	DialTLS                func(network, addr string) (net.Conn, error)
+	TLSClientConfig        *tls.Config
+	TLSHandshakeTimeout    time.Duration
+	DisableKeepAlives      bool
+	DisableCompression     bool
+	MaxIdleConns           int
+	MaxIdleConnsPerHost    int
+	MaxConnsPerHost        int
+	IdleConnTimeout        time.Duration
+	ResponseHeaderTimeout  time.Duration
+	ExpectContinueTimeout  time.Duration
+	TLSNextProto           map[string]func(authority string, c *tls.Conn) http.RoundTripper
+	ProxyConnectHeader     http.Header
+	GetProxyConnectHeader  func(ctx context.Context, proxyURL *url.URL, target string) (http.Header, error)
+	MaxResponseHeaderBytes int64
+	WriteBufferSize        int
+	ReadBufferSize         int
+	ForceAttemptHTTP2      bool
+
+	dial func(ctx context.Context, network, addr string) (net.Conn, error) // dial of the Network, for http.DefaultTransport
+
+	once sync.Once
+	tr   *http.Transport
+}
+
+// transport returns the host transport of t.
+func (t *HTTPTransport) transport() *http.Transport {
+	t.once.Do(func() {
+		dial := t.DialContext
+		if dial == nil && t.Dial == nil {
+			dial = t.dial
+		}
+		if dial == nil && t.Dial == nil {
+			dial = func(ctx context.Context, network, addr string) (net.Conn, error) {
+				return nil, deniedError("dial", network)
+			}
+		}
+		t.tr = &http.Transport{
+			Proxy:                  t.Proxy,
+			OnProxyConnectResponse: t.OnProxyConnectResponse,
+			DialContext:            dial,
+			Dial:                   t.Dial, //nolint:staticcheck // Dial is used if DialContext is not set.
+			DialTLSContext:         t.DialTLSContext,
+			DialTLS:                t.DialTLS, //nolint:staticcheck // DialTLS is used if DialTLSContext is not set.
+			TLSClientConfig:        t.TLSClientConfig,
+			TLSHandshakeTimeout:    t.TLSHandshakeTimeout,
+			DisableKeepAlives:      t.DisableKeepAlives,
+			DisableCompression:     t.DisableCompression,
+			MaxIdleConns:           t.MaxIdleConns,
+			MaxIdleConnsPerHost:    t.MaxIdleConnsPerHost,
+			MaxConnsPerHost:        t.MaxConnsPerHost,
+			IdleConnTimeout:        t.IdleConnTimeout,
+			ResponseHeaderTimeout:  t.ResponseHeaderTimeout,
+			ExpectContinueTimeout:  t.ExpectContinueTimeout,
+			TLSNextProto:           t.TLSNextProto,
+			ProxyConnectHeader:     t.ProxyConnectHeader,
+			GetProxyConnectHeader:  t.GetProxyConnectHeader,
+			MaxResponseHeaderBytes: t.MaxResponseHeaderBytes,
+			WriteBufferSize:        t.WriteBufferSize,
+			ReadBufferSize:         t.ReadBufferSize,
+			ForceAttemptHTTP2:      t.ForceAttemptHTTP2,
+		}
+	})
+	return t.tr
+}
+
+// RoundTrip executes a single HTTP transaction, as http.Transport.RoundTrip.
+func (t *HTTPTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.transport().RoundTrip(req)
+}
+
+// Clone returns a copy of the fields of t, as http.Transport.Clone.
+func (t *HTTPTransport) Clone() *HTTPTransport {
+	return &HTTPTransport{
+		Proxy:                  t.Proxy,
+		OnProxyConnectResponse: t.OnProxyConnectResponse,
+		DialContext:            t.DialContext,
+		Dial:                   t.Dial,
+		DialTLSContext:         t.DialTLSContext,
+		DialTLS:                t.DialTLS,
+		TLSClientConfig:        t.TLSClientConfig.Clone(),
+		TLSHandshakeTimeout:    t.TLSHandshakeTimeout,
+		DisableKeepAlives:      t.DisableKeepAlives,
+		DisableCompression:     t.DisableCompression,
+		MaxIdleConns:           t.MaxIdleConns,
+		MaxIdleConnsPerHost:    t.MaxIdleConnsPerHost,
+		MaxConnsPerHost:        t.MaxConnsPerHost,
+		IdleConnTimeout:        t.IdleConnTimeout,
+		ResponseHeaderTimeout:  t.ResponseHeaderTimeout,
+		ExpectContinueTimeout:  t.ExpectContinueTimeout,
+		TLSNextProto:           t.TLSNextProto,
+		ProxyConnectHeader:     t.ProxyConnectHeader.Clone(),
+		GetProxyConnectHeader:  t.GetProxyConnectHeader,
+		MaxResponseHeaderBytes: t.MaxResponseHeaderBytes,
+		WriteBufferSize:        t.WriteBufferSize,
+		ReadBufferSize:         t.ReadBufferSize,
+		ForceAttemptHTTP2:      t.ForceAttemptHTTP2,
+		dial:                   t.dial,
+	}
+}
+
+// CloseIdleConnections closes the idle connections, as
+// http.Transport.CloseIdleConnections.
+func (t *HTTPTransport) CloseIdleConnections() { t.transport().CloseIdleConnections() }
+
+// RegisterProtocol registers a new protocol with scheme, as
+// http.Transport.RegisterProtocol.
+func (t *HTTPTransport) RegisterProtocol(scheme string, rt http.RoundTripper) {
+	t.transport().RegisterProtocol(scheme, rt)
+}
+
+// ReverseProxy replaces httputil.ReverseProxy in the net/http/httputil
+// package of the interpreted code when Options.Network is set. Its requests
+// are sent with Transport if set, else with http.DefaultTransport for the
+// proxies returned by httputil.NewSingleHostReverseProxy, and denied for the
+// other ReverseProxy values. The fields are read at the first request.
+type ReverseProxy struct {
+	Rewrite        func(*httputil.ProxyRequest)
+	Director       func(*http.Request)
+	Transport      http.RoundTripper
+	FlushInterval  time.Duration
+	ErrorLog       *log.Logger
+	BufferPool     httputil.BufferPool
+	ModifyResponse func(*http.Response) error
+	ErrorHandler   func(http.ResponseWriter, *http.Request, error)
+
+	transport *http.RoundTripper // http.DefaultTransport of the interpreter
+
+	once sync.Once
+	rp   *httputil.ReverseProxy
+}
+
+// ServeHTTP forwards the request to the backend, as httputil.ReverseProxy.ServeHTTP.
+func (p *ReverseProxy) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	p.once.Do(func() {
+		t := p.Transport
+		if t == nil && p.transport != nil {
+			t = *p.transport
+		}
+		if t == nil || hostTransport(t) {
+			t = deniedTransport{}
+		}
+		p.rp = &httputil.ReverseProxy{
+			Rewrite:        p.Rewrite,
+			Director:       p.Director,
+			Transport:      t,
+			FlushInterval:  p.FlushInterval,
+			ErrorLog:       p.ErrorLog,
+			BufferPool:     p.BufferPool,
+			ModifyResponse: p.ModifyResponse,
+			ErrorHandler:   p.ErrorHandler,
+		}
+	})
+	p.rp.ServeHTTP(rw, req)
+}
+
+// hostTransport returns true if t is an http.Transport dialing with the host
+// network, having no dial function.
+func hostTransport(t http.RoundTripper) bool {
+	tr, ok := t.(*http.Transport)
+	return ok && tr.DialContext == nil && tr.Dial == nil //nolint:staticcheck // Dial replaces the host dialer as well.
+}
+
+// deniedTransport is a RoundTripper denying all the requests.
+type deniedTransport struct{}
+
+func (deniedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return nil, deniedError("dial", "tcp")
+}
+
+// HTTPServer replaces http.Server in the net/http package of the interpreted
+// code when Options.Network is set. It serves the connections of the
+// listeners given to Serve and ServeTLS, and denies ListenAndServe and
+// ListenAndServeTLS: the interpreted code listens with the functions of
+// packages net and net/http instead. The fields are read at the first call
+// of a method.
+type HTTPServer struct {
+	Addr                         string
+	Handler                      http.Handler
+	DisableGeneralOptionsHandler bool
+	TLSConfig                    *tls.Config
+	ReadTimeout                  time.Duration
+	ReadHeaderTimeout            time.Duration
+	WriteTimeout                 time.Duration
+	IdleTimeout                  time.Duration
+	MaxHeaderBytes               int
+	TLSNextProto                 map[string]func(*http.Server, *tls.Conn, http.Handler)
+	ConnState                    func(net.Conn, http.ConnState)
+	ErrorLog                     *log.Logger
+	BaseContext                  func(net.Listener) context.Context
+	ConnContext                  func(ctx context.Context, c net.Conn) context.Context
+
+	once sync.Once
+	srv  *http.Server
+}
+
+// server returns the host server of s.
+func (s *HTTPServer) server() *http.Server {
+	s.once.Do(func() {
+		s.srv = &http.Server{
+			Addr:                         s.Addr,
+			Handler:                      s.Handler,
+			DisableGeneralOptionsHandler: s.DisableGeneralOptionsHandler,
+			TLSConfig:                    s.TLSConfig,
+			ReadTimeout:                  s.ReadTimeout,
+			ReadHeaderTimeout:            s.ReadHeaderTimeout,
+			WriteTimeout:                 s.WriteTimeout,
+			IdleTimeout:                  s.IdleTimeout,
+			MaxHeaderBytes:               s.MaxHeaderBytes,
+			TLSNextProto:                 s.TLSNextProto,
+			ConnState:                    s.ConnState,
+			ErrorLog:                     s.ErrorLog,
+			BaseContext:                  s.BaseContext,
+			ConnContext:                  s.ConnContext,
+		}
+	})
+	return s.srv
+}
+
+// Serve serves the connections of l, as http.Server.Serve.
+func (s *HTTPServer) Serve(l net.Listener) error { return s.server().Serve(l) }
+
+// ServeTLS serves the TLS connections of l, as http.Server.ServeTLS.
+func (s *HTTPServer) ServeTLS(l net.Listener, certFile, keyFile string) error {
+	return s.server().ServeTLS(l, certFile, keyFile)
+}
+
+// ListenAndServe returns an error wrapping ErrNetworkDenied.
+func (s *HTTPServer) ListenAndServe() error { return deniedError("listen", "tcp") }
+
+// ListenAndServeTLS returns an error wrapping ErrNetworkDenied.
+func (s *HTTPServer) ListenAndServeTLS(certFile, keyFile string) error {
+	return deniedError("listen", "tcp")
+}
+
+// Close closes the listeners and connections, as http.Server.Close.
+func (s *HTTPServer) Close() error { return s.server().Close() }
+
+// Shutdown gracefully shuts down the server, as http.Server.Shutdown.
+func (s *HTTPServer) Shutdown(ctx context.Context) error { return s.server().Shutdown(ctx) }
+
+// RegisterOnShutdown registers a function to call on Shutdown, as
+// http.Server.RegisterOnShutdown.
+func (s *HTTPServer) RegisterOnShutdown(f func()) { s.server().RegisterOnShutdown(f) }
+
+// SetKeepAlivesEnabled controls HTTP keep-alives, as http.Server.SetKeepAlivesEnabled.
+func (s *HTTPServer) SetKeepAlivesEnabled(v bool) { s.server().SetKeepAlivesEnabled(v) }
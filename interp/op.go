@@ -6,12 +6,16 @@ import (
 	"go/constant"
 	"go/token"
 	"reflect"
+	"unsafe"
 )
 
 // Arithmetic operators
 
 func add(n *node) {
 	next := getExec(n.tnext)
+	if addUnboxed(n, next) {
+		return
+	}
 	typ := n.typ.concrete().TypeOf()
 	isInterface := n.typ.TypeOf().Kind() == reflect.Interface
 	dest := genValueOutput(n, typ)
@@ -221,6 +225,9 @@ func addConst(n *node) {
 
 func and(n *node) {
 	next := getExec(n.tnext)
+	if andUnboxed(n, next) {
+		return
+	}
 	typ := n.typ.concrete().TypeOf()
 	isInterface := n.typ.TypeOf().Kind() == reflect.Interface
 	dest := genValueOutput(n, typ)
@@ -325,6 +332,9 @@ func andConst(n *node) {
 
 func andNot(n *node) {
 	next := getExec(n.tnext)
+	if andNotUnboxed(n, next) {
+		return
+	}
 	typ := n.typ.concrete().TypeOf()
 	isInterface := n.typ.TypeOf().Kind() == reflect.Interface
 	dest := genValueOutput(n, typ)
@@ -429,6 +439,9 @@ func andNotConst(n *node) {
 
 func mul(n *node) {
 	next := getExec(n.tnext)
+	if mulUnboxed(n, next) {
+		return
+	}
 	typ := n.typ.concrete().TypeOf()
 	isInterface := n.typ.TypeOf().Kind() == reflect.Interface
 	dest := genValueOutput(n, typ)
@@ -605,6 +618,9 @@ func mulConst(n *node) {
 
 func or(n *node) {
 	next := getExec(n.tnext)
+	if orUnboxed(n, next) {
+		return
+	}
 	typ := n.typ.concrete().TypeOf()
 	isInterface := n.typ.TypeOf().Kind() == reflect.Interface
 	dest := genValueOutput(n, typ)
@@ -709,6 +725,9 @@ func orConst(n *node) {
 
 func quo(n *node) {
 	next := getExec(n.tnext)
+	if quoUnboxed(n, next) {
+		return
+	}
 	typ := n.typ.concrete().TypeOf()
 	isInterface := n.typ.TypeOf().Kind() == reflect.Interface
 	dest := genValueOutput(n, typ)
@@ -894,6 +913,9 @@ func quoConst(n *node) {
 
 func rem(n *node) {
 	next := getExec(n.tnext)
+	if remUnboxed(n, next) {
+		return
+	}
 	typ := n.typ.concrete().TypeOf()
 	isInterface := n.typ.TypeOf().Kind() == reflect.Interface
 	dest := genValueOutput(n, typ)
@@ -1206,6 +1228,9 @@ func shrConst(n *node) {
 
 func sub(n *node) {
 	next := getExec(n.tnext)
+	if subUnboxed(n, next) {
+		return
+	}
 	typ := n.typ.concrete().TypeOf()
 	isInterface := n.typ.TypeOf().Kind() == reflect.Interface
 	dest := genValueOutput(n, typ)
@@ -1382,6 +1407,9 @@ func subConst(n *node) {
 
 func xor(n *node) {
 	next := getExec(n.tnext)
+	if xorUnboxed(n, next) {
+		return
+	}
 	typ := n.typ.concrete().TypeOf()
 	isInterface := n.typ.TypeOf().Kind() == reflect.Interface
 	dest := genValueOutput(n, typ)
@@ -1488,6 +1516,9 @@ func xorConst(n *node) {
 
 func addAssign(n *node) {
 	next := getExec(n.tnext)
+	if addAssignUnboxed(n, next) {
+		return
+	}
 	typ := n.typ.TypeOf()
 	c0, c1 := n.child[0], n.child[1]
 	setMap := isMapEntry(c0)
@@ -1622,6 +1653,9 @@ func addAssign(n *node) {
 
 func andAssign(n *node) {
 	next := getExec(n.tnext)
+	if andAssignUnboxed(n, next) {
+		return
+	}
 	typ := n.typ.TypeOf()
 	c0, c1 := n.child[0], n.child[1]
 	setMap := isMapEntry(c0)
@@ -1689,6 +1723,9 @@ func andAssign(n *node) {
 
 func andNotAssign(n *node) {
 	next := getExec(n.tnext)
+	if andNotAssignUnboxed(n, next) {
+		return
+	}
 	typ := n.typ.TypeOf()
 	c0, c1 := n.child[0], n.child[1]
 	setMap := isMapEntry(c0)
@@ -1756,6 +1793,9 @@ func andNotAssign(n *node) {
 
 func mulAssign(n *node) {
 	next := getExec(n.tnext)
+	if mulAssignUnboxed(n, next) {
+		return
+	}
 	typ := n.typ.TypeOf()
 	c0, c1 := n.child[0], n.child[1]
 	setMap := isMapEntry(c0)
@@ -1868,6 +1908,9 @@ func mulAssign(n *node) {
 
 func orAssign(n *node) {
 	next := getExec(n.tnext)
+	if orAssignUnboxed(n, next) {
+		return
+	}
 	typ := n.typ.TypeOf()
 	c0, c1 := n.child[0], n.child[1]
 	setMap := isMapEntry(c0)
@@ -1935,6 +1978,9 @@ func orAssign(n *node) {
 
 func quoAssign(n *node) {
 	next := getExec(n.tnext)
+	if quoAssignUnboxed(n, next) {
+		return
+	}
 	typ := n.typ.TypeOf()
 	c0, c1 := n.child[0], n.child[1]
 	setMap := isMapEntry(c0)
@@ -2047,6 +2093,9 @@ func quoAssign(n *node) {
 
 func remAssign(n *node) {
 	next := getExec(n.tnext)
+	if remAssignUnboxed(n, next) {
+		return
+	}
 	typ := n.typ.TypeOf()
 	c0, c1 := n.child[0], n.child[1]
 	setMap := isMapEntry(c0)
@@ -2248,6 +2297,9 @@ func shrAssign(n *node) {
 
 func subAssign(n *node) {
 	next := getExec(n.tnext)
+	if subAssignUnboxed(n, next) {
+		return
+	}
 	typ := n.typ.TypeOf()
 	c0, c1 := n.child[0], n.child[1]
 	setMap := isMapEntry(c0)
@@ -2360,6 +2412,9 @@ func subAssign(n *node) {
 
 func xorAssign(n *node) {
 	next := getExec(n.tnext)
+	if xorAssignUnboxed(n, next) {
+		return
+	}
 	typ := n.typ.TypeOf()
 	c0, c1 := n.child[0], n.child[1]
 	setMap := isMapEntry(c0)
@@ -2427,6 +2482,9 @@ func xorAssign(n *node) {
 
 func dec(n *node) {
 	next := getExec(n.tnext)
+	if decUnboxed(n, next) {
+		return
+	}
 	typ := n.typ.TypeOf()
 	c0 := n.child[0]
 	setMap := isMapEntry(c0)
@@ -2483,6 +2541,9 @@ func dec(n *node) {
 
 func inc(n *node) {
 	next := getExec(n.tnext)
+	if incUnboxed(n, next) {
+		return
+	}
 	typ := n.typ.TypeOf()
 	c0 := n.child[0]
 	setMap := isMapEntry(c0)
@@ -2620,6 +2681,9 @@ func posConst(n *node) {
 
 func equal(n *node) {
 	tnext := getExec(n.tnext)
+	if equalUnboxed(n, tnext) {
+		return
+	}
 	dest := genValueOutput(n, reflect.TypeOf(true))
 	typ := n.typ.concrete().TypeOf()
 	isInterface := n.typ.TypeOf().Kind() == reflect.Interface
@@ -3220,6 +3284,9 @@ func equal(n *node) {
 
 func greater(n *node) {
 	tnext := getExec(n.tnext)
+	if greaterUnboxed(n, tnext) {
+		return
+	}
 	dest := genValueOutput(n, reflect.TypeOf(true))
 	typ := n.typ.concrete().TypeOf()
 	isInterface := n.typ.TypeOf().Kind() == reflect.Interface
@@ -3548,6 +3615,9 @@ func greater(n *node) {
 
 func greaterEqual(n *node) {
 	tnext := getExec(n.tnext)
+	if greaterEqualUnboxed(n, tnext) {
+		return
+	}
 	dest := genValueOutput(n, reflect.TypeOf(true))
 	typ := n.typ.concrete().TypeOf()
 	isInterface := n.typ.TypeOf().Kind() == reflect.Interface
@@ -3876,6 +3946,9 @@ func greaterEqual(n *node) {
 
 func lower(n *node) {
 	tnext := getExec(n.tnext)
+	if lowerUnboxed(n, tnext) {
+		return
+	}
 	dest := genValueOutput(n, reflect.TypeOf(true))
 	typ := n.typ.concrete().TypeOf()
 	isInterface := n.typ.TypeOf().Kind() == reflect.Interface
@@ -4204,6 +4277,9 @@ func lower(n *node) {
 
 func lowerEqual(n *node) {
 	tnext := getExec(n.tnext)
+	if lowerEqualUnboxed(n, tnext) {
+		return
+	}
 	dest := genValueOutput(n, reflect.TypeOf(true))
 	typ := n.typ.concrete().TypeOf()
 	isInterface := n.typ.TypeOf().Kind() == reflect.Interface
@@ -4532,6 +4608,9 @@ func lowerEqual(n *node) {
 
 func notEqual(n *node) {
 	tnext := getExec(n.tnext)
+	if notEqualUnboxed(n, tnext) {
+		return
+	}
 	dest := genValueOutput(n, reflect.TypeOf(true))
 	typ := n.typ.concrete().TypeOf()
 	isInterface := n.typ.TypeOf().Kind() == reflect.Interface
@@ -5129,3 +5208,3573 @@ func notEqual(n *node) {
 		}
 	}
 }
+
+// Operators on unboxed values, see unbox.go.
+
+func addUnboxed(n *node, next bltn) bool {
+	d, i0, i1, t, ok := unboxedOperands(n, n, n.child[0], n.child[1])
+	if !ok || t != n.typ.TypeOf() {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int:
+		if i1 < 0 {
+			j := int(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int)(unsafe.Pointer(&f.num[d])) = *(*int)(unsafe.Pointer(&f.num[i0])) + j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int)(unsafe.Pointer(&f.num[d])) = *(*int)(unsafe.Pointer(&f.num[i0])) + *(*int)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int32:
+		if i1 < 0 {
+			j := int32(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int32)(unsafe.Pointer(&f.num[d])) = *(*int32)(unsafe.Pointer(&f.num[i0])) + j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int32)(unsafe.Pointer(&f.num[d])) = *(*int32)(unsafe.Pointer(&f.num[i0])) + *(*int32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int64:
+		if i1 < 0 {
+			j := int64(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int64)(unsafe.Pointer(&f.num[d])) = *(*int64)(unsafe.Pointer(&f.num[i0])) + j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int64)(unsafe.Pointer(&f.num[d])) = *(*int64)(unsafe.Pointer(&f.num[i0])) + *(*int64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint:
+		if i1 < 0 {
+			j := uint(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint)(unsafe.Pointer(&f.num[d])) = *(*uint)(unsafe.Pointer(&f.num[i0])) + j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint)(unsafe.Pointer(&f.num[d])) = *(*uint)(unsafe.Pointer(&f.num[i0])) + *(*uint)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint8:
+		if i1 < 0 {
+			j := uint8(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint8)(unsafe.Pointer(&f.num[d])) = *(*uint8)(unsafe.Pointer(&f.num[i0])) + j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint8)(unsafe.Pointer(&f.num[d])) = *(*uint8)(unsafe.Pointer(&f.num[i0])) + *(*uint8)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint32:
+		if i1 < 0 {
+			j := uint32(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint32)(unsafe.Pointer(&f.num[d])) = *(*uint32)(unsafe.Pointer(&f.num[i0])) + j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint32)(unsafe.Pointer(&f.num[d])) = *(*uint32)(unsafe.Pointer(&f.num[i0])) + *(*uint32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint64:
+		if i1 < 0 {
+			j := uint64(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint64)(unsafe.Pointer(&f.num[d])) = *(*uint64)(unsafe.Pointer(&f.num[i0])) + j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint64)(unsafe.Pointer(&f.num[d])) = *(*uint64)(unsafe.Pointer(&f.num[i0])) + *(*uint64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Float32:
+		if i1 < 0 {
+			j := float32(vFloat(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*float32)(unsafe.Pointer(&f.num[d])) = *(*float32)(unsafe.Pointer(&f.num[i0])) + j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*float32)(unsafe.Pointer(&f.num[d])) = *(*float32)(unsafe.Pointer(&f.num[i0])) + *(*float32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Float64:
+		if i1 < 0 {
+			j := float64(vFloat(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*float64)(unsafe.Pointer(&f.num[d])) = *(*float64)(unsafe.Pointer(&f.num[i0])) + j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*float64)(unsafe.Pointer(&f.num[d])) = *(*float64)(unsafe.Pointer(&f.num[i0])) + *(*float64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	}
+	return false
+}
+
+func addAssignUnboxed(n *node, next bltn) bool {
+	d, _, i1, t, ok := unboxedOperands(n, n.child[0], n.child[0], n.child[1])
+	if !ok {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int:
+		if i1 < 0 {
+			j := int(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int)(unsafe.Pointer(&f.num[d])) += j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int)(unsafe.Pointer(&f.num[d])) += *(*int)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int32:
+		if i1 < 0 {
+			j := int32(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int32)(unsafe.Pointer(&f.num[d])) += j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int32)(unsafe.Pointer(&f.num[d])) += *(*int32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int64:
+		if i1 < 0 {
+			j := int64(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int64)(unsafe.Pointer(&f.num[d])) += j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int64)(unsafe.Pointer(&f.num[d])) += *(*int64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint:
+		if i1 < 0 {
+			j := uint(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint)(unsafe.Pointer(&f.num[d])) += j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint)(unsafe.Pointer(&f.num[d])) += *(*uint)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint8:
+		if i1 < 0 {
+			j := uint8(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint8)(unsafe.Pointer(&f.num[d])) += j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint8)(unsafe.Pointer(&f.num[d])) += *(*uint8)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint32:
+		if i1 < 0 {
+			j := uint32(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint32)(unsafe.Pointer(&f.num[d])) += j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint32)(unsafe.Pointer(&f.num[d])) += *(*uint32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint64:
+		if i1 < 0 {
+			j := uint64(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint64)(unsafe.Pointer(&f.num[d])) += j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint64)(unsafe.Pointer(&f.num[d])) += *(*uint64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Float32:
+		if i1 < 0 {
+			j := float32(vFloat(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*float32)(unsafe.Pointer(&f.num[d])) += j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*float32)(unsafe.Pointer(&f.num[d])) += *(*float32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Float64:
+		if i1 < 0 {
+			j := float64(vFloat(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*float64)(unsafe.Pointer(&f.num[d])) += j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*float64)(unsafe.Pointer(&f.num[d])) += *(*float64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	}
+	return false
+}
+
+func andUnboxed(n *node, next bltn) bool {
+	d, i0, i1, t, ok := unboxedOperands(n, n, n.child[0], n.child[1])
+	if !ok || t != n.typ.TypeOf() {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int:
+		if i1 < 0 {
+			j := int(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int)(unsafe.Pointer(&f.num[d])) = *(*int)(unsafe.Pointer(&f.num[i0])) & j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int)(unsafe.Pointer(&f.num[d])) = *(*int)(unsafe.Pointer(&f.num[i0])) & *(*int)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int32:
+		if i1 < 0 {
+			j := int32(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int32)(unsafe.Pointer(&f.num[d])) = *(*int32)(unsafe.Pointer(&f.num[i0])) & j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int32)(unsafe.Pointer(&f.num[d])) = *(*int32)(unsafe.Pointer(&f.num[i0])) & *(*int32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int64:
+		if i1 < 0 {
+			j := int64(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int64)(unsafe.Pointer(&f.num[d])) = *(*int64)(unsafe.Pointer(&f.num[i0])) & j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int64)(unsafe.Pointer(&f.num[d])) = *(*int64)(unsafe.Pointer(&f.num[i0])) & *(*int64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint:
+		if i1 < 0 {
+			j := uint(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint)(unsafe.Pointer(&f.num[d])) = *(*uint)(unsafe.Pointer(&f.num[i0])) & j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint)(unsafe.Pointer(&f.num[d])) = *(*uint)(unsafe.Pointer(&f.num[i0])) & *(*uint)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint8:
+		if i1 < 0 {
+			j := uint8(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint8)(unsafe.Pointer(&f.num[d])) = *(*uint8)(unsafe.Pointer(&f.num[i0])) & j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint8)(unsafe.Pointer(&f.num[d])) = *(*uint8)(unsafe.Pointer(&f.num[i0])) & *(*uint8)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint32:
+		if i1 < 0 {
+			j := uint32(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint32)(unsafe.Pointer(&f.num[d])) = *(*uint32)(unsafe.Pointer(&f.num[i0])) & j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint32)(unsafe.Pointer(&f.num[d])) = *(*uint32)(unsafe.Pointer(&f.num[i0])) & *(*uint32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint64:
+		if i1 < 0 {
+			j := uint64(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint64)(unsafe.Pointer(&f.num[d])) = *(*uint64)(unsafe.Pointer(&f.num[i0])) & j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint64)(unsafe.Pointer(&f.num[d])) = *(*uint64)(unsafe.Pointer(&f.num[i0])) & *(*uint64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	}
+	return false
+}
+
+func andAssignUnboxed(n *node, next bltn) bool {
+	d, _, i1, t, ok := unboxedOperands(n, n.child[0], n.child[0], n.child[1])
+	if !ok {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int:
+		if i1 < 0 {
+			j := int(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int)(unsafe.Pointer(&f.num[d])) &= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int)(unsafe.Pointer(&f.num[d])) &= *(*int)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int32:
+		if i1 < 0 {
+			j := int32(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int32)(unsafe.Pointer(&f.num[d])) &= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int32)(unsafe.Pointer(&f.num[d])) &= *(*int32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int64:
+		if i1 < 0 {
+			j := int64(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int64)(unsafe.Pointer(&f.num[d])) &= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int64)(unsafe.Pointer(&f.num[d])) &= *(*int64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint:
+		if i1 < 0 {
+			j := uint(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint)(unsafe.Pointer(&f.num[d])) &= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint)(unsafe.Pointer(&f.num[d])) &= *(*uint)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint8:
+		if i1 < 0 {
+			j := uint8(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint8)(unsafe.Pointer(&f.num[d])) &= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint8)(unsafe.Pointer(&f.num[d])) &= *(*uint8)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint32:
+		if i1 < 0 {
+			j := uint32(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint32)(unsafe.Pointer(&f.num[d])) &= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint32)(unsafe.Pointer(&f.num[d])) &= *(*uint32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint64:
+		if i1 < 0 {
+			j := uint64(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint64)(unsafe.Pointer(&f.num[d])) &= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint64)(unsafe.Pointer(&f.num[d])) &= *(*uint64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	}
+	return false
+}
+
+func andNotUnboxed(n *node, next bltn) bool {
+	d, i0, i1, t, ok := unboxedOperands(n, n, n.child[0], n.child[1])
+	if !ok || t != n.typ.TypeOf() {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int:
+		if i1 < 0 {
+			j := int(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int)(unsafe.Pointer(&f.num[d])) = *(*int)(unsafe.Pointer(&f.num[i0])) &^ j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int)(unsafe.Pointer(&f.num[d])) = *(*int)(unsafe.Pointer(&f.num[i0])) &^ *(*int)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int32:
+		if i1 < 0 {
+			j := int32(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int32)(unsafe.Pointer(&f.num[d])) = *(*int32)(unsafe.Pointer(&f.num[i0])) &^ j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int32)(unsafe.Pointer(&f.num[d])) = *(*int32)(unsafe.Pointer(&f.num[i0])) &^ *(*int32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int64:
+		if i1 < 0 {
+			j := int64(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int64)(unsafe.Pointer(&f.num[d])) = *(*int64)(unsafe.Pointer(&f.num[i0])) &^ j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int64)(unsafe.Pointer(&f.num[d])) = *(*int64)(unsafe.Pointer(&f.num[i0])) &^ *(*int64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint:
+		if i1 < 0 {
+			j := uint(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint)(unsafe.Pointer(&f.num[d])) = *(*uint)(unsafe.Pointer(&f.num[i0])) &^ j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint)(unsafe.Pointer(&f.num[d])) = *(*uint)(unsafe.Pointer(&f.num[i0])) &^ *(*uint)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint8:
+		if i1 < 0 {
+			j := uint8(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint8)(unsafe.Pointer(&f.num[d])) = *(*uint8)(unsafe.Pointer(&f.num[i0])) &^ j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint8)(unsafe.Pointer(&f.num[d])) = *(*uint8)(unsafe.Pointer(&f.num[i0])) &^ *(*uint8)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint32:
+		if i1 < 0 {
+			j := uint32(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint32)(unsafe.Pointer(&f.num[d])) = *(*uint32)(unsafe.Pointer(&f.num[i0])) &^ j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint32)(unsafe.Pointer(&f.num[d])) = *(*uint32)(unsafe.Pointer(&f.num[i0])) &^ *(*uint32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint64:
+		if i1 < 0 {
+			j := uint64(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint64)(unsafe.Pointer(&f.num[d])) = *(*uint64)(unsafe.Pointer(&f.num[i0])) &^ j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint64)(unsafe.Pointer(&f.num[d])) = *(*uint64)(unsafe.Pointer(&f.num[i0])) &^ *(*uint64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	}
+	return false
+}
+
+func andNotAssignUnboxed(n *node, next bltn) bool {
+	d, _, i1, t, ok := unboxedOperands(n, n.child[0], n.child[0], n.child[1])
+	if !ok {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int:
+		if i1 < 0 {
+			j := int(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int)(unsafe.Pointer(&f.num[d])) &^= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int)(unsafe.Pointer(&f.num[d])) &^= *(*int)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int32:
+		if i1 < 0 {
+			j := int32(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int32)(unsafe.Pointer(&f.num[d])) &^= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int32)(unsafe.Pointer(&f.num[d])) &^= *(*int32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int64:
+		if i1 < 0 {
+			j := int64(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int64)(unsafe.Pointer(&f.num[d])) &^= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int64)(unsafe.Pointer(&f.num[d])) &^= *(*int64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint:
+		if i1 < 0 {
+			j := uint(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint)(unsafe.Pointer(&f.num[d])) &^= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint)(unsafe.Pointer(&f.num[d])) &^= *(*uint)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint8:
+		if i1 < 0 {
+			j := uint8(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint8)(unsafe.Pointer(&f.num[d])) &^= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint8)(unsafe.Pointer(&f.num[d])) &^= *(*uint8)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint32:
+		if i1 < 0 {
+			j := uint32(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint32)(unsafe.Pointer(&f.num[d])) &^= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint32)(unsafe.Pointer(&f.num[d])) &^= *(*uint32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint64:
+		if i1 < 0 {
+			j := uint64(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint64)(unsafe.Pointer(&f.num[d])) &^= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint64)(unsafe.Pointer(&f.num[d])) &^= *(*uint64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	}
+	return false
+}
+
+func mulUnboxed(n *node, next bltn) bool {
+	d, i0, i1, t, ok := unboxedOperands(n, n, n.child[0], n.child[1])
+	if !ok || t != n.typ.TypeOf() {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int:
+		if i1 < 0 {
+			j := int(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int)(unsafe.Pointer(&f.num[d])) = *(*int)(unsafe.Pointer(&f.num[i0])) * j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int)(unsafe.Pointer(&f.num[d])) = *(*int)(unsafe.Pointer(&f.num[i0])) * *(*int)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int32:
+		if i1 < 0 {
+			j := int32(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int32)(unsafe.Pointer(&f.num[d])) = *(*int32)(unsafe.Pointer(&f.num[i0])) * j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int32)(unsafe.Pointer(&f.num[d])) = *(*int32)(unsafe.Pointer(&f.num[i0])) * *(*int32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int64:
+		if i1 < 0 {
+			j := int64(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int64)(unsafe.Pointer(&f.num[d])) = *(*int64)(unsafe.Pointer(&f.num[i0])) * j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int64)(unsafe.Pointer(&f.num[d])) = *(*int64)(unsafe.Pointer(&f.num[i0])) * *(*int64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint:
+		if i1 < 0 {
+			j := uint(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint)(unsafe.Pointer(&f.num[d])) = *(*uint)(unsafe.Pointer(&f.num[i0])) * j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint)(unsafe.Pointer(&f.num[d])) = *(*uint)(unsafe.Pointer(&f.num[i0])) * *(*uint)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint8:
+		if i1 < 0 {
+			j := uint8(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint8)(unsafe.Pointer(&f.num[d])) = *(*uint8)(unsafe.Pointer(&f.num[i0])) * j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint8)(unsafe.Pointer(&f.num[d])) = *(*uint8)(unsafe.Pointer(&f.num[i0])) * *(*uint8)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint32:
+		if i1 < 0 {
+			j := uint32(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint32)(unsafe.Pointer(&f.num[d])) = *(*uint32)(unsafe.Pointer(&f.num[i0])) * j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint32)(unsafe.Pointer(&f.num[d])) = *(*uint32)(unsafe.Pointer(&f.num[i0])) * *(*uint32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint64:
+		if i1 < 0 {
+			j := uint64(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint64)(unsafe.Pointer(&f.num[d])) = *(*uint64)(unsafe.Pointer(&f.num[i0])) * j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint64)(unsafe.Pointer(&f.num[d])) = *(*uint64)(unsafe.Pointer(&f.num[i0])) * *(*uint64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Float32:
+		if i1 < 0 {
+			j := float32(vFloat(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*float32)(unsafe.Pointer(&f.num[d])) = *(*float32)(unsafe.Pointer(&f.num[i0])) * j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*float32)(unsafe.Pointer(&f.num[d])) = *(*float32)(unsafe.Pointer(&f.num[i0])) * *(*float32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Float64:
+		if i1 < 0 {
+			j := float64(vFloat(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*float64)(unsafe.Pointer(&f.num[d])) = *(*float64)(unsafe.Pointer(&f.num[i0])) * j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*float64)(unsafe.Pointer(&f.num[d])) = *(*float64)(unsafe.Pointer(&f.num[i0])) * *(*float64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	}
+	return false
+}
+
+func mulAssignUnboxed(n *node, next bltn) bool {
+	d, _, i1, t, ok := unboxedOperands(n, n.child[0], n.child[0], n.child[1])
+	if !ok {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int:
+		if i1 < 0 {
+			j := int(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int)(unsafe.Pointer(&f.num[d])) *= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int)(unsafe.Pointer(&f.num[d])) *= *(*int)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int32:
+		if i1 < 0 {
+			j := int32(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int32)(unsafe.Pointer(&f.num[d])) *= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int32)(unsafe.Pointer(&f.num[d])) *= *(*int32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int64:
+		if i1 < 0 {
+			j := int64(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int64)(unsafe.Pointer(&f.num[d])) *= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int64)(unsafe.Pointer(&f.num[d])) *= *(*int64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint:
+		if i1 < 0 {
+			j := uint(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint)(unsafe.Pointer(&f.num[d])) *= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint)(unsafe.Pointer(&f.num[d])) *= *(*uint)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint8:
+		if i1 < 0 {
+			j := uint8(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint8)(unsafe.Pointer(&f.num[d])) *= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint8)(unsafe.Pointer(&f.num[d])) *= *(*uint8)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint32:
+		if i1 < 0 {
+			j := uint32(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint32)(unsafe.Pointer(&f.num[d])) *= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint32)(unsafe.Pointer(&f.num[d])) *= *(*uint32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint64:
+		if i1 < 0 {
+			j := uint64(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint64)(unsafe.Pointer(&f.num[d])) *= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint64)(unsafe.Pointer(&f.num[d])) *= *(*uint64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Float32:
+		if i1 < 0 {
+			j := float32(vFloat(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*float32)(unsafe.Pointer(&f.num[d])) *= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*float32)(unsafe.Pointer(&f.num[d])) *= *(*float32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Float64:
+		if i1 < 0 {
+			j := float64(vFloat(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*float64)(unsafe.Pointer(&f.num[d])) *= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*float64)(unsafe.Pointer(&f.num[d])) *= *(*float64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	}
+	return false
+}
+
+func orUnboxed(n *node, next bltn) bool {
+	d, i0, i1, t, ok := unboxedOperands(n, n, n.child[0], n.child[1])
+	if !ok || t != n.typ.TypeOf() {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int:
+		if i1 < 0 {
+			j := int(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int)(unsafe.Pointer(&f.num[d])) = *(*int)(unsafe.Pointer(&f.num[i0])) | j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int)(unsafe.Pointer(&f.num[d])) = *(*int)(unsafe.Pointer(&f.num[i0])) | *(*int)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int32:
+		if i1 < 0 {
+			j := int32(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int32)(unsafe.Pointer(&f.num[d])) = *(*int32)(unsafe.Pointer(&f.num[i0])) | j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int32)(unsafe.Pointer(&f.num[d])) = *(*int32)(unsafe.Pointer(&f.num[i0])) | *(*int32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int64:
+		if i1 < 0 {
+			j := int64(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int64)(unsafe.Pointer(&f.num[d])) = *(*int64)(unsafe.Pointer(&f.num[i0])) | j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int64)(unsafe.Pointer(&f.num[d])) = *(*int64)(unsafe.Pointer(&f.num[i0])) | *(*int64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint:
+		if i1 < 0 {
+			j := uint(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint)(unsafe.Pointer(&f.num[d])) = *(*uint)(unsafe.Pointer(&f.num[i0])) | j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint)(unsafe.Pointer(&f.num[d])) = *(*uint)(unsafe.Pointer(&f.num[i0])) | *(*uint)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint8:
+		if i1 < 0 {
+			j := uint8(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint8)(unsafe.Pointer(&f.num[d])) = *(*uint8)(unsafe.Pointer(&f.num[i0])) | j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint8)(unsafe.Pointer(&f.num[d])) = *(*uint8)(unsafe.Pointer(&f.num[i0])) | *(*uint8)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint32:
+		if i1 < 0 {
+			j := uint32(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint32)(unsafe.Pointer(&f.num[d])) = *(*uint32)(unsafe.Pointer(&f.num[i0])) | j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint32)(unsafe.Pointer(&f.num[d])) = *(*uint32)(unsafe.Pointer(&f.num[i0])) | *(*uint32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint64:
+		if i1 < 0 {
+			j := uint64(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint64)(unsafe.Pointer(&f.num[d])) = *(*uint64)(unsafe.Pointer(&f.num[i0])) | j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint64)(unsafe.Pointer(&f.num[d])) = *(*uint64)(unsafe.Pointer(&f.num[i0])) | *(*uint64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	}
+	return false
+}
+
+func orAssignUnboxed(n *node, next bltn) bool {
+	d, _, i1, t, ok := unboxedOperands(n, n.child[0], n.child[0], n.child[1])
+	if !ok {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int:
+		if i1 < 0 {
+			j := int(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int)(unsafe.Pointer(&f.num[d])) |= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int)(unsafe.Pointer(&f.num[d])) |= *(*int)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int32:
+		if i1 < 0 {
+			j := int32(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int32)(unsafe.Pointer(&f.num[d])) |= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int32)(unsafe.Pointer(&f.num[d])) |= *(*int32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int64:
+		if i1 < 0 {
+			j := int64(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int64)(unsafe.Pointer(&f.num[d])) |= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int64)(unsafe.Pointer(&f.num[d])) |= *(*int64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint:
+		if i1 < 0 {
+			j := uint(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint)(unsafe.Pointer(&f.num[d])) |= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint)(unsafe.Pointer(&f.num[d])) |= *(*uint)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint8:
+		if i1 < 0 {
+			j := uint8(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint8)(unsafe.Pointer(&f.num[d])) |= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint8)(unsafe.Pointer(&f.num[d])) |= *(*uint8)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint32:
+		if i1 < 0 {
+			j := uint32(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint32)(unsafe.Pointer(&f.num[d])) |= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint32)(unsafe.Pointer(&f.num[d])) |= *(*uint32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint64:
+		if i1 < 0 {
+			j := uint64(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint64)(unsafe.Pointer(&f.num[d])) |= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint64)(unsafe.Pointer(&f.num[d])) |= *(*uint64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	}
+	return false
+}
+
+func quoUnboxed(n *node, next bltn) bool {
+	d, i0, i1, t, ok := unboxedOperands(n, n, n.child[0], n.child[1])
+	if !ok || t != n.typ.TypeOf() {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int:
+		if i1 < 0 {
+			j := int(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int)(unsafe.Pointer(&f.num[d])) = *(*int)(unsafe.Pointer(&f.num[i0])) / j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int)(unsafe.Pointer(&f.num[d])) = *(*int)(unsafe.Pointer(&f.num[i0])) / *(*int)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int32:
+		if i1 < 0 {
+			j := int32(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int32)(unsafe.Pointer(&f.num[d])) = *(*int32)(unsafe.Pointer(&f.num[i0])) / j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int32)(unsafe.Pointer(&f.num[d])) = *(*int32)(unsafe.Pointer(&f.num[i0])) / *(*int32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int64:
+		if i1 < 0 {
+			j := int64(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int64)(unsafe.Pointer(&f.num[d])) = *(*int64)(unsafe.Pointer(&f.num[i0])) / j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int64)(unsafe.Pointer(&f.num[d])) = *(*int64)(unsafe.Pointer(&f.num[i0])) / *(*int64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint:
+		if i1 < 0 {
+			j := uint(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint)(unsafe.Pointer(&f.num[d])) = *(*uint)(unsafe.Pointer(&f.num[i0])) / j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint)(unsafe.Pointer(&f.num[d])) = *(*uint)(unsafe.Pointer(&f.num[i0])) / *(*uint)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint8:
+		if i1 < 0 {
+			j := uint8(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint8)(unsafe.Pointer(&f.num[d])) = *(*uint8)(unsafe.Pointer(&f.num[i0])) / j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint8)(unsafe.Pointer(&f.num[d])) = *(*uint8)(unsafe.Pointer(&f.num[i0])) / *(*uint8)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint32:
+		if i1 < 0 {
+			j := uint32(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint32)(unsafe.Pointer(&f.num[d])) = *(*uint32)(unsafe.Pointer(&f.num[i0])) / j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint32)(unsafe.Pointer(&f.num[d])) = *(*uint32)(unsafe.Pointer(&f.num[i0])) / *(*uint32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint64:
+		if i1 < 0 {
+			j := uint64(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint64)(unsafe.Pointer(&f.num[d])) = *(*uint64)(unsafe.Pointer(&f.num[i0])) / j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint64)(unsafe.Pointer(&f.num[d])) = *(*uint64)(unsafe.Pointer(&f.num[i0])) / *(*uint64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Float32:
+		if i1 < 0 {
+			j := float32(vFloat(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*float32)(unsafe.Pointer(&f.num[d])) = *(*float32)(unsafe.Pointer(&f.num[i0])) / j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*float32)(unsafe.Pointer(&f.num[d])) = *(*float32)(unsafe.Pointer(&f.num[i0])) / *(*float32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Float64:
+		if i1 < 0 {
+			j := float64(vFloat(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*float64)(unsafe.Pointer(&f.num[d])) = *(*float64)(unsafe.Pointer(&f.num[i0])) / j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*float64)(unsafe.Pointer(&f.num[d])) = *(*float64)(unsafe.Pointer(&f.num[i0])) / *(*float64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	}
+	return false
+}
+
+func quoAssignUnboxed(n *node, next bltn) bool {
+	d, _, i1, t, ok := unboxedOperands(n, n.child[0], n.child[0], n.child[1])
+	if !ok {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int:
+		if i1 < 0 {
+			j := int(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int)(unsafe.Pointer(&f.num[d])) /= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int)(unsafe.Pointer(&f.num[d])) /= *(*int)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int32:
+		if i1 < 0 {
+			j := int32(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int32)(unsafe.Pointer(&f.num[d])) /= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int32)(unsafe.Pointer(&f.num[d])) /= *(*int32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int64:
+		if i1 < 0 {
+			j := int64(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int64)(unsafe.Pointer(&f.num[d])) /= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int64)(unsafe.Pointer(&f.num[d])) /= *(*int64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint:
+		if i1 < 0 {
+			j := uint(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint)(unsafe.Pointer(&f.num[d])) /= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint)(unsafe.Pointer(&f.num[d])) /= *(*uint)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint8:
+		if i1 < 0 {
+			j := uint8(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint8)(unsafe.Pointer(&f.num[d])) /= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint8)(unsafe.Pointer(&f.num[d])) /= *(*uint8)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint32:
+		if i1 < 0 {
+			j := uint32(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint32)(unsafe.Pointer(&f.num[d])) /= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint32)(unsafe.Pointer(&f.num[d])) /= *(*uint32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint64:
+		if i1 < 0 {
+			j := uint64(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint64)(unsafe.Pointer(&f.num[d])) /= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint64)(unsafe.Pointer(&f.num[d])) /= *(*uint64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Float32:
+		if i1 < 0 {
+			j := float32(vFloat(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*float32)(unsafe.Pointer(&f.num[d])) /= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*float32)(unsafe.Pointer(&f.num[d])) /= *(*float32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Float64:
+		if i1 < 0 {
+			j := float64(vFloat(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*float64)(unsafe.Pointer(&f.num[d])) /= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*float64)(unsafe.Pointer(&f.num[d])) /= *(*float64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	}
+	return false
+}
+
+func remUnboxed(n *node, next bltn) bool {
+	d, i0, i1, t, ok := unboxedOperands(n, n, n.child[0], n.child[1])
+	if !ok || t != n.typ.TypeOf() {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int:
+		if i1 < 0 {
+			j := int(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int)(unsafe.Pointer(&f.num[d])) = *(*int)(unsafe.Pointer(&f.num[i0])) % j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int)(unsafe.Pointer(&f.num[d])) = *(*int)(unsafe.Pointer(&f.num[i0])) % *(*int)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int32:
+		if i1 < 0 {
+			j := int32(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int32)(unsafe.Pointer(&f.num[d])) = *(*int32)(unsafe.Pointer(&f.num[i0])) % j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int32)(unsafe.Pointer(&f.num[d])) = *(*int32)(unsafe.Pointer(&f.num[i0])) % *(*int32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int64:
+		if i1 < 0 {
+			j := int64(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int64)(unsafe.Pointer(&f.num[d])) = *(*int64)(unsafe.Pointer(&f.num[i0])) % j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int64)(unsafe.Pointer(&f.num[d])) = *(*int64)(unsafe.Pointer(&f.num[i0])) % *(*int64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint:
+		if i1 < 0 {
+			j := uint(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint)(unsafe.Pointer(&f.num[d])) = *(*uint)(unsafe.Pointer(&f.num[i0])) % j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint)(unsafe.Pointer(&f.num[d])) = *(*uint)(unsafe.Pointer(&f.num[i0])) % *(*uint)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint8:
+		if i1 < 0 {
+			j := uint8(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint8)(unsafe.Pointer(&f.num[d])) = *(*uint8)(unsafe.Pointer(&f.num[i0])) % j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint8)(unsafe.Pointer(&f.num[d])) = *(*uint8)(unsafe.Pointer(&f.num[i0])) % *(*uint8)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint32:
+		if i1 < 0 {
+			j := uint32(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint32)(unsafe.Pointer(&f.num[d])) = *(*uint32)(unsafe.Pointer(&f.num[i0])) % j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint32)(unsafe.Pointer(&f.num[d])) = *(*uint32)(unsafe.Pointer(&f.num[i0])) % *(*uint32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint64:
+		if i1 < 0 {
+			j := uint64(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint64)(unsafe.Pointer(&f.num[d])) = *(*uint64)(unsafe.Pointer(&f.num[i0])) % j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint64)(unsafe.Pointer(&f.num[d])) = *(*uint64)(unsafe.Pointer(&f.num[i0])) % *(*uint64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	}
+	return false
+}
+
+func remAssignUnboxed(n *node, next bltn) bool {
+	d, _, i1, t, ok := unboxedOperands(n, n.child[0], n.child[0], n.child[1])
+	if !ok {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int:
+		if i1 < 0 {
+			j := int(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int)(unsafe.Pointer(&f.num[d])) %= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int)(unsafe.Pointer(&f.num[d])) %= *(*int)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int32:
+		if i1 < 0 {
+			j := int32(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int32)(unsafe.Pointer(&f.num[d])) %= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int32)(unsafe.Pointer(&f.num[d])) %= *(*int32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int64:
+		if i1 < 0 {
+			j := int64(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int64)(unsafe.Pointer(&f.num[d])) %= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int64)(unsafe.Pointer(&f.num[d])) %= *(*int64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint:
+		if i1 < 0 {
+			j := uint(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint)(unsafe.Pointer(&f.num[d])) %= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint)(unsafe.Pointer(&f.num[d])) %= *(*uint)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint8:
+		if i1 < 0 {
+			j := uint8(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint8)(unsafe.Pointer(&f.num[d])) %= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint8)(unsafe.Pointer(&f.num[d])) %= *(*uint8)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint32:
+		if i1 < 0 {
+			j := uint32(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint32)(unsafe.Pointer(&f.num[d])) %= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint32)(unsafe.Pointer(&f.num[d])) %= *(*uint32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint64:
+		if i1 < 0 {
+			j := uint64(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint64)(unsafe.Pointer(&f.num[d])) %= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint64)(unsafe.Pointer(&f.num[d])) %= *(*uint64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	}
+	return false
+}
+
+func subUnboxed(n *node, next bltn) bool {
+	d, i0, i1, t, ok := unboxedOperands(n, n, n.child[0], n.child[1])
+	if !ok || t != n.typ.TypeOf() {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int:
+		if i1 < 0 {
+			j := int(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int)(unsafe.Pointer(&f.num[d])) = *(*int)(unsafe.Pointer(&f.num[i0])) - j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int)(unsafe.Pointer(&f.num[d])) = *(*int)(unsafe.Pointer(&f.num[i0])) - *(*int)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int32:
+		if i1 < 0 {
+			j := int32(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int32)(unsafe.Pointer(&f.num[d])) = *(*int32)(unsafe.Pointer(&f.num[i0])) - j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int32)(unsafe.Pointer(&f.num[d])) = *(*int32)(unsafe.Pointer(&f.num[i0])) - *(*int32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int64:
+		if i1 < 0 {
+			j := int64(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int64)(unsafe.Pointer(&f.num[d])) = *(*int64)(unsafe.Pointer(&f.num[i0])) - j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int64)(unsafe.Pointer(&f.num[d])) = *(*int64)(unsafe.Pointer(&f.num[i0])) - *(*int64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint:
+		if i1 < 0 {
+			j := uint(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint)(unsafe.Pointer(&f.num[d])) = *(*uint)(unsafe.Pointer(&f.num[i0])) - j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint)(unsafe.Pointer(&f.num[d])) = *(*uint)(unsafe.Pointer(&f.num[i0])) - *(*uint)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint8:
+		if i1 < 0 {
+			j := uint8(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint8)(unsafe.Pointer(&f.num[d])) = *(*uint8)(unsafe.Pointer(&f.num[i0])) - j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint8)(unsafe.Pointer(&f.num[d])) = *(*uint8)(unsafe.Pointer(&f.num[i0])) - *(*uint8)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint32:
+		if i1 < 0 {
+			j := uint32(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint32)(unsafe.Pointer(&f.num[d])) = *(*uint32)(unsafe.Pointer(&f.num[i0])) - j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint32)(unsafe.Pointer(&f.num[d])) = *(*uint32)(unsafe.Pointer(&f.num[i0])) - *(*uint32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint64:
+		if i1 < 0 {
+			j := uint64(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint64)(unsafe.Pointer(&f.num[d])) = *(*uint64)(unsafe.Pointer(&f.num[i0])) - j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint64)(unsafe.Pointer(&f.num[d])) = *(*uint64)(unsafe.Pointer(&f.num[i0])) - *(*uint64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Float32:
+		if i1 < 0 {
+			j := float32(vFloat(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*float32)(unsafe.Pointer(&f.num[d])) = *(*float32)(unsafe.Pointer(&f.num[i0])) - j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*float32)(unsafe.Pointer(&f.num[d])) = *(*float32)(unsafe.Pointer(&f.num[i0])) - *(*float32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Float64:
+		if i1 < 0 {
+			j := float64(vFloat(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*float64)(unsafe.Pointer(&f.num[d])) = *(*float64)(unsafe.Pointer(&f.num[i0])) - j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*float64)(unsafe.Pointer(&f.num[d])) = *(*float64)(unsafe.Pointer(&f.num[i0])) - *(*float64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	}
+	return false
+}
+
+func subAssignUnboxed(n *node, next bltn) bool {
+	d, _, i1, t, ok := unboxedOperands(n, n.child[0], n.child[0], n.child[1])
+	if !ok {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int:
+		if i1 < 0 {
+			j := int(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int)(unsafe.Pointer(&f.num[d])) -= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int)(unsafe.Pointer(&f.num[d])) -= *(*int)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int32:
+		if i1 < 0 {
+			j := int32(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int32)(unsafe.Pointer(&f.num[d])) -= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int32)(unsafe.Pointer(&f.num[d])) -= *(*int32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int64:
+		if i1 < 0 {
+			j := int64(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int64)(unsafe.Pointer(&f.num[d])) -= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int64)(unsafe.Pointer(&f.num[d])) -= *(*int64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint:
+		if i1 < 0 {
+			j := uint(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint)(unsafe.Pointer(&f.num[d])) -= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint)(unsafe.Pointer(&f.num[d])) -= *(*uint)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint8:
+		if i1 < 0 {
+			j := uint8(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint8)(unsafe.Pointer(&f.num[d])) -= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint8)(unsafe.Pointer(&f.num[d])) -= *(*uint8)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint32:
+		if i1 < 0 {
+			j := uint32(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint32)(unsafe.Pointer(&f.num[d])) -= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint32)(unsafe.Pointer(&f.num[d])) -= *(*uint32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint64:
+		if i1 < 0 {
+			j := uint64(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint64)(unsafe.Pointer(&f.num[d])) -= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint64)(unsafe.Pointer(&f.num[d])) -= *(*uint64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Float32:
+		if i1 < 0 {
+			j := float32(vFloat(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*float32)(unsafe.Pointer(&f.num[d])) -= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*float32)(unsafe.Pointer(&f.num[d])) -= *(*float32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Float64:
+		if i1 < 0 {
+			j := float64(vFloat(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*float64)(unsafe.Pointer(&f.num[d])) -= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*float64)(unsafe.Pointer(&f.num[d])) -= *(*float64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	}
+	return false
+}
+
+func xorUnboxed(n *node, next bltn) bool {
+	d, i0, i1, t, ok := unboxedOperands(n, n, n.child[0], n.child[1])
+	if !ok || t != n.typ.TypeOf() {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int:
+		if i1 < 0 {
+			j := int(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int)(unsafe.Pointer(&f.num[d])) = *(*int)(unsafe.Pointer(&f.num[i0])) ^ j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int)(unsafe.Pointer(&f.num[d])) = *(*int)(unsafe.Pointer(&f.num[i0])) ^ *(*int)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int32:
+		if i1 < 0 {
+			j := int32(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int32)(unsafe.Pointer(&f.num[d])) = *(*int32)(unsafe.Pointer(&f.num[i0])) ^ j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int32)(unsafe.Pointer(&f.num[d])) = *(*int32)(unsafe.Pointer(&f.num[i0])) ^ *(*int32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int64:
+		if i1 < 0 {
+			j := int64(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int64)(unsafe.Pointer(&f.num[d])) = *(*int64)(unsafe.Pointer(&f.num[i0])) ^ j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int64)(unsafe.Pointer(&f.num[d])) = *(*int64)(unsafe.Pointer(&f.num[i0])) ^ *(*int64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint:
+		if i1 < 0 {
+			j := uint(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint)(unsafe.Pointer(&f.num[d])) = *(*uint)(unsafe.Pointer(&f.num[i0])) ^ j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint)(unsafe.Pointer(&f.num[d])) = *(*uint)(unsafe.Pointer(&f.num[i0])) ^ *(*uint)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint8:
+		if i1 < 0 {
+			j := uint8(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint8)(unsafe.Pointer(&f.num[d])) = *(*uint8)(unsafe.Pointer(&f.num[i0])) ^ j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint8)(unsafe.Pointer(&f.num[d])) = *(*uint8)(unsafe.Pointer(&f.num[i0])) ^ *(*uint8)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint32:
+		if i1 < 0 {
+			j := uint32(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint32)(unsafe.Pointer(&f.num[d])) = *(*uint32)(unsafe.Pointer(&f.num[i0])) ^ j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint32)(unsafe.Pointer(&f.num[d])) = *(*uint32)(unsafe.Pointer(&f.num[i0])) ^ *(*uint32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint64:
+		if i1 < 0 {
+			j := uint64(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint64)(unsafe.Pointer(&f.num[d])) = *(*uint64)(unsafe.Pointer(&f.num[i0])) ^ j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint64)(unsafe.Pointer(&f.num[d])) = *(*uint64)(unsafe.Pointer(&f.num[i0])) ^ *(*uint64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	}
+	return false
+}
+
+func xorAssignUnboxed(n *node, next bltn) bool {
+	d, _, i1, t, ok := unboxedOperands(n, n.child[0], n.child[0], n.child[1])
+	if !ok {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int:
+		if i1 < 0 {
+			j := int(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int)(unsafe.Pointer(&f.num[d])) ^= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int)(unsafe.Pointer(&f.num[d])) ^= *(*int)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int32:
+		if i1 < 0 {
+			j := int32(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int32)(unsafe.Pointer(&f.num[d])) ^= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int32)(unsafe.Pointer(&f.num[d])) ^= *(*int32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Int64:
+		if i1 < 0 {
+			j := int64(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*int64)(unsafe.Pointer(&f.num[d])) ^= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*int64)(unsafe.Pointer(&f.num[d])) ^= *(*int64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint:
+		if i1 < 0 {
+			j := uint(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint)(unsafe.Pointer(&f.num[d])) ^= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint)(unsafe.Pointer(&f.num[d])) ^= *(*uint)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint8:
+		if i1 < 0 {
+			j := uint8(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint8)(unsafe.Pointer(&f.num[d])) ^= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint8)(unsafe.Pointer(&f.num[d])) ^= *(*uint8)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint32:
+		if i1 < 0 {
+			j := uint32(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint32)(unsafe.Pointer(&f.num[d])) ^= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint32)(unsafe.Pointer(&f.num[d])) ^= *(*uint32)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	case reflect.Uint64:
+		if i1 < 0 {
+			j := uint64(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				*(*uint64)(unsafe.Pointer(&f.num[d])) ^= j
+				return next
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			*(*uint64)(unsafe.Pointer(&f.num[d])) ^= *(*uint64)(unsafe.Pointer(&f.num[i1]))
+			return next
+		}
+		return true
+	}
+	return false
+}
+
+func decUnboxed(n *node, next bltn) bool {
+	d, t, ok := unboxedIndex(n.child[0])
+	if !ok {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int:
+		n.exec = func(f *frame) bltn {
+			*(*int)(unsafe.Pointer(&f.num[d]))--
+			return next
+		}
+		return true
+	case reflect.Int32:
+		n.exec = func(f *frame) bltn {
+			*(*int32)(unsafe.Pointer(&f.num[d]))--
+			return next
+		}
+		return true
+	case reflect.Int64:
+		n.exec = func(f *frame) bltn {
+			*(*int64)(unsafe.Pointer(&f.num[d]))--
+			return next
+		}
+		return true
+	case reflect.Uint:
+		n.exec = func(f *frame) bltn {
+			*(*uint)(unsafe.Pointer(&f.num[d]))--
+			return next
+		}
+		return true
+	case reflect.Uint8:
+		n.exec = func(f *frame) bltn {
+			*(*uint8)(unsafe.Pointer(&f.num[d]))--
+			return next
+		}
+		return true
+	case reflect.Uint32:
+		n.exec = func(f *frame) bltn {
+			*(*uint32)(unsafe.Pointer(&f.num[d]))--
+			return next
+		}
+		return true
+	case reflect.Uint64:
+		n.exec = func(f *frame) bltn {
+			*(*uint64)(unsafe.Pointer(&f.num[d]))--
+			return next
+		}
+		return true
+	case reflect.Float32:
+		n.exec = func(f *frame) bltn {
+			*(*float32)(unsafe.Pointer(&f.num[d]))--
+			return next
+		}
+		return true
+	case reflect.Float64:
+		n.exec = func(f *frame) bltn {
+			*(*float64)(unsafe.Pointer(&f.num[d]))--
+			return next
+		}
+		return true
+	}
+	return false
+}
+
+func incUnboxed(n *node, next bltn) bool {
+	d, t, ok := unboxedIndex(n.child[0])
+	if !ok {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int:
+		n.exec = func(f *frame) bltn {
+			*(*int)(unsafe.Pointer(&f.num[d]))++
+			return next
+		}
+		return true
+	case reflect.Int32:
+		n.exec = func(f *frame) bltn {
+			*(*int32)(unsafe.Pointer(&f.num[d]))++
+			return next
+		}
+		return true
+	case reflect.Int64:
+		n.exec = func(f *frame) bltn {
+			*(*int64)(unsafe.Pointer(&f.num[d]))++
+			return next
+		}
+		return true
+	case reflect.Uint:
+		n.exec = func(f *frame) bltn {
+			*(*uint)(unsafe.Pointer(&f.num[d]))++
+			return next
+		}
+		return true
+	case reflect.Uint8:
+		n.exec = func(f *frame) bltn {
+			*(*uint8)(unsafe.Pointer(&f.num[d]))++
+			return next
+		}
+		return true
+	case reflect.Uint32:
+		n.exec = func(f *frame) bltn {
+			*(*uint32)(unsafe.Pointer(&f.num[d]))++
+			return next
+		}
+		return true
+	case reflect.Uint64:
+		n.exec = func(f *frame) bltn {
+			*(*uint64)(unsafe.Pointer(&f.num[d]))++
+			return next
+		}
+		return true
+	case reflect.Float32:
+		n.exec = func(f *frame) bltn {
+			*(*float32)(unsafe.Pointer(&f.num[d]))++
+			return next
+		}
+		return true
+	case reflect.Float64:
+		n.exec = func(f *frame) bltn {
+			*(*float64)(unsafe.Pointer(&f.num[d]))++
+			return next
+		}
+		return true
+	}
+	return false
+}
+
+func equalUnboxed(n *node, tnext bltn) bool {
+	d, i0, i1, t, ok := unboxedOperands(n, n, n.child[0], n.child[1])
+	if !ok {
+		return false
+	}
+	var fnext bltn
+	if n.fnext != nil {
+		fnext = getExec(n.fnext)
+	}
+	switch t.Kind() {
+	case reflect.Int:
+		if i1 < 0 {
+			j := int(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*int)(unsafe.Pointer(&f.num[i0])) == j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*int)(unsafe.Pointer(&f.num[i0])) == *(*int)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Int32:
+		if i1 < 0 {
+			j := int32(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*int32)(unsafe.Pointer(&f.num[i0])) == j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*int32)(unsafe.Pointer(&f.num[i0])) == *(*int32)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Int64:
+		if i1 < 0 {
+			j := int64(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*int64)(unsafe.Pointer(&f.num[i0])) == j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*int64)(unsafe.Pointer(&f.num[i0])) == *(*int64)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Uint:
+		if i1 < 0 {
+			j := uint(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*uint)(unsafe.Pointer(&f.num[i0])) == j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*uint)(unsafe.Pointer(&f.num[i0])) == *(*uint)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Uint8:
+		if i1 < 0 {
+			j := uint8(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*uint8)(unsafe.Pointer(&f.num[i0])) == j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*uint8)(unsafe.Pointer(&f.num[i0])) == *(*uint8)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Uint32:
+		if i1 < 0 {
+			j := uint32(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*uint32)(unsafe.Pointer(&f.num[i0])) == j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*uint32)(unsafe.Pointer(&f.num[i0])) == *(*uint32)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Uint64:
+		if i1 < 0 {
+			j := uint64(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*uint64)(unsafe.Pointer(&f.num[i0])) == j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*uint64)(unsafe.Pointer(&f.num[i0])) == *(*uint64)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Float32:
+		if i1 < 0 {
+			j := float32(vFloat(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*float32)(unsafe.Pointer(&f.num[i0])) == j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*float32)(unsafe.Pointer(&f.num[i0])) == *(*float32)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Float64:
+		if i1 < 0 {
+			j := float64(vFloat(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*float64)(unsafe.Pointer(&f.num[i0])) == j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*float64)(unsafe.Pointer(&f.num[i0])) == *(*float64)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	}
+	return false
+}
+
+func greaterUnboxed(n *node, tnext bltn) bool {
+	d, i0, i1, t, ok := unboxedOperands(n, n, n.child[0], n.child[1])
+	if !ok {
+		return false
+	}
+	var fnext bltn
+	if n.fnext != nil {
+		fnext = getExec(n.fnext)
+	}
+	switch t.Kind() {
+	case reflect.Int:
+		if i1 < 0 {
+			j := int(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*int)(unsafe.Pointer(&f.num[i0])) > j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*int)(unsafe.Pointer(&f.num[i0])) > *(*int)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Int32:
+		if i1 < 0 {
+			j := int32(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*int32)(unsafe.Pointer(&f.num[i0])) > j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*int32)(unsafe.Pointer(&f.num[i0])) > *(*int32)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Int64:
+		if i1 < 0 {
+			j := int64(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*int64)(unsafe.Pointer(&f.num[i0])) > j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*int64)(unsafe.Pointer(&f.num[i0])) > *(*int64)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Uint:
+		if i1 < 0 {
+			j := uint(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*uint)(unsafe.Pointer(&f.num[i0])) > j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*uint)(unsafe.Pointer(&f.num[i0])) > *(*uint)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Uint8:
+		if i1 < 0 {
+			j := uint8(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*uint8)(unsafe.Pointer(&f.num[i0])) > j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*uint8)(unsafe.Pointer(&f.num[i0])) > *(*uint8)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Uint32:
+		if i1 < 0 {
+			j := uint32(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*uint32)(unsafe.Pointer(&f.num[i0])) > j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*uint32)(unsafe.Pointer(&f.num[i0])) > *(*uint32)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Uint64:
+		if i1 < 0 {
+			j := uint64(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*uint64)(unsafe.Pointer(&f.num[i0])) > j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*uint64)(unsafe.Pointer(&f.num[i0])) > *(*uint64)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Float32:
+		if i1 < 0 {
+			j := float32(vFloat(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*float32)(unsafe.Pointer(&f.num[i0])) > j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*float32)(unsafe.Pointer(&f.num[i0])) > *(*float32)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Float64:
+		if i1 < 0 {
+			j := float64(vFloat(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*float64)(unsafe.Pointer(&f.num[i0])) > j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*float64)(unsafe.Pointer(&f.num[i0])) > *(*float64)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	}
+	return false
+}
+
+func greaterEqualUnboxed(n *node, tnext bltn) bool {
+	d, i0, i1, t, ok := unboxedOperands(n, n, n.child[0], n.child[1])
+	if !ok {
+		return false
+	}
+	var fnext bltn
+	if n.fnext != nil {
+		fnext = getExec(n.fnext)
+	}
+	switch t.Kind() {
+	case reflect.Int:
+		if i1 < 0 {
+			j := int(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*int)(unsafe.Pointer(&f.num[i0])) >= j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*int)(unsafe.Pointer(&f.num[i0])) >= *(*int)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Int32:
+		if i1 < 0 {
+			j := int32(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*int32)(unsafe.Pointer(&f.num[i0])) >= j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*int32)(unsafe.Pointer(&f.num[i0])) >= *(*int32)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Int64:
+		if i1 < 0 {
+			j := int64(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*int64)(unsafe.Pointer(&f.num[i0])) >= j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*int64)(unsafe.Pointer(&f.num[i0])) >= *(*int64)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Uint:
+		if i1 < 0 {
+			j := uint(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*uint)(unsafe.Pointer(&f.num[i0])) >= j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*uint)(unsafe.Pointer(&f.num[i0])) >= *(*uint)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Uint8:
+		if i1 < 0 {
+			j := uint8(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*uint8)(unsafe.Pointer(&f.num[i0])) >= j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*uint8)(unsafe.Pointer(&f.num[i0])) >= *(*uint8)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Uint32:
+		if i1 < 0 {
+			j := uint32(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*uint32)(unsafe.Pointer(&f.num[i0])) >= j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*uint32)(unsafe.Pointer(&f.num[i0])) >= *(*uint32)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Uint64:
+		if i1 < 0 {
+			j := uint64(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*uint64)(unsafe.Pointer(&f.num[i0])) >= j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*uint64)(unsafe.Pointer(&f.num[i0])) >= *(*uint64)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Float32:
+		if i1 < 0 {
+			j := float32(vFloat(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*float32)(unsafe.Pointer(&f.num[i0])) >= j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*float32)(unsafe.Pointer(&f.num[i0])) >= *(*float32)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Float64:
+		if i1 < 0 {
+			j := float64(vFloat(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*float64)(unsafe.Pointer(&f.num[i0])) >= j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*float64)(unsafe.Pointer(&f.num[i0])) >= *(*float64)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	}
+	return false
+}
+
+func lowerUnboxed(n *node, tnext bltn) bool {
+	d, i0, i1, t, ok := unboxedOperands(n, n, n.child[0], n.child[1])
+	if !ok {
+		return false
+	}
+	var fnext bltn
+	if n.fnext != nil {
+		fnext = getExec(n.fnext)
+	}
+	switch t.Kind() {
+	case reflect.Int:
+		if i1 < 0 {
+			j := int(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*int)(unsafe.Pointer(&f.num[i0])) < j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*int)(unsafe.Pointer(&f.num[i0])) < *(*int)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Int32:
+		if i1 < 0 {
+			j := int32(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*int32)(unsafe.Pointer(&f.num[i0])) < j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*int32)(unsafe.Pointer(&f.num[i0])) < *(*int32)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Int64:
+		if i1 < 0 {
+			j := int64(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*int64)(unsafe.Pointer(&f.num[i0])) < j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*int64)(unsafe.Pointer(&f.num[i0])) < *(*int64)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Uint:
+		if i1 < 0 {
+			j := uint(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*uint)(unsafe.Pointer(&f.num[i0])) < j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*uint)(unsafe.Pointer(&f.num[i0])) < *(*uint)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Uint8:
+		if i1 < 0 {
+			j := uint8(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*uint8)(unsafe.Pointer(&f.num[i0])) < j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*uint8)(unsafe.Pointer(&f.num[i0])) < *(*uint8)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Uint32:
+		if i1 < 0 {
+			j := uint32(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*uint32)(unsafe.Pointer(&f.num[i0])) < j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*uint32)(unsafe.Pointer(&f.num[i0])) < *(*uint32)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Uint64:
+		if i1 < 0 {
+			j := uint64(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*uint64)(unsafe.Pointer(&f.num[i0])) < j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*uint64)(unsafe.Pointer(&f.num[i0])) < *(*uint64)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Float32:
+		if i1 < 0 {
+			j := float32(vFloat(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*float32)(unsafe.Pointer(&f.num[i0])) < j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*float32)(unsafe.Pointer(&f.num[i0])) < *(*float32)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Float64:
+		if i1 < 0 {
+			j := float64(vFloat(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*float64)(unsafe.Pointer(&f.num[i0])) < j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*float64)(unsafe.Pointer(&f.num[i0])) < *(*float64)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	}
+	return false
+}
+
+func lowerEqualUnboxed(n *node, tnext bltn) bool {
+	d, i0, i1, t, ok := unboxedOperands(n, n, n.child[0], n.child[1])
+	if !ok {
+		return false
+	}
+	var fnext bltn
+	if n.fnext != nil {
+		fnext = getExec(n.fnext)
+	}
+	switch t.Kind() {
+	case reflect.Int:
+		if i1 < 0 {
+			j := int(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*int)(unsafe.Pointer(&f.num[i0])) <= j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*int)(unsafe.Pointer(&f.num[i0])) <= *(*int)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Int32:
+		if i1 < 0 {
+			j := int32(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*int32)(unsafe.Pointer(&f.num[i0])) <= j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*int32)(unsafe.Pointer(&f.num[i0])) <= *(*int32)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Int64:
+		if i1 < 0 {
+			j := int64(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*int64)(unsafe.Pointer(&f.num[i0])) <= j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*int64)(unsafe.Pointer(&f.num[i0])) <= *(*int64)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Uint:
+		if i1 < 0 {
+			j := uint(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*uint)(unsafe.Pointer(&f.num[i0])) <= j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*uint)(unsafe.Pointer(&f.num[i0])) <= *(*uint)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Uint8:
+		if i1 < 0 {
+			j := uint8(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*uint8)(unsafe.Pointer(&f.num[i0])) <= j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*uint8)(unsafe.Pointer(&f.num[i0])) <= *(*uint8)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Uint32:
+		if i1 < 0 {
+			j := uint32(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*uint32)(unsafe.Pointer(&f.num[i0])) <= j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*uint32)(unsafe.Pointer(&f.num[i0])) <= *(*uint32)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Uint64:
+		if i1 < 0 {
+			j := uint64(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*uint64)(unsafe.Pointer(&f.num[i0])) <= j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*uint64)(unsafe.Pointer(&f.num[i0])) <= *(*uint64)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Float32:
+		if i1 < 0 {
+			j := float32(vFloat(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*float32)(unsafe.Pointer(&f.num[i0])) <= j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*float32)(unsafe.Pointer(&f.num[i0])) <= *(*float32)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Float64:
+		if i1 < 0 {
+			j := float64(vFloat(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*float64)(unsafe.Pointer(&f.num[i0])) <= j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*float64)(unsafe.Pointer(&f.num[i0])) <= *(*float64)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	}
+	return false
+}
+
+func notEqualUnboxed(n *node, tnext bltn) bool {
+	d, i0, i1, t, ok := unboxedOperands(n, n, n.child[0], n.child[1])
+	if !ok {
+		return false
+	}
+	var fnext bltn
+	if n.fnext != nil {
+		fnext = getExec(n.fnext)
+	}
+	switch t.Kind() {
+	case reflect.Int:
+		if i1 < 0 {
+			j := int(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*int)(unsafe.Pointer(&f.num[i0])) != j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*int)(unsafe.Pointer(&f.num[i0])) != *(*int)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Int32:
+		if i1 < 0 {
+			j := int32(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*int32)(unsafe.Pointer(&f.num[i0])) != j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*int32)(unsafe.Pointer(&f.num[i0])) != *(*int32)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Int64:
+		if i1 < 0 {
+			j := int64(vInt(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*int64)(unsafe.Pointer(&f.num[i0])) != j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*int64)(unsafe.Pointer(&f.num[i0])) != *(*int64)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Uint:
+		if i1 < 0 {
+			j := uint(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*uint)(unsafe.Pointer(&f.num[i0])) != j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*uint)(unsafe.Pointer(&f.num[i0])) != *(*uint)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Uint8:
+		if i1 < 0 {
+			j := uint8(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*uint8)(unsafe.Pointer(&f.num[i0])) != j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*uint8)(unsafe.Pointer(&f.num[i0])) != *(*uint8)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Uint32:
+		if i1 < 0 {
+			j := uint32(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*uint32)(unsafe.Pointer(&f.num[i0])) != j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*uint32)(unsafe.Pointer(&f.num[i0])) != *(*uint32)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Uint64:
+		if i1 < 0 {
+			j := uint64(vUint(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*uint64)(unsafe.Pointer(&f.num[i0])) != j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*uint64)(unsafe.Pointer(&f.num[i0])) != *(*uint64)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Float32:
+		if i1 < 0 {
+			j := float32(vFloat(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*float32)(unsafe.Pointer(&f.num[i0])) != j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*float32)(unsafe.Pointer(&f.num[i0])) != *(*float32)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	case reflect.Float64:
+		if i1 < 0 {
+			j := float64(vFloat(n.child[1].rval))
+			n.exec = func(f *frame) bltn {
+				r := *(*float64)(unsafe.Pointer(&f.num[i0])) != j
+				*(*bool)(unsafe.Pointer(&f.num[d])) = r
+				if r || fnext == nil {
+					return tnext
+				}
+				return fnext
+			}
+			return true
+		}
+		n.exec = func(f *frame) bltn {
+			r := *(*float64)(unsafe.Pointer(&f.num[i0])) != *(*float64)(unsafe.Pointer(&f.num[i1]))
+			*(*bool)(unsafe.Pointer(&f.num[d])) = r
+			if r || fnext == nil {
+				return tnext
+			}
+			return fnext
+		}
+		return true
+	}
+	return false
+}
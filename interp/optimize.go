@@ -0,0 +1,419 @@
+package interp
+
+import (
+	"go/constant"
+	"reflect"
+	"sync/atomic"
+)
+
+// In addition to the folding of constant expressions, the CFG builder
+// performs the following optimisations:
+//
+//   - calls to small interpreted functions, whose body is a single return of
+//     an expression of their parameters, are replaced by the expression
+//     itself, avoiding the frame creation in call;
+//   - local variables of basic types assigned only once from a constant are
+//     turned into constants, so their uses are folded in turn;
+//   - logical expressions short-circuited by a constant operand are folded,
+//     so if statements with such a condition skip the dead branch.
+//
+// They can be disabled by setting the YAEGI_NO_OPT environment variable,
+// for debugging purpose.
+
+// maxInlineSize is the maximum number of nodes of an inlined expression.
+const maxInlineSize = 16
+
+// inlineBody is the body of a function which can be inlined at call sites.
+type inlineBody struct {
+	pkgID   string   // package of the function
+	recv    string   // receiver name, empty if not a method, "_" if unnamed
+	recvSel bool     // receiver is only used as the operand of selectors
+	params  []string // parameter names
+	expr    *node    // unprocessed copy of the returned expression
+}
+
+// newInlineBody returns the inlinable body of function declaration def, or nil.
+// It must be called prior to the CFG processing of the function.
+func newInlineBody(def *node, pkgID string) *inlineBody {
+	if len(def.child) < 4 || def.child[3] == nil || len(def.child[3].child) != 1 {
+		return nil
+	}
+	ret := def.child[3].child[0]
+	if ret.kind != returnStmt || len(ret.child) != 1 {
+		return nil
+	}
+	ftyp := def.child[2]
+	if len(ftyp.child) != 3 || len(ftyp.child[0].child) > 0 || numFields(ftyp.child[2]) != 1 {
+		// Generic function, or not a single result.
+		return nil
+	}
+
+	b := &inlineBody{pkgID: pkgID}
+	if recv := def.child[0].child; len(recv) == 1 {
+		t := recv[0].lastChild()
+		if t.kind == starExpr {
+			t = t.child[0]
+		}
+		if t.kind != identExpr {
+			// Method of a generic type.
+			return nil
+		}
+		b.recv = "_"
+		if len(recv[0].child) == 2 {
+			b.recv = recv[0].child[0].ident
+		}
+	}
+	for _, f := range ftyp.child[1].child {
+		if len(f.child) < 2 || f.lastChild().kind == ellipsisExpr {
+			return nil
+		}
+		for _, c := range f.child[:len(f.child)-1] {
+			b.params = append(b.params, c.ident)
+		}
+	}
+
+	names := map[string]bool{b.recv: true}
+	for _, p := range b.params {
+		names[p] = true
+	}
+	size, ok := 0, true
+	b.recvSel = true
+	ret.child[0].Walk(func(n *node) bool {
+		if size++; size > maxInlineSize {
+			ok = false
+		}
+		switch n.kind {
+		case basicLit, binaryExpr, landExpr, lorExpr, parenExpr, selectorExpr:
+		case identExpr:
+			if n.anc.kind == selectorExpr && n == n.anc.child[1] {
+				// Field name.
+				break
+			}
+			if n.ident == "_" || !names[n.ident] {
+				ok = false
+			}
+			if n.ident == b.recv && (n.anc.kind != selectorExpr || n != n.anc.child[0]) {
+				b.recvSel = false
+			}
+		case unaryExpr:
+			if n.action == aRecv {
+				ok = false
+			}
+		default:
+			ok = false
+		}
+		return ok
+	}, nil)
+	if !ok {
+		return nil
+	}
+	b.expr = copyNode(ret.child[0], nil, true)
+	return b
+}
+
+// numFields returns the number of fields declared in field list n.
+func numFields(n *node) (num int) {
+	for _, f := range n.child {
+		if len(f.child) < 2 {
+			num++
+		} else {
+			num += len(f.child) - 1
+		}
+	}
+	return num
+}
+
+// inlinableType returns true if values of type t can be passed to or
+// returned from an inlined function.
+func inlinableType(t *itype) bool {
+	return t != nil && !t.incomplete && !t.untyped && !isInterface(t) && !isFunc(t)
+}
+
+// inlineCall replaces call expression n by the body of the called function,
+// if possible. The replacement node is a parenExpr holding the function
+// arguments followed by the inlined expression, where the parameters are
+// bound to the frame locations of the arguments, or to their value if constant.
+func (interp *Interpreter) inlineCall(n *node, sc *scope, importPath, pkgName string) {
+	if interp.opt.noOpt || interp.debugger != nil || sc.def == nil || sc.global || n.action == aCallSlice {
+		return
+	}
+	switch n.anc.kind {
+	case defineXStmt, assignXStmt, goStmt, deferStmt:
+		return
+	}
+
+	var def, base *node
+	switch c0 := n.child[0]; {
+	case c0.kind == identExpr && c0.sym != nil && c0.sym.kind == funcSym:
+		def, _ = c0.val.(*node)
+	case c0.kind == selectorExpr && c0.action == aGetMethod && c0.recv != nil && len(c0.recv.index) == 0:
+		def, _ = c0.val.(*node)
+		base = c0.child[0]
+	}
+	if def == nil || def.kind != funcDecl || def.inline == nil || def.inline.pkgID != sc.pkgID {
+		return
+	}
+	body, args := def.inline, n.child[1:]
+	if (base == nil) != (body.recv == "") || len(args) != len(body.params) || len(def.typ.arg) != len(args) || len(def.typ.ret) != 1 {
+		return
+	}
+	rtyp := def.typ.ret[0]
+	if !inlinableType(rtyp) || n.typ == nil || n.typ.id() != rtyp.id() {
+		return
+	}
+
+	bsc := sc.pushBloc()
+	if base != nil {
+		t := def.child[0].child[0].lastChild().typ
+		if body.recvSel && t != nil && base.typ != nil && (t.cat == ptrT && t.val.id() == base.typ.id() || base.typ.cat == ptrT && base.typ.val.id() == t.id()) {
+			// Field selection applies to both the value and the pointer.
+			t = base.typ
+		}
+		if !bindInlineArg(bsc, body.recv, base, t) {
+			bsc.pop()
+			return
+		}
+		args = append([]*node{base}, args...)
+	}
+	for i, name := range body.params {
+		if !bindInlineArg(bsc, name, args[len(args)-len(body.params)+i], def.typ.arg[i]) {
+			bsc.pop()
+			return
+		}
+	}
+
+	w := &node{
+		anc:    n.anc,
+		interp: interp,
+		index:  atomic.AddInt64(&interp.nindex, 1),
+		kind:   parenExpr,
+		pos:    n.pos,
+		action: aNop,
+		gen:    nop,
+		scope:  sc,
+		typ:    rtyp,
+	}
+	w.start = w
+	expr := copyNode(body.expr, w, true)
+	w.child = append(append([]*node{}, args...), expr)
+
+	// The scope bsc is popped by cfg.
+	if _, err := interp.cfg(expr, bsc, importPath, pkgName); err != nil || expr.typ == nil || expr.typ.id() != rtyp.id() {
+		return
+	}
+	constArgs := true
+	for _, c := range args {
+		constArgs = constArgs && isConstNode(c)
+	}
+	if expr.rval.IsValid() && !constArgs {
+		// Preserve the evaluation of arguments.
+		return
+	}
+
+	for _, c := range args {
+		c.anc = w
+	}
+	n.anc.child[childPos(n)] = w
+	if expr.rval.IsValid() {
+		w.rval = expr.rval
+		w.findex = notInFrame
+		return
+	}
+	wireChild(w)
+	w.findex, w.level = expr.findex, expr.level
+}
+
+// bindInlineArg defines in scope sc the parameter name of type t of an
+// inlined function, bound to the argument node arg.
+func bindInlineArg(sc *scope, name string, arg *node, t *itype) bool {
+	switch {
+	case !inlinableType(t):
+		return false
+	case isConstNode(arg):
+		v, ok := constValue(arg.rval, t.TypeOf())
+		if !ok {
+			return false
+		}
+		if name != "_" {
+			sc.sym[name] = &symbol{index: sc.add(t), kind: constSym, typ: t, rval: v, folded: true}
+		}
+	case arg.findex < 0 || arg.level != 0 || arg.typ == nil || arg.typ.id() != t.id():
+		return false
+	case name != "_":
+		sc.sym[name] = &symbol{index: arg.findex, kind: varSym, typ: t}
+	}
+	return true
+}
+
+// assignCount holds the number of assignments of local variables by name,
+// per function.
+type assignCount map[*node]map[string]int
+
+// count returns the number of assignments or definitions of variables named
+// name in function def. Variables whose address is taken count as assigned.
+func (a assignCount) count(def *node, name string) int {
+	if m, ok := a[def]; ok {
+		return m[name]
+	}
+	m := map[string]int{}
+	def.Walk(func(n *node) bool {
+		switch n.kind {
+		case assignStmt, assignXStmt, defineStmt, defineXStmt:
+			for _, c := range n.child[:n.nleft] {
+				m[c.ident]++
+			}
+		case addressExpr, incDecStmt:
+			c := n.child[0]
+			for c.kind == parenExpr {
+				c = c.lastChild()
+			}
+			m[c.ident]++
+		case fieldExpr, valueSpec:
+			for _, c := range n.child {
+				m[c.ident]++
+			}
+		case rangeStmt:
+			for _, c := range n.child[:len(n.child)-2] {
+				m[c.ident]++
+			}
+		}
+		return true
+	}, nil)
+	a[def] = m
+	return m[name]
+}
+
+// propagateConst turns the local variable symbol sym, defined by the
+// assignment of src to dest in statement n, into a constant if its value
+// never changes.
+func (interp *Interpreter) propagateConst(sc *scope, assigned assignCount, n, dest, src *node, sym *symbol) {
+	if interp.opt.noOpt || sc.def == nil || sc.global || n.nright == 0 || sym.kind != varSym || !isConstNode(src) {
+		return
+	}
+	switch n.anc.kind {
+	case forStmt0, forStmt1, forStmt2, forStmt3, forStmt4, forStmt5, forStmt6, forStmt7:
+		// Loop variables are copied at each iteration.
+		return
+	}
+	t := dest.typ
+	if t == nil || t.path != "" || t.name == "" || t.untyped || !isConstType(t) {
+		// Only predeclared types.
+		return
+	}
+	if assigned.count(sc.def, dest.ident) != 1 {
+		return
+	}
+	if v, ok := constValue(src.rval, t.TypeOf()); ok {
+		sym.kind = constSym
+		sym.rval = v
+		sym.folded = true
+	}
+}
+
+// isFoldedShift returns true if n is a shift of an untyped constant by a
+// count depending on constants inferred by the optimizer. Such a shift is
+// not a constant expression, and the left operand must take the type of
+// the context instead of being shifted with arbitrary precision.
+func isFoldedShift(n *node) bool {
+	if !isShiftNode(n) || !n.child[0].typ.untyped {
+		return false
+	}
+	folded := false
+	n.child[1].Walk(func(c *node) bool {
+		folded = folded || c.sym != nil && c.sym.folded
+		return !folded
+	}, nil)
+	return folded
+}
+
+// isConstNode returns true if n is a constant expression. Other nodes with
+// a valid rval may hold the value of a variable.
+func isConstNode(n *node) bool {
+	switch n.kind {
+	case basicLit, binaryExpr, unaryExpr, parenExpr, landExpr, lorExpr:
+		return n.rval.IsValid()
+	}
+	return false
+}
+
+// constValue returns the constant value v converted to type t. Numeric zero
+// values are rejected, as folding them could turn a run time division by zero
+// into a compile error.
+func constValue(v reflect.Value, t reflect.Type) (reflect.Value, bool) {
+	if c, ok := v.Interface().(constant.Value); ok {
+		switch {
+		case t.Kind() == reflect.Bool && c.Kind() == constant.Bool:
+			v = reflect.ValueOf(constant.BoolVal(c))
+		case t.Kind() == reflect.String && c.Kind() == constant.String:
+			v = reflect.ValueOf(constant.StringVal(c))
+		case isUint(t):
+			u, exact := constant.Uint64Val(constant.ToInt(c))
+			if !exact {
+				return v, false
+			}
+			v = reflect.ValueOf(u)
+		case isInt(t):
+			i, exact := constant.Int64Val(constant.ToInt(c))
+			if !exact {
+				return v, false
+			}
+			v = reflect.ValueOf(i)
+		case isFloat(t):
+			f, _ := constant.Float64Val(constant.ToFloat(c))
+			v = reflect.ValueOf(f)
+		default:
+			return v, false
+		}
+	}
+	switch {
+	case v.Kind() == t.Kind():
+	case (isInt(v.Type()) || isFloat(v.Type())) && (isInt(t) || isFloat(t)):
+	default:
+		return v, false
+	}
+	v = v.Convert(t)
+	if isNumber(t) && v.IsZero() {
+		return v, false
+	}
+	return v, true
+}
+
+// foldLogical computes the value of the logical expression n, if determined
+// by its constant operands.
+func (interp *Interpreter) foldLogical(n *node) {
+	c0, c1 := n.child[0], n.child[1]
+	if interp.opt.noOpt || !c0.rval.IsValid() || c0.typ == nil {
+		return
+	}
+	b0, ok := boolValue(c0.rval)
+	if !ok {
+		return
+	}
+	switch {
+	case b0 == (n.kind == lorExpr):
+		// false && x, true || x
+		n.rval = c0.rval
+	case c1.rval.IsValid() && c1.typ != nil && c1.typ.id() == c0.typ.id():
+		// true && c, false || c
+		n.rval = c1.rval
+	default:
+		return
+	}
+	n.start = n
+	n.gen = nop
+	n.findex = notInFrame
+}
+
+// boolValue returns the boolean held by constant value v.
+func boolValue(v reflect.Value) (bool, bool) {
+	if c, ok := v.Interface().(constant.Value); ok {
+		if c.Kind() != constant.Bool {
+			return false, false
+		}
+		return constant.BoolVal(c), true
+	}
+	if v.Kind() != reflect.Bool {
+		return false, false
+	}
+	return v.Bool(), true
+}
@@ -0,0 +1,287 @@
+package interp
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Limits of the REPL result pretty-printer.
+const (
+	maxPrintDepth = 4  // nested composite values deeper are elided
+	maxPrintLen   = 32 // elements of slices, arrays and maps beyond are elided
+	maxPrintWidth = 80 // composite values longer are printed on multiple lines
+)
+
+var (
+	stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	errorType    = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// resultString returns the representation of v, of interpreted type t, as a
+// REPL result. By default, it is the type followed by the value, pretty-printed,
+// or the signature alone for functions. If goSyntax is true, it is the Go
+// syntax representation of the value, as by the %#v verb. Results of
+// declarations, which have no type, are not printed.
+func resultString(v reflect.Value, t *itype, goSyntax bool) (s string) {
+	if !v.IsValid() || t == nil || t.cat == nilT {
+		return ""
+	}
+	if goSyntax {
+		if n, ok := nodeValue(v); ok {
+			return typeString(n.typ)
+		}
+		return fmt.Sprintf("%#v", v)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			s = fmt.Sprint(v)
+		}
+	}()
+
+	typ := v.Type().String()
+	if !t.untyped {
+		typ = typeString(t)
+	}
+	if v.Kind() == reflect.Func {
+		return typ
+	}
+	p := &printer{seen: map[uintptr]bool{}}
+	var b strings.Builder
+	b.WriteString(typ + " = ")
+	p.value(v, t, 0).write(&b, "")
+	return b.String()
+}
+
+// printer pretty-prints values.
+type printer struct {
+	seen map[uintptr]bool // pointers being printed, to detect cycles
+}
+
+// pval is a printed value. Composite values are enclosed by head and tail,
+// and printed on one or multiple lines, depending on their length.
+type pval struct {
+	head      string
+	elems     []pval
+	tail      string
+	composite bool
+	list      bool // elements of a slice or array, packed on lines if scalar
+}
+
+func (p pval) flat() string {
+	if !p.composite {
+		return p.head
+	}
+	s := make([]string, len(p.elems))
+	for i, e := range p.elems {
+		s[i] = e.flat()
+	}
+	return p.head + strings.Join(s, ", ") + p.tail
+}
+
+func (p pval) write(b *strings.Builder, indent string) {
+	if f := p.flat(); !p.composite || len(p.elems) == 0 || len(indent)+len(f) <= maxPrintWidth {
+		b.WriteString(f)
+		return
+	}
+	b.WriteString(p.head + "\n")
+	if p.list && !p.nested() {
+		line := ""
+		for _, e := range p.elems {
+			if line != "" && len(indent)+len(line)+len(e.head)+6 > maxPrintWidth {
+				b.WriteString(indent + "    " + line + "\n")
+				line = ""
+			}
+			if line != "" {
+				line += " "
+			}
+			line += e.head + ","
+		}
+		b.WriteString(indent + "    " + line + "\n" + indent + p.tail)
+		return
+	}
+	for _, e := range p.elems {
+		b.WriteString(indent + "    ")
+		e.write(b, indent+"    ")
+		b.WriteString(",\n")
+	}
+	b.WriteString(indent + p.tail)
+}
+
+// nested returns true if some elements of p are composite.
+func (p pval) nested() bool {
+	for _, e := range p.elems {
+		if e.composite {
+			return true
+		}
+	}
+	return false
+}
+
+// withKey prefixes the printed value with a field name or a map key.
+func (p pval) withKey(key string) pval {
+	p.head = key + ": " + p.head
+	return p
+}
+
+// nodeValue returns the interpreted function node held by v, if any.
+func nodeValue(v reflect.Value) (*node, bool) {
+	if !v.CanInterface() {
+		return nil, false
+	}
+	n, ok := v.Interface().(*node)
+	return n, ok && n != nil
+}
+
+// value returns the printed value v of interpreted type t, possibly nil.
+func (p *printer) value(v reflect.Value, t *itype, depth int) pval {
+	for t != nil && t.cat == linkedT && t.val != nil {
+		t = t.val
+	}
+	if t != nil && t.cat == valueT {
+		t = nil
+	}
+	if !v.IsValid() {
+		return pval{head: "nil"}
+	}
+
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return pval{head: "nil"}
+		}
+		e := v.Elem()
+		if e.CanInterface() {
+			if vi, ok := e.Interface().(valueInterface); ok {
+				var dt *itype
+				if vi.node != nil {
+					dt = vi.node.typ
+				}
+				return p.value(vi.value, dt, depth)
+			}
+		}
+		return p.value(e, nil, depth)
+	}
+	if n, ok := nodeValue(v); ok {
+		return pval{head: typeString(n.typ)}
+	}
+	if t == nil && v.CanInterface() && !(v.Kind() == reflect.Ptr && v.IsNil()) && (v.Type().Implements(stringerType) || v.Type().Implements(errorType)) {
+		return pval{head: fmt.Sprint(v.Interface())}
+	}
+
+	switch v.Kind() {
+	case reflect.Func:
+		if v.IsNil() {
+			return pval{head: "nil"}
+		}
+		if t != nil {
+			return pval{head: typeString(t)}
+		}
+		return pval{head: v.Type().String()}
+
+	case reflect.Ptr:
+		if v.IsNil() {
+			return pval{head: "nil"}
+		}
+		if p.seen[v.Pointer()] {
+			return pval{head: "<cycle>"}
+		}
+		p.seen[v.Pointer()] = true
+		defer delete(p.seen, v.Pointer())
+		e := p.value(v.Elem(), elemType(t), depth)
+		e.head = "&" + e.head
+		return e
+
+	case reflect.Struct:
+		if depth >= maxPrintDepth {
+			return pval{head: "{...}"}
+		}
+		pv := pval{head: "{", tail: "}", composite: true}
+		var fields []structField
+		if t != nil && t.cat == structT && len(t.field) == v.NumField() {
+			fields = t.field
+		}
+		for i := 0; i < v.NumField(); i++ {
+			name, ft := v.Type().Field(i).Name, (*itype)(nil)
+			if fields != nil {
+				name, ft = fields[i].name, fields[i].typ
+			}
+			pv.elems = append(pv.elems, p.value(v.Field(i), ft, depth+1).withKey(name))
+		}
+		return pv
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return pval{head: "nil"}
+		}
+		if depth >= maxPrintDepth {
+			return pval{head: "[...]"}
+		}
+		pv := pval{head: "[", tail: "]", composite: true, list: true}
+		for i := 0; i < v.Len() && i < maxPrintLen; i++ {
+			pv.elems = append(pv.elems, p.value(v.Index(i), elemType(t), depth+1))
+		}
+		if n := v.Len() - maxPrintLen; n > 0 {
+			pv.elems = append(pv.elems, pval{head: "... +" + strconv.Itoa(n) + " more"})
+		}
+		return pv
+
+	case reflect.Map:
+		if v.IsNil() {
+			return pval{head: "nil"}
+		}
+		if depth >= maxPrintDepth {
+			return pval{head: "{...}"}
+		}
+		var kt, vt *itype
+		if t != nil && t.cat == mapT {
+			kt, vt = t.key, t.val
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keyLess(keys[i], keys[j]) })
+		pv := pval{head: "{", tail: "}", composite: true}
+		for n, k := range keys {
+			if n == maxPrintLen {
+				pv.elems = append(pv.elems, pval{head: "... +" + strconv.Itoa(len(keys)-n) + " more"})
+				break
+			}
+			key := p.value(k, kt, maxPrintDepth).flat()
+			pv.elems = append(pv.elems, p.value(v.MapIndex(k), vt, depth+1).withKey(key))
+		}
+		return pv
+
+	case reflect.String:
+		return pval{head: strconv.Quote(v.String())}
+	}
+	return pval{head: fmt.Sprint(v)}
+}
+
+// elemType returns the type of the elements of the interpreted type t, or
+// nil if unknown.
+func elemType(t *itype) *itype {
+	if t == nil {
+		return nil
+	}
+	switch t.cat {
+	case ptrT, sliceT, arrayT, variadicT:
+		return t.val
+	}
+	return nil
+}
+
+// keyLess orders map keys, numerically for numbers, and by their printed
+// value otherwise.
+func keyLess(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float()
+	case reflect.String:
+		return a.String() < b.String()
+	}
+	return fmt.Sprint(a) < fmt.Sprint(b)
+}
@@ -0,0 +1,81 @@
+package interp
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResultString(t *testing.T) {
+	i := New(Options{})
+	if _, err := i.Eval(`
+type Node struct {
+	Name  string
+	kids  []*Node
+	attrs map[string]int
+	next  *Node
+}`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := i.Eval(`
+n := &Node{Name: "root", kids: []*Node{{Name: "a"}, {Name: "b"}}, attrs: map[string]int{"y": 2, "x": 1}}
+n.next = n
+`); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		src, pretty, goSyntax string
+	}{
+		{"1 + 2", "int = 3", "3"},
+		{`"a" + "b"`, `string = "ab"`, `"ab"`},
+		{"func h(a int) string { return \"\" }", "", ""},
+		{"h", "func(int) string", "(func(int) string)"},
+		{"type T int", "", ""},
+		{"errors.New(\"boom\")", "error = boom", "(*errors.errorString)("},
+		{"map[int]bool{10: true, 2: false}", "map[int]bool = {2: false, 10: true}", "map[int]bool{2:false, 10:true}"},
+		{"*n", `main.Node = {
+    Name: "root",
+    kids: [
+        &{Name: "a", kids: nil, attrs: nil, next: nil},
+        &{Name: "b", kids: nil, attrs: nil, next: nil},
+    ],
+    attrs: {"x": 1, "y": 2},
+    next: &{
+        Name: "root",
+        kids: [
+            &{Name: "a", kids: nil, attrs: nil, next: nil},
+            &{Name: "b", kids: nil, attrs: nil, next: nil},
+        ],
+        attrs: {"x": 1, "y": 2},
+        next: <cycle>,
+    },
+}`, ""},
+		{"[][][][][]int{{{{{1}}}}}", "[][][][][]int = [[[[[...]]]]]", ""},
+		{"make([]int, 40)", `[]int = [
+    0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+    0, 0, 0, 0, 0, 0, 0, ... +8 more,
+]`, ""},
+	}
+	if err := i.Use(Exports{"errors/errors": {"New": reflect.ValueOf(errors.New)}}); err != nil {
+		t.Fatal(err)
+	}
+	i.ImportUsed()
+	for _, test := range tests {
+		v, typ, err := i.evalWithContext(context.Background(), test.src)
+		if err != nil {
+			t.Fatalf("%s: %v", test.src, err)
+		}
+		if got := resultString(v, typ, false); got != test.pretty {
+			t.Errorf("%s: got %q, want %q", test.src, got, test.pretty)
+		}
+		if test.goSyntax == "" {
+			continue
+		}
+		if got := resultString(v, typ, true); !strings.HasPrefix(got, test.goSyntax) {
+			t.Errorf("%s: got %q, want %q", test.src, got, test.goSyntax)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"go/ast"
 	"go/token"
 	"os"
+	"path/filepath"
 	"reflect"
 	"runtime"
 	"runtime/debug"
@@ -15,6 +16,7 @@ type Program struct {
 	pkgName string
 	root    *node
 	init    []*node
+	main    *node
 }
 
 // PackageName returns name used in a package clause.
@@ -36,8 +38,10 @@ func (interp *Interpreter) Compile(src string) (*Program, error) {
 // CompilePath parses and compiles a Go code located at the given path.
 func (interp *Interpreter) CompilePath(path string) (*Program, error) {
 	if !isFile(interp.filesystem, path) {
+		interp.compiling.Lock()
+		defer interp.compiling.Unlock()
 		_, err := interp.importSrc(mainID, path, NoTest)
-		return nil, err
+		return nil, interp.compileError(err)
 	}
 
 	b, err := os.ReadFile(path)
@@ -54,11 +58,20 @@ func (interp *Interpreter) compileSrc(src, name string, inc bool) (*Program, err
 	if interp.name == "" {
 		interp.name = DefaultSourceName
 	}
+	if name != "" && !inc {
+		// A source file has the language version of its module.
+		if err := interp.setLang(mainID, filepath.Dir(name)); err != nil {
+			return nil, interp.compileError(err)
+		}
+	}
 
 	// Parse source to AST.
 	n, err := interp.parse(src, interp.name, inc)
 	if err != nil {
-		return nil, err
+		return nil, interp.compileError(err)
+	}
+	if name != "" && !inc {
+		interp.upgradeLang(mainID, name, src)
 	}
 
 	return interp.CompileAST(n)
@@ -71,6 +84,20 @@ func (interp *Interpreter) compileSrc(src, name string, inc bool) (*Program, err
 // WARNING: The node must have been parsed using interp.FileSet(). Results are
 // unpredictable otherwise.
 func (interp *Interpreter) CompileAST(n ast.Node) (*Program, error) {
+	p, err := interp.compileAST(n)
+	return p, interp.compileError(err)
+}
+
+func (interp *Interpreter) compileAST(n ast.Node) (*Program, error) {
+	interp.compiling.Lock()
+	defer interp.compiling.Unlock()
+
+	if f, ok := n.(*ast.File); ok {
+		// Parse the imported source packages ahead of processing them.
+		interp.preload(interp.srcImports(f.Name.Name, f)...)
+		defer interp.parsed.release()
+	}
+
 	// Convert AST.
 	pkgName, root, err := interp.ast(n)
 	if err != nil || root == nil {
@@ -89,13 +116,13 @@ func (interp *Interpreter) CompileAST(n ast.Node) (*Program, error) {
 	}
 
 	// Perform global types analysis.
-	if err = interp.gtaRetry([]*node{root}, pkgName, pkgName); err != nil {
+	if err = interp.gtaRetry([]*node{root}, pkgName, pkgName); err != nil && !interp.report(err) {
 		return nil, err
 	}
 
 	// Annotate AST with CFG informations.
 	initNodes, err := interp.cfg(root, nil, pkgName, pkgName)
-	if err != nil {
+	if err != nil && !interp.report(err) {
 		if interp.cfgDot {
 			dotCmd := interp.dotCmd
 			if dotCmd == "" {
@@ -120,9 +147,10 @@ func (interp *Interpreter) CompileAST(n ast.Node) (*Program, error) {
 	}
 	interp.mutex.Unlock()
 
-	// Add main to list of functions to run, after all inits.
+	// Main is run after all inits.
+	var main *node
 	if m := gs.sym[mainID]; pkgName == mainID && m != nil {
-		initNodes = append(initNodes, m.node)
+		main = m.node
 	}
 
 	if interp.cfgDot {
@@ -133,7 +161,7 @@ func (interp *Interpreter) CompileAST(n ast.Node) (*Program, error) {
 		root.cfgDot(dotWriter(dotCmd))
 	}
 
-	return &Program{pkgName, root, initNodes}, nil
+	return &Program{pkgName, root, initNodes, main}, nil
 }
 
 // Execute executes compiled Go code.
@@ -147,29 +175,30 @@ func (interp *Interpreter) Execute(p *Program) (res reflect.Value, err error) {
 		}
 	}()
 
-	// Generate node exec closures.
-	if err = genRun(p.root); err != nil {
+	if err = interp.prepare(p); err != nil {
 		return res, err
 	}
 
-	// Init interpreter execution memory frame.
-	interp.frame.setrunid(interp.runid())
-	interp.frame.mutex.Lock()
-	interp.resizeFrame()
-	interp.frame.mutex.Unlock()
+	if s := interp.sched; s != nil && !s.holds() {
+		s.enter()
+		defer s.leave()
+	}
 
 	// Execute node closures.
-	interp.run(p.root, nil)
+	interp.runInit(p.root, false)
 
 	// Wire and execute global vars.
 	n, err := genGlobalVars([]*node{p.root}, interp.scopes[p.pkgName])
 	if err != nil {
-		return res, err
+		return res, interp.compileError(err)
 	}
-	interp.run(n, nil)
+	interp.runInit(n, false)
 
 	for _, n := range p.init {
-		interp.run(n, interp.frame)
+		interp.runInit(n, true)
+	}
+	if p.main != nil {
+		interp.run(p.main, interp.frame)
 	}
 	v := genValue(p.root)
 	res = v(interp.frame)
@@ -177,22 +206,49 @@ func (interp *Interpreter) Execute(p *Program) (res reflect.Value, err error) {
 	// If result is an interpreter node, wrap it in a runtime callable function.
 	if res.IsValid() {
 		if n, ok := res.Interface().(*node); ok {
+			interp.compiling.Lock()
 			res = genFunctionWrapper(n)(interp.frame)
+			interp.compiling.Unlock()
 		}
 	}
 
 	return res, err
 }
 
+// prepare generates the exec closures of p and resizes the global frame
+// accordingly. It is serialized with compilations, as it accesses the
+// symbols shared with clones.
+func (interp *Interpreter) prepare(p *Program) error {
+	interp.compiling.Lock()
+	defer interp.compiling.Unlock()
+
+	// Generate node exec closures.
+	if err := genRun(p.root); err != nil {
+		return interp.compileError(err)
+	}
+
+	// Init interpreter execution memory frame.
+	interp.frame.setrunid(interp.runid())
+	interp.frame.mutex.Lock()
+	interp.resizeFrame()
+	interp.frame.mutex.Unlock()
+	return nil
+}
+
 // ExecuteWithContext executes compiled Go code.
-func (interp *Interpreter) ExecuteWithContext(ctx context.Context, p *Program) (res reflect.Value, err error) {
+func (interp *Interpreter) ExecuteWithContext(ctx context.Context, p *Program) (reflect.Value, error) {
+	var res reflect.Value
+	var err error
+
 	interp.mutex.Lock()
 	interp.done = make(chan struct{})
 	interp.cancelChan = !interp.opt.fastChan
 	interp.mutex.Unlock()
 
 	done := make(chan struct{})
+	_, exit := interp.goroutines.start(nil, "", token.NoPos)
 	go func() {
+		defer exit()
 		defer close(done)
 		res, err = interp.Execute(p)
 	}()
@@ -0,0 +1,415 @@
+package interp
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// An IdentInfo describes an identifier of the compiled source code, and the
+// symbol it refers to.
+type IdentInfo struct {
+	Name string
+	Kind string         // symbol kind: constant, function, type, variable, package, builtin, field or method
+	Type string         // symbol type, or underlying type for a type, or import path for a package
+	Pos  token.Position // start of the identifier
+	End  token.Position // end of the identifier
+	Decl DeclInfo       // declaration of the symbol, with an invalid position if unknown or binary
+}
+
+// A Completion is a candidate for the completion of an identifier.
+type Completion struct {
+	Name string
+	Kind string // as in IdentInfo
+	Type string // as in IdentInfo
+}
+
+// IdentAt returns the description of the identifier at position pos, in the
+// code checked last by Check or else in the code compiled so far, or nil if
+// there is none.
+//
+// It is intended for editors and other tools, on code compiled by Check,
+// which preserves all the identifiers and also processes code which only
+// partially compiles.
+func (interp *Interpreter) IdentAt(pos token.Position) *IdentInfo {
+	if ci := interp.lastChecked(); ci != nil {
+		if info := ci.identInfoAt(pos); info != nil {
+			return info
+		}
+	}
+	return interp.identInfoAt(pos)
+}
+
+// identInfoAt returns the description of the identifier at position pos,
+// in the code compiled by interp, or nil if there is none.
+func (interp *Interpreter) identInfoAt(pos token.Position) (info *IdentInfo) {
+	interp.compiling.Lock()
+	defer interp.compiling.Unlock()
+	interp.mutex.RLock()
+	defer interp.mutex.RUnlock()
+	defer func() {
+		// Types of incorrect code may be incomplete.
+		if recover() != nil {
+			info = nil
+		}
+	}()
+
+	n, _ := interp.identAt(pos)
+	if n == nil {
+		return nil
+	}
+	return interp.identInfo(n)
+}
+
+// Completions returns the candidates for the completion of the identifier
+// ending at position pos, in the code compiled so far, sorted by name.
+// The candidates are the members of the package, or the fields and methods
+// of the value, for a selector, and the symbols in scope otherwise. They are
+// filtered by the part of the identifier preceding pos.
+func (interp *Interpreter) Completions(pos token.Position) []Completion {
+	if ci := interp.lastChecked(); ci != nil {
+		if res := ci.completionsAt(pos); res != nil {
+			return res
+		}
+	}
+	return interp.completionsAt(pos)
+}
+
+// completionsAt returns the candidates for the completion of the identifier
+// ending at position pos, in the code compiled by interp.
+func (interp *Interpreter) completionsAt(pos token.Position) (res []Completion) {
+	interp.compiling.Lock()
+	defer interp.compiling.Unlock()
+	interp.mutex.RLock()
+	defer interp.mutex.RUnlock()
+	defer func() {
+		if recover() != nil {
+			res = nil
+		}
+	}()
+
+	n, p := interp.identAt(pos)
+	if n == nil {
+		return nil
+	}
+	prefix := n.ident
+	if l := int(p - n.pos); l < len(prefix) {
+		prefix = prefix[:l]
+	}
+
+	seen := map[string]bool{}
+	add := func(name, kind, typ string) {
+		if seen[name] || name == "_" || !strings.HasPrefix(name, prefix) {
+			return
+		}
+		seen[name] = true
+		res = append(res, Completion{Name: name, Kind: kind, Type: typ})
+	}
+
+	if a := n.anc; a != nil && a.kind == selectorExpr && a.child[1] == n {
+		interp.memberCompletions(a.child[0], add)
+	} else {
+		base := "/" + filepath.Base(pos.Filename)
+		for sc := n.scope; sc != nil; sc = sc.anc {
+			for name, sym := range sc.sym {
+				if i := strings.Index(name, "/"); i >= 0 {
+					// Imported package, only visible in its file.
+					if !strings.HasSuffix(name, base) {
+						continue
+					}
+					name = name[:i]
+				}
+				add(name, symKindName(sym.kind), symTypeString(sym))
+			}
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+// identAt returns the identifier node containing position pos, or ending
+// at pos, and pos in the file set.
+func (interp *Interpreter) identAt(pos token.Position) (*node, token.Pos) {
+	// Latest roots come first, as a file name may be compiled several times.
+	for i := len(interp.roots) - 1; i >= 0; i-- {
+		r := interp.roots[i]
+		f := interp.fset.File(r.pos)
+		if f == nil || f.Name() != pos.Filename || pos.Line < 1 || pos.Line > f.LineCount() {
+			continue
+		}
+		p := f.LineStart(pos.Line) + token.Pos(pos.Column-1)
+		var in, end *node
+		r.Walk(func(n *node) bool {
+			if in != nil && in.kind == identExpr {
+				return false
+			}
+			if !isIdentNode(n) {
+				return true
+			}
+			// Identifiers are preferred to constants, as some constant
+			// nodes are added at the position of other identifiers.
+			switch e := n.pos + token.Pos(len(n.ident)); {
+			case n.pos <= p && p < e && (in == nil || n.kind == identExpr):
+				in = n
+			case p == e && (end == nil || n.kind == identExpr):
+				end = n
+			}
+			return true
+		}, nil)
+		if in != nil {
+			return in, p
+		}
+		if end != nil {
+			return end, p
+		}
+	}
+	return nil, token.NoPos
+}
+
+// isIdentNode returns true if n is an identifier, including a constant one
+// which has been replaced by its value.
+func isIdentNode(n *node) bool {
+	return n.ident != "" && (n.kind == identExpr || n.kind == basicLit && n.sym != nil)
+}
+
+func (interp *Interpreter) identInfo(n *node) *IdentInfo {
+	info := &IdentInfo{
+		Name: n.ident,
+		Pos:  interp.fset.Position(n.pos),
+		End:  interp.fset.Position(n.pos + token.Pos(len(n.ident))),
+	}
+	info.Decl.Name = n.ident
+
+	if a := n.anc; a != nil && a.kind == selectorExpr && a.child[1] == n {
+		interp.selectorInfo(info, a)
+		return info
+	}
+	if a := n.anc; a != nil && a.kind == funcDecl && a.child[1] == n {
+		// Function and method names are not in scope.
+		info.Kind, info.Type = "function", typeString(a.typ)
+		if isMethod(a) {
+			info.Kind = "method"
+		}
+		info.Decl = interp.funcInfo(a).DeclInfo
+		return info
+	}
+
+	sym := n.sym
+	if sym == nil && n.scope != nil {
+		// Identifiers at declaration are not resolved.
+		sym, _, _ = n.scope.lookup(n.ident)
+	}
+	if sym == nil {
+		return info
+	}
+	info.Kind, info.Type = symKindName(sym.kind), symTypeString(sym)
+	if sym.kind == pkgSym {
+		return info
+	}
+	interp.symbolDecl(info, sym, n)
+	return info
+}
+
+// symbolDecl sets the declaration of the symbol sym referred by node n.
+func (interp *Interpreter) symbolDecl(info *IdentInfo, sym *symbol, n *node) {
+	switch {
+	case sym.kind == typeSym:
+		if d := typeSpecNode(sym); d != nil {
+			info.Decl = interp.declInfo(info.Name, d)
+		}
+	case sym.kind == funcSym && sym.node != nil && sym.node.kind == funcDecl:
+		info.Decl = interp.funcInfo(sym.node).DeclInfo
+	case sym.global && sym.node != nil:
+		info.Decl = interp.declInfo(info.Name, sym.node)
+	case sym.kind == varSym && n.scope != nil:
+		// A local variable is declared by the first identifier in its scope.
+		root := n
+		for root.anc != nil {
+			root = root.anc
+		}
+		var decl *node
+		root.Walk(func(c *node) bool {
+			if decl == nil && c.kind == identExpr && c.ident == info.Name && c.scope != nil && c.scope.sym[c.ident] == sym {
+				decl = c
+			}
+			return decl == nil
+		}, nil)
+		if decl != nil {
+			info.Decl.Pos = interp.fset.Position(decl.pos)
+		}
+	}
+}
+
+// selectorInfo sets the description of the selector expression a.
+func (interp *Interpreter) selectorInfo(info *IdentInfo, a *node) {
+	q, name := a.child[0], info.Name
+	t := q.typ
+	switch {
+	case t == nil:
+	case t.cat == srcPkgT:
+		if sym := interp.srcPkg[t.path][name]; sym != nil {
+			info.Kind, info.Type = symKindName(sym.kind), symTypeString(sym)
+			interp.symbolDecl(info, sym, a)
+		}
+	case t.cat == binPkgT:
+		if v, ok := interp.binPkg[t.path][name]; ok {
+			info.Kind, info.Type = binKindType(v)
+		}
+	default:
+		if def, _ := t.lookupMethod(name); def != nil && def.kind == funcDecl {
+			info.Kind, info.Type = "method", typeString(def.typ)
+			info.Decl = interp.funcInfo(def).DeclInfo
+			return
+		}
+		if a.typ != nil {
+			info.Type = typeString(a.typ)
+		}
+		if ti := t.lookupField(name); len(ti) > 0 {
+			info.Kind = "field"
+			info.Decl = interp.fieldDecl(t, ti, name)
+			return
+		}
+		if rt := t.TypeOf(); rt != nil {
+			if m, ok := rt.MethodByName(name); ok {
+				info.Kind, info.Type = "method", m.Type.String()
+			}
+		}
+	}
+}
+
+// fieldDecl returns the declaration of the field name of type t, at index
+// sequence ti, as returned by lookupField.
+func (interp *Interpreter) fieldDecl(t *itype, ti []int, name string) DeclInfo {
+	for _, i := range ti[:len(ti)-1] {
+		t = t.fieldSeq([]int{i})
+	}
+	for t.cat == ptrT || t.cat == linkedT && t.val != nil {
+		t = t.val
+	}
+	spec := typeSpecNode(&symbol{typ: t})
+	if spec == nil {
+		return DeclInfo{Name: name}
+	}
+	var decl DeclInfo
+	spec.Walk(func(c *node) bool {
+		if c.kind == fieldExpr {
+			for _, id := range c.child {
+				if id.kind == identExpr && id.ident == name {
+					decl = interp.declInfo(name, c)
+					decl.Pos = interp.fset.Position(id.pos)
+					return false
+				}
+			}
+		}
+		return decl.Pos.Filename == ""
+	}, nil)
+	decl.Name = name
+	return decl
+}
+
+// memberCompletions adds the exported symbols of the package, or the fields
+// and methods of the value, designated by node q.
+func (interp *Interpreter) memberCompletions(q *node, add func(name, kind, typ string)) {
+	t := q.typ
+	switch {
+	case t == nil:
+	case t.cat == srcPkgT:
+		for name, sym := range interp.srcPkg[t.path] {
+			if canExport(name) {
+				add(name, symKindName(sym.kind), symTypeString(sym))
+			}
+		}
+	case t.cat == binPkgT:
+		for name, v := range interp.binPkg[t.path] {
+			if canExport(name) {
+				kind, typ := binKindType(v)
+				add(name, kind, typ)
+			}
+		}
+	default:
+		seen := map[*itype]bool{}
+		var members func(t *itype)
+		members = func(t *itype) {
+			for t.cat == ptrT || t.cat == linkedT && t.val != nil {
+				t = t.val
+			}
+			if seen[t] {
+				return
+			}
+			seen[t] = true
+			for _, m := range t.method {
+				if m.kind == funcDecl {
+					add(m.child[1].ident, "method", typeString(m.typ))
+				}
+			}
+			if t.ptr != nil {
+				for _, m := range t.ptr.method {
+					if m.kind == funcDecl {
+						add(m.child[1].ident, "method", typeString(m.typ))
+					}
+				}
+			}
+			for _, f := range t.field {
+				kind := "field"
+				if isInterfaceSrc(t) {
+					kind = "method"
+				}
+				add(f.name, kind, typeString(f.typ))
+				if f.embed {
+					members(f.typ)
+				}
+			}
+		}
+		members(t)
+		if t.cat == valueT && t.rtype != nil {
+			rt := t.rtype
+			for i := 0; i < rt.NumMethod(); i++ {
+				m := rt.Method(i)
+				add(m.Name, "method", m.Type.String())
+			}
+			if rt.Kind() == reflect.Ptr {
+				rt = rt.Elem()
+			}
+			if rt.Kind() == reflect.Struct {
+				for i := 0; i < rt.NumField(); i++ {
+					if f := rt.Field(i); f.IsExported() {
+						add(f.Name, "field", f.Type.String())
+					}
+				}
+			}
+		}
+	}
+}
+
+// symTypeString returns the type of symbol sym, as described in IdentInfo.
+func symTypeString(sym *symbol) string {
+	switch sym.kind {
+	case pkgSym:
+		return sym.typ.path
+	case typeSym:
+		if sym.typ != nil && sym.typ.cat != genericT {
+			return underlyingString(sym.typ)
+		}
+		return ""
+	case bltnSym:
+		return ""
+	}
+	return typeString(sym.typ)
+}
+
+// binKindType returns the kind and type of the binary symbol v.
+func binKindType(v reflect.Value) (string, string) {
+	switch {
+	case isBinType(v):
+		return "type", v.Type().Elem().Kind().String()
+	case v.Kind() == reflect.Func:
+		return "function", v.Type().String()
+	case v.Kind() == reflect.Ptr:
+		// Binary variables are exported as pointers.
+		return "variable", v.Type().Elem().String()
+	}
+	return "constant", v.Type().String()
+}
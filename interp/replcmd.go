@@ -0,0 +1,657 @@
+package interp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// replSession holds the state of a REPL session used by the meta-commands.
+type replSession struct {
+	src      []string // inputs successfully evaluated, in order
+	goSyntax bool     // print results in Go syntax, instead of pretty-printed
+}
+
+// result returns the representation of a result value v of interpreted type t.
+func (s *replSession) result(v reflect.Value, t *itype) string {
+	return resultString(v, t, s.goSyntax)
+}
+
+// replCommands describes the REPL meta-commands, for :help.
+var replCommands = []struct{ name, args, help string }{
+	{"help", "", "list the commands"},
+	{"type", "expr", "show the type of an expression, without evaluating it"},
+	{"doc", "[pkg.]sym", "show the declaration and documentation of a symbol or package"},
+	{"vars", "", "list the global variables and constants, with their values"},
+	{"imports", "", "list the packages explicitly imported in the session"},
+	{"load", "file.go", "evaluate a Go file in the session"},
+	{"format", "pretty|go", "print the results pretty-printed with their type, or in Go syntax"},
+	{"reset", "", "forget all the declarations and imports of the session"},
+	{"save", "file.go", "write the declarations of the session to a Go file"},
+}
+
+// replCommand executes the REPL meta-command in line, which starts with ':'.
+func (interp *Interpreter) replCommand(ctx context.Context, s *replSession, line string) error {
+	out := interp.stdout
+	name, arg, _ := strings.Cut(strings.TrimSpace(line[1:]), " ")
+	arg = strings.TrimSpace(arg)
+
+	switch name {
+	case "help":
+		for _, c := range replCommands {
+			fmt.Fprintf(out, "  :%-18s %s\n", strings.TrimSpace(c.name+" "+c.args), c.help)
+		}
+		return nil
+	case "vars", "imports", "reset":
+		if arg != "" {
+			return fmt.Errorf(":%s takes no argument", name)
+		}
+	case "type", "doc", "format", "load", "save":
+		if arg == "" {
+			return fmt.Errorf("missing argument, usage: :%s %s", name, replUsage(name))
+		}
+	default:
+		return fmt.Errorf("unknown command :%s, see :help", name)
+	}
+
+	switch name {
+	case "type":
+		t, err := interp.typeOfExpr(arg)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, t)
+	case "doc":
+		return interp.replDoc(out, arg)
+	case "vars":
+		interp.replVars(out)
+	case "imports":
+		imports := interp.replImports(false)
+		names := make([]string, 0, len(imports))
+		for name := range imports {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool { return imports[names[i]] < imports[names[j]] })
+		for _, name := range names {
+			fmt.Fprintln(out, importString(name, imports[name]))
+		}
+	case "format":
+		switch arg {
+		case "pretty", "go":
+			s.goSyntax = arg == "go"
+		default:
+			return fmt.Errorf("unknown format %s, usage: :format %s", arg, replUsage(name))
+		}
+	case "load":
+		b, err := os.ReadFile(arg)
+		if err != nil {
+			return err
+		}
+		if _, err := interp.EvalPathWithContext(ctx, arg); err != nil {
+			return err
+		}
+		s.src = append(s.src, string(b))
+	case "reset":
+		interp.resetMain()
+		s.src = nil
+	case "save":
+		b, err := s.source(interp.replImports(true))
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(arg, b, 0o644)
+	}
+	return nil
+}
+
+func replUsage(name string) string {
+	for _, c := range replCommands {
+		if c.name == name {
+			return c.args
+		}
+	}
+	return ""
+}
+
+// typeOfExpr returns the type of the expression src, which is compiled but
+// not executed.
+func (interp *Interpreter) typeOfExpr(src string) (string, error) {
+	if _, err := parser.ParseExpr(src); err != nil {
+		return "", fmt.Errorf("not an expression: %s", src)
+	}
+	p, err := interp.Compile(src)
+	if err != nil {
+		return "", err
+	}
+	if p.root.typ == nil {
+		return "", errors.New("expression has no type")
+	}
+	return typeString(p.root.typ), nil
+}
+
+// replVars prints the global variables and constants of the session.
+func (interp *Interpreter) replVars(out io.Writer) {
+	pi, err := interp.Package(mainID)
+	if err != nil {
+		return
+	}
+	globals := interp.Globals()
+	var lines []string
+	for _, c := range pi.Consts {
+		lines = append(lines, fmt.Sprintf("const %s %s = %s", c.Name, c.Type, c.Value))
+	}
+	for _, v := range pi.Vars {
+		lines = append(lines, fmt.Sprintf("var %s %s = %v", v.Name, v.Type, globals[v.Name]))
+	}
+	for _, l := range lines {
+		fmt.Fprintln(out, l)
+	}
+}
+
+// replImports returns the packages visible in the session, by name. If all
+// is false, only the packages explicitly imported are returned.
+func (interp *Interpreter) replImports(all bool) map[string]string {
+	interp.mutex.RLock()
+	defer interp.mutex.RUnlock()
+
+	res := map[string]string{}
+	scopes := []*scope{interp.scopes[mainID]}
+	if all {
+		scopes = append([]*scope{interp.universe}, scopes...)
+	}
+	for _, sc := range scopes {
+		if sc == nil {
+			continue
+		}
+		for name, sym := range sc.sym {
+			if sym.kind != pkgSym || sym.typ == nil {
+				continue
+			}
+			if i := strings.Index(name, "/"); i >= 0 {
+				name = name[:i]
+			}
+			res[name] = sym.typ.path
+		}
+	}
+	return res
+}
+
+func importString(name, ipath string) string {
+	if name == path.Base(ipath) {
+		return "import " + strconv.Quote(ipath)
+	}
+	return "import " + name + " " + strconv.Quote(ipath)
+}
+
+// resetMain forgets the declarations and imports of the main package.
+func (interp *Interpreter) resetMain() {
+	interp.compiling.Lock()
+	defer interp.compiling.Unlock()
+	interp.mutex.Lock()
+	defer interp.mutex.Unlock()
+
+	delete(interp.srcPkg, mainID)
+	delete(interp.scopes, mainID)
+	delete(interp.pkgNames, mainID)
+	if sym := interp.universe.sym[mainID]; sym != nil && sym.typ != nil && sym.typ.cat == srcPkgT {
+		delete(interp.universe.sym, mainID)
+	}
+}
+
+// replDoc prints the declaration and documentation of the symbol or package
+// designated by name.
+func (interp *Interpreter) replDoc(out io.Writer, name string) error {
+	ipath, sym := interp.resolvePackage(name)
+	if ipath == "" {
+		ipath, sym = mainID, name
+	}
+
+	if pi, err := interp.Package(ipath); err == nil {
+		if sym == "" {
+			fmt.Fprintf(out, "package %s // import %q\n\n", pi.Name, pi.Path)
+			for _, l := range packageDecls(pi) {
+				fmt.Fprintln(out, l)
+			}
+			return nil
+		}
+		decl, doc, ok := symbolDoc(pi, sym)
+		if !ok {
+			return fmt.Errorf("no symbol %s in package %s", sym, pi.Path)
+		}
+		fmt.Fprintln(out, decl)
+		printDoc(out, doc)
+		return nil
+	}
+
+	syms := interp.Symbols(ipath)[ipath]
+	if len(syms) == 0 {
+		return fmt.Errorf("package %q not found", ipath)
+	}
+	if sym == "" {
+		fmt.Fprintf(out, "package %s // import %q\n\n", path.Base(ipath), ipath)
+		names := make([]string, 0, len(syms))
+		for n := range syms {
+			if canExport(n) {
+				names = append(names, n)
+			}
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			fmt.Fprintln(out, binDecl(n, syms[n]))
+		}
+		return nil
+	}
+	v, ok := syms[sym]
+	if !ok {
+		return fmt.Errorf("no symbol %s in package %s", sym, ipath)
+	}
+	fmt.Fprintln(out, binDecl(sym, v))
+	if isBinType(v) {
+		rt := reflect.PtrTo(v.Type().Elem())
+		for i := 0; i < rt.NumMethod(); i++ {
+			m := rt.Method(i)
+			fmt.Fprintf(out, "func (%s) %s%s\n", rt, m.Name, funcTypeString(m.Type, 1))
+		}
+	}
+	return nil
+}
+
+// resolvePackage splits name into the import path of a package, as known by
+// the interpreter, and the remaining symbol name. The package is designated
+// by its import path, or by its name in the session.
+func (interp *Interpreter) resolvePackage(name string) (ipath, sym string) {
+	imports := interp.replImports(true)
+	interp.mutex.RLock()
+	defer interp.mutex.RUnlock()
+
+	for i := len(name); i > 0; i = strings.LastIndex(name[:i], ".") {
+		p, rest := name[:i], strings.TrimPrefix(name[i:], ".")
+		if _, ok := interp.binPkg[p]; ok && p != "" {
+			return p, rest
+		}
+		if _, ok := interp.srcPkg[p]; ok && p != mainID {
+			return p, rest
+		}
+		if ip, ok := imports[p]; ok {
+			return ip, rest
+		}
+	}
+	return "", ""
+}
+
+// packageDecls returns a one line description of the declarations of a
+// source package.
+func packageDecls(pi *PackageInfo) []string {
+	var res []string
+	for _, c := range pi.Consts {
+		res = append(res, "const "+c.Name+" "+c.Type+" = "+c.Value)
+	}
+	for _, v := range pi.Vars {
+		res = append(res, "var "+v.Name+" "+v.Type)
+	}
+	for _, f := range pi.Funcs {
+		res = append(res, funcInfoString(f))
+	}
+	for _, t := range pi.Types {
+		res = append(res, typeInfoString(t))
+	}
+	return res
+}
+
+// symbolDoc returns the declaration and doc comment of the symbol sym of a
+// source package. The symbol may be a method, in the form "T.M".
+func symbolDoc(pi *PackageInfo, sym string) (decl, doc string, ok bool) {
+	for _, c := range pi.Consts {
+		if c.Name == sym {
+			return "const " + c.Name + " " + c.Type + " = " + c.Value, c.Doc, true
+		}
+	}
+	for _, v := range pi.Vars {
+		if v.Name == sym {
+			return "var " + v.Name + " " + v.Type, v.Doc, true
+		}
+	}
+	for _, f := range pi.Funcs {
+		if f.Name == sym {
+			return funcInfoString(f), f.Doc, true
+		}
+	}
+	tname, mname, _ := strings.Cut(sym, ".")
+	for _, t := range pi.Types {
+		if t.Name != tname {
+			continue
+		}
+		if mname == "" {
+			decl = typeInfoString(t)
+			for _, m := range t.Methods {
+				if m.Recv != "" {
+					decl += "\n" + funcInfoString(m)
+				}
+			}
+			return decl, t.Doc, true
+		}
+		for _, m := range t.Methods {
+			if m.Name == mname {
+				return funcInfoString(m), m.Doc, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+func printDoc(out io.Writer, doc string) {
+	if doc == "" {
+		return
+	}
+	fmt.Fprintln(out)
+	for _, l := range strings.Split(strings.TrimRight(doc, "\n"), "\n") {
+		fmt.Fprintln(out, "    "+l)
+	}
+}
+
+func typeInfoString(t TypeInfo) string {
+	if t.Alias {
+		return "type " + t.Name + " = " + t.Underlying
+	}
+	return "type " + t.Name + " " + t.Underlying
+}
+
+func funcInfoString(f FuncInfo) string {
+	params := make([]string, len(f.Params))
+	for i, p := range f.Params {
+		typ := p.Type
+		if f.Variadic && i == len(f.Params)-1 {
+			typ = "..." + strings.TrimPrefix(typ, "[]")
+		}
+		params[i] = strings.TrimSpace(p.Name + " " + typ)
+	}
+	s := "func "
+	if f.Recv != "" {
+		s += "(" + f.Recv + ") "
+	}
+	s += f.Name + "(" + strings.Join(params, ", ") + ")"
+
+	results := make([]string, len(f.Results))
+	for i, r := range f.Results {
+		results[i] = strings.TrimSpace(r.Name + " " + r.Type)
+	}
+	switch {
+	case len(results) == 1 && f.Results[0].Name == "":
+		s += " " + results[0]
+	case len(results) > 0:
+		s += " (" + strings.Join(results, ", ") + ")"
+	}
+	return s
+}
+
+// binDecl returns the declaration of the binary symbol name of value v.
+func binDecl(name string, v reflect.Value) string {
+	kind, typ := binKindType(v)
+	switch kind {
+	case "type":
+		return "type " + name + " " + typ
+	case "function":
+		return "func " + name + funcTypeString(v.Type(), 0)
+	case "variable":
+		return "var " + name + " " + typ
+	}
+	return "const " + name + " " + typ
+}
+
+// funcTypeString returns the signature of the function type t, without the
+// func keyword, skipping its first skip parameters.
+func funcTypeString(t reflect.Type, skip int) string {
+	var params, results []string
+	for i := skip; i < t.NumIn(); i++ {
+		p := t.In(i).String()
+		if t.IsVariadic() && i == t.NumIn()-1 {
+			p = "..." + t.In(i).Elem().String()
+		}
+		params = append(params, p)
+	}
+	for i := 0; i < t.NumOut(); i++ {
+		results = append(results, t.Out(i).String())
+	}
+	s := "(" + strings.Join(params, ", ") + ")"
+	switch len(results) {
+	case 0:
+	case 1:
+		s += " " + results[0]
+	default:
+		s += " (" + strings.Join(results, ", ") + ")"
+	}
+	return s
+}
+
+// source returns the declarations of the session as a Go source file of the
+// main package. Only the last declaration of each symbol is kept, as symbols
+// may be redeclared in the REPL. Short variable declarations are converted
+// into variable declarations, and assignments are kept, in order, in an init
+// function, which runs after the initialization of the package variables.
+// Other statements are omitted. The packages used are imported from imports,
+// indexed by name.
+func (s *replSession) source(imports map[string]string) ([]byte, error) {
+	var decls []replDecl
+	var stmts []string
+	hasMain := false
+
+	// add appends a declaration of names, replacing the previous ones.
+	add := func(text string, names ...string) {
+		for i, d := range decls {
+			for _, name := range names {
+				if d.declares(name) {
+					decls[i].text = ""
+				}
+			}
+		}
+		decls = append(decls, replDecl{names, text})
+	}
+
+	for _, src := range s.src {
+		text := src
+		if !strings.HasPrefix(strings.TrimSpace(text), "package ") {
+			text = "package main\n" + text
+		}
+		fset := token.NewFileSet()
+		if f, err := parser.ParseFile(fset, "", text, parser.ParseComments); err == nil {
+			for _, d := range f.Decls {
+				switch d := d.(type) {
+				case *ast.GenDecl:
+					if d.Tok == token.IMPORT {
+						for _, spec := range d.Specs {
+							is := spec.(*ast.ImportSpec)
+							ipath, _ := strconv.Unquote(is.Path.Value)
+							name := path.Base(ipath)
+							if is.Name != nil {
+								name = is.Name.Name
+							}
+							imports[name] = ipath
+						}
+						continue
+					}
+				case *ast.FuncDecl:
+					if d.Recv == nil && d.Name.Name == "main" {
+						hasMain = true
+					}
+				}
+				add(nodeSource(fset, text, d, declDoc(d)), declNames(d)...)
+			}
+			continue
+		}
+
+		text = "package main\nfunc _() {\n" + src + "\n}"
+		fset = token.NewFileSet()
+		f, err := parser.ParseFile(fset, "", text, parser.ParseComments)
+		if err != nil {
+			continue
+		}
+		for _, st := range f.Decls[0].(*ast.FuncDecl).Body.List {
+			switch st := st.(type) {
+			case *ast.DeclStmt:
+				add(nodeSource(fset, text, st, nil), declNames(st.Decl)...)
+			case *ast.AssignStmt:
+				if st.Tok != token.DEFINE {
+					stmts = append(stmts, nodeSource(fset, text, st, nil))
+					continue
+				}
+				lhs := nodeSource(fset, text, listNode(st.Lhs), nil)
+				rhs := nodeSource(fset, text, listNode(st.Rhs), nil)
+				add("var "+lhs+" = "+rhs, identNames(st.Lhs)...)
+			case *ast.IncDecStmt:
+				stmts = append(stmts, nodeSource(fset, text, st, nil))
+			}
+		}
+	}
+
+	var texts []string
+	for _, d := range decls {
+		if d.text != "" {
+			texts = append(texts, d.text)
+		}
+	}
+	if len(stmts) > 0 {
+		texts = append(texts, "func init() {\n"+strings.Join(stmts, "\n")+"\n}")
+	}
+	if !hasMain {
+		texts = append(texts, "func main() {}")
+	}
+	body := strings.Join(texts, "\n\n") + "\n"
+
+	// Import the packages used in the declarations.
+	f, err := parser.ParseFile(token.NewFileSet(), "", "package main\n"+body, 0)
+	if err != nil {
+		return nil, err
+	}
+	used := map[string]bool{}
+	ast.Inspect(f, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok && imports[id.Name] != "" && f.Scope.Lookup(id.Name) == nil {
+				used[id.Name] = true
+			}
+		}
+		return true
+	})
+	var specs []string
+	for name := range used {
+		specs = append(specs, strings.TrimPrefix(importString(name, imports[name]), "import "))
+	}
+	sort.Strings(specs)
+
+	var b strings.Builder
+	b.WriteString("package main\n\n")
+	if len(specs) > 0 {
+		b.WriteString("import (\n\t" + strings.Join(specs, "\n\t") + "\n)\n\n")
+	}
+	b.WriteString(body)
+	return format.Source([]byte(b.String()))
+}
+
+// replDecl is the source text of a declaration of the session, empty if
+// replaced by a later declaration.
+type replDecl struct {
+	names []string // declared symbols, methods in the form "T.M"
+	text  string
+}
+
+func (d replDecl) declares(name string) bool {
+	for _, n := range d.names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// declNames returns the names of the symbols declared by d, methods in the
+// form "T.M". Init functions, which may be declared several times, and blank
+// identifiers are omitted.
+func declNames(d ast.Decl) []string {
+	var names []string
+	switch d := d.(type) {
+	case *ast.GenDecl:
+		for _, spec := range d.Specs {
+			switch spec := spec.(type) {
+			case *ast.TypeSpec:
+				names = append(names, spec.Name.Name)
+			case *ast.ValueSpec:
+				for _, id := range spec.Names {
+					if id.Name != "_" {
+						names = append(names, id.Name)
+					}
+				}
+			}
+		}
+	case *ast.FuncDecl:
+		switch {
+		case d.Recv != nil && len(d.Recv.List) > 0:
+			if t := recvTypeName(d.Recv.List[0].Type); t != "" {
+				names = append(names, t+"."+d.Name.Name)
+			}
+		case d.Name.Name != "init":
+			names = append(names, d.Name.Name)
+		}
+	}
+	return names
+}
+
+// recvTypeName returns the name of the base type of receiver type expression e.
+func recvTypeName(e ast.Expr) string {
+	switch e := e.(type) {
+	case *ast.StarExpr:
+		return recvTypeName(e.X)
+	case *ast.IndexExpr:
+		return recvTypeName(e.X)
+	case *ast.IndexListExpr:
+		return recvTypeName(e.X)
+	case *ast.Ident:
+		return e.Name
+	}
+	return ""
+}
+
+// identNames returns the names of identifiers in l, except blank ones.
+func identNames(l []ast.Expr) []string {
+	var names []string
+	for _, e := range l {
+		if id, ok := e.(*ast.Ident); ok && id.Name != "_" {
+			names = append(names, id.Name)
+		}
+	}
+	return names
+}
+
+// listNode is a node spanning a list of expressions.
+type listNode []ast.Expr
+
+func (l listNode) Pos() token.Pos { return l[0].Pos() }
+func (l listNode) End() token.Pos { return l[len(l)-1].End() }
+
+func declDoc(d ast.Decl) *ast.CommentGroup {
+	switch d := d.(type) {
+	case *ast.GenDecl:
+		return d.Doc
+	case *ast.FuncDecl:
+		return d.Doc
+	}
+	return nil
+}
+
+// nodeSource returns the source text of node n, including its doc comment.
+func nodeSource(fset *token.FileSet, src string, n ast.Node, doc *ast.CommentGroup) string {
+	start := n.Pos()
+	if doc != nil {
+		start = doc.Pos()
+	}
+	return src[fset.Position(start).Offset:fset.Position(n.End()).Offset]
+}
@@ -0,0 +1,124 @@
+package interp
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestREPLCommand(t *testing.T) {
+	dir := t.TempDir()
+	file, lib := filepath.Join(dir, "session.go"), filepath.Join(dir, "lib", "lib.go")
+	if err := os.Mkdir(filepath.Dir(lib), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(lib, []byte("package main\n\n// Triple returns three times n.\nfunc Triple(n int) int { return 3 * n }\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	input := strings.Join([]string{
+		`import "guthib.com/app"`,
+		`func Double(n int) int { return n }`,
+		`// Double returns twice n.`,
+		`func Double(n int) int { return 2 * n }`,
+		`type T struct{ Name string }`,
+		`func (t T) Upper() string { return app.Greet(t.Name) }`,
+		`x := Double(21)`,
+		`const c = 3`,
+		`x++`,
+		`:type x + 1`,
+		`:type T{}.Upper`,
+		`:type y := 1`,
+		`:doc Double`,
+		`:doc T`,
+		`:doc app.Greet`,
+		`:vars`,
+		`:imports`,
+		`:save ` + file,
+		`:reset`,
+		`:vars`,
+		`:type x`,
+		`:load ` + file,
+		`:vars`,
+		`:doc T`,
+		`:load ` + lib,
+		`:doc Triple`,
+		`:bogus`,
+	}, "\n") + "\n"
+	var out, errs strings.Builder
+	i := New(Options{Stdin: strings.NewReader(input), Stdout: &out, Stderr: &errs})
+	if err := i.Use(Exports{"guthib.com/app/app": {"Greet": reflect.ValueOf(func(string) string { return "" })}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := i.REPL(); err != nil {
+		t.Fatal(err)
+	}
+
+	wantOut := `int
+func() string
+func Double(n int) int
+type T struct { Name string}
+func (main.T) Upper() string
+func Greet(string) string
+const c untyped int = 3
+var x int = 43
+import "guthib.com/app"
+const c untyped int = 3
+var x int = 43
+type T struct { Name string}
+func (main.T) Upper() string
+func Triple(n int) int
+
+    Triple returns three times n.
+`
+	if got := out.String(); got != wantOut {
+		t.Errorf("got output:\n%s\nwant:\n%s", got, wantOut)
+	}
+	wantErrs := `not an expression: y := 1
+1:28: undefined: x
+unknown command :bogus, see :help
+`
+	if got := errs.String(); got != wantErrs {
+		t.Errorf("got errors:\n%s\nwant:\n%s", got, wantErrs)
+	}
+
+	b, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantFile := `package main
+
+import (
+	"guthib.com/app"
+)
+
+func Double(n int) int { return 2 * n }
+
+type T struct{ Name string }
+
+func (t T) Upper() string { return app.Greet(t.Name) }
+
+var x = Double(21)
+
+const c = 3
+
+func init() {
+	x++
+}
+
+func main() {}
+`
+	if string(b) != wantFile {
+		t.Errorf("got saved file:\n%s\nwant:\n%s", b, wantFile)
+	}
+
+	// The saved file compiles on its own.
+	i = New(Options{})
+	if err := i.Use(Exports{"guthib.com/app/app": {"Greet": reflect.ValueOf(func(string) string { return "" })}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := i.CompilePath(file); err != nil {
+		t.Errorf("saved file does not compile: %v", err)
+	}
+}
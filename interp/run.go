@@ -113,8 +113,11 @@ func (interp *Interpreter) run(n *node, cf *frame) {
 	f.done = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: c}
 	f.mutex.Unlock()
 
+	if n.unbox != nil {
+		f.num = make([]uint64, len(n.types))
+	}
 	for i, t := range n.types {
-		f.data[i] = reflect.New(t).Elem()
+		f.data[i] = newFrameValue(f, n.unbox, i, t)
 	}
 	runCfg(n.start, f, n, nil)
 }
@@ -230,7 +233,7 @@ func runCfg(n *node, f *frame, funcNode, callNode *node) {
 
 	dbg := n.interp.debugger
 	if dbg == nil {
-		for exec := n.exec; exec != nil && f.runid() == n.interp.runid(); {
+		for exec := n.exec; exec != nil && f.runid() == f.root.interp.runid(); {
 			exec = exec(f)
 		}
 		return
@@ -243,7 +246,7 @@ func runCfg(n *node, f *frame, funcNode, callNode *node) {
 	dbg.enterCall(funcNode, callNode, f)
 	defer dbg.exitCall(funcNode, callNode, f)
 
-	for m, exec := n, n.exec; f.runid() == n.interp.runid(); {
+	for m, exec := n, n.exec; f.runid() == f.root.interp.runid(); {
 		if dbg.exec(m, f) {
 			break
 		}
@@ -326,7 +329,7 @@ func typeAssert(n *node, withResult, withOk bool) {
 			}
 			if !ok {
 				if !withOk {
-					panic(n.cfgErrorf("interface conversion: nil is not %v", typID))
+					panic(n.cfgErrorCodef(ErrTypeMismatch, "interface conversion: nil is not %v", typID))
 				}
 				return next
 			}
@@ -344,7 +347,7 @@ func typeAssert(n *node, withResult, withOk bool) {
 			if len(m0) < len(m1) {
 				ok = false
 				if !withOk {
-					panic(n.cfgErrorf("interface conversion: %v is not %v", v.node.typ.id(), typID))
+					panic(n.cfgErrorCodef(ErrTypeMismatch, "interface conversion: %v is not %v", v.node.typ.id(), typID))
 				}
 				return next
 			}
@@ -435,7 +438,7 @@ func typeAssert(n *node, withResult, withOk bool) {
 			ok = v.IsValid()
 			if !ok {
 				if !withOk {
-					panic(n.cfgErrorf("interface conversion: interface {} is nil, not %s", rtype.String()))
+					panic(n.cfgErrorCodef(ErrTypeMismatch, "interface conversion: interface {} is nil, not %s", rtype.String()))
 				}
 				return next
 			}
@@ -443,7 +446,7 @@ func typeAssert(n *node, withResult, withOk bool) {
 			if !ok {
 				if !withOk {
 					method := firstMissingMethod(leftType, rtype)
-					panic(n.cfgErrorf("interface conversion: %s is not %s: missing method %s", leftType.String(), rtype.String(), method))
+					panic(n.cfgErrorCodef(ErrTypeMismatch, "interface conversion: %s is not %s: missing method %s", leftType.String(), rtype.String(), method))
 				}
 				return next
 			}
@@ -473,9 +476,9 @@ func typeAssert(n *node, withResult, withOk bool) {
 				if !withOk {
 					// TODO(mpl): think about whether this should ever happen.
 					if ctyp == nil {
-						panic(n.cfgErrorf("interface conversion: interface {} is nil, not %s", rtype.String()))
+						panic(n.cfgErrorCodef(ErrTypeMismatch, "interface conversion: interface {} is nil, not %s", rtype.String()))
 					}
-					panic(n.cfgErrorf("interface conversion: interface {} is %s, not %s", ctyp.String(), rtype.String()))
+					panic(n.cfgErrorCodef(ErrTypeMismatch, "interface conversion: interface {} is %s, not %s", ctyp.String(), rtype.String()))
 				}
 				return next
 			}
@@ -500,7 +503,7 @@ func typeAssert(n *node, withResult, withOk bool) {
 			}
 			if !ok {
 				if !withOk {
-					panic(n.cfgErrorf("interface conversion: interface {} is nil, not %s", rtype.String()))
+					panic(n.cfgErrorCodef(ErrTypeMismatch, "interface conversion: interface {} is nil, not %s", rtype.String()))
 				}
 				return next
 			}
@@ -513,7 +516,7 @@ func typeAssert(n *node, withResult, withOk bool) {
 			if !ok {
 				if !withOk {
 					method := firstMissingMethod(v.Type(), rtype)
-					panic(n.cfgErrorf("interface conversion: %s is not %s: missing method %s", v.Type().String(), rtype.String(), method))
+					panic(n.cfgErrorCodef(ErrTypeMismatch, "interface conversion: %s is not %s: missing method %s", v.Type().String(), rtype.String(), method))
 				}
 				return next
 			}
@@ -533,7 +536,7 @@ func typeAssert(n *node, withResult, withOk bool) {
 			if !ok || !v.value.IsValid() {
 				ok = false
 				if !withOk {
-					panic(n.cfgErrorf("interface conversion: interface {} is nil, not %s", rtype.String()))
+					panic(n.cfgErrorCodef(ErrTypeMismatch, "interface conversion: interface {} is nil, not %s", rtype.String()))
 				}
 				return next
 			}
@@ -541,7 +544,7 @@ func typeAssert(n *node, withResult, withOk bool) {
 			ok = canAssertTypes(v.value.Type(), rtype)
 			if !ok {
 				if !withOk {
-					panic(n.cfgErrorf("interface conversion: interface {} is %s, not %s", v.value.Type().String(), rtype.String()))
+					panic(n.cfgErrorCodef(ErrTypeMismatch, "interface conversion: interface {} is %s, not %s", v.value.Type().String(), rtype.String()))
 				}
 				return next
 			}
@@ -713,7 +716,7 @@ func assign(n *node) {
 				d(f).SetMapIndex(i(f), s(f))
 				return next
 			}
-		case n.kind == defineStmt:
+		case n.kind == defineStmt && !isUnboxed(n):
 			l := n.level
 			ind := n.findex
 			n.exec = func(f *frame) bltn {
@@ -751,6 +754,10 @@ func assign(n *node) {
 
 	if n.kind == defineStmt {
 		// Handle a multiple var declararation / assign. It cannot be a swap.
+		unboxed := make([]bool, n.nleft)
+		for i := range unboxed {
+			unboxed[i] = isUnboxed(n.child[i])
+		}
 		n.exec = func(f *frame) bltn {
 			for i, s := range svalue {
 				if n.child[i].ident == "_" {
@@ -758,7 +765,9 @@ func assign(n *node) {
 				}
 				data := getFrame(f, level[i]).data
 				j := index[i]
-				data[j] = reflect.New(data[j].Type()).Elem()
+				if !unboxed[i] {
+					data[j] = reflect.New(data[j].Type()).Elem()
+				}
 				data[j].Set(s(f))
 			}
 			return next
@@ -978,8 +987,6 @@ func genFunctionWrapper(n *node) func(*frame) reflect.Value {
 	if def, ok = n.val.(*node); !ok {
 		return genValueAsFunctionWrapper(n)
 	}
-	start := def.child[3].start
-	numRet := len(def.typ.ret)
 	var rcvr func(*frame) reflect.Value
 
 	if n.recv != nil {
@@ -993,7 +1000,8 @@ func genFunctionWrapper(n *node) func(*frame) reflect.Value {
 		isDefer = true
 	}
 
-	return func(f *frame) reflect.Value {
+	var wrapper func(*frame) reflect.Value
+	wrapper = func(f *frame) reflect.Value {
 		v := value(f)
 		if !isDefer && v.Kind() == reflect.Func {
 			// fixes #1634, if v is already a func, then don't re-wrap
@@ -1001,62 +1009,86 @@ func genFunctionWrapper(n *node) func(*frame) reflect.Value {
 			return v
 		}
 
-		return reflect.MakeFunc(funcType, func(in []reflect.Value) []reflect.Value {
-			// Allocate and init local frame. All values to be settable and addressable.
-			fr := newFrame(f, len(def.types), f.runid())
-			d := fr.data
-			for i, t := range def.types {
-				d[i] = reflect.New(t).Elem()
-			}
+		var recv func() reflect.Value
+		if rcvr != nil {
+			recv = func() reflect.Value { return rcvr(f) }
+		}
+		fct := makeFuncWrapper(n, def, funcType, f, recv)
+		recordFunc(f, fct, wrapper)
+		return fct
+	}
+	return wrapper
+}
 
-			if rcvr == nil {
-				d = d[numRet:]
-			} else {
-				// Copy method receiver as first argument.
-				src, dest := rcvr(f), d[numRet]
-				sk, dk := src.Kind(), dest.Kind()
-				for {
-					vs, ok := src.Interface().(valueInterface)
-					if !ok {
-						break
-					}
-					src = vs.value
-					sk = src.Kind()
-				}
-				switch {
-				case sk == reflect.Ptr && dk != reflect.Ptr:
-					dest.Set(src.Elem())
-				case sk != reflect.Ptr && dk == reflect.Ptr:
-					dest.Set(src.Addr())
-				default:
-					dest.Set(src)
-				}
-				d = d[numRet+1:]
-			}
+// makeFuncWrapper returns a function of type funcType running the interpreted
+// function def, called from n, in a frame child of f. If recv is not nil, it
+// returns the receiver of the method def.
+func makeFuncWrapper(n, def *node, funcType reflect.Type, f *frame, recv func() reflect.Value) reflect.Value {
+	start := def.child[3].start
+	numRet := len(def.typ.ret)
 
-			// Copy function input arguments in local frame.
-			for i, arg := range in {
-				if i >= len(d) {
-					// In case of unused arg, there may be not even a frame entry allocated, just skip.
+	return reflect.MakeFunc(funcType, func(in []reflect.Value) []reflect.Value {
+		// Allocate and init local frame. All values to be settable and addressable.
+		fr := newFrame(f, len(def.types), f.runid())
+		if def.unbox != nil {
+			fr.num = make([]uint64, len(def.types))
+		}
+		d := fr.data
+		for i, t := range def.types {
+			d[i] = newFrameValue(fr, def.unbox, i, t)
+		}
+
+		if recv == nil {
+			d = d[numRet:]
+		} else {
+			// Copy method receiver as first argument.
+			src, dest := recv(), d[numRet]
+			sk, dk := src.Kind(), dest.Kind()
+			for {
+				vs, ok := src.Interface().(valueInterface)
+				if !ok {
 					break
 				}
-				typ := def.typ.arg[i]
-				switch {
-				case isEmptyInterface(typ) || typ.TypeOf() == valueInterfaceType:
-					d[i].Set(arg)
-				case isInterfaceSrc(typ):
-					d[i].Set(reflect.ValueOf(valueInterface{value: arg.Elem()}))
-				default:
-					d[i].Set(arg)
-				}
+				src = vs.value
+				sk = src.Kind()
 			}
+			switch {
+			case sk == reflect.Ptr && dk != reflect.Ptr:
+				dest.Set(src.Elem())
+			case sk != reflect.Ptr && dk == reflect.Ptr:
+				dest.Set(src.Addr())
+			default:
+				dest.Set(src)
+			}
+			d = d[numRet+1:]
+		}
 
-			// Interpreter code execution.
-			runCfg(start, fr, def, n)
+		// Copy function input arguments in local frame.
+		for i, arg := range in {
+			if i >= len(d) {
+				// In case of unused arg, there may be not even a frame entry allocated, just skip.
+				break
+			}
+			typ := def.typ.arg[i]
+			switch {
+			case isEmptyInterface(typ) || typ.TypeOf() == valueInterfaceType:
+				d[i].Set(arg)
+			case isInterfaceSrc(typ):
+				d[i].Set(reflect.ValueOf(valueInterface{value: arg.Elem()}))
+			default:
+				d[i].Set(arg)
+			}
+		}
 
-			return fr.data[:numRet]
-		})
-	}
+		// Interpreter code execution, under the scheduler in deterministic mode.
+		if s := n.interp.sched; s != nil && !s.holds() {
+			s.enter()
+			defer s.leave()
+		}
+		runCfg(start, fr, def, n)
+
+		return fr.data[:numRet]
+	})
 }
 
 func genInterfaceWrapper(n *node, typ reflect.Type) func(*frame) reflect.Value {
@@ -1118,7 +1150,7 @@ func genInterfaceWrapper(n *node, typ reflect.Type) func(*frame) reflect.Value {
 					continue
 				}
 				if n2 == nil {
-					panic(n.cfgErrorf("method not found: %s", names[i]))
+					panic(n.cfgErrorCodef(ErrUndefined, "method not found: %s", names[i]))
 				}
 				// Method lookup in embedded valueInterface.
 				m2, i2 := n2.typ.lookupMethod(names[i])
@@ -1128,7 +1160,7 @@ func genInterfaceWrapper(n *node, typ reflect.Type) func(*frame) reflect.Value {
 					w.Field(i + 1).Set(genFunctionWrapper(&nod)(f))
 					continue
 				}
-				panic(n.cfgErrorf("method not found: %s", names[i]))
+				panic(n.cfgErrorCodef(ErrUndefined, "method not found: %s", names[i]))
 			}
 			nod := *m
 			nod.recv = &receiver{n, v, indexes[i]}
@@ -1332,7 +1364,7 @@ func call(n *node) {
 					in[i].Set(value)
 				}
 
-				go callf(in)
+				goRun(n, f, binFuncName(bf), false, func(g *routine) { g.block(f, n.pos); callf(in) })
 				return tnext
 			}
 
@@ -1340,7 +1372,34 @@ func call(n *node) {
 			for i, v := range values {
 				in[i] = v(f)
 			}
+			p := f.g.block(f, n.pos)
 			out := callf(in)
+			f.g.unblock(p)
+			for i, v := range rvalues {
+				if v != nil {
+					v(f).Set(out[i])
+				}
+			}
+			if fnext != nil && !out[0].Bool() {
+				return fnext
+			}
+			return tnext
+		}
+
+		if def.vm != nil && def.vm.done {
+			// Execute function lowered to bytecode.
+			in := make([]reflect.Value, len(values))
+			for i, v := range values {
+				in[i] = v(f)
+			}
+			if goroutine {
+				goRun(n, f, funcName(def), false, func(*routine) { callVM(n.interp, def.vm, f, in) })
+				return tnext
+			}
+			out := callVM(n.interp, def.vm, f, in)
+			if out == nil {
+				return nil
+			}
 			for i, v := range rvalues {
 				if v != nil {
 					v(f).Set(out[i])
@@ -1353,6 +1412,10 @@ func call(n *node) {
 		}
 
 		nf := newFrame(f, len(def.types), f.runid())
+		nf.pos = n.pos
+		if def.unbox != nil {
+			nf.num = make([]uint64, len(def.types))
+		}
 		var vararg reflect.Value
 
 		// Init return values
@@ -1366,7 +1429,7 @@ func call(n *node) {
 
 		// Init local frame values
 		for i, t := range def.types[numRet:] {
-			nf.data[numRet+i] = reflect.New(t).Elem()
+			nf.data[numRet+i] = newFrameValue(nf, def.unbox, numRet+i, t)
 		}
 
 		// Init variadic argument vector
@@ -1408,7 +1471,10 @@ func call(n *node) {
 
 		// Execute function body
 		if goroutine {
-			go runCfg(def.child[3].start, nf, def, n)
+			goRun(n, f, funcName(def), false, func(g *routine) {
+				nf.g = g
+				runCfg(def.child[3].start, nf, def, n)
+			})
 			return tnext
 		}
 		runCfg(def.child[3].start, nf, def, n)
@@ -1582,7 +1648,8 @@ func callBin(n *node) {
 			for i, v := range values {
 				in[i] = getBinValue(getMapType, v, f)
 			}
-			go callFn(value(f), in)
+			fn := value(f)
+			goRun(n, f, binFuncName(fn), true, func(g *routine) { g.block(f, n.pos); callFn(fn, in) })
 			return tnext
 		}
 	case fnext != nil:
@@ -1594,7 +1661,9 @@ func callBin(n *node) {
 			for i, v := range values {
 				in[i] = getBinValue(getMapType, v, f)
 			}
+			p := f.g.block(f, n.pos)
 			res := callFn(value(f), in)
+			f.g.unblock(p)
 			b := res[0].Bool()
 			getFrame(f, level).data[index].SetBool(b)
 			if b {
@@ -1626,7 +1695,9 @@ func callBin(n *node) {
 				for i, v := range values {
 					in[i] = getBinValue(getMapType, v, f)
 				}
+				p := f.g.block(f, n.pos)
 				out := callFn(value(f), in)
+				f.g.unblock(p)
 				for i, v := range rvalues {
 					if v == nil {
 						continue // Skip assign "_".
@@ -1654,7 +1725,9 @@ func callBin(n *node) {
 				for i, v := range values {
 					in[i] = getBinValue(getMapType, v, f)
 				}
+				p := f.g.block(f, n.pos)
 				out := callFn(value(f), in)
+				f.g.unblock(p)
 				for i, v := range out {
 					dest := f.data[b+i]
 					if _, ok := dest.Interface().(valueInterface); ok {
@@ -1670,7 +1743,9 @@ func callBin(n *node) {
 				for i, v := range values {
 					in[i] = getBinValue(getMapType, v, f)
 				}
+				p := f.g.block(f, n.pos)
 				out := callFn(value(f), in)
+				f.g.unblock(p)
 				for i := 0; i < len(out); i++ {
 					r := out[i]
 					if r.Kind() == reflect.Func {
@@ -1905,7 +1980,8 @@ func getFunc(n *node) {
 	next := getExec(n.tnext)
 	numRet := len(n.typ.ret)
 
-	n.exec = func(f *frame) bltn {
+	var closure func(f *frame) reflect.Value
+	closure = func(f *frame) reflect.Value {
 		fr := f.clone()
 		o := getFrame(f, l).data[i]
 
@@ -1935,7 +2011,11 @@ func getFunc(n *node) {
 				}
 			}
 
-			// Interpreter code execution.
+			// Interpreter code execution, under the scheduler in deterministic mode.
+			if s := n.interp.sched; s != nil && !s.holds() {
+				s.enter()
+				defer s.leave()
+			}
 			runCfg(n.child[3].start, fr2, n, n)
 
 			f.mutex.Lock()
@@ -1944,6 +2024,12 @@ func getFunc(n *node) {
 
 			return fr2.data[:numRet]
 		})
+		recordFunc(f, fct, closure)
+		return fct
+	}
+
+	n.exec = func(f *frame) bltn {
+		fct := closure(f)
 
 		f.mutex.Lock()
 		getFrame(f, l).data[i] = fct
@@ -1995,7 +2081,7 @@ func getMethodByName(n *node) {
 				}
 			}
 			if !ok {
-				panic(n.cfgErrorf("invalid interface value %v", val0))
+				panic(n.cfgErrorCodef(ErrInvalidOperation, "invalid interface value %v", val0))
 			}
 		}
 		// Traverse nested interface values to get the concrete value.
@@ -2017,7 +2103,7 @@ func getMethodByName(n *node) {
 			// It happens with a var of empty interface type, that has value of concrete type
 			// from runtime, being asserted to "user-defined" interface.
 			if _, ok := typ.rtype.MethodByName(name); !ok {
-				panic(n.cfgErrorf("method not found: %s", name))
+				panic(n.cfgErrorCodef(ErrUndefined, "method not found: %s", name))
 			}
 			return next
 		}
@@ -2029,7 +2115,7 @@ func getMethodByName(n *node) {
 			return next
 		}
 		if m == nil {
-			panic(n.cfgErrorf("method not found: %s", name))
+			panic(n.cfgErrorCodef(ErrUndefined, "method not found: %s", name))
 		}
 
 		nod := *m
@@ -2667,7 +2753,7 @@ func compositeBinSlice(n *node) {
 func doCompositeBinStruct(n *node, hasType bool) {
 	next := getExec(n.tnext)
 	value := valueGenerator(n, n.findex)
-	typ := baseType(n.typ).rtype
+	typ := derefType(n.typ).TypeOf()
 	child := n.child
 	if hasType {
 		child = n.child[1:]
@@ -2768,7 +2854,7 @@ func doComposite(n *node, hasType bool, keyed bool) {
 
 	frameIndex := n.findex
 	l := n.level
-	rt := typ.TypeOf()
+	rt := derefType(n.typ).TypeOf()
 
 	n.exec = func(f *frame) bltn {
 		a := reflect.New(rt).Elem()
@@ -2916,23 +3002,25 @@ func rangeInt(n *node) {
 	}
 }
 
-func loopVarKey(n *node) {
-	ixn := n.anc.anc.child[0]
-	next := getExec(n.tnext)
-	n.exec = func(f *frame) bltn {
-		rv := f.data[ixn.findex]
-		nv := reflect.New(rv.Type()).Elem()
-		nv.Set(rv)
-		f.data[n.findex] = nv
-		return next
-	}
-}
+func loopVarKey(n *node) { loopVar(n, n.anc.anc.child[0]) }
+
+func loopVarVal(n *node) { loopVar(n, n.anc.anc.child[1]) }
 
-func loopVarVal(n *node) {
-	vln := n.anc.anc.child[1]
+func loopVarFor(n *node) { loopVar(n, n.anc.anc.child[0].child[0]) }
+
+// loopVar sets a per iteration copy of the loop variable src.
+func loopVar(n, src *node) {
 	next := getExec(n.tnext)
+	if isUnboxed(n) {
+		// The variable can not be captured, the storage can be reused.
+		n.exec = func(f *frame) bltn {
+			f.data[n.findex].Set(f.data[src.findex])
+			return next
+		}
+		return
+	}
 	n.exec = func(f *frame) bltn {
-		rv := f.data[vln.findex]
+		rv := f.data[src.findex]
 		nv := reflect.New(rv.Type()).Elem()
 		nv.Set(rv)
 		f.data[n.findex] = nv
@@ -2940,30 +3028,38 @@ func loopVarVal(n *node) {
 	}
 }
 
-func loopVarFor(n *node) {
-	ixn := n.anc.anc.child[0].child[0]
-	next := getExec(n.tnext)
-	n.exec = func(f *frame) bltn {
-		fv := f.data[ixn.findex]
-		nv := reflect.New(fv.Type()).Elem()
-		nv.Set(fv)
-		f.data[n.findex] = nv
-		return next
-	}
-}
-
 func rangeChan(n *node) {
 	i := n.child[0].findex        // element index location in frame
 	value := genValue(n.child[1]) // chan
 	fnext := getExec(n.fnext)
 	tnext := getExec(n.tnext)
 
+	if s := n.interp.sched; s != nil {
+		// Deterministic channel read.
+		n.exec = func(f *frame) bltn {
+			p := f.g.block(f, n.pos)
+			v, ok, recv := s.recv(frameDone(f), value(f))
+			f.g.unblock(p)
+			if !recv {
+				return nil
+			}
+			if !ok {
+				return fnext
+			}
+			f.data[i].Set(v)
+			return tnext
+		}
+		return
+	}
+
 	n.exec = func(f *frame) bltn {
 		f.mutex.RLock()
 		done := f.done
 		f.mutex.RUnlock()
 
+		p := f.g.block(f, n.pos)
 		chosen, v, ok := reflect.Select([]reflect.SelectCase{done, {Dir: reflect.SelectRecv, Chan: value(f)}})
+		f.g.unblock(p)
 		if chosen == 0 {
 			return nil
 		}
@@ -2985,7 +3081,7 @@ func rangeMap(n *node) {
 	if len(n.child) == 4 && n.child[1].ident != "_" {
 		index1 := n.child[1].findex // map value location in frame
 		n.exec = func(f *frame) bltn {
-			iter := f.data[index2].Interface().(*reflect.MapIter)
+			iter := f.data[index2].Interface().(mapIterator)
 			if !iter.Next() {
 				return fnext
 			}
@@ -2995,7 +3091,7 @@ func rangeMap(n *node) {
 		}
 	} else {
 		n.exec = func(f *frame) bltn {
-			iter := f.data[index2].Interface().(*reflect.MapIter)
+			iter := f.data[index2].Interface().(mapIterator)
 			if !iter.Next() {
 				return fnext
 			}
@@ -3006,6 +3102,14 @@ func rangeMap(n *node) {
 
 	// Init sequence
 	next := n.exec
+	if n.interp.sched != nil {
+		// Iterate in the order of keys in deterministic mode.
+		n.child[0].exec = func(f *frame) bltn {
+			f.data[index2].Set(reflect.ValueOf(newMapIter(value(f))))
+			return next
+		}
+		return
+	}
 	n.child[0].exec = func(f *frame) bltn {
 		f.data[index2].Set(reflect.ValueOf(value(f).MapRange()))
 		return next
@@ -3343,8 +3447,14 @@ func _copy(n *node) {
 func _close(n *node) {
 	in := []func(*frame) reflect.Value{genValue(n.child[1])}
 
+	sched := n.interp.sched
+
 	genBuiltinDeferWrapper(n, in, nil, func(args []reflect.Value) []reflect.Value {
 		args[0].Close()
+		if sched != nil {
+			// Closing unblocks the receivers.
+			sched.progress()
+		}
 		return nil
 	})
 }
@@ -3561,6 +3671,13 @@ func reset(n *node) {
 	case 1:
 		typ := n.child[0].typ.frameType()
 		i := n.child[0].findex
+		if isUnboxed(n.child[0]) {
+			n.exec = func(f *frame) bltn {
+				f.data[i].SetZero()
+				return next
+			}
+			break
+		}
 		n.exec = func(f *frame) bltn {
 			f.data[i] = reflect.New(typ).Elem()
 			return next
@@ -3569,27 +3686,40 @@ func reset(n *node) {
 		c0, c1 := n.child[0], n.child[1]
 		i0, i1 := c0.findex, c1.findex
 		t0, t1 := c0.typ.frameType(), c1.typ.frameType()
+		u0, u1 := isUnboxed(c0), isUnboxed(c1)
 		n.exec = func(f *frame) bltn {
-			f.data[i0] = reflect.New(t0).Elem()
-			f.data[i1] = reflect.New(t1).Elem()
+			resetValue(f, i0, t0, u0)
+			resetValue(f, i1, t1, u1)
 			return next
 		}
 	default:
 		types := make([]reflect.Type, l)
 		index := make([]int, l)
+		unboxed := make([]bool, l)
 		for i, c := range n.child[:l] {
 			index[i] = c.findex
 			types[i] = c.typ.frameType()
+			unboxed[i] = isUnboxed(c)
 		}
 		n.exec = func(f *frame) bltn {
 			for i, ind := range index {
-				f.data[ind] = reflect.New(types[i]).Elem()
+				resetValue(f, ind, types[i], unboxed[i])
 			}
 			return next
 		}
 	}
 }
 
+// resetValue sets a new zero value of type t in frame slot i, or clears the
+// existing storage if unboxed.
+func resetValue(f *frame, i int, t reflect.Type, unboxed bool) {
+	if unboxed {
+		f.data[i].SetZero()
+		return
+	}
+	f.data[i] = reflect.New(t).Elem()
+}
+
 // recv reads from a channel.
 func recv(n *node) {
 	value := genValue(n.child[0])
@@ -3597,6 +3727,25 @@ func recv(n *node) {
 	i := n.findex
 	l := n.level
 
+	if s := n.interp.sched; s != nil {
+		// Deterministic channel read.
+		fnext := getExec(n.fnext)
+		n.exec = func(f *frame) bltn {
+			p := f.g.block(f, n.pos)
+			r, _, recv := s.recv(frameDone(f), value(f))
+			f.g.unblock(p)
+			if !recv {
+				return nil
+			}
+			getFrame(f, l).data[i] = r
+			if fnext != nil && !r.Bool() {
+				return fnext
+			}
+			return tnext
+		}
+		return
+	}
+
 	if n.interp.cancelChan {
 		// Cancellable channel read
 		if n.fnext != nil {
@@ -3616,7 +3765,9 @@ func recv(n *node) {
 				done := f.done
 				f.mutex.RUnlock()
 
+				p := f.g.block(f, n.pos)
 				chosen, v, _ := reflect.Select([]reflect.SelectCase{done, {Dir: reflect.SelectRecv, Chan: ch}})
+				f.g.unblock(p)
 				if chosen == 0 {
 					return nil
 				}
@@ -3639,7 +3790,9 @@ func recv(n *node) {
 				f.mutex.RUnlock()
 
 				var chosen int
+				p := f.g.block(f, n.pos)
 				chosen, getFrame(f, l).data[i], _ = reflect.Select([]reflect.SelectCase{done, {Dir: reflect.SelectRecv, Chan: ch}})
+				f.g.unblock(p)
 				if chosen == 0 {
 					return nil
 				}
@@ -3651,7 +3804,10 @@ func recv(n *node) {
 		if n.fnext != nil {
 			fnext := getExec(n.fnext)
 			n.exec = func(f *frame) bltn {
-				if r, _ := value(f).Recv(); r.Bool() {
+				p := f.g.block(f, n.pos)
+				r, _ := value(f).Recv()
+				f.g.unblock(p)
+				if r.Bool() {
 					getFrame(f, l).data[i] = r
 					return tnext
 				}
@@ -3660,7 +3816,9 @@ func recv(n *node) {
 		} else {
 			i := n.findex
 			n.exec = func(f *frame) bltn {
+				p := f.g.block(f, n.pos)
 				getFrame(f, l).data[i], _ = value(f).Recv()
+				f.g.unblock(p)
 				return tnext
 			}
 		}
@@ -3673,6 +3831,22 @@ func recv2(n *node) {
 	vok := genValue(n.anc.child[1])  // status
 	tnext := getExec(n.tnext)
 
+	if s := n.interp.sched; s != nil {
+		// Deterministic channel read.
+		n.exec = func(f *frame) bltn {
+			p := f.g.block(f, n.pos)
+			v, ok, recv := s.recv(frameDone(f), vchan(f))
+			f.g.unblock(p)
+			if !recv {
+				return nil
+			}
+			vres(f).Set(v)
+			vok(f).SetBool(ok)
+			return tnext
+		}
+		return
+	}
+
 	if n.interp.cancelChan {
 		// Cancellable channel read
 		n.exec = func(f *frame) bltn {
@@ -3688,7 +3862,9 @@ func recv2(n *node) {
 			done := f.done
 			f.mutex.RUnlock()
 
+			p := f.g.block(f, n.pos)
 			chosen, v, ok := reflect.Select([]reflect.SelectCase{done, {Dir: reflect.SelectRecv, Chan: ch}})
+			f.g.unblock(p)
 			if chosen == 0 {
 				return nil
 			}
@@ -3699,7 +3875,9 @@ func recv2(n *node) {
 	} else {
 		// Blocking channel read (less overhead)
 		n.exec = func(f *frame) bltn {
+			p := f.g.block(f, n.pos)
 			v, ok := vchan(f).Recv()
+			f.g.unblock(p)
 			vres(f).Set(v)
 			vok(f).SetBool(ok)
 			return tnext
@@ -3743,7 +3921,7 @@ func convertConstantValue(n *node) {
 	case constant.Int:
 		i, x := constant.Int64Val(c)
 		if !x {
-			panic(n.cfgErrorf("constant %s overflows int64", c.ExactString()))
+			panic(n.cfgErrorCodef(ErrInvalidOperation, "constant %s overflows int64", c.ExactString()))
 		}
 		v = reflect.ValueOf(int(i))
 	case constant.Float:
@@ -3765,10 +3943,26 @@ func send(n *node) {
 	value0 := genValue(c0) // Send channel.
 	value1 := genDestValue(c0.typ.val, c1)
 
+	if s := n.interp.sched; s != nil {
+		// Deterministic send.
+		n.exec = func(f *frame) bltn {
+			p := f.g.block(f, n.pos)
+			sent := s.send(frameDone(f), value0(f), value1(f))
+			f.g.unblock(p)
+			if !sent {
+				return nil
+			}
+			return next
+		}
+		return
+	}
+
 	if !n.interp.cancelChan {
 		// Send is non-cancellable, has the least overhead.
 		n.exec = func(f *frame) bltn {
+			p := f.g.block(f, n.pos)
 			value0(f).Send(value1(f))
+			f.g.unblock(p)
 			return next
 		}
 		return
@@ -3786,7 +3980,9 @@ func send(n *node) {
 		done := f.done
 		f.mutex.RUnlock()
 
+		p := f.g.block(f, n.pos)
 		chosen, _, _ := reflect.Select([]reflect.SelectCase{done, {Dir: reflect.SelectSend, Chan: ch, Send: data}})
+		f.g.unblock(p)
 		if chosen == 0 {
 			return nil
 		}
@@ -3889,8 +4085,17 @@ func _select(n *node) {
 				// Keep zero values for comm clause
 			}
 		}
-		j, v, s := reflect.Select(cases)
-		if j == nbClause {
+		var j int
+		var v reflect.Value
+		var s bool
+		p := f.g.block(f, n.pos)
+		if sched := n.interp.sched; sched != nil {
+			j, v, s = sched.selectCases(frameDone(f), cases[:nbClause])
+		} else {
+			j, v, s = reflect.Select(cases)
+		}
+		f.g.unblock(p)
+		if j < 0 || j == nbClause {
 			return nil
 		}
 		if cases[j].Dir == reflect.SelectRecv && assignedValues[j] != nil {
@@ -0,0 +1,715 @@
+package interp
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// scheduler runs the goroutines of an interpreter one at a time, in a
+// reproducible order, when the interpreter is deterministic.
+//
+// A goroutine runs while holding the token of the scheduler, until it exits
+// or blocks. Blocking operations (channel operations, select statements,
+// sleeps and the locks of package sync) are attempted without blocking, and
+// on failure the token is passed to the next goroutine, in order of arrival.
+// When all goroutines are blocked, the virtual clock is advanced to the next
+// timer.
+type scheduler struct {
+	mu      sync.Mutex
+	running bool            // the token is held
+	queue   []chan struct{} // goroutines waiting for the token, in order
+	n       int             // number of goroutines under the scheduler
+	fails   int             // number of consecutive failed attempts to block
+	wake    chan struct{}   // signaled when the clock is advanced by the host
+	owner   atomic.Uint64   // id of the goroutine holding the token, or 0
+
+	omu    sync.Mutex
+	offers map[uintptr][]*offer // offers of pending selects, by channel
+
+	clock      *Clock
+	goroutines *goroutines   // tracking of the goroutines started by timers
+	rnd        *rand.Rand    // choice of ready select cases
+	rand       *rand.Rand    // math/rand
+	rand2      reflect.Value // math/rand/v2, invalid if not available
+}
+
+// owners are the schedulers by id of the goroutines holding their token.
+var owners sync.Map
+
+// syncMu protects the state of the replacements of package sync types.
+var syncMu sync.Mutex
+
+func newScheduler(seed int64, start time.Time) *scheduler {
+	if start.IsZero() {
+		start = time.Date(2009, 11, 10, 23, 0, 0, 0, time.UTC)
+	}
+	s := &scheduler{
+		wake:   make(chan struct{}, 1),
+		offers: map[uintptr][]*offer{},
+		rnd:    rand.New(rand.NewSource(seed)),
+		rand:   rand.New(rand.NewSource(seed)),
+		rand2:  newRandV2(seed),
+	}
+	s.clock = &Clock{now: start, sched: s}
+	return s
+}
+
+// goid returns the id of the current goroutine.
+func goid() uint64 {
+	var buf [32]byte
+	b := bytes.TrimPrefix(buf[:runtime.Stack(buf[:], false)], []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
+
+// current returns the scheduler whose token is held by the current goroutine, or nil.
+func current() *scheduler {
+	if s, ok := owners.Load(goid()); ok {
+		return s.(*scheduler)
+	}
+	return nil
+}
+
+// holds returns true if the current goroutine holds the token of s.
+func (s *scheduler) holds() bool { return s.owner.Load() == goid() }
+
+// enter puts the current goroutine under the scheduler, and waits for its turn.
+func (s *scheduler) enter() {
+	s.mu.Lock()
+	s.n++
+	s.fails = 0
+	s.acquire()
+}
+
+// leave removes the current goroutine from the scheduler, passing the token.
+func (s *scheduler) leave() {
+	s.mu.Lock()
+	s.n--
+	s.fails = 0
+	s.release()
+	s.mu.Unlock()
+}
+
+// spawn runs fn in a new goroutine under the scheduler, after the ones
+// already waiting for their turn.
+func (s *scheduler) spawn(fn func()) {
+	s.mu.Lock()
+	s.n++
+	s.fails = 0
+	w := make(chan struct{})
+	if s.running {
+		s.queue = append(s.queue, w)
+	} else {
+		s.running = true
+		close(w)
+	}
+	s.mu.Unlock()
+	go func() {
+		<-w
+		s.own()
+		defer s.leave()
+		fn()
+	}()
+}
+
+// yield passes the token to the next goroutine, and waits for the next turn.
+func (s *scheduler) yield() {
+	s.mu.Lock()
+	s.release()
+	s.acquire()
+}
+
+// acquire waits for the token. It is called with s.mu locked, and unlocks it.
+func (s *scheduler) acquire() {
+	if !s.running {
+		s.running = true
+		s.mu.Unlock()
+		s.own()
+		return
+	}
+	w := make(chan struct{})
+	s.queue = append(s.queue, w)
+	s.mu.Unlock()
+	<-w
+	s.own()
+}
+
+// own records the current goroutine as the holder of the token.
+func (s *scheduler) own() {
+	id := goid()
+	s.owner.Store(id)
+	owners.Store(id, s)
+}
+
+// release passes the token to the next goroutine waiting, if any.
+// It is called with s.mu locked.
+func (s *scheduler) release() {
+	if id := s.owner.Swap(0); id != 0 {
+		owners.Delete(id)
+	}
+	if len(s.queue) == 0 {
+		s.running = false
+		return
+	}
+	w := s.queue[0]
+	s.queue = s.queue[1:]
+	close(w)
+}
+
+// progress records a change of state which may unblock goroutines.
+func (s *scheduler) progress() {
+	s.mu.Lock()
+	s.fails = 0
+	s.mu.Unlock()
+}
+
+// wait runs try until it succeeds, letting the other goroutines run in
+// between. It returns false if done is closed before.
+func (s *scheduler) wait(done <-chan struct{}, try func() bool) bool {
+	for !try() {
+		select {
+		case <-done:
+			return false
+		default:
+		}
+		if !s.holds() {
+			// Not a goroutine of the interpreter, run by a binary package.
+			time.Sleep(time.Millisecond)
+			continue
+		}
+		s.block()
+	}
+	s.progress()
+	return true
+}
+
+// block lets the other goroutines run after a failed attempt. If all the
+// goroutines are blocked, the clock is advanced to the next timer, or
+// else it waits for an external event.
+func (s *scheduler) block() {
+	s.mu.Lock()
+	s.fails++
+	idle := s.fails >= s.n
+	s.mu.Unlock()
+	if idle && !s.clock.next() {
+		select {
+		case <-s.wake:
+		case <-time.After(time.Millisecond):
+		}
+	}
+	s.yield()
+}
+
+// schedWait runs try until it succeeds, under the scheduler of the current
+// goroutine, if any.
+func schedWait(try func() bool) {
+	locked := func() bool {
+		syncMu.Lock()
+		defer syncMu.Unlock()
+		return try()
+	}
+	if s := current(); s != nil {
+		s.wait(nil, locked)
+		return
+	}
+	for !locked() {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// schedProgress records a change of state of a replacement of a package sync type.
+func schedProgress() {
+	if s := current(); s != nil {
+		s.progress()
+	}
+}
+
+// frameDone returns the channel closed when the run of frame f is cancelled.
+func frameDone(f *frame) <-chan struct{} {
+	f.mutex.RLock()
+	c := f.done.Chan
+	f.mutex.RUnlock()
+	if !c.IsValid() || c.IsNil() {
+		return nil
+	}
+	return c.Interface().(chan struct{})
+}
+
+// recv receives a value from channel ch, as TryRecv. It returns false if
+// done is closed before.
+func (s *scheduler) recv(done <-chan struct{}, ch reflect.Value) (v reflect.Value, ok, recv bool) {
+	i, v, ok := s.selectCases(done, []reflect.SelectCase{{Dir: reflect.SelectRecv, Chan: ch}})
+	return v, ok, i == 0
+}
+
+// send sends v on channel ch. It returns false if done is closed before.
+func (s *scheduler) send(done <-chan struct{}, ch, v reflect.Value) bool {
+	i, _, _ := s.selectCases(done, []reflect.SelectCase{{Dir: reflect.SelectSend, Chan: ch, Send: v}})
+	return i == 0
+}
+
+// pending is a blocked select, or channel operation, of a goroutine.
+type pending struct {
+	done   bool
+	chosen int
+	v      reflect.Value
+	ok     bool
+}
+
+// offer is a case of a pending select. As the goroutines under the
+// scheduler never block in a channel operation, the communications on
+// unbuffered channels are performed by matching offers.
+type offer struct {
+	p     *pending
+	index int
+	c     reflect.SelectCase
+}
+
+// selectCases performs a select on cases, the ready one being chosen in a
+// reproducible pseudo-random order. It returns -1 if done is closed before.
+func (s *scheduler) selectCases(done <-chan struct{}, cases []reflect.SelectCase) (chosen int, v reflect.Value, ok bool) {
+	def := -1
+	for i, c := range cases {
+		if c.Dir == reflect.SelectDefault {
+			def = i
+		}
+	}
+	p := &pending{}
+	registered := false
+	try := func() bool {
+		s.omu.Lock()
+		defer s.omu.Unlock()
+		if p.done {
+			return true
+		}
+		for _, i := range s.rnd.Perm(len(cases)) {
+			c := cases[i]
+			if c.Dir == reflect.SelectDefault || c.Chan.IsNil() {
+				continue
+			}
+			if j, r, rok := reflect.Select([]reflect.SelectCase{c, {Dir: reflect.SelectDefault}}); j == 0 {
+				p.done, p.chosen, p.v, p.ok = true, i, r, rok
+				return true
+			}
+			if s.match(p, i, c) {
+				return true
+			}
+		}
+		if def >= 0 {
+			p.done, p.chosen = true, def
+			return true
+		}
+		if !registered {
+			registered = true
+			for i, c := range cases {
+				if c.Dir != reflect.SelectDefault && !c.Chan.IsNil() {
+					k := c.Chan.Pointer()
+					s.offers[k] = append(s.offers[k], &offer{p: p, index: i, c: c})
+				}
+			}
+		}
+		return false
+	}
+	res := s.wait(done, try)
+
+	s.omu.Lock()
+	defer s.omu.Unlock()
+	if registered {
+		for _, c := range cases {
+			if c.Dir != reflect.SelectDefault && !c.Chan.IsNil() {
+				s.removeOffers(c.Chan.Pointer(), p)
+			}
+		}
+	}
+	if !res && !p.done {
+		p.done = true // cancelled
+		return -1, v, false
+	}
+	return p.chosen, p.v, p.ok
+}
+
+// match completes the case c of index i of p with a matching offer of another
+// goroutine, if any. It is called with s.omu locked.
+func (s *scheduler) match(p *pending, i int, c reflect.SelectCase) bool {
+	for _, o := range s.offers[c.Chan.Pointer()] {
+		if o.p == p || o.p.done || o.c.Dir == c.Dir {
+			continue
+		}
+		switch c.Dir {
+		case reflect.SelectRecv:
+			v := reflect.New(c.Chan.Type().Elem()).Elem()
+			v.Set(o.c.Send)
+			o.p.done, o.p.chosen = true, o.index
+			p.done, p.chosen, p.v, p.ok = true, i, v, true
+		case reflect.SelectSend:
+			v := reflect.New(o.c.Chan.Type().Elem()).Elem()
+			v.Set(c.Send)
+			o.p.done, o.p.chosen, o.p.v, o.p.ok = true, o.index, v, true
+			p.done, p.chosen = true, i
+		}
+		return true
+	}
+	return false
+}
+
+// removeOffers removes the offers of p on the channel k. It is called with
+// s.omu locked.
+func (s *scheduler) removeOffers(k uintptr, p *pending) {
+	l := s.offers[k][:0]
+	for _, o := range s.offers[k] {
+		if o.p != p {
+			l = append(l, o)
+		}
+	}
+	if len(l) == 0 {
+		delete(s.offers, k)
+		return
+	}
+	s.offers[k] = l
+}
+
+// mapIterator iterates over a map, as reflect.MapIter.
+type mapIterator interface {
+	Next() bool
+	Key() reflect.Value
+	Value() reflect.Value
+}
+
+// mapIter iterates over a map in the order of its keys, used by range
+// statements when the interpreter is deterministic.
+type mapIter struct {
+	m    reflect.Value
+	keys []reflect.Value
+	key  reflect.Value
+}
+
+func newMapIter(m reflect.Value) *mapIter {
+	keys := m.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return mapKeyLess(keys[i], keys[j]) })
+	return &mapIter{m: m, keys: keys}
+}
+
+// mapKeyLess orders map keys, by type then by value.
+func mapKeyLess(a, b reflect.Value) bool {
+	if a.Kind() == reflect.Interface {
+		a, b = a.Elem(), b.Elem()
+	}
+	switch {
+	case !a.IsValid() || !b.IsValid():
+		return !a.IsValid() && b.IsValid()
+	case a.Type() != b.Type():
+		return a.Type().String() < b.Type().String()
+	case a.Kind() == reflect.Bool:
+		return !a.Bool() && b.Bool()
+	}
+	return keyLess(a, b)
+}
+
+func (it *mapIter) Next() bool {
+	for len(it.keys) > 0 {
+		k := it.keys[0]
+		it.keys = it.keys[1:]
+		if it.m.MapIndex(k).IsValid() { // skip entries deleted during iteration
+			it.key = k
+			return true
+		}
+	}
+	return false
+}
+
+func (it *mapIter) Key() reflect.Value   { return it.key }
+func (it *mapIter) Value() reflect.Value { return it.m.MapIndex(it.key) }
+
+// mapIterType returns the type of the map iterators of range statements.
+func (interp *Interpreter) mapIterType() reflect.Type {
+	if interp.sched != nil {
+		return reflect.TypeOf((*mapIter)(nil))
+	}
+	return reflect.TypeOf((*reflect.MapIter)(nil))
+}
+
+// fixDeterministic replaces the symbols of the time, math/rand, sync and
+// runtime packages by deterministic versions, see Options.Deterministic.
+func fixDeterministic(interp *Interpreter) {
+	s := interp.sched
+	c := s.clock
+	done := func() <-chan struct{} {
+		interp.mutex.RLock()
+		defer interp.mutex.RUnlock()
+		return interp.done
+	}
+
+	if p := interp.binPkg["time"]; p != nil {
+		p["Now"] = reflect.ValueOf(c.Now)
+		p["Since"] = reflect.ValueOf(func(t time.Time) time.Duration { return c.Now().Sub(t) })
+		p["Until"] = reflect.ValueOf(func(t time.Time) time.Duration { return t.Sub(c.Now()) })
+		p["Sleep"] = reflect.ValueOf(func(d time.Duration) {
+			if !s.holds() {
+				time.Sleep(d)
+				return
+			}
+			if d <= 0 {
+				s.yield()
+				return
+			}
+			t := c.newTimer(d, 0, nil)
+			s.recv(done(), reflect.ValueOf(t.c))
+		})
+		p["After"] = reflect.ValueOf(func(d time.Duration) <-chan time.Time { return c.NewTimer(d).C })
+		p["AfterFunc"] = reflect.ValueOf(c.AfterFunc)
+		p["NewTimer"] = reflect.ValueOf(c.NewTimer)
+		p["NewTicker"] = reflect.ValueOf(c.NewTicker)
+		p["Tick"] = reflect.ValueOf(func(d time.Duration) <-chan time.Time {
+			if d <= 0 {
+				return nil
+			}
+			return c.NewTicker(d).C
+		})
+		p["Timer"] = reflect.ValueOf((*Timer)(nil))
+		p["Ticker"] = reflect.ValueOf((*Ticker)(nil))
+	}
+
+	// Replace the top level functions of math/rand by the methods of a seeded generator.
+	for path, r := range map[string]reflect.Value{"math/rand": reflect.ValueOf(s.rand), "math/rand/v2": s.rand2} {
+		p := interp.binPkg[path]
+		if !r.IsValid() {
+			continue
+		}
+		for name, v := range p {
+			if v.Kind() != reflect.Func {
+				continue
+			}
+			if m := r.MethodByName(name); m.IsValid() && m.Type() == v.Type() {
+				p[name] = m
+			}
+		}
+	}
+
+	if p := interp.binPkg["sync"]; p != nil {
+		p["Mutex"] = reflect.ValueOf((*syncMutex)(nil))
+		p["RWMutex"] = reflect.ValueOf((*syncRWMutex)(nil))
+		p["WaitGroup"] = reflect.ValueOf((*syncWaitGroup)(nil))
+		p["Once"] = reflect.ValueOf((*syncOnce)(nil))
+		p["Cond"] = reflect.ValueOf((*syncCond)(nil))
+		p["NewCond"] = reflect.ValueOf(func(l sync.Locker) *syncCond { return &syncCond{L: l} })
+	}
+
+	if p := interp.binPkg["runtime"]; p != nil {
+		p["Gosched"] = reflect.ValueOf(func() {
+			if s.holds() {
+				s.yield()
+			} else {
+				runtime.Gosched()
+			}
+		})
+	}
+}
+
+// syncMutex replaces sync.Mutex when the interpreter is deterministic.
+type syncMutex struct{ locked bool }
+
+func (m *syncMutex) Lock() { schedWait(m.tryLock) }
+
+// tryLock locks m if possible, with syncMu locked.
+func (m *syncMutex) tryLock() bool {
+	if m.locked {
+		return false
+	}
+	m.locked = true
+	return true
+}
+
+func (m *syncMutex) TryLock() bool {
+	syncMu.Lock()
+	defer syncMu.Unlock()
+	return m.tryLock()
+}
+
+func (m *syncMutex) Unlock() {
+	syncMu.Lock()
+	locked := m.locked
+	m.locked = false
+	syncMu.Unlock()
+	if !locked {
+		panic("sync: unlock of unlocked mutex")
+	}
+	schedProgress()
+}
+
+// syncRWMutex replaces sync.RWMutex when the interpreter is deterministic.
+type syncRWMutex struct {
+	writer  bool
+	readers int
+}
+
+func (rw *syncRWMutex) Lock() {
+	schedWait(func() bool {
+		if rw.writer || rw.readers > 0 {
+			return false
+		}
+		rw.writer = true
+		return true
+	})
+}
+
+func (rw *syncRWMutex) RLock() {
+	schedWait(func() bool {
+		if rw.writer {
+			return false
+		}
+		rw.readers++
+		return true
+	})
+}
+
+func (rw *syncRWMutex) TryLock() bool {
+	syncMu.Lock()
+	defer syncMu.Unlock()
+	if rw.writer || rw.readers > 0 {
+		return false
+	}
+	rw.writer = true
+	return true
+}
+
+func (rw *syncRWMutex) TryRLock() bool {
+	syncMu.Lock()
+	defer syncMu.Unlock()
+	if rw.writer {
+		return false
+	}
+	rw.readers++
+	return true
+}
+
+func (rw *syncRWMutex) Unlock() {
+	syncMu.Lock()
+	writer := rw.writer
+	rw.writer = false
+	syncMu.Unlock()
+	if !writer {
+		panic("sync: Unlock of unlocked RWMutex")
+	}
+	schedProgress()
+}
+
+func (rw *syncRWMutex) RUnlock() {
+	syncMu.Lock()
+	readers := rw.readers
+	if readers > 0 {
+		rw.readers--
+	}
+	syncMu.Unlock()
+	if readers == 0 {
+		panic("sync: RUnlock of unlocked RWMutex")
+	}
+	schedProgress()
+}
+
+func (rw *syncRWMutex) RLocker() sync.Locker { return rlocker{rw} }
+
+type rlocker struct{ rw *syncRWMutex }
+
+func (r rlocker) Lock()   { r.rw.RLock() }
+func (r rlocker) Unlock() { r.rw.RUnlock() }
+
+// syncWaitGroup replaces sync.WaitGroup when the interpreter is deterministic.
+type syncWaitGroup struct{ n int }
+
+func (wg *syncWaitGroup) Add(delta int) {
+	syncMu.Lock()
+	wg.n += delta
+	n := wg.n
+	syncMu.Unlock()
+	if n < 0 {
+		panic("sync: negative WaitGroup counter")
+	}
+	schedProgress()
+}
+
+func (wg *syncWaitGroup) Done() { wg.Add(-1) }
+
+func (wg *syncWaitGroup) Wait() { schedWait(func() bool { return wg.n == 0 }) }
+
+func (wg *syncWaitGroup) Go(f func()) {
+	wg.Add(1)
+	fn := func() {
+		defer wg.Done()
+		f()
+	}
+	if s := current(); s != nil {
+		s.spawn(fn)
+		return
+	}
+	go fn()
+}
+
+// syncOnce replaces sync.Once when the interpreter is deterministic.
+type syncOnce struct{ done, running bool }
+
+func (o *syncOnce) Do(f func()) {
+	var done bool
+	schedWait(func() bool {
+		if o.running {
+			return false
+		}
+		done, o.running = o.done, !o.done
+		return true
+	})
+	if done {
+		return
+	}
+	defer func() {
+		syncMu.Lock()
+		o.done, o.running = true, false
+		syncMu.Unlock()
+		schedProgress()
+	}()
+	f()
+}
+
+// syncCond replaces sync.Cond when the interpreter is deterministic.
+type syncCond struct {
+	L       sync.Locker
+	waiters []*bool
+}
+
+func (c *syncCond) Wait() {
+	w := new(bool)
+	syncMu.Lock()
+	c.waiters = append(c.waiters, w)
+	syncMu.Unlock()
+	c.L.Unlock()
+	schedWait(func() bool { return *w })
+	c.L.Lock()
+}
+
+func (c *syncCond) Signal() {
+	syncMu.Lock()
+	if len(c.waiters) > 0 {
+		*c.waiters[0] = true
+		c.waiters = c.waiters[1:]
+	}
+	syncMu.Unlock()
+	schedProgress()
+}
+
+func (c *syncCond) Broadcast() {
+	syncMu.Lock()
+	for _, w := range c.waiters {
+		*w = true
+	}
+	c.waiters = nil
+	syncMu.Unlock()
+	schedProgress()
+}
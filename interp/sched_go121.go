@@ -0,0 +1,8 @@
+//go:build !go1.22
+
+package interp
+
+import "reflect"
+
+// newRandV2 returns an invalid value, as math/rand/v2 is not available.
+func newRandV2(seed int64) reflect.Value { return reflect.Value{} }
@@ -0,0 +1,13 @@
+//go:build go1.22
+
+package interp
+
+import (
+	"math/rand/v2"
+	"reflect"
+)
+
+// newRandV2 returns a math/rand/v2 generator seeded with seed.
+func newRandV2(seed int64) reflect.Value {
+	return reflect.ValueOf(rand.New(rand.NewPCG(uint64(seed), uint64(seed))))
+}
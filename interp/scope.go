@@ -4,6 +4,7 @@ import (
 	"log"
 	"reflect"
 	"strconv"
+	"sync"
 )
 
 // A sKind represents the kind of symbol.
@@ -55,6 +56,7 @@ type symbol struct {
 	rval    reflect.Value // default value (used for constants)
 	builtin bltnGenerator // Builtin function or nil
 	global  bool          // true if symbol is defined in global space
+	folded  bool          // true if constant value is inferred by the optimizer, not declared
 }
 
 // scope type stores symbols in maps, and frame layout as array of types
@@ -85,6 +87,7 @@ type scope struct {
 	sym         map[string]*symbol // map of symbols defined in this current scope
 	global      bool               // true if scope refers to global space (single frame for universe and package level scopes)
 	iota        int                // iota value in this scope
+	mu          *sync.Mutex        // in universe, guards the state shared by the packages compiled concurrently
 }
 
 // push creates a new child scope and chain it to the current one.
@@ -110,7 +113,7 @@ func (s *scope) pushBloc() *scope { return s.push(false) }
 func (s *scope) pushFunc() *scope { return s.push(true) }
 
 func (s *scope) pop() *scope {
-	if s.level == s.anc.level {
+	if s.level == s.anc.level && !s.global {
 		// Propagate size and types, as scopes at same level share the same frame.
 		s.anc.types = s.types
 	}
@@ -214,15 +217,34 @@ func (s *scope) add(typ *itype) (index int) {
 	if typ == nil {
 		log.Panic("nil type")
 	}
-	index = len(s.types)
 	t := typ.frameType()
 	if t == nil {
 		log.Panic("nil reflect type")
 	}
+	if s.global {
+		// The global frame is shared by all the packages, which may be
+		// compiled concurrently: its layout is kept in the universe.
+		u := s.universe()
+		u.mu.Lock()
+		index = len(u.types)
+		u.types = append(u.types, t)
+		s.types = u.types
+		u.mu.Unlock()
+		return
+	}
+	index = len(s.types)
 	s.types = append(s.types, t)
 	return
 }
 
+// universe returns the root scope.
+func (s *scope) universe() *scope {
+	for s.anc != nil {
+		s = s.anc
+	}
+	return s
+}
+
 func (interp *Interpreter) initScopePkg(pkgID, pkgName string) *scope {
 	sc := interp.universe
 
@@ -3,10 +3,21 @@ package interp
 import (
 	"errors"
 	"fmt"
+	"go/ast"
+	"go/build"
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"go/types"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 )
 
 // importSrc calls gta on the source code for the package identified by
@@ -19,72 +30,154 @@ func (interp *Interpreter) importSrc(rPath, importPath string, skipTest bool) (s
 	if interp.srcPkg[importPath] != nil {
 		name, ok := interp.pkgNames[importPath]
 		if !ok {
-			return "", fmt.Errorf("inconsistent knowledge about %s", importPath)
+			return "", importErrorf("inconsistent knowledge about %s", importPath)
 		}
 		return name, nil
 	}
 
-	// For relative import paths in the form "./xxx" or "../xxx", the initial
-	// base path is the directory of the interpreter input file, or "." if no file
-	// was provided.
-	// In all other cases, absolute import paths are resolved from the GOPATH
-	// and the nested "vendor" directories.
-	if isPathRelative(importPath) {
-		if rPath == mainID {
-			rPath = "."
-		}
-		dir = filepath.Join(filepath.Dir(interp.name), rPath, importPath)
-	} else if dir, rPath, err = interp.pkgDir(interp.context.GOPATH, rPath, importPath); err != nil {
-		// Try again, assuming a root dir at the source location.
-		if rPath, err = interp.rootFromSourceLocation(); err != nil {
-			return "", err
-		}
-		if dir, rPath, err = interp.pkgDir(interp.context.GOPATH, rPath, importPath); err != nil {
+	if dir, rPath, err = interp.srcDir(rPath, importPath); err != nil {
+		return "", err
+	}
+
+	if !interp.parsed.visited(dir) {
+		// Parse the package and its dependencies, and compile the leaf
+		// packages, ahead of processing them.
+		interp.preload(srcImport{rPath: rPath, importPath: importPath, dir: dir, skipTest: skipTest})
+		defer interp.parsed.release()
+	}
+
+	p := interp.parsed.compiled(importPath, dir)
+	if p == nil {
+		if err = interp.setLang(importPath, dir); err != nil {
 			return "", err
 		}
 	}
 
 	if interp.rdir[importPath] {
-		return "", fmt.Errorf("import cycle not allowed\n\timports %s", importPath)
+		return "", importErrorf("import cycle not allowed\n\timports %s", importPath)
 	}
 	interp.rdir[importPath] = true
 
-	files, err := fs.ReadDir(interp.opt.filesystem, dir)
+	if p == nil {
+		p = interp.compilePkg(rPath, importPath, dir, skipTest)
+	}
+	if p.panic != nil {
+		panic(p.panic)
+	}
+	if p.err != nil {
+		return "", p.err
+	}
+	pkgName, rootNodes, initNodes := p.name, p.roots, p.inits
+
+	// Register source package in the interpreter. The package contains only
+	// the global symbols in the package scope.
+	interp.mutex.Lock()
+	gs := interp.scopes[importPath]
+	if gs == nil {
+		interp.mutex.Unlock()
+		// A nil scope means that no even an empty package is created from source.
+		return "", importErrorf("no Go files in %s", dir)
+	}
+	interp.srcPkg[importPath] = gs.sym
+	interp.pkgNames[importPath] = pkgName
+
+	interp.frame.mutex.Lock()
+	interp.resizeFrame()
+	interp.frame.mutex.Unlock()
+	interp.mutex.Unlock()
+
+	// Once all package sources have been parsed, execute entry points then init functions.
+	for _, n := range rootNodes {
+		if err = genRun(n); err != nil {
+			return "", err
+		}
+		if !interp.noRun {
+			interp.runInit(n, false)
+		}
+	}
+	if interp.noRun {
+		return pkgName, nil
+	}
+
+	// Wire and execute global vars in global scope gs.
+	n, err := genGlobalVars(rootNodes, gs)
 	if err != nil {
 		return "", err
 	}
+	interp.runInit(n, false)
+
+	for _, n := range initNodes {
+		interp.runInit(n, true)
+	}
+
+	// Run main after all inits.
+	if m := gs.sym[mainID]; pkgName == mainID && m != nil && skipTest {
+		interp.run(m.node, interp.frame)
+	}
+
+	return pkgName, nil
+}
+
+// compiledPkg is a source package parsed and compiled, to be registered and
+// initialized by importSrc.
+type compiledPkg struct {
+	importPath string      // package import path, if compiled ahead
+	name       string      // package name
+	roots      []*node     // roots of the files
+	inits      []*node     // init functions
+	err        error       // compilation error
+	panic      interface{} // panic of the compilation, if compiled ahead
+}
+
+// compilePkg parses the source files of package importPath in dir, and
+// performs the global types analysis and the CFG of the package.
+func (interp *Interpreter) compilePkg(rPath, importPath, dir string, skipTest bool) *compiledPkg {
+	p := &compiledPkg{}
+	files, err := fs.ReadDir(interp.opt.filesystem, dir)
+	if err != nil {
+		p.err = err
+		return p
+	}
 
-	var initNodes []*node
-	var rootNodes []*node
 	revisit := make(map[string][]*node)
 
 	var root *node
-	var pkgName string
 
 	// Parse source files.
+	ctx := interp.asmContext(dir, files, skipTest)
 	for _, file := range files {
 		name := file.Name()
-		if skipFile(&interp.context, name, skipTest) {
+		if skipFile(ctx, name, skipTest) {
 			continue
 		}
 
 		name = filepath.Join(dir, name)
 		var buf []byte
 		if buf, err = fs.ReadFile(interp.opt.filesystem, name); err != nil {
-			return "", err
+			p.err = err
+			return p
 		}
 
-		n, err := interp.parse(string(buf), name, false)
+		n, err := interp.parseWith(ctx, string(buf), name, false)
 		if err != nil {
-			return "", err
+			if interp.report(err) {
+				continue
+			}
+			p.err = err
+			return p
 		}
 		if n == nil {
 			continue
 		}
+		interp.upgradeLang(importPath, name, string(buf))
 
 		var pname string
 		if pname, root, err = interp.ast(n); err != nil {
-			return "", err
+			if interp.report(err) {
+				continue
+			}
+			p.err = err
+			return p
 		}
 		if root == nil {
 			continue
@@ -97,80 +190,242 @@ func (interp *Interpreter) importSrc(rPath, importPath string, skipTest bool) (s
 			}
 			root.astDot(dotWriter(dotCmd), name)
 		}
-		if pkgName == "" {
-			pkgName = pname
-		} else if pkgName != pname && skipTest {
-			return "", fmt.Errorf("found packages %s and %s in %s", pkgName, pname, dir)
+		if p.name == "" {
+			p.name = pname
+		} else if p.name != pname && skipTest {
+			p.err = importErrorf("found packages %s and %s in %s", p.name, pname, dir)
+			return p
 		}
-		rootNodes = append(rootNodes, root)
+		p.roots = append(p.roots, root)
 
 		subRPath := effectivePkg(rPath, importPath)
 		var list []*node
-		list, err = interp.gta(root, subRPath, importPath, pkgName)
+		list, err = interp.gta(root, subRPath, importPath, p.name)
 		if err != nil {
-			return "", err
+			if interp.report(err) {
+				continue
+			}
+			p.err = err
+			return p
 		}
 		revisit[subRPath] = append(revisit[subRPath], list...)
 	}
 
 	// Revisit incomplete nodes where GTA could not complete.
 	for _, nodes := range revisit {
-		if err = interp.gtaRetry(nodes, importPath, pkgName); err != nil {
-			return "", err
+		if err = interp.gtaRetry(nodes, importPath, p.name); err != nil && !interp.report(err) {
+			p.err = err
+			return p
 		}
 	}
 
 	// Generate control flow graphs.
-	for _, root := range rootNodes {
+	for _, root := range p.roots {
 		var nodes []*node
-		if nodes, err = interp.cfg(root, nil, importPath, pkgName); err != nil {
-			return "", err
+		if nodes, err = interp.cfg(root, nil, importPath, p.name); err != nil && !interp.report(err) {
+			p.err = err
+			return p
 		}
-		initNodes = append(initNodes, nodes...)
+		p.inits = append(p.inits, nodes...)
 	}
 
-	// Register source package in the interpreter. The package contains only
-	// the global symbols in the package scope.
-	interp.mutex.Lock()
-	gs := interp.scopes[importPath]
-	if gs == nil {
-		interp.mutex.Unlock()
-		// A nil scope means that no even an empty package is created from source.
-		return "", fmt.Errorf("no Go files in %s", dir)
-	}
-	interp.srcPkg[importPath] = gs.sym
-	interp.pkgNames[importPath] = pkgName
+	return p
+}
 
-	interp.frame.mutex.Lock()
-	interp.resizeFrame()
-	interp.frame.mutex.Unlock()
-	interp.mutex.Unlock()
+// asmContext returns the build context selecting the source files of the
+// package in dir. If the package has assembly files, and functions declared
+// without body in the files selected by the interpreter build context, the
+// files selected with the purego and noasm build tags are preferred, if they
+// declare all those functions with a body.
+func (interp *Interpreter) asmContext(dir string, files []fs.DirEntry, skipTest bool) *build.Context {
+	hasAsm := false
+	for _, f := range files {
+		if strings.HasSuffix(f.Name(), ".s") {
+			hasAsm = true
+			break
+		}
+	}
+	if !hasAsm {
+		return &interp.context
+	}
+	missing, _ := interp.funcBodies(&interp.context, dir, files, skipTest)
+	if len(missing) == 0 {
+		return &interp.context
+	}
+	ctx := interp.context
+	ctx.BuildTags = append(append([]string{}, ctx.BuildTags...), "purego", "noasm")
+	pureMissing, defined := interp.funcBodies(&ctx, dir, files, skipTest)
+	if len(pureMissing) > 0 {
+		return &interp.context
+	}
+	for name := range missing {
+		if !defined[name] {
+			return &interp.context
+		}
+	}
+	return &ctx
+}
 
-	// Once all package sources have been parsed, execute entry points then init functions.
-	for _, n := range rootNodes {
-		if err = genRun(n); err != nil {
-			return "", err
+// funcBodies returns the names of the functions declared without and with
+// a body in the source files of the package in dir selected by ctx. Method
+// names are prefixed by their receiver type.
+func (interp *Interpreter) funcBodies(ctx *build.Context, dir string, files []fs.DirEntry, skipTest bool) (without, with map[string]bool) {
+	without, with = map[string]bool{}, map[string]bool{}
+	for _, file := range files {
+		name := file.Name()
+		if skipFile(ctx, name, skipTest) {
+			continue
+		}
+		name = filepath.Join(dir, name)
+		buf, err := fs.ReadFile(interp.opt.filesystem, name)
+		if err != nil {
+			continue
+		}
+		if ok, err := interp.buildOk(ctx, name, string(buf)); !ok || err != nil {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), name, buf, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		for _, d := range f.Decls {
+			fd, ok := d.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			key := fd.Name.Name
+			if fd.Recv != nil && len(fd.Recv.List) > 0 {
+				key = types.ExprString(fd.Recv.List[0].Type) + "." + key
+			}
+			if fd.Body == nil {
+				without[key] = true
+			} else {
+				with[key] = true
+			}
 		}
-		interp.run(n, nil)
 	}
+	return without, with
+}
 
-	// Wire and execute global vars in global scope gs.
-	n, err := genGlobalVars(rootNodes, gs)
+// setLang records the Go language version of the package importPath, located
+// in dir, as given by the go directive of the go.mod file of its module.
+// Packages not part of a module use the language version of the interpreter,
+// as all packages do if it is set in Options.
+func (interp *Interpreter) setLang(importPath, dir string) error {
+	if interp.langSet {
+		return nil
+	}
+	file, m, err := interp.modGoVersion(dir)
 	if err != nil {
-		return "", err
+		return err
 	}
-	interp.run(n, nil)
+	if file != "" {
+		if v := goMinorVersion(&interp.context); m > v {
+			return fmt.Errorf("%s requires go >= 1.%d (running go 1.%d)", file, m, v)
+		}
+	}
+	interp.universe.mu.Lock()
+	defer interp.universe.mu.Unlock()
+	if file == "" {
+		delete(interp.langs, importPath)
+	} else {
+		interp.langs[importPath] = langVersion{minor: m, file: file}
+	}
+	return nil
+}
 
-	// Add main to list of functions to run, after all inits.
-	if m := gs.sym[mainID]; pkgName == mainID && m != nil && skipTest {
-		initNodes = append(initNodes, m.node)
+// upgradeLang raises the language version of the package importPath to the
+// Go version required by the //go:build constraint of its file src, as the
+// go command does for the files of modules at go1.21 or later. The upgrade
+// applies to the whole package, as language versions are per package.
+func (interp *Interpreter) upgradeLang(importPath, name, src string) {
+	lv, ok := interp.langOf(importPath)
+	if interp.langSet || !ok || lv.minor < 21 {
+		return
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), name, src, parser.PackageClauseOnly|parser.ParseComments)
+	if err != nil {
+		return
 	}
+	for _, g := range f.Comments {
+		for _, c := range g.List {
+			if c.Pos() > f.Package || !constraint.IsGoBuild(c.Text) {
+				continue
+			}
+			expr, err := constraint.Parse(c.Text)
+			if err != nil {
+				return
+			}
+			if v, err := parseGoVersion(constraint.GoVersion(expr)); err == nil && v > lv.minor {
+				interp.universe.mu.Lock()
+				if lv := interp.langs[importPath]; v > lv.minor {
+					interp.langs[importPath] = langVersion{minor: v, file: name, build: true}
+				}
+				interp.universe.mu.Unlock()
+			}
+			return
+		}
+	}
+}
 
-	for _, n := range initNodes {
-		interp.run(n, interp.frame)
+// modGoVersion returns the path of the go.mod file of the module containing
+// dir, and the minor version number of its go directive. The returned path is
+// empty if dir is not part of a module, or if the go directive is missing.
+func (interp *Interpreter) modGoVersion(dir string) (string, int, error) {
+	if _, ok := interp.opt.filesystem.(*realFS); ok {
+		if d, err := filepath.Abs(dir); err == nil {
+			dir = d
+		}
 	}
+	for {
+		file := filepath.Join(dir, "go.mod")
+		if b, err := fs.ReadFile(interp.opt.filesystem, file); err == nil {
+			for _, line := range strings.Split(string(b), "\n") {
+				if i := strings.Index(line, "//"); i >= 0 {
+					line = line[:i]
+				}
+				f := strings.Fields(line)
+				if len(f) != 2 || f[0] != "go" {
+					continue
+				}
+				m, err := parseGoVersion(f[1])
+				if err != nil {
+					return "", 0, fmt.Errorf("%s: %w", file, err)
+				}
+				return file, m, nil
+			}
+			return "", 0, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", 0, nil
+		}
+		dir = parent
+	}
+}
 
-	return pkgName, nil
+// srcDir returns the directory containing the source code of the package
+// identified by importPath, and the root of its subtree dependencies.
+func (interp *Interpreter) srcDir(rPath, importPath string) (dir string, root string, err error) {
+	// For relative import paths in the form "./xxx" or "../xxx", the initial
+	// base path is the directory of the interpreter input file, or "." if no file
+	// was provided.
+	// In all other cases, absolute import paths are resolved from the GOPATH
+	// and the nested "vendor" directories.
+	if isPathRelative(importPath) {
+		if rPath == mainID {
+			rPath = "."
+		}
+		return filepath.Join(filepath.Dir(interp.name), rPath, importPath), rPath, nil
+	}
+	if dir, root, err = interp.pkgDir(interp.context.GOPATH, rPath, importPath); err != nil {
+		// Try again, assuming a root dir at the source location.
+		if rPath, err = interp.rootFromSourceLocation(); err != nil {
+			return "", "", err
+		}
+		return interp.pkgDir(interp.context.GOPATH, rPath, importPath)
+	}
+	return dir, root, nil
 }
 
 // rootFromSourceLocation returns the path to the directory containing the input
@@ -188,7 +443,7 @@ func (interp *Interpreter) rootFromSourceLocation() (string, error) {
 	pkgDir := filepath.Join(wd, filepath.Dir(sourceFile))
 	root := strings.TrimPrefix(pkgDir, filepath.Join(interp.context.GOPATH, "src")+"/")
 	if root == wd {
-		return "", fmt.Errorf("package location %s not in GOPATH", pkgDir)
+		return "", importErrorf("package location %s not in GOPATH", pkgDir)
 	}
 	return root, nil
 }
@@ -211,9 +466,9 @@ func (interp *Interpreter) pkgDir(goPath string, root, importPath string) (strin
 
 	if root == "" {
 		if interp.context.GOPATH == "" {
-			return "", "", fmt.Errorf("unable to find source related to: %q. Either the GOPATH environment variable, or the Interpreter.Options.GoPath needs to be set", importPath)
+			return "", "", importErrorf("unable to find source related to: %q. Either the GOPATH environment variable, or the Interpreter.Options.GoPath needs to be set", importPath)
 		}
-		return "", "", fmt.Errorf("unable to find source related to: %q", importPath)
+		return "", "", importErrorf("unable to find source related to: %q", importPath)
 	}
 
 	rootPath := filepath.Join(goPath, "src", root)
@@ -326,3 +581,269 @@ func effectivePkg(root, path string) string {
 func isPathRelative(s string) bool {
 	return strings.HasPrefix(s, "./") || strings.HasPrefix(s, "../")
 }
+
+// Source packages are imported in three stages. First, the import graph is
+// walked from the entry point and the source files of all the packages in it
+// are read and parsed concurrently. Then the leaf packages of the graph, which
+// import only binary packages, are compiled concurrently: their global types
+// analysis and CFG only define symbols in their own package scope, and the
+// global frame layout shared by all the packages is guarded by the lock of
+// the universe scope, as are the other tables of the interpreter updated
+// during compilation. Finally the packages are processed one by one in import
+// order by importSrc, which compiles the other packages, once their imports
+// are completed, and registers and initializes all of them. As the results
+// are consumed in the same order as before, errors are reported
+// deterministically, and import cycles are still detected through rdir.
+
+// srcImport is a source package to import.
+type srcImport struct {
+	rPath      string // root of the subtree dependencies
+	importPath string // package import path
+	dir        string // package directory, if already known
+	skipTest   bool   // skip test files
+}
+
+// parsedFile is the result of the parsing of a source file.
+type parsedFile struct {
+	src  string
+	file *ast.File
+	err  error
+}
+
+// srcCache holds the source files parsed, and the packages compiled, ahead
+// of their import.
+type srcCache struct {
+	sync.Mutex
+	refs  int                     // number of pending preloads
+	dirs  map[string]bool         // directories already loaded
+	files map[string]*parsedFile  // parsed files, indexed by file name
+	pkgs  map[string]*compiledPkg // compiled packages, indexed by directory
+}
+
+// visited returns true if directory dir has already been loaded.
+func (c *srcCache) visited(dir string) bool {
+	c.Lock()
+	defer c.Unlock()
+	return c.dirs[dir]
+}
+
+// visit marks directory dir as loaded, and returns false if it already was.
+func (c *srcCache) visit(dir string) bool {
+	c.Lock()
+	defer c.Unlock()
+	if c.dirs[dir] {
+		return false
+	}
+	c.dirs[dir] = true
+	return true
+}
+
+func (c *srcCache) add(name string, p *parsedFile) {
+	c.Lock()
+	c.files[name] = p
+	c.Unlock()
+}
+
+// take returns and removes the parsed file of given name, or nil if not
+// found or if its content differs from src.
+func (c *srcCache) take(name, src string) *parsedFile {
+	c.Lock()
+	defer c.Unlock()
+	p := c.files[name]
+	if p == nil || p.src != src {
+		return nil
+	}
+	delete(c.files, name)
+	return p
+}
+
+// compiled returns and removes the package importPath in dir compiled
+// ahead of its import, or nil if not found.
+func (c *srcCache) compiled(importPath, dir string) *compiledPkg {
+	c.Lock()
+	defer c.Unlock()
+	p := c.pkgs[dir]
+	if p == nil || p.importPath != importPath {
+		return nil
+	}
+	delete(c.pkgs, dir)
+	return p
+}
+
+// release terminates a preload, and frees the cache once all the preloads
+// are terminated.
+func (c *srcCache) release() {
+	c.Lock()
+	defer c.Unlock()
+	if c.refs--; c.refs == 0 {
+		c.dirs, c.files, c.pkgs = nil, nil, nil
+	}
+}
+
+// preload reads and parses concurrently the source files of the given
+// packages and of their dependencies, then compiles the leaf packages. The
+// results are retrieved by parse and importSrc. Each call to preload must be
+// followed by a call to interp.parsed.release, once the packages are imported.
+func (interp *Interpreter) preload(imports ...srcImport) {
+	c := &interp.parsed
+	c.Lock()
+	if c.refs++; c.refs == 1 {
+		c.dirs, c.files, c.pkgs = map[string]bool{}, map[string]*parsedFile{}, map[string]*compiledPkg{}
+	}
+	c.Unlock()
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	var mu sync.Mutex
+	var leaves []srcImport
+
+	var load func(imp srcImport)
+	load = func(imp srcImport) {
+		defer wg.Done()
+		dir, rPath := imp.dir, imp.rPath
+		if dir == "" {
+			var err error
+			if dir, rPath, err = interp.srcDir(rPath, imp.importPath); err != nil {
+				// The error is reported when importing the package.
+				return
+			}
+		}
+		if !c.visit(dir) {
+			return
+		}
+		sem <- struct{}{}
+		files := interp.parseDir(dir, imp.skipTest)
+		<-sem
+
+		subRPath := effectivePkg(rPath, imp.importPath)
+		leaf := true
+		for _, f := range files {
+			for _, imp := range interp.srcImports(subRPath, f) {
+				leaf = false
+				wg.Add(1)
+				go load(imp)
+			}
+		}
+		if leaf && len(files) > 0 {
+			mu.Lock()
+			leaves = append(leaves, srcImport{rPath: rPath, importPath: imp.importPath, dir: dir, skipTest: imp.skipTest})
+			mu.Unlock()
+		}
+	}
+
+	for _, imp := range imports {
+		wg.Add(1)
+		go load(imp)
+	}
+	wg.Wait()
+
+	interp.compileLeaves(leaves)
+}
+
+// compileLeaves compiles concurrently the given leaf packages, not importing
+// source packages, and stores them in the source cache. The packages are
+// compiled by importSrc instead in check mode, where the errors are reported
+// as they are found, and if the AST or CFG graphs are displayed.
+func (interp *Interpreter) compileLeaves(leaves []srcImport) {
+	if len(leaves) < 2 || interp.check != nil || interp.astDot || interp.cfgDot {
+		return
+	}
+	sort.Slice(leaves, func(i, j int) bool { return leaves[i].dir < leaves[j].dir })
+
+	// The language versions are set before, as they are read by the CFG.
+	paths := map[string]int{}
+	for _, l := range leaves {
+		paths[l.importPath]++
+	}
+	pkgs := make([]*compiledPkg, len(leaves))
+	for i, l := range leaves {
+		if paths[l.importPath] > 1 || interp.scopes[l.importPath] != nil || interp.setLang(l.importPath, l.dir) != nil {
+			// Left to importSrc, which reports the errors.
+			continue
+		}
+		pkgs[i] = &compiledPkg{}
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	for i, l := range leaves {
+		if pkgs[i] == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, l srcImport) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			defer func() {
+				// The panic is raised again by importSrc, in the goroutine
+				// of the compilation.
+				if r := recover(); r != nil {
+					pkgs[i].panic = r
+				}
+			}()
+			*pkgs[i] = *interp.compilePkg(l.rPath, l.importPath, l.dir, l.skipTest)
+		}(i, l)
+	}
+	wg.Wait()
+
+	c := &interp.parsed
+	c.Lock()
+	for i, l := range leaves {
+		if p := pkgs[i]; p != nil {
+			p.importPath = l.importPath
+			c.pkgs[l.dir] = p
+		}
+	}
+	c.Unlock()
+}
+
+// parseDir parses the source files in directory dir, stores the results in
+// the source cache and returns the parsed files.
+func (interp *Interpreter) parseDir(dir string, skipTest bool) []*ast.File {
+	entries, err := fs.ReadDir(interp.opt.filesystem, dir)
+	if err != nil {
+		return nil
+	}
+	var files []*ast.File
+	for _, e := range entries {
+		name := e.Name()
+		if skipFile(&interp.context, name, skipTest) {
+			continue
+		}
+		name = filepath.Join(dir, name)
+		buf, err := fs.ReadFile(interp.opt.filesystem, name)
+		if err != nil {
+			continue
+		}
+		mode := parser.DeclarationErrors | parser.ParseComments
+		if interp.check != nil {
+			mode |= parser.AllErrors
+		}
+		f, err := parser.ParseFile(interp.fset, name, buf, mode)
+		interp.parsed.add(name, &parsedFile{src: string(buf), file: f, err: err})
+		if f != nil {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// srcImports returns the source packages imported by file f.
+func (interp *Interpreter) srcImports(rPath string, f *ast.File) []srcImport {
+	var imports []srcImport
+	for _, s := range f.Imports {
+		ipath, err := strconv.Unquote(s.Path.Value)
+		if err != nil {
+			continue
+		}
+		if packageName := path.Base(ipath); path.Dir(ipath) == packageName {
+			ipath = packageName
+		}
+		if interp.binPkg[ipath] != nil || interp.srcPkg[ipath] != nil {
+			continue
+		}
+		imports = append(imports, srcImport{rPath: rPath, importPath: ipath, skipTest: NoTest})
+	}
+	return imports
+}
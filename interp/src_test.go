@@ -1,9 +1,14 @@
 package interp
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"testing/fstest"
 )
 
 func Test_effectivePkg(t *testing.T) {
@@ -255,3 +260,141 @@ func Test_previousRoot(t *testing.T) {
 		})
 	}
 }
+
+func TestPreload(t *testing.T) {
+	fsys := fstest.MapFS{
+		"_pkg/src/guthib.com/a/a.go":  {Data: []byte(`package a; import "guthib.com/c"; func F() int { return c.V + 1 }`)},
+		"_pkg/src/guthib.com/b/b.go":  {Data: []byte(`package b; import "guthib.com/c"; func F() int { return c.V + 2 }`)},
+		"_pkg/src/guthib.com/c/c.go":  {Data: []byte(`package c; const V = 40`)},
+		"_pkg/src/guthib.com/e1/e.go": {Data: []byte(`package e1; func F() int { return "e1" }`)},
+		"_pkg/src/guthib.com/e2/e.go": {Data: []byte(`package e2; func F() int { return "e2" }`)},
+		"_pkg/src/guthib.com/x/x.go":  {Data: []byte(`package x; import "guthib.com/y"; var X = y.Y`)},
+		"_pkg/src/guthib.com/y/y.go":  {Data: []byte(`package y; import "guthib.com/x"; var Y = x.X`)},
+	}
+	newInterp := func() *Interpreter {
+		return New(Options{GoPath: "./_pkg", SourcecodeFilesystem: fsys})
+	}
+
+	i := newInterp()
+	if _, err := i.Eval(`import ("guthib.com/a"; "guthib.com/b")`); err != nil {
+		t.Fatal(err)
+	}
+	res, err := i.Eval("a.F() + b.F()")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := res.Interface(); v != 83 {
+		t.Errorf("got %v, want 83", v)
+	}
+	if i.parsed.refs != 0 || i.parsed.files != nil {
+		t.Error("source cache not released")
+	}
+
+	// Errors must not depend on the order of concurrent parsing.
+	var first string
+	for n := 0; n < 10; n++ {
+		_, err := newInterp().Eval(`import (_ "guthib.com/a"; _ "guthib.com/e2"; _ "guthib.com/e1")`)
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		if n == 0 {
+			first = err.Error()
+			if !strings.Contains(first, "e2") {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		} else if err.Error() != first {
+			t.Fatalf("got error %q, want %q", err, first)
+		}
+	}
+
+	if _, err := newInterp().Eval(`import "guthib.com/x"`); err == nil || !strings.Contains(err.Error(), "import cycle not allowed") {
+		t.Errorf("got %v, want import cycle error", err)
+	}
+}
+
+func TestCompileLeaves(t *testing.T) {
+	fsys := fstest.MapFS{"_pkg/src/guthib.com/m/m.go": {Data: []byte(`package m
+
+import (
+	"strings"
+
+	"guthib.com/l0"
+	"guthib.com/l1"
+	"guthib.com/l2"
+	"guthib.com/l3"
+	"guthib.com/l4"
+	"guthib.com/l5"
+	"guthib.com/l6"
+	"guthib.com/l7"
+)
+
+func F() string {
+	return strings.Join([]string{l0.F(), l1.F(), l2.F(), l3.F(), l4.F(), l5.F(), l6.F(), l7.F()}, ",")
+}
+`)}}
+	// Leaf packages, with globals, types, methods, generics, closures and
+	// init functions, compiled concurrently.
+	for n := 0; n < 8; n++ {
+		fsys[fmt.Sprintf("_pkg/src/guthib.com/l%d/l.go", n)] = &fstest.MapFile{Data: []byte(fmt.Sprintf(`package l%[1]d
+
+import (
+	"strconv"
+
+	"guthib.com/trace"
+)
+
+type T struct{ n int }
+
+func (t *T) Add(m int) { t.n += m }
+
+type Number interface{ ~int | ~float64 }
+
+func Max[N Number](x, y N) N {
+	if x > y {
+		return x
+	}
+	return y
+}
+
+const (
+	a = iota + %[1]d
+	b
+)
+
+var (
+	t     = &T{n: b}
+	count = func() int { t.Add(a); return t.n }()
+	m     = map[string]int{"n": count}
+)
+
+func init() { trace.Add("l%[1]d") }
+
+func F() string { return strconv.Itoa(Max(m["n"], %[1]d) + %[1]d) }
+`, n))}
+	}
+
+	var trace []string
+	i := New(Options{GoPath: "./_pkg", SourcecodeFilesystem: fsys})
+	if err := i.Use(map[string]map[string]reflect.Value{
+		"guthib.com/trace/trace": {"Add": reflect.ValueOf(func(s string) { trace = append(trace, s) })},
+		"strconv/strconv":        {"Itoa": reflect.ValueOf(strconv.Itoa)},
+		"strings/strings":        {"Join": reflect.ValueOf(strings.Join)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := i.Eval(`import "guthib.com/m"`); err != nil {
+		t.Fatal(err)
+	}
+	res, err := i.Eval("m.F()")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// In lN, a = N, b = N+1, count = 2N+1, and F returns 3N+1.
+	if got, want := res.String(), "1,4,7,10,13,16,19,22"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	// The packages are initialized in import order.
+	if got, want := strings.Join(trace, ","), "l0,l1,l2,l3,l4,l5,l6,l7"; got != want {
+		t.Errorf("got initialization order %q, want %q", got, want)
+	}
+}
@@ -458,7 +458,7 @@ func nodeType2(interp *Interpreter, sc *scope, n *node, seen []*node) (t *itype,
 				case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
 					length = int(v.Uint())
 				default:
-					return nil, c0.cfgErrorf("non integer constant %v", v)
+					return nil, c0.cfgErrorCodef(ErrInvalidOperation, "non integer constant %v", v)
 				}
 			}
 		case c0.kind == ellipsisExpr:
@@ -475,7 +475,7 @@ func nodeType2(interp *Interpreter, sc *scope, n *node, seen []*node) (t *itype,
 			}
 			// Size is defined by a symbol which must be a constant integer.
 			if sym.kind != constSym {
-				return nil, c0.cfgErrorf("non-constant array bound %q", c0.ident)
+				return nil, c0.cfgErrorCodef(ErrInvalidOperation, "non-constant array bound %q", c0.ident)
 			}
 			if sym.typ == nil || !isInt(sym.typ.TypeOf()) || !sym.rval.IsValid() {
 				incomplete = true
@@ -493,7 +493,7 @@ func nodeType2(interp *Interpreter, sc *scope, n *node, seen []*node) (t *itype,
 				return nil, err
 			}
 			if !c0.rval.IsValid() {
-				return nil, c0.cfgErrorf("undefined array size")
+				return nil, c0.cfgErrorCodef(ErrUndefined, "undefined array size")
 			}
 			if length, ok = c0.rval.Interface().(int); !ok {
 				v, ok := c0.rval.Interface().(constant.Value)
@@ -534,10 +534,10 @@ func nodeType2(interp *Interpreter, sc *scope, n *node, seen []*node) (t *itype,
 			case constant.Complex:
 				t = untypedComplex(n)
 			default:
-				err = n.cfgErrorf("missing support for type %v", n.rval)
+				err = n.cfgErrorCodef(ErrUnsupported, "missing support for type %v", n.rval)
 			}
 		default:
-			err = n.cfgErrorf("missing support for type %T: %v", v, n.rval)
+			err = n.cfgErrorCodef(ErrUnsupported, "missing support for type %T: %v", v, n.rval)
 		}
 
 	case unaryExpr:
@@ -639,7 +639,7 @@ func nodeType2(interp *Interpreter, sc *scope, n *node, seen []*node) (t *itype,
 					case nt0.untyped && isFloat64(t1) || nt1.untyped && isFloat64(t0):
 						t = sc.getType("complex128")
 					default:
-						err = n.cfgErrorf("invalid types %s and %s", t0.Kind(), t1.Kind())
+						err = n.cfgErrorCodef(ErrTypeMismatch, "invalid types %s and %s", t0.Kind(), t1.Kind())
 					}
 					if nt0.untyped && nt1.untyped {
 						t = untypedComplex(n)
@@ -658,7 +658,7 @@ func nodeType2(interp *Interpreter, sc *scope, n *node, seen []*node) (t *itype,
 					case k == reflect.Complex128:
 						t = sc.getType("float64")
 					default:
-						err = n.cfgErrorf("invalid complex type %s", k)
+						err = n.cfgErrorCodef(ErrInvalidOperation, "invalid complex type %s", k)
 					}
 				}
 			case bltnCap, bltnCopy, bltnLen:
@@ -867,7 +867,7 @@ func nodeType2(interp *Interpreter, sc *scope, n *node, seen []*node) (t *itype,
 
 		// Index list expressions can be used only in context of generic types.
 		if lt.cat != genericT {
-			err = n.cfgErrorf("not a generic type: %s", lt.id())
+			err = n.cfgErrorCodef(ErrTypeMismatch, "not a generic type: %s", lt.id())
 			return nil, err
 		}
 		name := lt.id() + "["
@@ -956,7 +956,7 @@ func nodeType2(interp *Interpreter, sc *scope, n *node, seen []*node) (t *itype,
 		t.incomplete = key.incomplete || val.incomplete
 
 	case parenExpr:
-		t, err = nodeType2(interp, sc, n.child[0], seen)
+		t, err = nodeType2(interp, sc, n.lastChild(), seen)
 
 	case selectorExpr:
 		// Resolve the left part of selector, then lookup the right part on it
@@ -1000,7 +1000,7 @@ func nodeType2(interp *Interpreter, sc *scope, n *node, seen []*node) (t *itype,
 					break
 				}
 			}
-			err = n.cfgErrorf("undefined selector %s.%s", lt.path, name)
+			err = n.cfgErrorCodef(ErrUndefined, "undefined selector %s.%s", lt.path, name)
 		default:
 			if m, _ := lt.lookupMethod(name); m != nil {
 				t, err = nodeType2(interp, sc, m.child[2], seen)
@@ -1011,7 +1011,7 @@ func nodeType2(interp *Interpreter, sc *scope, n *node, seen []*node) (t *itype,
 			} else if bs, _, ok := lt.lookupBinField(name); ok {
 				t = valueTOf(bs.Type, withScope(sc))
 			} else {
-				err = lt.node.cfgErrorf("undefined selector %s", name)
+				err = lt.node.cfgErrorCodef(ErrUndefined, "undefined selector %s", name)
 			}
 		}
 
@@ -1099,11 +1099,11 @@ func nodeType2(interp *Interpreter, sc *scope, n *node, seen []*node) (t *itype,
 		t, err = nodeType2(interp, sc, n.child[1], seen)
 
 	default:
-		err = n.cfgErrorf("type definition not implemented: %s", n.kind)
+		err = n.cfgErrorCodef(ErrUnsupported, "type definition not implemented: %s", n.kind)
 	}
 
 	if err == nil && t != nil && t.cat == nilT && !t.incomplete {
-		err = n.cfgErrorf("use of untyped nil %s", t.name)
+		err = n.cfgErrorCodef(ErrInvalidOperation, "use of untyped nil %s", t.name)
 	}
 
 	// The existing symbol data needs to be recovered, but not in the
@@ -1682,7 +1682,11 @@ func (t *itype) zero() (v reflect.Value, err error) {
 	}
 	switch t.cat {
 	case linkedT:
-		v, err = t.val.zero()
+		if v, err = t.val.zero(); err == nil && v.IsValid() {
+			if ft := t.frameType(); v.Type() != ft {
+				v = reflect.New(ft).Elem()
+			}
+		}
 
 	case arrayT, ptrT, structT, sliceT:
 		v = reflect.New(t.frameType()).Elem()
@@ -2096,17 +2100,17 @@ func (t *itype) refType(ctx *refTypeContext) reflect.Type {
 	}
 	switch t.cat {
 	case linkedT:
-		t.rtype = t.val.refType(ctx)
+		t.rtype = t.namedRefType(t.val.refType(ctx))
 	case arrayT:
-		t.rtype = reflect.ArrayOf(t.length, t.val.refType(ctx))
+		t.rtype = t.namedRefType(reflect.ArrayOf(t.length, t.val.refType(ctx)))
 	case sliceT, variadicT:
-		t.rtype = reflect.SliceOf(t.val.refType(ctx))
+		t.rtype = t.namedRefType(reflect.SliceOf(t.val.refType(ctx)))
 	case chanT:
-		t.rtype = reflect.ChanOf(reflect.BothDir, t.val.refType(ctx))
+		t.rtype = t.namedRefType(reflect.ChanOf(reflect.BothDir, t.val.refType(ctx)))
 	case chanRecvT:
-		t.rtype = reflect.ChanOf(reflect.RecvDir, t.val.refType(ctx))
+		t.rtype = t.namedRefType(reflect.ChanOf(reflect.RecvDir, t.val.refType(ctx)))
 	case chanSendT:
-		t.rtype = reflect.ChanOf(reflect.SendDir, t.val.refType(ctx))
+		t.rtype = t.namedRefType(reflect.ChanOf(reflect.SendDir, t.val.refType(ctx)))
 	case errorT:
 		t.rtype = reflect.TypeOf(new(error)).Elem()
 	case funcT:
@@ -2120,7 +2124,7 @@ func (t *itype) refType(ctx *refTypeContext) reflect.Type {
 		for i, v := range t.ret {
 			out[i] = v.refType(ctx)
 		}
-		t.rtype = reflect.FuncOf(in, out, variadic)
+		t.rtype = t.namedRefType(reflect.FuncOf(in, out, variadic))
 	case interfaceT:
 		if len(t.field) == 0 {
 			// empty interface, do not wrap it
@@ -2129,7 +2133,7 @@ func (t *itype) refType(ctx *refTypeContext) reflect.Type {
 		}
 		t.rtype = valueInterfaceType
 	case mapT:
-		t.rtype = reflect.MapOf(t.key.refType(ctx), t.val.refType(ctx))
+		t.rtype = t.namedRefType(reflect.MapOf(t.key.refType(ctx), t.val.refType(ctx)))
 	case ptrT:
 		rt := t.val.refType(ctx)
 		if rt == unsafe2.DummyType && ctx.slevel > 1 {
@@ -2138,7 +2142,7 @@ func (t *itype) refType(ctx *refTypeContext) reflect.Type {
 			// be stored in future.
 			return reflect.PtrTo(rt)
 		}
-		t.rtype = reflect.PtrTo(rt)
+		t.rtype = t.namedRefType(reflect.PtrTo(rt))
 	case structT:
 		if t.name != "" {
 			ctx.defined[name] = t
@@ -2172,7 +2176,7 @@ func (t *itype) refType(ctx *refTypeContext) reflect.Type {
 			index int
 		}
 		fieldFix := []fixStructField{} // Slice of field indices to fix for recursivity.
-		t.rtype = reflect.StructOf(fields)
+		t.rtype = t.namedRefType(reflect.StructOf(fields))
 		if ctx.isComplete() {
 			for _, s := range ctx.defined {
 				for i := 0; i < s.rtype.NumField(); i++ {
@@ -2215,6 +2219,21 @@ func (t *itype) refType(ctx *refTypeContext) reflect.Type {
 	return t.rtype
 }
 
+// namedRefType returns a named copy of the runtime type rt if t is a named
+// type, so reflect and fmt (i.e. %T, %v) report the declared package path and
+// name instead of the underlying type. Otherwise, or if the kind of rt can not
+// be named, see unsafe2.NamedOf, rt is returned.
+func (t *itype) namedRefType(rt reflect.Type) reflect.Type {
+	if t.name == "" || t.path == "" || isGeneric(t) || isInterfaceSrc(t) {
+		return rt
+	}
+	pkgPath := t.path
+	if t.scope != nil && t.scope.pkgID != "" {
+		pkgPath = t.scope.pkgID
+	}
+	return unsafe2.NamedOf(rt, pkgPath, t.path+"."+t.name)
+}
+
 // TypeOf returns the reflection type of dynamic interpreter type t.
 func (t *itype) TypeOf() reflect.Type {
 	return t.refType(nil)
@@ -2227,11 +2246,11 @@ func (t *itype) frameType() (r reflect.Type) {
 	}
 	switch t.cat {
 	case linkedT:
-		r = t.val.frameType()
+		r = t.namedRefType(t.val.frameType())
 	case arrayT:
-		r = reflect.ArrayOf(t.length, t.val.frameType())
+		r = t.namedRefType(reflect.ArrayOf(t.length, t.val.frameType()))
 	case sliceT, variadicT:
-		r = reflect.SliceOf(t.val.frameType())
+		r = t.namedRefType(reflect.SliceOf(t.val.frameType()))
 	case interfaceT:
 		if len(t.field) == 0 {
 			// empty interface, do not wrap it
@@ -2240,9 +2259,9 @@ func (t *itype) frameType() (r reflect.Type) {
 		}
 		r = valueInterfaceType
 	case mapT:
-		r = reflect.MapOf(t.key.frameType(), t.val.frameType())
+		r = t.namedRefType(reflect.MapOf(t.key.frameType(), t.val.frameType()))
 	case ptrT:
-		r = reflect.PtrTo(t.val.frameType())
+		r = t.namedRefType(reflect.PtrTo(t.val.frameType()))
 	default:
 		r = t.TypeOf()
 	}
@@ -23,10 +23,10 @@ type typecheck struct {
 func (check typecheck) op(p opPredicates, a action, n, c *node, t reflect.Type) error {
 	if pred := p[a]; pred != nil {
 		if !pred(t) {
-			return n.cfgErrorf("invalid operation: operator %v not defined on %s", n.action, c.typ.id())
+			return n.cfgErrorCodef(ErrInvalidOperation, "invalid operation: operator %v not defined on %s", n.action, c.typ.id())
 		}
 	} else {
-		return n.cfgErrorf("invalid operation: unknown operator %v", n.action)
+		return n.cfgErrorCodef(ErrInvalidOperation, "invalid operation: unknown operator %v", n.action)
 	}
 	return nil
 }
@@ -36,12 +36,12 @@ func (check typecheck) op(p opPredicates, a action, n, c *node, t reflect.Type)
 // Use typ == nil to indicate assignment to an untyped blank identifier.
 func (check typecheck) assignment(n *node, typ *itype, context string) error {
 	if n.typ == nil {
-		return n.cfgErrorf("invalid type in %s", context)
+		return n.cfgErrorCodef(ErrInvalidOperation, "invalid type in %s", context)
 	}
 	if n.typ.untyped {
 		if typ == nil || isInterface(typ) {
 			if typ == nil && n.typ.cat == nilT {
-				return n.cfgErrorf("use of untyped nil in %s", context)
+				return n.cfgErrorCodef(ErrInvalidOperation, "use of untyped nil in %s", context)
 			}
 			typ = n.typ.defaultType(n.rval, check.scope)
 		}
@@ -56,9 +56,9 @@ func (check typecheck) assignment(n *node, typ *itype, context string) error {
 
 	if !n.typ.assignableTo(typ) && typ.str != "*unsafe2.dummy" {
 		if context == "" {
-			return n.cfgErrorf("cannot use type %s as type %s", n.typ.id(), typ.id())
+			return n.cfgErrorCodef(ErrTypeMismatch, "cannot use type %s as type %s", n.typ.id(), typ.id())
 		}
-		return n.cfgErrorf("cannot use type %s as type %s in %s", n.typ.id(), typ.id(), context)
+		return n.cfgErrorCodef(ErrTypeMismatch, "cannot use type %s as type %s in %s", n.typ.id(), typ.id(), context)
 	}
 	return nil
 }
@@ -79,7 +79,7 @@ func (check typecheck) assignExpr(n, dest, src *node) error {
 
 	// assignment operations.
 	if n.nleft > 1 || n.nright > 1 {
-		return n.cfgErrorf("assignment operation %s requires single-valued expressions", n.action)
+		return n.cfgErrorCodef(ErrInvalidOperation, "assignment operation %s requires single-valued expressions", n.action)
 	}
 
 	return check.binaryExpr(n)
@@ -111,7 +111,7 @@ func (check typecheck) addressExpr(n *node) error {
 			found = true
 			continue
 		}
-		return n.cfgErrorf("invalid operation: cannot take address of %s [kind: %s]", c0.typ.id(), kinds[c0.kind])
+		return n.cfgErrorCodef(ErrInvalidOperation, "invalid operation: cannot take address of %s [kind: %s]", c0.typ.id(), kinds[c0.kind])
 	}
 	return nil
 }
@@ -119,7 +119,7 @@ func (check typecheck) addressExpr(n *node) error {
 // starExpr type checks a star expression on a variable.
 func (check typecheck) starExpr(n *node) error {
 	if n.typ.TypeOf().Kind() != reflect.Ptr {
-		return n.cfgErrorf("invalid operation: cannot indirect %q", n.name())
+		return n.cfgErrorCodef(ErrInvalidOperation, "invalid operation: cannot indirect %q", n.name())
 	}
 	return nil
 }
@@ -137,16 +137,16 @@ var unaryOpPredicates = opPredicates{
 func (check typecheck) unaryExpr(n *node) error {
 	c0 := n.child[0]
 	if isBlank(c0) {
-		return n.cfgErrorf("cannot use _ as value")
+		return n.cfgErrorCodef(ErrInvalidOperation, "cannot use _ as value")
 	}
 	t0 := c0.typ.TypeOf()
 
 	if n.action == aRecv {
 		if !isChan(c0.typ) {
-			return n.cfgErrorf("invalid operation: cannot receive from non-channel %s", c0.typ.id())
+			return n.cfgErrorCodef(ErrInvalidOperation, "invalid operation: cannot receive from non-channel %s", c0.typ.id())
 		}
 		if isSendChan(c0.typ) {
-			return n.cfgErrorf("invalid operation: cannot receive from send-only channel %s", c0.typ.id())
+			return n.cfgErrorCodef(ErrInvalidOperation, "invalid operation: cannot receive from send-only channel %s", c0.typ.id())
 		}
 		return nil
 	}
@@ -166,18 +166,18 @@ func (check typecheck) shift(n *node) error {
 	}
 
 	if !(c0.typ.untyped && v0 != nil && v0.Kind() == constant.Int || isInt(t0)) {
-		return n.cfgErrorf("invalid operation: shift of type %v", c0.typ.id())
+		return n.cfgErrorCodef(ErrInvalidOperation, "invalid operation: shift of type %v", c0.typ.id())
 	}
 
 	switch {
 	case c1.typ.untyped:
 		if err := check.convertUntyped(c1, check.scope.getType("uint")); err != nil {
-			return n.cfgErrorf("invalid operation: shift count type %v, must be integer", c1.typ.id())
+			return n.cfgErrorCodef(ErrInvalidOperation, "invalid operation: shift count type %v, must be integer", c1.typ.id())
 		}
 	case isInt(t1):
 		// nothing to do
 	default:
-		return n.cfgErrorf("invalid operation: shift count type %v, must be integer", c1.typ.id())
+		return n.cfgErrorCodef(ErrInvalidOperation, "invalid operation: shift count type %v, must be integer", c1.typ.id())
 	}
 	return nil
 }
@@ -187,14 +187,14 @@ func (check typecheck) comparison(n *node) error {
 	t0, t1 := n.child[0].typ, n.child[1].typ
 
 	if !t0.assignableTo(t1) && !t1.assignableTo(t0) {
-		return n.cfgErrorf("invalid operation: mismatched types %s and %s", t0.id(), t1.id())
+		return n.cfgErrorCodef(ErrTypeMismatch, "invalid operation: mismatched types %s and %s", t0.id(), t1.id())
 	}
 
 	ok := false
 
 	if !isInterface(t0) && !isInterface(t1) && !t0.isNil() && !t1.isNil() && t0.untyped == t1.untyped && t0.id() != t1.id() && !typeDefined(t0, t1) {
 		// Non interface types must be really equals.
-		return n.cfgErrorf("invalid operation: mismatched types %s and %s", t0.id(), t1.id())
+		return n.cfgErrorCodef(ErrTypeMismatch, "invalid operation: mismatched types %s and %s", t0.id(), t1.id())
 	}
 
 	switch n.action {
@@ -208,7 +208,7 @@ func (check typecheck) comparison(n *node) error {
 		if typ.isNil() {
 			typ = t1
 		}
-		return n.cfgErrorf("invalid operation: operator %v not defined on %s", n.action, typ.id())
+		return n.cfgErrorCodef(ErrInvalidOperation, "invalid operation: operator %v not defined on %s", n.action, typ.id())
 	}
 	return nil
 }
@@ -234,7 +234,7 @@ func (check typecheck) binaryExpr(n *node) error {
 	c0, c1 := n.child[0], n.child[1]
 
 	if isBlank(c0) || isBlank(c1) {
-		return n.cfgErrorf("cannot use _ as value")
+		return n.cfgErrorCodef(ErrInvalidOperation, "cannot use _ as value")
 	}
 
 	a := n.action
@@ -254,15 +254,15 @@ func (check typecheck) binaryExpr(n *node) error {
 		// Catch mixing string and number for "+" operator use.
 		k, k0, k1 := isNumber(n.typ.TypeOf()), isNumber(c0.typ.TypeOf()), isNumber(c1.typ.TypeOf())
 		if k != k0 || k != k1 {
-			return n.cfgErrorf("cannot use type %s as type %s in assignment", c0.typ.id(), n.typ.id())
+			return n.cfgErrorCodef(ErrTypeMismatch, "cannot use type %s as type %s in assignment", c0.typ.id(), n.typ.id())
 		}
 	case aRem:
 		if zeroConst(c1) {
-			return n.cfgErrorf("invalid operation: division by zero")
+			return n.cfgErrorCodef(ErrInvalidOperation, "invalid operation: division by zero")
 		}
 	case aQuo:
 		if zeroConst(c1) {
-			return n.cfgErrorf("invalid operation: division by zero")
+			return n.cfgErrorCodef(ErrInvalidOperation, "invalid operation: division by zero")
 		}
 		if c0.rval.IsValid() && c1.rval.IsValid() {
 			// Avoid constant conversions below to ensure correct constant integer quotient.
@@ -279,7 +279,7 @@ func (check typecheck) binaryExpr(n *node) error {
 	}
 
 	if !c0.typ.equals(c1.typ) {
-		return n.cfgErrorf("invalid operation: mismatched types %s and %s", c0.typ.id(), c1.typ.id())
+		return n.cfgErrorCodef(ErrTypeMismatch, "invalid operation: mismatched types %s and %s", c0.typ.id(), c1.typ.id())
 	}
 
 	t0 := c0.typ.TypeOf()
@@ -297,7 +297,7 @@ func (check typecheck) index(n *node, max int) error {
 	}
 
 	if !isInt(n.typ.TypeOf()) {
-		return n.cfgErrorf("index %s must be integer", n.typ.id())
+		return n.cfgErrorCodef(ErrInvalidOperation, "index %s must be integer", n.typ.id())
 	}
 
 	if !n.rval.IsValid() || max < 1 {
@@ -305,7 +305,7 @@ func (check typecheck) index(n *node, max int) error {
 	}
 
 	if int(vInt(n.rval)) >= max {
-		return n.cfgErrorf("index %s is out of bounds", n.typ.id())
+		return n.cfgErrorCodef(ErrInvalidOperation, "index %s is out of bounds", n.typ.id())
 	}
 
 	return nil
@@ -323,16 +323,16 @@ func (check typecheck) arrayLitExpr(child []*node, typ *itype) error {
 		switch {
 		case c.kind == keyValueExpr:
 			if err := check.index(c.child[0], length); err != nil {
-				return c.cfgErrorf("index %s must be integer constant", c.child[0].typ.id())
+				return c.cfgErrorCodef(ErrInvalidOperation, "index %s must be integer constant", c.child[0].typ.id())
 			}
 			n = c.child[1]
 			index = int(vInt(c.child[0].rval))
 		case cat == arrayT && index >= length:
-			return c.cfgErrorf("index %d is out of bounds (>= %d)", index, length)
+			return c.cfgErrorCodef(ErrInvalidOperation, "index %d is out of bounds (>= %d)", index, length)
 		}
 
 		if visited[index] {
-			return n.cfgErrorf("duplicate index %d in array or slice literal", index)
+			return n.cfgErrorCodef(ErrInvalidOperation, "duplicate index %d in array or slice literal", index)
 		}
 		visited[index] = true
 		index++
@@ -349,7 +349,7 @@ func (check typecheck) mapLitExpr(child []*node, ktyp, vtyp *itype) error {
 	visited := make(map[interface{}]bool, len(child))
 	for _, c := range child {
 		if c.kind != keyValueExpr {
-			return c.cfgErrorf("missing key in map literal")
+			return c.cfgErrorCodef(ErrInvalidOperation, "missing key in map literal")
 		}
 
 		key, val := c.child[0], c.child[1]
@@ -360,7 +360,7 @@ func (check typecheck) mapLitExpr(child []*node, ktyp, vtyp *itype) error {
 		if key.rval.IsValid() {
 			kval := key.rval.Interface()
 			if visited[kval] {
-				return c.cfgErrorf("duplicate key %s in map literal", kval)
+				return c.cfgErrorCodef(ErrInvalidOperation, "duplicate key %s in map literal", kval)
 			}
 			visited[kval] = true
 		}
@@ -383,17 +383,17 @@ func (check typecheck) structLitExpr(child []*node, typ *itype) error {
 		visited := make([]bool, len(typ.field))
 		for _, c := range child {
 			if c.kind != keyValueExpr {
-				return c.cfgErrorf("mixture of field:value and value elements in struct literal")
+				return c.cfgErrorCodef(ErrInvalidOperation, "mixture of field:value and value elements in struct literal")
 			}
 
 			key, val := c.child[0], c.child[1]
 			name := key.ident
 			if name == "" {
-				return c.cfgErrorf("invalid field name %s in struct literal", key.typ.id())
+				return c.cfgErrorCodef(ErrInvalidOperation, "invalid field name %s in struct literal", key.typ.id())
 			}
 			i := typ.fieldIndex(name)
 			if i < 0 {
-				return c.cfgErrorf("unknown field %s in struct literal", name)
+				return c.cfgErrorCodef(ErrUndefined, "unknown field %s in struct literal", name)
 			}
 			field := typ.field[i]
 
@@ -402,7 +402,7 @@ func (check typecheck) structLitExpr(child []*node, typ *itype) error {
 			}
 
 			if visited[i] {
-				return c.cfgErrorf("duplicate field name %s in struct literal", name)
+				return c.cfgErrorCodef(ErrInvalidOperation, "duplicate field name %s in struct literal", name)
 			}
 			visited[i] = true
 		}
@@ -412,11 +412,11 @@ func (check typecheck) structLitExpr(child []*node, typ *itype) error {
 	// No children can be keyValueExpr
 	for i, c := range child {
 		if c.kind == keyValueExpr {
-			return c.cfgErrorf("mixture of field:value and value elements in struct literal")
+			return c.cfgErrorCodef(ErrInvalidOperation, "mixture of field:value and value elements in struct literal")
 		}
 
 		if i >= len(typ.field) {
-			return c.cfgErrorf("too many values in struct literal")
+			return c.cfgErrorCodef(ErrInvalidOperation, "too many values in struct literal")
 		}
 		field := typ.field[i]
 		// TODO(nick): check if this field is not exported and in a different package.
@@ -426,7 +426,7 @@ func (check typecheck) structLitExpr(child []*node, typ *itype) error {
 		}
 	}
 	if len(child) < len(typ.field) {
-		return child[len(child)-1].cfgErrorf("too few values in struct literal")
+		return child[len(child)-1].cfgErrorCodef(ErrInvalidOperation, "too few values in struct literal")
 	}
 	return nil
 }
@@ -442,17 +442,17 @@ func (check typecheck) structBinLitExpr(child []*node, typ reflect.Type) error {
 		visited := make(map[string]bool, typ.NumField())
 		for _, c := range child {
 			if c.kind != keyValueExpr {
-				return c.cfgErrorf("mixture of field:value and value elements in struct literal")
+				return c.cfgErrorCodef(ErrInvalidOperation, "mixture of field:value and value elements in struct literal")
 			}
 
 			key, val := c.child[0], c.child[1]
 			name := key.ident
 			if name == "" {
-				return c.cfgErrorf("invalid field name %s in struct literal", key.typ.id())
+				return c.cfgErrorCodef(ErrInvalidOperation, "invalid field name %s in struct literal", key.typ.id())
 			}
 			field, ok := typ.FieldByName(name)
 			if !ok {
-				return c.cfgErrorf("unknown field %s in struct literal", name)
+				return c.cfgErrorCodef(ErrUndefined, "unknown field %s in struct literal", name)
 			}
 
 			if err := check.assignment(val, valueTOf(field.Type), "struct literal"); err != nil {
@@ -460,7 +460,7 @@ func (check typecheck) structBinLitExpr(child []*node, typ reflect.Type) error {
 			}
 
 			if visited[field.Name] {
-				return c.cfgErrorf("duplicate field name %s in struct literal", name)
+				return c.cfgErrorCodef(ErrInvalidOperation, "duplicate field name %s in struct literal", name)
 			}
 			visited[field.Name] = true
 		}
@@ -470,15 +470,15 @@ func (check typecheck) structBinLitExpr(child []*node, typ reflect.Type) error {
 	// No children can be keyValueExpr
 	for i, c := range child {
 		if c.kind == keyValueExpr {
-			return c.cfgErrorf("mixture of field:value and value elements in struct literal")
+			return c.cfgErrorCodef(ErrInvalidOperation, "mixture of field:value and value elements in struct literal")
 		}
 
 		if i >= typ.NumField() {
-			return c.cfgErrorf("too many values in struct literal")
+			return c.cfgErrorCodef(ErrInvalidOperation, "too many values in struct literal")
 		}
 		field := typ.Field(i)
 		if !canExport(field.Name) {
-			return c.cfgErrorf("implicit assignment to unexported field %s in %s literal", field.Name, typ)
+			return c.cfgErrorCodef(ErrInvalidOperation, "implicit assignment to unexported field %s in %s literal", field.Name, typ)
 		}
 
 		if err := check.assignment(c, valueTOf(field.Type), "struct literal"); err != nil {
@@ -486,7 +486,7 @@ func (check typecheck) structBinLitExpr(child []*node, typ reflect.Type) error {
 		}
 	}
 	if len(child) < typ.NumField() {
-		return child[len(child)-1].cfgErrorf("too few values in struct literal")
+		return child[len(child)-1].cfgErrorCodef(ErrInvalidOperation, "too few values in struct literal")
 	}
 	return nil
 }
@@ -495,7 +495,7 @@ func (check typecheck) structBinLitExpr(child []*node, typ reflect.Type) error {
 func (check typecheck) sliceExpr(n *node) error {
 	for _, c := range n.child {
 		if isBlank(c) {
-			return n.cfgErrorf("cannot use _ as value")
+			return n.cfgErrorCodef(ErrInvalidOperation, "cannot use _ as value")
 		}
 	}
 
@@ -530,7 +530,7 @@ func (check typecheck) sliceExpr(n *node) error {
 			l = len(vString(c.rval))
 		}
 		if max != nil {
-			return max.cfgErrorf("invalid operation: 3-index slice of string")
+			return max.cfgErrorCodef(ErrInvalidOperation, "invalid operation: 3-index slice of string")
 		}
 	case reflect.Array:
 		valid = true
@@ -545,7 +545,7 @@ func (check typecheck) sliceExpr(n *node) error {
 		}
 	}
 	if !valid {
-		return c.cfgErrorf("cannot slice type %s", c.typ.id())
+		return c.cfgErrorCodef(ErrInvalidOperation, "cannot slice type %s", c.typ.id())
 	}
 
 	var ind [3]int64
@@ -579,7 +579,7 @@ func (check typecheck) sliceExpr(n *node) error {
 			if y < 0 || x <= y {
 				continue
 			}
-			return n.cfgErrorf("invalid index values, must be low <= high <= max")
+			return n.cfgErrorCodef(ErrInvalidOperation, "invalid index values, must be low <= high <= max")
 		}
 	}
 	return nil
@@ -592,7 +592,7 @@ func (check typecheck) typeAssertionExpr(n *node, typ *itype) error {
 	// type check Named types as they cannot be asserted.
 
 	if rt := n.typ.TypeOf(); rt.Kind() != reflect.Interface && rt != valueInterfaceType {
-		return n.cfgErrorf("invalid type assertion: non-interface type %s on left", n.typ.id())
+		return n.cfgErrorCodef(ErrInvalidOperation, "invalid type assertion: non-interface type %s on left", n.typ.id())
 	}
 	ims := n.typ.methods()
 	if len(ims) == 0 {
@@ -620,10 +620,10 @@ func (check typecheck) typeAssertionExpr(n *node, typ *itype) error {
 			if !token.IsExported(name) && isBin(typ) {
 				continue
 			}
-			return n.cfgErrorf("impossible type assertion: %s does not implement %s (missing %v method)", typ.id(), n.typ.id(), name)
+			return n.cfgErrorCodef(ErrTypeMismatch, "impossible type assertion: %s does not implement %s (missing %v method)", typ.id(), n.typ.id(), name)
 		}
 		if tm.recv != nil && tm.recv.TypeOf().Kind() == reflect.Ptr && typ.TypeOf().Kind() != reflect.Ptr {
-			return n.cfgErrorf("impossible type assertion: %s does not implement %s as %q method has a pointer receiver", typ.id(), n.typ.id(), name)
+			return n.cfgErrorCodef(ErrTypeMismatch, "impossible type assertion: %s does not implement %s as %q method has a pointer receiver", typ.id(), n.typ.id(), name)
 		}
 
 		if im.cat != funcT || tm.cat != funcT {
@@ -631,7 +631,7 @@ func (check typecheck) typeAssertionExpr(n *node, typ *itype) error {
 			continue
 		}
 
-		err := n.cfgErrorf("impossible type assertion: %s does not implement %s", typ.id(), n.typ.id())
+		err := n.cfgErrorCodef(ErrTypeMismatch, "impossible type assertion: %s does not implement %s", typ.id(), n.typ.id())
 		if im.numIn() != tm.numIn() || im.numOut() != tm.numOut() {
 			return err
 		}
@@ -677,7 +677,7 @@ func (check typecheck) conversion(n *node, typ *itype) error {
 		ok = true
 	}
 	if !ok {
-		return n.cfgErrorf("cannot convert expression of type %s to type %s", n.typ.id(), typ.id())
+		return n.cfgErrorCodef(ErrTypeMismatch, "cannot convert expression of type %s to type %s", n.typ.id(), typ.id())
 	}
 	if !n.typ.untyped || c == nil {
 		return nil
@@ -747,7 +747,7 @@ var builtinFuncs = map[string]struct {
 func (check typecheck) builtin(name string, n *node, child []*node, ellipsis bool) error {
 	fun := builtinFuncs[name]
 	if ellipsis && name != bltnAppend {
-		return n.cfgErrorf("invalid use of ... with builtin %s", name)
+		return n.cfgErrorCodef(ErrInvalidOperation, "invalid use of ... with builtin %s", name)
 	}
 
 	var params []param
@@ -761,9 +761,9 @@ func (check typecheck) builtin(name string, n *node, child []*node, ellipsis boo
 	}
 
 	if nparams < fun.args {
-		return n.cfgErrorf("not enough arguments in call to %s", name)
+		return n.cfgErrorCodef(ErrInvalidOperation, "not enough arguments in call to %s", name)
 	} else if !fun.variadic && nparams > fun.args {
-		return n.cfgErrorf("too many arguments for %s", name)
+		return n.cfgErrorCodef(ErrInvalidOperation, "too many arguments for %s", name)
 	}
 
 	switch name {
@@ -771,7 +771,7 @@ func (check typecheck) builtin(name string, n *node, child []*node, ellipsis boo
 		typ := params[0].Type()
 		t := typ.TypeOf()
 		if t == nil || t.Kind() != reflect.Slice {
-			return params[0].nod.cfgErrorf("first argument to append must be slice; have %s", typ.id())
+			return params[0].nod.cfgErrorCodef(ErrInvalidOperation, "first argument to append must be slice; have %s", typ.id())
 		}
 
 		if nparams == 1 {
@@ -808,17 +808,17 @@ func (check typecheck) builtin(name string, n *node, child []*node, ellipsis boo
 			ok = name == bltnLen
 		}
 		if !ok {
-			return params[0].nod.cfgErrorf("invalid argument for %s", name)
+			return params[0].nod.cfgErrorCodef(ErrInvalidOperation, "invalid argument for %s", name)
 		}
 	case bltnClose:
 		p := params[0]
 		typ := p.Type()
 		t := typ.TypeOf()
 		if t.Kind() != reflect.Chan {
-			return p.nod.cfgErrorf("invalid operation: non-chan type %s", p.nod.typ.id())
+			return p.nod.cfgErrorCodef(ErrInvalidOperation, "invalid operation: non-chan type %s", p.nod.typ.id())
 		}
 		if t.ChanDir() == reflect.RecvDir {
-			return p.nod.cfgErrorf("invalid operation: cannot close receive-only channel")
+			return p.nod.cfgErrorCodef(ErrInvalidOperation, "invalid operation: cannot close receive-only channel")
 		}
 	case bltnComplex:
 		var err error
@@ -844,10 +844,10 @@ func (check typecheck) builtin(name string, n *node, child []*node, ellipsis boo
 		// check we have the correct types after conversion.
 		typ0, typ1 = p0.Type(), p1.Type()
 		if !typ0.equals(typ1) {
-			return n.cfgErrorf("invalid operation: mismatched types %s and %s", typ0.id(), typ1.id())
+			return n.cfgErrorCodef(ErrTypeMismatch, "invalid operation: mismatched types %s and %s", typ0.id(), typ1.id())
 		}
 		if !isFloat(typ0.TypeOf()) {
-			return n.cfgErrorf("invalid operation: arguments have type %s, expected floating-point", typ0.id())
+			return n.cfgErrorCodef(ErrInvalidOperation, "invalid operation: arguments have type %s, expected floating-point", typ0.id())
 		}
 	case bltnImag, bltnReal:
 		p := params[0]
@@ -859,7 +859,7 @@ func (check typecheck) builtin(name string, n *node, child []*node, ellipsis boo
 		}
 		typ = p.Type()
 		if !isComplex(typ.TypeOf()) {
-			return p.nod.cfgErrorf("invalid argument type %s for %s", typ.id(), name)
+			return p.nod.cfgErrorCodef(ErrInvalidOperation, "invalid argument type %s for %s", typ.id(), name)
 		}
 	case bltnCopy:
 		typ0, typ1 := params[0].Type(), params[1].Type()
@@ -876,19 +876,19 @@ func (check typecheck) builtin(name string, n *node, child []*node, ellipsis boo
 		}
 
 		if t0 == nil || t1 == nil {
-			return n.cfgErrorf("copy expects slice arguments")
+			return n.cfgErrorCodef(ErrInvalidOperation, "copy expects slice arguments")
 		}
 		if !reflect.DeepEqual(t0, t1) {
-			return n.cfgErrorf("arguments to copy have different element types %s and %s", typ0.id(), typ1.id())
+			return n.cfgErrorCodef(ErrTypeMismatch, "arguments to copy have different element types %s and %s", typ0.id(), typ1.id())
 		}
 	case bltnDelete:
 		typ := params[0].Type()
 		if typ.TypeOf().Kind() != reflect.Map {
-			return params[0].nod.cfgErrorf("first argument to delete must be map; have %s", typ.id())
+			return params[0].nod.cfgErrorCodef(ErrInvalidOperation, "first argument to delete must be map; have %s", typ.id())
 		}
 		ktyp := params[1].Type()
 		if typ.key != nil && !ktyp.assignableTo(typ.key) {
-			return params[1].nod.cfgErrorf("cannot use %s as type %s in delete", ktyp.id(), typ.key.id())
+			return params[1].nod.cfgErrorCodef(ErrTypeMismatch, "cannot use %s as type %s in delete", ktyp.id(), typ.key.id())
 		}
 	case bltnMake:
 		var min int
@@ -898,12 +898,12 @@ func (check typecheck) builtin(name string, n *node, child []*node, ellipsis boo
 		case reflect.Map, reflect.Chan:
 			min = 1
 		default:
-			return child[0].cfgErrorf("cannot make %s; type must be slice, map, or channel", child[0].typ.id())
+			return child[0].cfgErrorCodef(ErrInvalidOperation, "cannot make %s; type must be slice, map, or channel", child[0].typ.id())
 		}
 		if nparams < min {
-			return n.cfgErrorf("not enough arguments in call to make")
+			return n.cfgErrorCodef(ErrInvalidOperation, "not enough arguments in call to make")
 		} else if nparams > min+1 {
-			return n.cfgErrorf("too many arguments for make")
+			return n.cfgErrorCodef(ErrInvalidOperation, "too many arguments for make")
 		}
 
 		var sizes []int
@@ -916,7 +916,7 @@ func (check typecheck) builtin(name string, n *node, child []*node, ellipsis boo
 			}
 		}
 		for len(sizes) == 2 && sizes[0] > sizes[1] {
-			return n.cfgErrorf("len larger than cap in make")
+			return n.cfgErrorCodef(ErrInvalidOperation, "len larger than cap in make")
 		}
 
 	case bltnPanic:
@@ -934,7 +934,7 @@ func (check typecheck) builtin(name string, n *node, child []*node, ellipsis boo
 	case bltnRecover, bltnNew, bltnAlignof, bltnOffsetof, bltnSizeof:
 		// Nothing to do.
 	default:
-		return n.cfgErrorf("unsupported builtin %s", name)
+		return n.cfgErrorCodef(ErrUnsupported, "unsupported builtin %s", name)
 	}
 	return nil
 }
@@ -961,10 +961,10 @@ func (check typecheck) arguments(n *node, child []*node, fun *node, ellipsis boo
 	l := len(child)
 	if ellipsis {
 		if !fun.typ.isVariadic() {
-			return n.cfgErrorf("invalid use of ..., corresponding parameter is non-variadic")
+			return n.cfgErrorCodef(ErrInvalidOperation, "invalid use of ..., corresponding parameter is non-variadic")
 		}
 		if len(params) > l {
-			return child[0].cfgErrorf("cannot use ... with %d-valued %s", child[0].child[0].typ.numOut(), child[0].child[0].typ.id())
+			return child[0].cfgErrorCodef(ErrTypeMismatch, "cannot use ... with %d-valued %s", child[0].child[0].typ.numOut(), child[0].child[0].typ.id())
 		}
 	}
 
@@ -981,7 +981,7 @@ func (check typecheck) arguments(n *node, child []*node, fun *node, ellipsis boo
 		cnt++
 	}
 	if cnt < fun.typ.numIn() {
-		return n.cfgErrorf("not enough arguments in call to %s", fun.name())
+		return n.cfgErrorCodef(ErrInvalidOperation, "not enough arguments in call to %s", fun.name())
 	}
 	return nil
 }
@@ -989,30 +989,30 @@ func (check typecheck) arguments(n *node, child []*node, fun *node, ellipsis boo
 func (check typecheck) argument(p param, ftyp *itype, i, l int, ellipsis bool) error {
 	atyp := getArg(ftyp, i)
 	if atyp == nil {
-		return p.nod.cfgErrorf("too many arguments")
+		return p.nod.cfgErrorCodef(ErrInvalidOperation, "too many arguments")
 	}
 
 	if p.typ == nil && isCall(p.nod) && p.nod.child[0].typ.numOut() != 1 {
 		if l == 1 {
-			return p.nod.cfgErrorf("cannot use %s as type %s", p.nod.child[0].typ.id(), getArgsID(ftyp))
+			return p.nod.cfgErrorCodef(ErrTypeMismatch, "cannot use %s as type %s", p.nod.child[0].typ.id(), getArgsID(ftyp))
 		}
-		return p.nod.cfgErrorf("cannot use %s as type %s", p.nod.child[0].typ.id(), atyp.id())
+		return p.nod.cfgErrorCodef(ErrTypeMismatch, "cannot use %s as type %s", p.nod.child[0].typ.id(), atyp.id())
 	}
 
 	if ellipsis {
 		if i != ftyp.numIn()-1 {
-			return p.nod.cfgErrorf("can only use ... with matching parameter")
+			return p.nod.cfgErrorCodef(ErrInvalidOperation, "can only use ... with matching parameter")
 		}
 		t := p.Type().TypeOf()
 		if t.Kind() != reflect.Slice || !(valueTOf(t.Elem())).assignableTo(atyp) {
-			return p.nod.cfgErrorf("cannot use %s as type %s", p.nod.typ.id(), (sliceOf(atyp)).id())
+			return p.nod.cfgErrorCodef(ErrTypeMismatch, "cannot use %s as type %s", p.nod.typ.id(), (sliceOf(atyp)).id())
 		}
 		return nil
 	}
 
 	if p.typ != nil {
 		if !p.typ.assignableTo(atyp) {
-			return p.nod.cfgErrorf("cannot use %s as type %s", p.nod.child[0].typ.id(), getArgsID(ftyp))
+			return p.nod.cfgErrorCodef(ErrTypeMismatch, "cannot use %s as type %s", p.nod.child[0].typ.id(), getArgsID(ftyp))
 		}
 		return nil
 	}
@@ -1053,7 +1053,7 @@ func (check typecheck) convertUntyped(n *node, typ *itype) error {
 		return nil
 	}
 
-	convErr := n.cfgErrorf("cannot convert %s to %s", n.typ.id(), typ.id())
+	convErr := n.cfgErrorCodef(ErrTypeMismatch, "cannot convert %s to %s", n.typ.id(), typ.id())
 
 	ntyp, ttyp := n.typ.TypeOf(), typ.TypeOf()
 	if typ.untyped {
@@ -1112,7 +1112,7 @@ func (check typecheck) convertUntyped(n *node, typ *itype) error {
 		if errors.Is(err, errCantConvert) {
 			return convErr
 		}
-		return n.cfgErrorf(err.Error())
+		return n.cfgErrorCodef(ErrInvalidOperation, err.Error())
 	}
 	n.typ = ityp
 	return nil
@@ -1140,11 +1140,11 @@ func (check typecheck) representable(n *node, t reflect.Type) error {
 			// float   -> float   : overflows
 			//
 			if !isInt(typ) && isInt(t) {
-				return n.cfgErrorf("%s truncated to %s", c.ExactString(), t.Kind().String())
+				return n.cfgErrorCodef(ErrInvalidOperation, "%s truncated to %s", c.ExactString(), t.Kind().String())
 			}
-			return n.cfgErrorf("%s overflows %s", c.ExactString(), t.Kind().String())
+			return n.cfgErrorCodef(ErrInvalidOperation, "%s overflows %s", c.ExactString(), t.Kind().String())
 		}
-		return n.cfgErrorf("cannot convert %s to %s", c.ExactString(), t.Kind().String())
+		return n.cfgErrorCodef(ErrTypeMismatch, "cannot convert %s to %s", c.ExactString(), t.Kind().String())
 	}
 	return nil
 }
@@ -1163,9 +1163,9 @@ func (check typecheck) convertConst(v reflect.Value, t reflect.Type) (reflect.Va
 	kind := t.Kind()
 	switch kind {
 	case reflect.Bool:
-		v = reflect.ValueOf(constant.BoolVal(c))
+		v = reflect.ValueOf(constant.BoolVal(c)).Convert(t)
 	case reflect.String:
-		v = reflect.ValueOf(constant.StringVal(c))
+		v = reflect.ValueOf(constant.StringVal(c)).Convert(t)
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		i, _ := constant.Int64Val(constant.ToInt(c))
 		v = reflect.ValueOf(i).Convert(t)
@@ -1174,10 +1174,10 @@ func (check typecheck) convertConst(v reflect.Value, t reflect.Type) (reflect.Va
 		v = reflect.ValueOf(i).Convert(t)
 	case reflect.Float32:
 		f, _ := constant.Float32Val(constant.ToFloat(c))
-		v = reflect.ValueOf(f)
+		v = reflect.ValueOf(f).Convert(t)
 	case reflect.Float64:
 		f, _ := constant.Float64Val(constant.ToFloat(c))
-		v = reflect.ValueOf(f)
+		v = reflect.ValueOf(f).Convert(t)
 	case reflect.Complex64:
 		r, _ := constant.Float32Val(constant.Real(c))
 		i, _ := constant.Float32Val(constant.Imag(c))
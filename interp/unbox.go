@@ -0,0 +1,185 @@
+package interp
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// Frame slots of a function holding local variables or temporaries of
+// predeclared scalar types (booleans, integers and floats) can be stored
+// unboxed in the frame.num side array instead of being allocated one by one.
+// The corresponding frame.data values still exist and point into frame.num,
+// so the generic reflect based operations remain valid, while the
+// specialised operations generated in op.go access frame.num directly.
+//
+// This requires that the frame.data entry of an unboxed slot is never
+// rebound to another storage during the function execution. setUnboxed
+// only selects slots which are accessed by nodes known to preserve this
+// property.
+
+// unboxedType returns true if values of type t can be stored unboxed.
+func unboxedType(t reflect.Type) bool {
+	if t == nil || t.PkgPath() != "" || t.Name() == "" {
+		// Only predeclared types, as named types may have methods.
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// setUnboxed computes the frame slots of function declaration def which
+// can be stored unboxed.
+func setUnboxed(def *node) {
+	if def.kind != funcDecl || len(def.child) < 4 || def.child[3] == nil {
+		return
+	}
+	numRet := len(def.typ.ret)
+	unbox := make([]bool, len(def.types))
+	for i, t := range def.types {
+		// Return values may be bound to the caller storage.
+		unbox[i] = i >= numRet && unboxedType(t)
+	}
+	used := make([]bool, len(def.types))
+	exclude := func(i, n int) {
+		for ; n > 0 && i < len(unbox); i, n = i+1, n-1 {
+			unbox[i] = false
+		}
+	}
+
+	closure := false
+	def.child[3].Walk(func(n *node) bool {
+		if n.kind == funcLit {
+			// Closures capture a copy of the frame.
+			closure = true
+			return false
+		}
+		if n.kind == defineXStmt || n.kind == assignXStmt {
+			// The destinations of multiple value assignments may be rebound
+			// to a new storage, by callBin, assignFromCall, recv2, getIndexMap2
+			// or typeAssertLong.
+			for _, c := range n.child[:len(n.child)-1] {
+				if c.level == 0 && c.findex >= 0 {
+					exclude(c.findex, 1)
+				}
+			}
+		}
+		if n.level != 0 || n.findex < 0 || n.findex >= len(unbox) || n.rval.IsValid() {
+			return true
+		}
+		used[n.findex] = true
+		switch n.kind {
+		case identExpr:
+			if n.anc.kind == addressExpr {
+				exclude(n.findex, 1)
+			}
+		case parenExpr, binaryExpr, assignStmt, defineStmt, incDecStmt:
+		case unaryExpr:
+			switch n.action {
+			case aNeg, aPos, aNot, aBitNot:
+			default:
+				exclude(n.findex, 1)
+			}
+		case callExpr:
+			exclude(n.findex, numOut(n.child[0].typ))
+		default:
+			exclude(n.findex, 1)
+		}
+		return true
+	}, nil)
+	if closure {
+		return
+	}
+
+	ok := false
+	for i, u := range unbox {
+		unbox[i] = u && used[i]
+		ok = ok || unbox[i]
+	}
+	if ok {
+		def.unbox = unbox
+	}
+}
+
+// numOut returns the number of results of a call to a function of type t,
+// or 1 if unknown.
+func numOut(t *itype) int {
+	switch {
+	case t == nil:
+	case t.cat == funcT && len(t.ret) > 0:
+		return len(t.ret)
+	case t.cat == valueT && t.rtype != nil && t.rtype.Kind() == reflect.Func && t.rtype.NumOut() > 0:
+		return t.rtype.NumOut()
+	}
+	return 1
+}
+
+// funcDef returns the function declaration whose frame holds the local
+// values of n, or nil.
+func funcDef(n *node) *node {
+	for a := n.anc; a != nil; a = a.anc {
+		switch a.kind {
+		case funcDecl:
+			return a
+		case funcLit:
+			return nil
+		}
+	}
+	return nil
+}
+
+// unboxedIndex returns the frame index of the value of n and its type, if
+// stored unboxed.
+func unboxedIndex(n *node) (int, reflect.Type, bool) {
+	if n.level != 0 || n.findex < 0 || n.rval.IsValid() {
+		return 0, nil, false
+	}
+	def := funcDef(n)
+	if def == nil || def.unbox == nil || n.findex >= len(def.unbox) || !def.unbox[n.findex] {
+		return 0, nil, false
+	}
+	return n.findex, def.types[n.findex], true
+}
+
+// isUnboxed returns true if the value of n is stored unboxed.
+func isUnboxed(n *node) bool {
+	_, _, ok := unboxedIndex(n)
+	return ok
+}
+
+// unboxedOperands returns the unboxed frame indices of the destination d
+// and the operands c0 and c1 of binary operation n, and the type of operands.
+// The index of a constant operand c1 is -1.
+func unboxedOperands(n, d, c0, c1 *node) (id, i0, i1 int, t reflect.Type, ok bool) {
+	if a := n.anc; a.action == aAssign && a.typ.cat == interfaceT || a.kind == returnStmt {
+		// Result may have to be wrapped, see genValueOutput.
+		return
+	}
+	if id, _, ok = unboxedIndex(d); !ok {
+		return
+	}
+	if i0, t, ok = unboxedIndex(c0); !ok {
+		return
+	}
+	if c1.rval.IsValid() {
+		return id, i0, -1, t, true
+	}
+	var t1 reflect.Type
+	if i1, t1, ok = unboxedIndex(c1); !ok || t1 != t {
+		return id, i0, i1, t, false
+	}
+	return id, i0, i1, t, true
+}
+
+// newFrameValue returns a new zero value of type t for the slot i of frame f.
+func newFrameValue(f *frame, unbox []bool, i int, t reflect.Type) reflect.Value {
+	if unbox != nil && unbox[i] {
+		return reflect.NewAt(t, unsafe.Pointer(&f.num[i])).Elem()
+	}
+	return reflect.New(t).Elem()
+}
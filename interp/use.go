@@ -9,6 +9,7 @@ import (
 	"os"
 	"path"
 	"reflect"
+	"sort"
 
 	gen "github.com/traefik/yaegi/stdlib/generic"
 )
@@ -124,6 +125,17 @@ func (interp *Interpreter) Use(values Exports) error {
 			continue
 		}
 
+		if packageName == asmName {
+			// Implementations of functions of a source package.
+			if interp.asm[importPath] == nil {
+				interp.asm[importPath] = make(map[string]reflect.Value)
+			}
+			for s, sym := range v {
+				interp.asm[importPath][s] = sym
+			}
+			continue
+		}
+
 		if interp.binPkg[importPath] == nil {
 			interp.binPkg[importPath] = make(map[string]reflect.Value)
 			interp.pkgNames[importPath] = packageName
@@ -135,6 +147,14 @@ func (interp *Interpreter) Use(values Exports) error {
 		if k == selfPath {
 			interp.binPkg[importPath]["Self"] = reflect.ValueOf(interp)
 		}
+		if importPath == cgoPath {
+			fixC(interp.binPkg[importPath])
+		}
+	}
+
+	if _, ok := values["os/exec/exec"]; ok && interp.runner != nil {
+		// Keep the command runner in place of the unrestricted os/exec symbols.
+		fixExec(interp)
 	}
 
 	// Checks if input values correspond to stdlib packages by looking for one
@@ -249,6 +269,7 @@ func fixStdlib(interp *Interpreter) {
 				for k, v := range interp.env {
 					a = append(a, k+"="+v)
 				}
+				sort.Strings(a)
 				return
 			})
 		}
@@ -258,4 +279,16 @@ func fixStdlib(interp *Interpreter) {
 		// Do not trust extracted value maybe from another arch.
 		p["UintSize"] = reflect.ValueOf(constant.MakeInt64(bits.UintSize))
 	}
+
+	if interp.network != nil {
+		fixNetwork(interp)
+	}
+
+	if interp.runner != nil {
+		fixExec(interp)
+	}
+
+	if interp.sched != nil {
+		fixDeterministic(interp)
+	}
 }
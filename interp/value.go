@@ -215,7 +215,7 @@ func genValueRangeArray(n *node) func(*frame) reflect.Value {
 					case valueInterface:
 						v = append(v, av)
 					default:
-						panic(n.cfgErrorf("invalid type %v", val.Index(i).Type()))
+						panic(n.cfgErrorCodef(ErrInvalidOperation, "invalid type %v", val.Index(i).Type()))
 					}
 				}
 				return reflect.ValueOf(v)
@@ -0,0 +1,1492 @@
+package interp
+
+import (
+	"go/constant"
+	"reflect"
+	"unsafe"
+)
+
+// This file implements an optional execution tier for interpreted functions.
+//
+// Functions which operate only on primitive values (booleans, integers,
+// floats, strings and slices of those) are lowered after CFG generation
+// into a compact register bytecode, where registers are stored unboxed
+// in typed arrays instead of reflect.Value frames. Calls to such functions
+// from the closure interpreter are dispatched to the bytecode, and calls
+// between bytecode functions stay in the bytecode machine.
+//
+// Any function containing a construct not supported here is left to the
+// closure interpreter, which remains the reference implementation.
+
+// vmClass is the storage class of a bytecode register.
+type vmClass uint8
+
+const (
+	vmNone   vmClass = iota
+	vmInt            // signed integer, stored in ints
+	vmUint           // unsigned integer, stored as bits in ints
+	vmBool           // boolean, stored as 0 or 1 in ints
+	vmFloat          // floating point, stored in floats
+	vmString         // string, stored in strs
+	vmSlice          // slice of primitive values, stored in vals
+)
+
+// vmClassOf returns the storage class for values of type t, or vmNone if
+// type t is not supported by the bytecode.
+func vmClassOf(t reflect.Type) vmClass {
+	if t == nil {
+		return vmNone
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return vmInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return vmUint
+	case reflect.Bool:
+		return vmBool
+	case reflect.Float32, reflect.Float64:
+		return vmFloat
+	case reflect.String:
+		return vmString
+	case reflect.Slice:
+		if c := vmClassOf(t.Elem()); c != vmNone && c != vmSlice {
+			return vmSlice
+		}
+	}
+	return vmNone
+}
+
+// vmOp is a bytecode operation code.
+type vmOp uint8
+
+// Bytecode operations. Unless specified, operands a, b and c are register
+// indices, with a being the destination.
+const (
+	opNop vmOp = iota
+	opMovI
+	opMovF
+	opMovS
+	opMovV
+	opWrapI // sign extend a from b bits
+	opWrapU // zero extend a from b bits
+	opRoundF32
+	opAddI
+	opSubI
+	opMulI
+	opQuoI
+	opQuoU
+	opRemI
+	opRemU
+	opAndI
+	opOrI
+	opXorI
+	opAndNotI
+	opShlI
+	opShrI
+	opShrU
+	opNegI
+	opBitNotI
+	opNotB
+	opEqI
+	opNeI
+	opLtI
+	opLeI
+	opGtI
+	opGeI
+	opLtU
+	opLeU
+	opGtU
+	opGeU
+	opAddF
+	opSubF
+	opMulF
+	opQuoF
+	opNegF
+	opEqF
+	opNeF
+	opLtF
+	opLeF
+	opGtF
+	opGeF
+	opAddS
+	opEqS
+	opNeS
+	opLtS
+	opLeS
+	opGtS
+	opGeS
+	opLenS
+	opIndexS
+	opIToF
+	opUToF
+	opFToI
+	opFToU
+	opLenV
+	opCapV
+	opIndexI // a = b[c] for signed integer elements
+	opIndexU
+	opIndexB
+	opIndexF
+	opIndexS2 // a = b[c] for string elements
+	opSetIndexI
+	opSetIndexU
+	opSetIndexB
+	opSetIndexF
+	opSetIndexS
+	opMake   // a = make(types[a], b, c)
+	opAppend // a = append(b, c)
+	opJmp    // jump to a
+	opJmpF   // jump to a if b is false
+	opJmpT   // jump to a if b is true
+	opLoop   // jump to a, checking for cancellation
+	opCall   // call calls[a]
+	opRet
+)
+
+// vmInstr is a bytecode instruction.
+type vmInstr struct {
+	op      vmOp
+	a, b, c int32
+}
+
+// vmCall describes a call site in a bytecode function.
+type vmCall struct {
+	fn   *vmFunc
+	args []int32 // registers of arguments in caller
+	rets []int32 // registers receiving results in caller
+}
+
+// vmFunc is an interpreted function lowered to bytecode.
+type vmFunc struct {
+	name   string
+	done   bool // bytecode is complete and can be executed
+	code   []vmInstr
+	nret   int            // number of results, in registers [0, nret)
+	narg   int            // number of parameters, in registers [nret, nret+narg)
+	class  []vmClass      // storage class per register
+	types  []reflect.Type // type per register
+	ints   []int64        // initial integer registers (constants)
+	floats []float64      // initial float registers (constants)
+	strs   []string       // initial string registers (constants)
+	vals   []vmSliceVal   // initial slice registers (zero values)
+	calls  []vmCall
+	callee []*node // functions called by this one, to invalidate on failure
+}
+
+func (fn *vmFunc) nregs() int { return len(fn.class) }
+
+// vmStack holds the registers of all active bytecode functions of a call chain.
+// Each function uses a window of the stack arrays starting at its base.
+type vmStack struct {
+	ints    []int64
+	floats  []float64
+	strs    []string
+	vals    []vmSliceVal
+	interp  *Interpreter
+	f       *frame // frame of caller in closure interpreter, for cancellation
+	stopped bool
+}
+
+// enter initializes the register window of fn at base.
+func (s *vmStack) enter(fn *vmFunc, base int) {
+	end := base + fn.nregs()
+	if end > len(s.ints) {
+		l := 2*len(s.ints) + fn.nregs()
+		s.ints = append(s.ints, make([]int64, l-len(s.ints))...)
+		s.floats = append(s.floats, make([]float64, l-len(s.floats))...)
+		s.strs = append(s.strs, make([]string, l-len(s.strs))...)
+		s.vals = append(s.vals, make([]vmSliceVal, l-len(s.vals))...)
+	}
+	copy(s.ints[base:end], fn.ints)
+	copy(s.floats[base:end], fn.floats)
+	copy(s.strs[base:end], fn.strs)
+	copy(s.vals[base:end], fn.vals)
+}
+
+// set stores the value v in register r of the window at base.
+func (s *vmStack) set(fn *vmFunc, base, r int, v reflect.Value) {
+	switch fn.class[r] {
+	case vmInt:
+		s.ints[base+r] = v.Int()
+	case vmUint:
+		s.ints[base+r] = int64(v.Uint())
+	case vmBool:
+		s.ints[base+r] = b2i(v.Bool())
+	case vmFloat:
+		s.floats[base+r] = v.Float()
+	case vmString:
+		s.strs[base+r] = v.String()
+	case vmSlice:
+		s.vals[base+r] = vmSliceOf(v)
+	}
+}
+
+// value returns register r of the window at base as a reflect value.
+func (s *vmStack) value(fn *vmFunc, base, r int) reflect.Value {
+	if fn.class[r] == vmSlice {
+		return s.vals[base+r].value()
+	}
+	v := reflect.New(fn.types[r]).Elem()
+	switch fn.class[r] {
+	case vmInt:
+		v.SetInt(s.ints[base+r])
+	case vmUint:
+		v.SetUint(uint64(s.ints[base+r]))
+	case vmBool:
+		v.SetBool(s.ints[base+r] != 0)
+	case vmFloat:
+		v.SetFloat(s.floats[base+r])
+	case vmString:
+		v.SetString(s.strs[base+r])
+	}
+	return v
+}
+
+func b2i(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// run executes the bytecode function fn using the register window at base.
+func (s *vmStack) run(fn *vmFunc, base int) {
+	end := base + fn.nregs()
+	ints, floats, strs, vals := s.ints[base:end], s.floats[base:end], s.strs[base:end], s.vals[base:end]
+	code := fn.code
+	interp := s.interp
+
+	for pc := 0; pc < len(code); pc++ {
+		in := &code[pc]
+		a, b, c := in.a, in.b, in.c
+		switch in.op {
+		case opNop:
+		case opMovI:
+			ints[a] = ints[b]
+		case opMovF:
+			floats[a] = floats[b]
+		case opMovS:
+			strs[a] = strs[b]
+		case opMovV:
+			vals[a] = vals[b]
+		case opWrapI:
+			ints[a] = ints[a] << (64 - b) >> (64 - b)
+		case opWrapU:
+			ints[a] = int64(uint64(ints[a]) << (64 - b) >> (64 - b))
+		case opRoundF32:
+			floats[a] = float64(float32(floats[a]))
+		case opAddI:
+			ints[a] = ints[b] + ints[c]
+		case opSubI:
+			ints[a] = ints[b] - ints[c]
+		case opMulI:
+			ints[a] = ints[b] * ints[c]
+		case opQuoI:
+			ints[a] = ints[b] / ints[c]
+		case opQuoU:
+			ints[a] = int64(uint64(ints[b]) / uint64(ints[c]))
+		case opRemI:
+			ints[a] = ints[b] % ints[c]
+		case opRemU:
+			ints[a] = int64(uint64(ints[b]) % uint64(ints[c]))
+		case opAndI:
+			ints[a] = ints[b] & ints[c]
+		case opOrI:
+			ints[a] = ints[b] | ints[c]
+		case opXorI:
+			ints[a] = ints[b] ^ ints[c]
+		case opAndNotI:
+			ints[a] = ints[b] &^ ints[c]
+		case opShlI:
+			ints[a] = ints[b] << uint64(ints[c])
+		case opShrI:
+			ints[a] = ints[b] >> uint64(ints[c])
+		case opShrU:
+			ints[a] = int64(uint64(ints[b]) >> uint64(ints[c]))
+		case opNegI:
+			ints[a] = -ints[b]
+		case opBitNotI:
+			ints[a] = ^ints[b]
+		case opNotB:
+			ints[a] = ints[b] ^ 1
+		case opEqI:
+			ints[a] = b2i(ints[b] == ints[c])
+		case opNeI:
+			ints[a] = b2i(ints[b] != ints[c])
+		case opLtI:
+			ints[a] = b2i(ints[b] < ints[c])
+		case opLeI:
+			ints[a] = b2i(ints[b] <= ints[c])
+		case opGtI:
+			ints[a] = b2i(ints[b] > ints[c])
+		case opGeI:
+			ints[a] = b2i(ints[b] >= ints[c])
+		case opLtU:
+			ints[a] = b2i(uint64(ints[b]) < uint64(ints[c]))
+		case opLeU:
+			ints[a] = b2i(uint64(ints[b]) <= uint64(ints[c]))
+		case opGtU:
+			ints[a] = b2i(uint64(ints[b]) > uint64(ints[c]))
+		case opGeU:
+			ints[a] = b2i(uint64(ints[b]) >= uint64(ints[c]))
+		case opAddF:
+			floats[a] = floats[b] + floats[c]
+		case opSubF:
+			floats[a] = floats[b] - floats[c]
+		case opMulF:
+			floats[a] = floats[b] * floats[c]
+		case opQuoF:
+			floats[a] = floats[b] / floats[c]
+		case opNegF:
+			floats[a] = -floats[b]
+		case opEqF:
+			ints[a] = b2i(floats[b] == floats[c])
+		case opNeF:
+			ints[a] = b2i(floats[b] != floats[c])
+		case opLtF:
+			ints[a] = b2i(floats[b] < floats[c])
+		case opLeF:
+			ints[a] = b2i(floats[b] <= floats[c])
+		case opGtF:
+			ints[a] = b2i(floats[b] > floats[c])
+		case opGeF:
+			ints[a] = b2i(floats[b] >= floats[c])
+		case opAddS:
+			strs[a] = strs[b] + strs[c]
+		case opEqS:
+			ints[a] = b2i(strs[b] == strs[c])
+		case opNeS:
+			ints[a] = b2i(strs[b] != strs[c])
+		case opLtS:
+			ints[a] = b2i(strs[b] < strs[c])
+		case opLeS:
+			ints[a] = b2i(strs[b] <= strs[c])
+		case opGtS:
+			ints[a] = b2i(strs[b] > strs[c])
+		case opGeS:
+			ints[a] = b2i(strs[b] >= strs[c])
+		case opLenS:
+			ints[a] = int64(len(strs[b]))
+		case opIndexS:
+			ints[a] = int64(strs[b][ints[c]])
+		case opIToF:
+			floats[a] = float64(ints[b])
+		case opUToF:
+			floats[a] = float64(uint64(ints[b]))
+		case opFToI:
+			ints[a] = int64(floats[b])
+		case opFToU:
+			ints[a] = int64(uint64(floats[b]))
+		case opLenV:
+			ints[a] = int64(vals[b].len)
+		case opCapV:
+			ints[a] = int64(vals[b].cap)
+		case opIndexI:
+			ints[a] = vals[b].loadInt(ints[c])
+		case opIndexU:
+			ints[a] = int64(vals[b].loadUint(ints[c]))
+		case opIndexB:
+			ints[a] = b2i(*(*bool)(vals[b].elem(ints[c])))
+		case opIndexF:
+			floats[a] = vals[b].loadFloat(ints[c])
+		case opIndexS2:
+			strs[a] = *(*string)(vals[b].elem(ints[c]))
+		case opSetIndexI, opSetIndexU:
+			vals[a].storeInt(ints[b], ints[c])
+		case opSetIndexB:
+			*(*bool)(vals[a].elem(ints[b])) = ints[c] != 0
+		case opSetIndexF:
+			vals[a].storeFloat(ints[b], floats[c])
+		case opSetIndexS:
+			*(*string)(vals[a].elem(ints[b])) = strs[c]
+		case opMake:
+			vals[a] = vmSliceOf(reflect.MakeSlice(fn.types[a], int(ints[b]), int(ints[c])))
+		case opAppend:
+			vals[a] = vmAppend(fn, vals[b], ints, floats, strs, c)
+		case opJmp:
+			pc = int(a) - 1
+		case opJmpF:
+			if ints[b] == 0 {
+				pc = int(a) - 1
+			}
+		case opJmpT:
+			if ints[b] != 0 {
+				pc = int(a) - 1
+			}
+		case opLoop:
+			if s.f.runid() != interp.runid() {
+				s.stopped = true
+				return
+			}
+			pc = int(a) - 1
+		case opCall:
+			call := &fn.calls[a]
+			nbase := end
+			s.enter(call.fn, nbase)
+			// Stack arrays may have been reallocated.
+			ints, floats, strs, vals = s.ints[base:end], s.floats[base:end], s.strs[base:end], s.vals[base:end]
+			for i, r := range call.args {
+				d := nbase + call.fn.nret + i
+				switch call.fn.class[call.fn.nret+i] {
+				case vmInt, vmUint, vmBool:
+					s.ints[d] = ints[r]
+				case vmFloat:
+					s.floats[d] = floats[r]
+				case vmString:
+					s.strs[d] = strs[r]
+				case vmSlice:
+					s.vals[d] = vals[r]
+				}
+			}
+			if s.f.runid() != interp.runid() {
+				s.stopped = true
+				return
+			}
+			s.run(call.fn, nbase)
+			if s.stopped {
+				return
+			}
+			ints, floats, strs, vals = s.ints[base:end], s.floats[base:end], s.strs[base:end], s.vals[base:end]
+			for i, r := range call.rets {
+				src := nbase + i
+				switch call.fn.class[i] {
+				case vmInt, vmUint, vmBool:
+					ints[r] = s.ints[src]
+				case vmFloat:
+					floats[r] = s.floats[src]
+				case vmString:
+					strs[r] = s.strs[src]
+				case vmSlice:
+					vals[r] = s.vals[src]
+				}
+			}
+		case opRet:
+			return
+		}
+	}
+}
+
+// vmSliceVal is the representation of a slice in a bytecode register. It
+// allows to access elements directly, without reflect.
+type vmSliceVal struct {
+	ptr      unsafe.Pointer // pointer to first element
+	len, cap int
+	typ      reflect.Type // slice type
+	kind     reflect.Kind // element kind
+	size     uintptr      // element size
+}
+
+func vmSliceOf(v reflect.Value) vmSliceVal {
+	return vmSliceVal{
+		ptr:  v.UnsafePointer(),
+		len:  v.Len(),
+		cap:  v.Cap(),
+		typ:  v.Type(),
+		kind: v.Type().Elem().Kind(),
+		size: v.Type().Elem().Size(),
+	}
+}
+
+// value returns the slice as a reflect value.
+func (s vmSliceVal) value() reflect.Value {
+	h := &struct {
+		ptr      unsafe.Pointer
+		len, cap int
+	}{s.ptr, s.len, s.cap}
+	return reflect.NewAt(s.typ, unsafe.Pointer(h)).Elem()
+}
+
+// elem returns a pointer to the element at index i, or panics with the same
+// error as the Go runtime if i is out of range.
+func (s vmSliceVal) elem(i int64) unsafe.Pointer {
+	if uint64(i) >= uint64(s.len) {
+		_ = make([]struct{}, s.len)[i]
+	}
+	return unsafe.Add(s.ptr, uintptr(i)*s.size)
+}
+
+func (s vmSliceVal) loadInt(i int64) int64 {
+	p := s.elem(i)
+	switch s.kind {
+	case reflect.Int8:
+		return int64(*(*int8)(p))
+	case reflect.Int16:
+		return int64(*(*int16)(p))
+	case reflect.Int32:
+		return int64(*(*int32)(p))
+	case reflect.Int:
+		return int64(*(*int)(p))
+	}
+	return *(*int64)(p)
+}
+
+func (s vmSliceVal) loadUint(i int64) uint64 {
+	p := s.elem(i)
+	switch s.kind {
+	case reflect.Uint8:
+		return uint64(*(*uint8)(p))
+	case reflect.Uint16:
+		return uint64(*(*uint16)(p))
+	case reflect.Uint32:
+		return uint64(*(*uint32)(p))
+	case reflect.Uint:
+		return uint64(*(*uint)(p))
+	case reflect.Uintptr:
+		return uint64(*(*uintptr)(p))
+	}
+	return *(*uint64)(p)
+}
+
+func (s vmSliceVal) storeInt(i, v int64) {
+	p := s.elem(i)
+	switch s.size {
+	case 1:
+		*(*int8)(p) = int8(v)
+	case 2:
+		*(*int16)(p) = int16(v)
+	case 4:
+		*(*int32)(p) = int32(v)
+	default:
+		*(*int64)(p) = v
+	}
+}
+
+func (s vmSliceVal) loadFloat(i int64) float64 {
+	p := s.elem(i)
+	if s.kind == reflect.Float32 {
+		return float64(*(*float32)(p))
+	}
+	return *(*float64)(p)
+}
+
+func (s vmSliceVal) storeFloat(i int64, v float64) {
+	p := s.elem(i)
+	if s.kind == reflect.Float32 {
+		*(*float32)(p) = float32(v)
+		return
+	}
+	*(*float64)(p) = v
+}
+
+func vmAppend(fn *vmFunc, s vmSliceVal, ints []int64, floats []float64, strs []string, r int32) vmSliceVal {
+	v := s.value()
+	e := reflect.New(v.Type().Elem()).Elem()
+	switch fn.class[r] {
+	case vmInt:
+		e.SetInt(ints[r])
+	case vmUint:
+		e.SetUint(uint64(ints[r]))
+	case vmBool:
+		e.SetBool(ints[r] != 0)
+	case vmFloat:
+		e.SetFloat(floats[r])
+	case vmString:
+		e.SetString(strs[r])
+	}
+	return vmSliceOf(reflect.Append(v, e))
+}
+
+// callVM executes the bytecode function fn with the given arguments, from
+// the closure interpreter frame f. It returns nil if execution was cancelled.
+func callVM(interp *Interpreter, fn *vmFunc, f *frame, args []reflect.Value) []reflect.Value {
+	s := &vmStack{interp: interp, f: f}
+	s.enter(fn, 0)
+	for i, a := range args {
+		s.set(fn, 0, fn.nret+i, a)
+	}
+	s.run(fn, 0)
+	if s.stopped {
+		return nil
+	}
+	res := make([]reflect.Value, fn.nret)
+	for i := range res {
+		res[i] = s.value(fn, 0, i)
+	}
+	return res
+}
+
+// vmLoopCtx records pending jumps of break and continue statements in a loop.
+type vmLoopCtx struct {
+	breaks, continues []int
+}
+
+// vmCompiler lowers a function declaration to bytecode.
+type vmCompiler struct {
+	fn    *vmFunc
+	def   *node
+	loops []*vmLoopCtx
+}
+
+// vmError is used to abort the lowering of a function.
+type vmError struct{ n *node }
+
+// compileVM attempts to lower the function declaration def, and the
+// functions it calls, to bytecode. On success, def.vm is set and ready to
+// execute.
+func compileVM(def *node) {
+	pending := map[*node]*vmFunc{}
+	compileVMFunc(def, pending)
+
+	// Functions calling a function which could not be compiled are
+	// themselves invalid. This may happen on mutual recursion.
+	for changed := true; changed; {
+		changed = false
+		for n, fn := range pending {
+			if fn == nil {
+				continue
+			}
+			for _, c := range fn.callee {
+				if c.vm == nil || pending[c] == nil && !c.vm.done {
+					pending[n] = nil
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	for n, fn := range pending {
+		if fn == nil {
+			n.vm = &vmFunc{} // Mark as not compilable.
+			continue
+		}
+		fn.done = true
+	}
+}
+
+// compileVMFunc lowers the function def. It returns nil if the function
+// can not be lowered.
+func compileVMFunc(def *node, pending map[*node]*vmFunc) (fn *vmFunc) {
+	if def.vm != nil {
+		if def.vm.code == nil && !def.vm.done {
+			if pfn, ok := pending[def]; ok {
+				return pfn // Recursive call, compilation is in progress.
+			}
+			return nil
+		}
+		return def.vm
+	}
+	if !vmCompilable(def) {
+		def.vm = &vmFunc{}
+		return nil
+	}
+
+	fn = &vmFunc{name: def.child[1].ident}
+	def.vm = fn
+	pending[def] = fn
+	c := &vmCompiler{fn: fn, def: def}
+
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(vmError); !ok {
+				panic(r)
+			}
+			pending[def] = nil
+			def.vm = &vmFunc{}
+			fn = nil
+		}
+	}()
+
+	for _, t := range def.types {
+		c.newReg(t)
+	}
+	fn.nret = def.typ.numOut()
+	fn.narg = len(def.typ.arg)
+	c.block(def.child[3], pending)
+	c.emit(opRet, 0, 0, 0)
+	return fn
+}
+
+// vmCompilable returns true if the function declaration def may be lowered
+// to bytecode: a plain function operating only on supported types.
+func vmCompilable(def *node) bool {
+	if def.kind != funcDecl || len(def.child) < 4 || isMethod(def) || def.typ == nil {
+		return false
+	}
+	if len(def.child[2].child[0].child) > 0 {
+		return false // Generic function.
+	}
+	for _, t := range def.typ.arg {
+		if t.cat == variadicT || vmClassOf(t.TypeOf()) == vmNone {
+			return false
+		}
+	}
+	for _, f := range def.child[2].child[1].child {
+		if len(f.child) < 2 {
+			return false // Unnamed parameters have no frame slot.
+		}
+	}
+	for _, t := range def.types {
+		if vmClassOf(t) == vmNone {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *vmCompiler) fail(n *node) { panic(vmError{n}) }
+
+func (c *vmCompiler) emit(op vmOp, a, b, d int) int {
+	c.fn.code = append(c.fn.code, vmInstr{op, int32(a), int32(b), int32(d)})
+	return len(c.fn.code) - 1
+}
+
+func (c *vmCompiler) pc() int { return len(c.fn.code) }
+
+func (c *vmCompiler) patch(i, target int) { c.fn.code[i].a = int32(target) }
+
+// newReg allocates a register for values of type t.
+func (c *vmCompiler) newReg(t reflect.Type) int {
+	fn := c.fn
+	fn.class = append(fn.class, vmClassOf(t))
+	fn.types = append(fn.types, t)
+	fn.ints = append(fn.ints, 0)
+	fn.floats = append(fn.floats, 0)
+	fn.strs = append(fn.strs, "")
+	var v vmSliceVal
+	if fn.class[len(fn.class)-1] == vmSlice {
+		v = vmSliceOf(reflect.Zero(t))
+	}
+	fn.vals = append(fn.vals, v)
+	return len(fn.class) - 1
+}
+
+// constReg allocates a register initialized to the constant value v, of type t.
+func (c *vmCompiler) constReg(n *node, v reflect.Value, t reflect.Type) int {
+	r := c.newReg(t)
+	switch c.fn.class[r] {
+	case vmInt:
+		c.fn.ints[r] = vInt(v)
+	case vmUint:
+		c.fn.ints[r] = int64(vUint(v))
+	case vmBool:
+		if cv := vConstantValue(v); cv != nil {
+			c.fn.ints[r] = b2i(constant.BoolVal(cv))
+		} else if v.Kind() == reflect.Bool {
+			c.fn.ints[r] = b2i(v.Bool())
+		} else {
+			c.fail(n)
+		}
+	case vmFloat:
+		c.fn.floats[r] = vFloat(v)
+		if t.Kind() == reflect.Float32 {
+			c.fn.floats[r] = float64(float32(c.fn.floats[r]))
+		}
+	case vmString:
+		if v.Kind() != reflect.String && vConstantValue(v) == nil {
+			c.fail(n)
+		}
+		c.fn.strs[r] = vString(v)
+	default:
+		c.fail(n)
+	}
+	return r
+}
+
+// mov emits a register copy according to the class of dest.
+func (c *vmCompiler) mov(dest, src int) {
+	if dest == src {
+		return
+	}
+	switch c.fn.class[dest] {
+	case vmInt, vmUint, vmBool:
+		c.emit(opMovI, dest, src, 0)
+	case vmFloat:
+		c.emit(opMovF, dest, src, 0)
+	case vmString:
+		c.emit(opMovS, dest, src, 0)
+	case vmSlice:
+		c.emit(opMovV, dest, src, 0)
+	}
+}
+
+// wrap emits the truncation of the integer register r to the size of its type.
+func (c *vmCompiler) wrap(r int) {
+	t := c.fn.types[r]
+	bits := int(t.Size()) * 8
+	switch {
+	case bits >= 64:
+	case c.fn.class[r] == vmInt:
+		c.emit(opWrapI, r, bits, 0)
+	case c.fn.class[r] == vmUint:
+		c.emit(opWrapU, r, bits, 0)
+	case c.fn.class[r] == vmFloat && t.Kind() == reflect.Float32:
+		c.emit(opRoundF32, r, 0, 0)
+	}
+}
+
+// local returns the register of a local variable.
+func (c *vmCompiler) local(n *node) int {
+	if n.kind != identExpr || n.level != 0 || (n.sym != nil && n.sym.kind != varSym) || n.findex < 0 || n.findex >= len(c.def.types) {
+		c.fail(n)
+	}
+	return n.findex
+}
+
+func (c *vmCompiler) block(n *node, pending map[*node]*vmFunc) {
+	for _, s := range n.child {
+		c.stmt(s, pending)
+	}
+}
+
+func (c *vmCompiler) stmt(n *node, pending map[*node]*vmFunc) {
+	switch n.kind {
+	case blockStmt:
+		c.block(n, pending)
+
+	case identExpr:
+		// Per iteration copy of a loop variable, see loopVarFor, loopVarKey
+		// and loopVarVal.
+		if n.ident == "" || n.findex < 0 || n.anc.anc == nil {
+			break
+		}
+		var src *node
+		switch l := n.anc.anc; {
+		case l.kind == forStmt7:
+			src = l.child[0].child[0]
+		case l.kind == rangeStmt && n == n.anc.child[0]:
+			src = l.child[0]
+		case l.kind == rangeStmt && n == n.anc.child[1]:
+			src = l.child[1]
+		default:
+			c.fail(n)
+		}
+		c.mov(c.local(n), c.local(src))
+
+	case exprStmt:
+		c.expr(n.child[0], nil, pending)
+
+	case declStmt:
+		for _, d := range n.child {
+			if d.kind != varDecl {
+				c.fail(d)
+			}
+			for _, s := range d.child {
+				c.stmt(s, pending)
+			}
+		}
+
+	case defineStmt, assignStmt:
+		if n.action != aAssign {
+			c.opAssign(n, pending)
+			break
+		}
+		lhs := n.child[:n.nleft]
+		if n.nright == 0 {
+			// Variable declaration without value: reset to zero.
+			for _, l := range lhs {
+				r := c.local(l)
+				if c.fn.class[r] == vmSlice {
+					c.mov(r, c.newReg(c.fn.types[r]))
+					continue
+				}
+				c.mov(r, c.constReg(l, reflect.New(c.fn.types[r]).Elem(), c.fn.types[r]))
+			}
+			break
+		}
+		rhs := n.child[len(n.child)-n.nright:]
+		if len(lhs) != len(rhs) {
+			c.fail(n)
+		}
+		regs := make([]int, len(rhs))
+		for i, r := range rhs {
+			var t reflect.Type
+			if lhs[i].ident != "_" {
+				t = c.lhsType(lhs[i])
+			}
+			regs[i] = c.expr(r, t, pending)
+		}
+		if len(rhs) > 1 {
+			// Parallel assignment: evaluate all values before storing.
+			for i, r := range regs {
+				tmp := c.newReg(c.fn.types[r])
+				c.mov(tmp, r)
+				regs[i] = tmp
+			}
+		}
+		for i, l := range lhs {
+			c.store(l, regs[i], pending)
+		}
+
+	case defineXStmt, assignXStmt:
+		call := n.child[n.nleft]
+		if call.kind != callExpr {
+			c.fail(n)
+		}
+		rets := c.call(call, pending)
+		for i, l := range n.child[:n.nleft] {
+			c.store(l, rets[i], pending)
+		}
+
+	case incDecStmt:
+		l := n.child[0]
+		r := c.load(l, pending)
+		one := c.constReg(n, reflect.ValueOf(constant.MakeInt64(1)), c.fn.types[r])
+		res := c.newReg(c.fn.types[r])
+		op := map[vmClass][2]vmOp{vmInt: {opAddI, opSubI}, vmUint: {opAddI, opSubI}, vmFloat: {opAddF, opSubF}}[c.fn.class[r]]
+		if op[0] == opNop {
+			c.fail(n)
+		}
+		if n.action == aInc {
+			c.emit(op[0], res, r, one)
+		} else {
+			c.emit(op[1], res, r, one)
+		}
+		c.wrap(res)
+		c.store(l, res, pending)
+
+	case ifStmt0, ifStmt1, ifStmt2, ifStmt3:
+		child := n.child
+		if n.kind == ifStmt2 || n.kind == ifStmt3 {
+			c.stmt(child[0], pending)
+			child = child[1:]
+		}
+		cond := c.expr(child[0], reflect.TypeOf(false), pending)
+		jf := c.emit(opJmpF, 0, cond, 0)
+		c.stmt(child[1], pending)
+		if len(child) < 3 {
+			c.patch(jf, c.pc())
+			break
+		}
+		je := c.emit(opJmp, 0, 0, 0)
+		c.patch(jf, c.pc())
+		c.stmt(child[2], pending)
+		c.patch(je, c.pc())
+
+	case forStmt0, forStmt1, forStmt2, forStmt3, forStmt4, forStmt5, forStmt6, forStmt7:
+		var init, cond, post *node
+		child := n.child
+		switch n.kind {
+		case forStmt1:
+			init = child[0]
+		case forStmt2:
+			cond = child[0]
+		case forStmt3:
+			init, cond = child[0], child[1]
+		case forStmt4:
+			post = child[0]
+		case forStmt5:
+			cond, post = child[0], child[1]
+		case forStmt6:
+			init, post = child[0], child[1]
+		case forStmt7:
+			init, cond, post = child[0], child[1], child[2]
+		}
+		body := n.lastChild()
+		if init != nil {
+			c.stmt(init, pending)
+		}
+		loop := &vmLoopCtx{}
+		c.loops = append(c.loops, loop)
+		top := c.pc()
+		exit := -1
+		if cond != nil {
+			exit = c.emit(opJmpF, 0, c.expr(cond, reflect.TypeOf(false), pending), 0)
+		}
+		c.stmt(body, pending)
+		cont := c.pc()
+		if post != nil {
+			c.stmt(post, pending)
+		}
+		c.emit(opLoop, top, 0, 0)
+		c.endLoop(loop, exit, cont)
+
+	case forRangeStmt:
+		c.rangeLoop(n.child[0], pending)
+
+	case breakStmt, continueStmt:
+		if len(n.child) > 0 || len(c.loops) == 0 {
+			c.fail(n) // Labels are not supported.
+		}
+		loop := c.loops[len(c.loops)-1]
+		j := c.emit(opJmp, 0, 0, 0)
+		if n.kind == breakStmt {
+			loop.breaks = append(loop.breaks, j)
+		} else {
+			loop.continues = append(loop.continues, j)
+		}
+
+	case returnStmt:
+		switch {
+		case len(n.child) == 1 && c.fn.nret > 1:
+			for i, r := range c.call(n.child[0], pending) {
+				c.mov(i, r)
+			}
+		default:
+			regs := make([]int, len(n.child))
+			for i, r := range n.child {
+				regs[i] = c.expr(r, c.fn.types[i], pending)
+			}
+			for i, r := range regs {
+				c.mov(i, r)
+			}
+		}
+		c.emit(opRet, 0, 0, 0)
+
+	default:
+		c.fail(n)
+	}
+}
+
+// endLoop patches the jumps of loop.
+func (c *vmCompiler) endLoop(loop *vmLoopCtx, exit, cont int) {
+	end := c.pc()
+	if exit >= 0 {
+		c.patch(exit, end)
+	}
+	for _, j := range loop.breaks {
+		c.patch(j, end)
+	}
+	for _, j := range loop.continues {
+		c.patch(j, cont)
+	}
+	c.loops = c.loops[:len(c.loops)-1]
+}
+
+// rangeLoop lowers a range statement over an integer or a slice.
+func (c *vmCompiler) rangeLoop(n *node, pending map[*node]*vmFunc) {
+	var k, v, o, body *node
+	if len(n.child) == 4 {
+		k, v, o, body = n.child[0], n.child[1], n.child[2], n.child[3]
+	} else {
+		k, o, body = n.child[0], n.child[1], n.child[2]
+	}
+	intType := reflect.TypeOf(0)
+	x := c.expr(o, nil, pending)
+	xtmp := c.newReg(c.fn.types[x])
+	c.mov(xtmp, x)
+	count := xtmp
+	switch c.fn.class[x] {
+	case vmInt, vmUint:
+	case vmSlice:
+		count = c.newReg(intType)
+		c.emit(opLenV, count, xtmp, 0)
+	default:
+		c.fail(o)
+	}
+	i := c.newReg(c.fn.types[count])
+	c.mov(i, c.constReg(n, reflect.ValueOf(0), c.fn.types[count]))
+	one := c.constReg(n, reflect.ValueOf(1), c.fn.types[count])
+	test := c.newReg(reflect.TypeOf(false))
+
+	loop := &vmLoopCtx{}
+	c.loops = append(c.loops, loop)
+	top := c.pc()
+	if c.fn.class[count] == vmUint {
+		c.emit(opLtU, test, i, count)
+	} else {
+		c.emit(opLtI, test, i, count)
+	}
+	exit := c.emit(opJmpF, 0, test, 0)
+	if k.ident != "_" {
+		c.mov(c.local(k), i)
+	}
+	if v != nil && v.ident != "_" {
+		c.emit(vmIndexOp(c.fn.class[c.local(v)]), c.local(v), xtmp, i)
+	}
+	c.stmt(body, pending)
+	cont := c.pc()
+	c.emit(opAddI, i, i, one)
+	c.emit(opLoop, top, 0, 0)
+	c.endLoop(loop, exit, cont)
+}
+
+func vmIndexOp(class vmClass) vmOp {
+	return map[vmClass]vmOp{vmInt: opIndexI, vmUint: opIndexU, vmBool: opIndexB, vmFloat: opIndexF, vmString: opIndexS2}[class]
+}
+
+func vmSetIndexOp(class vmClass) vmOp {
+	return map[vmClass]vmOp{vmInt: opSetIndexI, vmUint: opSetIndexU, vmBool: opSetIndexB, vmFloat: opSetIndexF, vmString: opSetIndexS}[class]
+}
+
+// lhsType returns the type of an assignable expression.
+func (c *vmCompiler) lhsType(n *node) reflect.Type {
+	if n.kind == indexExpr {
+		return n.child[0].typ.TypeOf().Elem()
+	}
+	return c.fn.types[c.local(n)]
+}
+
+// load returns a register containing the value of an assignable expression.
+func (c *vmCompiler) load(n *node, pending map[*node]*vmFunc) int {
+	if n.kind == indexExpr {
+		return c.expr(n, nil, pending)
+	}
+	return c.local(n)
+}
+
+// store emits the assignment of register r to an assignable expression.
+func (c *vmCompiler) store(n *node, r int, pending map[*node]*vmFunc) {
+	switch {
+	case n.kind == identExpr && n.ident == "_":
+	case n.kind == indexExpr:
+		s := c.expr(n.child[0], nil, pending)
+		if c.fn.class[s] != vmSlice {
+			c.fail(n)
+		}
+		i := c.expr(n.child[1], reflect.TypeOf(0), pending)
+		op := vmSetIndexOp(vmClassOf(c.fn.types[s].Elem()))
+		c.emit(op, s, i, r)
+	default:
+		c.mov(c.local(n), r)
+	}
+}
+
+// opAssign lowers an assignment combined with an operation, i.e. a += b.
+func (c *vmCompiler) opAssign(n *node, pending map[*node]*vmFunc) {
+	if n.nleft != 1 || n.nright != 1 {
+		c.fail(n)
+	}
+	l := n.child[0]
+	x := c.load(l, pending)
+	t := c.fn.types[x]
+	var y int
+	if n.action == aShlAssign || n.action == aShrAssign {
+		y = c.expr(n.child[1], reflect.TypeOf(uint(0)), pending)
+	} else {
+		y = c.expr(n.child[1], t, pending)
+	}
+	res := c.newReg(t)
+	c.binop(n, n.action-1, res, x, y)
+	c.store(l, res, pending)
+}
+
+// binop emits a binary operation a = b op d, with operands of class of b.
+func (c *vmCompiler) binop(n *node, act action, a, b, d int) {
+	class := c.fn.class[b]
+	var op vmOp
+	switch class {
+	case vmInt, vmUint:
+		signed := class == vmInt
+		switch act {
+		case aAdd:
+			op = opAddI
+		case aSub:
+			op = opSubI
+		case aMul:
+			op = opMulI
+		case aQuo:
+			op = map[bool]vmOp{true: opQuoI, false: opQuoU}[signed]
+		case aRem:
+			op = map[bool]vmOp{true: opRemI, false: opRemU}[signed]
+		case aAnd:
+			op = opAndI
+		case aOr:
+			op = opOrI
+		case aXor:
+			op = opXorI
+		case aAndNot:
+			op = opAndNotI
+		case aShl:
+			op = opShlI
+		case aShr:
+			op = map[bool]vmOp{true: opShrI, false: opShrU}[signed]
+		case aEqual:
+			op = opEqI
+		case aNotEqual:
+			op = opNeI
+		case aLower:
+			op = map[bool]vmOp{true: opLtI, false: opLtU}[signed]
+		case aLowerEqual:
+			op = map[bool]vmOp{true: opLeI, false: opLeU}[signed]
+		case aGreater:
+			op = map[bool]vmOp{true: opGtI, false: opGtU}[signed]
+		case aGreaterEqual:
+			op = map[bool]vmOp{true: opGeI, false: opGeU}[signed]
+		}
+	case vmBool:
+		switch act {
+		case aEqual:
+			op = opEqI
+		case aNotEqual:
+			op = opNeI
+		}
+	case vmFloat:
+		switch act {
+		case aAdd:
+			op = opAddF
+		case aSub:
+			op = opSubF
+		case aMul:
+			op = opMulF
+		case aQuo:
+			op = opQuoF
+		case aEqual:
+			op = opEqF
+		case aNotEqual:
+			op = opNeF
+		case aLower:
+			op = opLtF
+		case aLowerEqual:
+			op = opLeF
+		case aGreater:
+			op = opGtF
+		case aGreaterEqual:
+			op = opGeF
+		}
+	case vmString:
+		switch act {
+		case aAdd:
+			op = opAddS
+		case aEqual:
+			op = opEqS
+		case aNotEqual:
+			op = opNeS
+		case aLower:
+			op = opLtS
+		case aLowerEqual:
+			op = opLeS
+		case aGreater:
+			op = opGtS
+		case aGreaterEqual:
+			op = opGeS
+		}
+	}
+	if op == opNop {
+		c.fail(n)
+	}
+	c.emit(op, a, b, d)
+	c.wrap(a)
+}
+
+// expr lowers the expression n and returns the register holding its value.
+// If want is not nil, constant values are materialized with this type.
+func (c *vmCompiler) expr(n *node, want reflect.Type, pending map[*node]*vmFunc) int {
+	if n.rval.IsValid() && n.kind != funcDecl {
+		t := want
+		if t == nil {
+			t = n.typ.TypeOf()
+		}
+		return c.constReg(n, n.rval, t)
+	}
+	switch n.kind {
+	case parenExpr:
+		// Leading children are the arguments of an inlined call, see inlineCall.
+		for _, a := range n.child[:len(n.child)-1] {
+			r := c.expr(a, nil, pending)
+			if a.kind == identExpr || a.rval.IsValid() {
+				continue
+			}
+			if a.level != 0 || a.findex < 0 || a.findex >= len(c.def.types) {
+				c.fail(a)
+			}
+			c.mov(a.findex, r)
+		}
+		return c.expr(n.lastChild(), want, pending)
+
+	case identExpr:
+		return c.local(n)
+
+	case binaryExpr:
+		return c.binary(n, pending)
+
+	case landExpr, lorExpr:
+		bt := reflect.TypeOf(false)
+		res := c.newReg(bt)
+		c.mov(res, c.expr(n.child[0], bt, pending))
+		op := opJmpF
+		if n.kind == lorExpr {
+			op = opJmpT
+		}
+		j := c.emit(op, 0, res, 0)
+		c.mov(res, c.expr(n.child[1], bt, pending))
+		c.patch(j, c.pc())
+		return res
+
+	case unaryExpr:
+		t := n.typ.TypeOf()
+		x := c.expr(n.child[0], t, pending)
+		res := c.newReg(t)
+		switch {
+		case n.action == aPos:
+			return x
+		case n.action == aNot && c.fn.class[x] == vmBool:
+			c.emit(opNotB, res, x, 0)
+		case n.action == aNeg && c.fn.class[x] == vmFloat:
+			c.emit(opNegF, res, x, 0)
+		case n.action == aNeg && (c.fn.class[x] == vmInt || c.fn.class[x] == vmUint):
+			c.emit(opNegI, res, x, 0)
+		case n.action == aBitNot && (c.fn.class[x] == vmInt || c.fn.class[x] == vmUint):
+			c.emit(opBitNotI, res, x, 0)
+		default:
+			c.fail(n)
+		}
+		c.wrap(res)
+		return res
+
+	case indexExpr:
+		x := c.expr(n.child[0], nil, pending)
+		i := c.expr(n.child[1], reflect.TypeOf(0), pending)
+		switch c.fn.class[x] {
+		case vmString:
+			res := c.newReg(reflect.TypeOf(byte(0)))
+			c.emit(opIndexS, res, x, i)
+			return res
+		case vmSlice:
+			et := c.fn.types[x].Elem()
+			res := c.newReg(et)
+			c.emit(vmIndexOp(vmClassOf(et)), res, x, i)
+			return res
+		}
+
+	case callExpr:
+		rets := c.call(n, pending)
+		if len(rets) != 1 {
+			c.fail(n)
+		}
+		return rets[0]
+	}
+	c.fail(n)
+	return 0
+}
+
+// binary lowers a binary expression.
+func (c *vmCompiler) binary(n *node, pending map[*node]*vmFunc) int {
+	c0, c1 := n.child[0], n.child[1]
+	var t reflect.Type
+	switch n.action {
+	case aEqual, aNotEqual, aLower, aLowerEqual, aGreater, aGreaterEqual:
+		// Operand type is the one of the non constant operand.
+		if c0.rval.IsValid() {
+			t = c1.typ.TypeOf()
+		} else {
+			t = c0.typ.TypeOf()
+		}
+	default:
+		t = n.typ.TypeOf()
+	}
+	x := c.expr(c0, t, pending)
+	var y int
+	if n.action == aShl || n.action == aShr {
+		y = c.expr(c1, reflect.TypeOf(uint(0)), pending)
+	} else {
+		y = c.expr(c1, t, pending)
+	}
+	if c.fn.class[x] != c.fn.class[y] && n.action != aShl && n.action != aShr {
+		c.fail(n)
+	}
+	res := c.newReg(n.typ.TypeOf())
+	c.binop(n, n.action, res, x, y)
+	return res
+}
+
+// call lowers a call expression to a bytecode function, a builtin or a type
+// conversion, and returns the registers holding the results.
+func (c *vmCompiler) call(n *node, pending map[*node]*vmFunc) []int {
+	if n.kind != callExpr || n.action == aCallSlice {
+		c.fail(n)
+	}
+	c0 := n.child[0]
+	args := n.child[1:]
+
+	if n.action == aConvert {
+		if len(args) != 1 {
+			c.fail(n)
+		}
+		return []int{c.convert(n, args[0], pending)}
+	}
+
+	if c0.kind == identExpr && c0.sym != nil && c0.sym.kind == bltnSym {
+		return []int{c.builtin(n, pending)}
+	}
+
+	def, ok := c0.val.(*node)
+	if c0.kind != identExpr || !ok || def.kind != funcDecl || c0.sym == nil || c0.sym.kind != funcSym {
+		c.fail(n)
+	}
+	callee := compileVMFunc(def, pending)
+	if callee == nil {
+		c.fail(n)
+	}
+	c.fn.callee = append(c.fn.callee, def)
+	call := vmCall{fn: callee}
+	for i, a := range args {
+		call.args = append(call.args, int32(c.expr(a, def.typ.arg[i].TypeOf(), pending)))
+	}
+	regs := make([]int, len(def.typ.ret))
+	for i, t := range def.typ.ret {
+		regs[i] = c.newReg(t.TypeOf())
+		call.rets = append(call.rets, int32(regs[i]))
+	}
+	c.fn.calls = append(c.fn.calls, call)
+	c.emit(opCall, len(c.fn.calls)-1, 0, 0)
+	return regs
+}
+
+// convert lowers the conversion of x to the type of n.
+func (c *vmCompiler) convert(n, x *node, pending map[*node]*vmFunc) int {
+	t := n.typ.TypeOf()
+	if x.rval.IsValid() {
+		return c.expr(x, t, pending)
+	}
+	src := c.expr(x, nil, pending)
+	res := c.newReg(t)
+	from, to := c.fn.class[src], c.fn.class[res]
+	switch {
+	case from == to && to != vmSlice:
+		c.mov(res, src)
+	case (from == vmInt || from == vmUint) && to == vmFloat:
+		c.emit(map[bool]vmOp{true: opIToF, false: opUToF}[from == vmInt], res, src, 0)
+	case from == vmFloat && (to == vmInt || to == vmUint):
+		c.emit(map[bool]vmOp{true: opFToI, false: opFToU}[to == vmInt], res, src, 0)
+	case (from == vmInt || from == vmUint) && (to == vmInt || to == vmUint):
+		c.emit(opMovI, res, src, 0)
+	default:
+		c.fail(n)
+	}
+	c.wrap(res)
+	return res
+}
+
+// builtin lowers a call to a builtin function.
+func (c *vmCompiler) builtin(n *node, pending map[*node]*vmFunc) int {
+	args := n.child[1:]
+	intType := reflect.TypeOf(0)
+	switch n.child[0].ident {
+	case bltnLen, bltnCap:
+		if len(args) != 1 {
+			c.fail(n)
+		}
+		x := c.expr(args[0], nil, pending)
+		res := c.newReg(intType)
+		switch {
+		case c.fn.class[x] == vmString && n.child[0].ident == bltnLen:
+			c.emit(opLenS, res, x, 0)
+		case c.fn.class[x] == vmSlice && n.child[0].ident == bltnLen:
+			c.emit(opLenV, res, x, 0)
+		case c.fn.class[x] == vmSlice:
+			c.emit(opCapV, res, x, 0)
+		default:
+			c.fail(n)
+		}
+		return res
+
+	case bltnMake:
+		t := n.typ.TypeOf()
+		if vmClassOf(t) != vmSlice || len(args) < 2 || len(args) > 3 {
+			c.fail(n)
+		}
+		l := c.expr(args[1], intType, pending)
+		cp := l
+		if len(args) == 3 {
+			cp = c.expr(args[2], intType, pending)
+		}
+		res := c.newReg(t)
+		c.emit(opMake, res, l, cp)
+		return res
+
+	case bltnAppend:
+		if len(args) != 2 {
+			c.fail(n)
+		}
+		s := c.expr(args[0], nil, pending)
+		if c.fn.class[s] != vmSlice {
+			c.fail(n)
+		}
+		e := c.expr(args[1], c.fn.types[s].Elem(), pending)
+		res := c.newReg(c.fn.types[s])
+		c.emit(opAppend, res, s, e)
+		return res
+	}
+	c.fail(n)
+	return 0
+}
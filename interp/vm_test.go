@@ -0,0 +1,204 @@
+package interp_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/traefik/yaegi/interp"
+	"github.com/traefik/yaegi/stdlib"
+)
+
+const vmSrc = `package main
+
+func fib(n int) int {
+	if n < 2 {
+		return n
+	}
+	return fib(n-1) + fib(n-2)
+}
+
+func sieve(n int) int {
+	composite := make([]bool, n+1)
+	count := 0
+	for i := 2; i <= n; i++ {
+		if composite[i] {
+			continue
+		}
+		count++
+		for j := i * i; j <= n; j += i {
+			composite[j] = true
+		}
+	}
+	return count
+}
+
+func wrap(a int8, b uint8) (int8, uint8) {
+	a += 100
+	b -= 10
+	return a * 2, b << 1
+}
+
+func divmod(a, b uint32) (q, r uint32) {
+	q, r = a/b, a%b
+	return
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func join(words []string, sep string) string {
+	s := ""
+	for i, w := range words {
+		if i > 0 {
+			s += sep
+		}
+		s += w
+	}
+	return s
+}
+
+func squares(n int) []int {
+	var r []int
+	for i := range n {
+		if i > 5 {
+			break
+		}
+		r = append(r, i*i)
+	}
+	return r
+}
+
+func pairs(xs []int) (n int) {
+	for _, a := range xs {
+		for _, b := range xs {
+			if a < b {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func count(s string, c byte) (n int) {
+	for i := 0; i < len(s); i++ {
+		if s[i] == c && !(i == 0 || s[i-1] == c) {
+			n++
+		}
+	}
+	return n
+}
+`
+
+// vmFallbackSrc holds functions which can not be lowered to bytecode.
+const vmFallbackSrc = `package main
+
+import "strconv"
+
+func total(m map[string]int) (n int) {
+	for _, v := range m {
+		n += v
+	}
+	return n
+}
+
+func sum(xs ...int) (n int) {
+	for _, x := range xs {
+		n += x
+	}
+	return n
+}
+
+func itoa(n int) string { return strconv.Itoa(n) }
+
+func twice(n int) string { return itoa(n) + itoa(n) }
+
+func adder(n int) int {
+	f := func(x int) int { return x + n }
+	return f(1)
+}
+`
+
+func TestBytecode(t *testing.T) {
+	tests := []struct {
+		src, fn, res string
+		lowered      bool
+	}{
+		{src: "fib(20)", fn: "fib", res: "6765", lowered: true},
+		{src: "sieve(1000)", fn: "sieve", res: "168", lowered: true},
+		{src: "fmt.Sprint(wrap(100, 5))", fn: "wrap", res: "-112 246", lowered: true},
+		{src: "fmt.Sprint(divmod(100, 7))", fn: "divmod", res: "14 2", lowered: true},
+		{src: "mean([]float64{1, 2, 3, 4})", fn: "mean", res: "2.5", lowered: true},
+		{src: `join([]string{"a", "b", "c"}, ", ")`, fn: "join", res: "a, b, c", lowered: true},
+		{src: "fmt.Sprint(squares(10))", fn: "squares", res: "[0 1 4 9 16 25]", lowered: true},
+		{src: "pairs([]int{1, 2, 3})", fn: "pairs", res: "3", lowered: true},
+		{src: `count("aa b aab bb", 'b')`, fn: "count", res: "3", lowered: true},
+
+		// Functions falling back to the closure execution.
+		{src: `total(map[string]int{"a": 1, "b": 2})`, fn: "total", res: "3"},
+		{src: "sum(1, 2, 3)", fn: "sum", res: "6"},
+		{src: "itoa(42)", fn: "itoa", res: "42"},
+		{src: "twice(4)", fn: "twice", res: "44"},
+		{src: "adder(2)", fn: "adder", res: "3"},
+	}
+
+	for _, bytecode := range []bool{false, true} {
+		i := interp.New(interp.Options{Bytecode: bytecode})
+		if err := i.Use(stdlib.Symbols); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := i.Eval(vmSrc); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := i.Eval(vmFallbackSrc); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := i.Eval(`import "fmt"`); err != nil {
+			t.Fatal(err)
+		}
+		for _, test := range tests {
+			res, err := i.Eval(test.src)
+			if err != nil {
+				t.Fatalf("bytecode %v: %s: %v", bytecode, test.src, err)
+			}
+			if s := fmt.Sprint(res); s != test.res {
+				t.Errorf("bytecode %v: %s: got %s, want %s", bytecode, test.src, s, test.res)
+			}
+			if lowered := i.Lowered(test.fn); lowered != (bytecode && test.lowered) {
+				t.Errorf("bytecode %v: %s: got lowered %v", bytecode, test.fn, lowered)
+			}
+		}
+	}
+}
+
+func benchmarkBytecode(b *testing.B, src string) {
+	for _, mode := range []struct {
+		name     string
+		bytecode bool
+	}{{"closure", false}, {"bytecode", true}} {
+		b.Run(mode.name, func(b *testing.B) {
+			i := interp.New(interp.Options{Bytecode: mode.bytecode})
+			if _, err := i.Eval(vmSrc); err != nil {
+				b.Fatal(err)
+			}
+			prog, err := i.Compile(src)
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				if _, err := i.Execute(prog); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkBytecodeFib(b *testing.B) { benchmarkBytecode(b, "fib(20)") }
+
+func BenchmarkBytecodeSieve(b *testing.B) { benchmarkBytecode(b, "sieve(100000)") }
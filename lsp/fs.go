@@ -0,0 +1,79 @@
+package lsp
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// overlay is a file system reading from the OS, except for the documents
+// open in the editor, whose content may not be saved yet.
+type overlay map[string]string // document contents, indexed by absolute path
+
+func (o overlay) content(name string) (string, bool) {
+	if len(o) == 0 {
+		return "", false
+	}
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return "", false
+	}
+	s, ok := o[abs]
+	return s, ok
+}
+
+// Open complies with the fs.FS interface.
+func (o overlay) Open(name string) (fs.File, error) {
+	if s, ok := o.content(name); ok {
+		return &docFile{Reader: bytes.NewReader([]byte(s)), info: docInfo{name: filepath.Base(name), size: int64(len(s))}}, nil
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// ReadFile complies with the fs.ReadFileFS interface.
+func (o overlay) ReadFile(name string) ([]byte, error) {
+	if s, ok := o.content(name); ok {
+		return []byte(s), nil
+	}
+	return os.ReadFile(name)
+}
+
+// Stat complies with the fs.StatFS interface.
+func (o overlay) Stat(name string) (fs.FileInfo, error) {
+	if s, ok := o.content(name); ok {
+		return docInfo{name: filepath.Base(name), size: int64(len(s))}, nil
+	}
+	return os.Stat(name)
+}
+
+// ReadDir complies with the fs.ReadDirFS interface.
+func (o overlay) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+// docFile is an open document.
+type docFile struct {
+	*bytes.Reader
+	info docInfo
+}
+
+func (f *docFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *docFile) Close() error               { return nil }
+
+type docInfo struct {
+	name string
+	size int64
+}
+
+func (i docInfo) Name() string       { return i.name }
+func (i docInfo) Size() int64        { return i.size }
+func (i docInfo) Mode() fs.FileMode  { return 0o444 }
+func (i docInfo) ModTime() time.Time { return time.Time{} }
+func (i docInfo) IsDir() bool        { return false }
+func (i docInfo) Sys() interface{}   { return nil }
@@ -0,0 +1,542 @@
+/*
+Package lsp implements a Language Server Protocol server for Go code
+interpreted by yaegi, including the scripts in REPL mode, starting with "#!".
+
+The code is analyzed by the interpreter itself, so the symbols of the binary
+packages made available to the scripts by the host application are known to
+the server, contrary to the standard Go tools. An application embedding
+yaegi can provide its own server, for example:
+
+	s := &lsp.Server{Exports: []interp.Exports{stdlib.Symbols, app.Symbols}}
+	err := s.Serve(os.Stdin, os.Stdout)
+
+The server provides diagnostics, hover, go to definition and completion.
+Documents are synchronized in full, and each request triggers a compilation
+in a fresh interpreter, as by Interpreter.Check.
+*/
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/token"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/traefik/yaegi/interp"
+)
+
+// Server is a Language Server Protocol server. Its fields must be set
+// before calling Serve.
+type Server struct {
+	// Options are the options of the interpreters analyzing the code.
+	// The source code filesystem is the OS one, overlaid with the documents
+	// open in the editor.
+	Options interp.Options
+
+	// Exports are the binary symbols available to the code, as given to
+	// Interpreter.Use.
+	Exports []interp.Exports
+
+	docs overlay
+	out  io.Writer
+}
+
+// Serve reads requests from in and writes responses to out, until the
+// client exits or in is closed.
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	s.docs, s.out = overlay{}, out
+	r := bufio.NewReader(in)
+	for {
+		body, err := readMessage(r)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			return err
+		}
+		if req.Method == "exit" {
+			return nil
+		}
+
+		res, rerr := s.handle(req)
+		switch {
+		case req.ID == nil:
+			// Notifications have no response.
+		case rerr != nil:
+			err = s.write(errorResponse{JSONRPC: "2.0", ID: req.ID, Error: *rerr})
+		default:
+			err = s.write(response{JSONRPC: "2.0", ID: req.ID, Result: res})
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(req request) (interface{}, *responseError) {
+	var err error
+	switch req.Method {
+	case "initialize":
+		return map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync":   1, // Full.
+				"hoverProvider":      true,
+				"definitionProvider": true,
+				"completionProvider": map[string]interface{}{"triggerCharacters": []string{"."}},
+			},
+			"serverInfo": map[string]string{"name": "yaegi"},
+		}, nil
+
+	case "shutdown":
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var p didOpenParams
+		if err = json.Unmarshal(req.Params, &p); err == nil {
+			err = s.update(p.TextDocument.URI, &p.TextDocument.Text)
+		}
+
+	case "textDocument/didChange":
+		var p didChangeParams
+		if err = json.Unmarshal(req.Params, &p); err == nil && len(p.ContentChanges) > 0 {
+			err = s.update(p.TextDocument.URI, &p.ContentChanges[len(p.ContentChanges)-1].Text)
+		}
+
+	case "textDocument/didSave":
+		var p didCloseParams
+		if err = json.Unmarshal(req.Params, &p); err == nil {
+			var path string
+			if path, err = uriPath(p.TextDocument.URI); err == nil {
+				err = s.publish(path)
+			}
+		}
+
+	case "textDocument/didClose":
+		var p didCloseParams
+		if err = json.Unmarshal(req.Params, &p); err == nil {
+			err = s.update(p.TextDocument.URI, nil)
+		}
+
+	case "textDocument/hover", "textDocument/definition", "textDocument/completion":
+		var p positionParams
+		if err = json.Unmarshal(req.Params, &p); err != nil {
+			break
+		}
+		var res interface{}
+		switch req.Method {
+		case "textDocument/hover":
+			res, err = s.hover(p)
+		case "textDocument/definition":
+			res, err = s.definition(p)
+		default:
+			res, err = s.completion(p)
+		}
+		if err == nil {
+			return res, nil
+		}
+
+	default:
+		if req.ID != nil {
+			return nil, &responseError{Code: codeMethodNotFound, Message: "method not supported: " + req.Method}
+		}
+	}
+
+	if err != nil {
+		return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil, nil
+}
+
+// update sets the content of the document at uri, or removes it if text is
+// nil, and publishes the resulting diagnostics.
+func (s *Server) update(uri string, text *string) error {
+	path, err := uriPath(uri)
+	if err != nil {
+		return err
+	}
+	if text == nil {
+		delete(s.docs, path)
+		// Diagnostics are cleared with the document.
+		return s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: uri, Diagnostics: []diagnostic{}})
+	}
+	s.docs[path] = *text
+	return s.publish(path)
+}
+
+// analysis is the result of the compilation of a document.
+type analysis struct {
+	interp *interp.Interpreter
+	name   string // file name of the document in the interpreter
+	pkg    bool   // the whole package of the document is compiled
+	err    error  // result of Check
+}
+
+// analyze compiles the document at path, of content src, with its package
+// unless it is a script or a test file, in a fresh interpreter.
+func (s *Server) analyze(path, src string) (*analysis, error) {
+	docs := overlay{}
+	for k, v := range s.docs {
+		docs[k] = v
+	}
+	docs[path] = src
+
+	opt := s.Options
+	opt.SourcecodeFilesystem = docs
+	opt.Stdin, opt.Stdout, opt.Stderr = strings.NewReader(""), io.Discard, io.Discard
+	i := interp.New(opt)
+	for _, e := range s.Exports {
+		if err := i.Use(e); err != nil {
+			return nil, err
+		}
+	}
+
+	a := &analysis{interp: i, name: path}
+	arg := path
+	if strings.HasPrefix(src, "#!") {
+		// Scripts are interpreted in REPL mode, with auto imports.
+		i.ImportUsed()
+	} else if !strings.HasSuffix(path, "_test.go") && hasSiblings(path) {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, filepath.Dir(path)); err == nil {
+				// Packages are compiled from their relative import path.
+				a.name, a.pkg = filepath.Join(rel, filepath.Base(path)), true
+				switch arg = filepath.ToSlash(rel) + "/"; {
+				case rel == ".":
+					arg = "./"
+				case !strings.HasPrefix(rel, ".."):
+					arg = "./" + arg
+				}
+			}
+		}
+	}
+	a.err = i.Check(arg)
+	return a, nil
+}
+
+// hasSiblings returns true if the directory of the Go file at path contains
+// other Go files, excluding tests.
+func hasSiblings(path string) bool {
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return false
+	}
+	base := filepath.Base(path)
+	for _, e := range entries {
+		name := e.Name()
+		if name != base && !e.IsDir() && strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go") {
+			return true
+		}
+	}
+	return false
+}
+
+// publish sends the diagnostics of the document at path, and of the other
+// open documents of its package.
+func (s *Server) publish(path string) error {
+	a, err := s.analyze(path, s.text(path))
+	if err != nil {
+		return err
+	}
+	diags := map[string][]diagnostic{path: {}}
+	if a.pkg {
+		for p := range s.docs {
+			if filepath.Dir(p) == filepath.Dir(path) && !strings.HasPrefix(s.docs[p], "#!") && !strings.HasSuffix(p, "_test.go") {
+				diags[p] = []diagnostic{}
+			}
+		}
+	}
+
+	var list interp.Diagnostics
+	if !errors.As(a.err, &list) && a.err != nil {
+		list = interp.Diagnostics{{Msg: a.err.Error()}}
+	}
+	for _, e := range list {
+		p, err := filepath.Abs(e.Pos.Filename)
+		if _, ok := diags[p]; !ok || err != nil || !e.Pos.IsValid() {
+			// Errors located elsewhere, as in imported packages, are
+			// reported at the start of the document.
+			diags[path] = append(diags[path], diagnostic{Severity: severityError, Code: e.Code.String(), Source: "yaegi", Message: e.Error()})
+			continue
+		}
+		r := s.lspRange(p, e.Pos, e.End)
+		diags[p] = append(diags[p], diagnostic{Range: r, Severity: severityError, Code: e.Code.String(), Source: "yaegi", Message: e.Msg})
+	}
+
+	for p, d := range diags {
+		if err := s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: fileURI(p), Diagnostics: d}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookup compiles the document of p, possibly modified by edit, and returns
+// the analysis and the position of p in the interpreter.
+func (s *Server) lookup(p positionParams, edit func(src string, offset int) string) (*analysis, token.Position, error) {
+	path, err := uriPath(p.TextDocument.URI)
+	if err != nil {
+		return nil, token.Position{}, err
+	}
+	src := s.text(path)
+	col := column(src, p.Position)
+	if edit != nil {
+		src = edit(src, lineOffset(src, p.Position.Line)+col-1)
+	}
+	a, err := s.analyze(path, src)
+	if err != nil {
+		return nil, token.Position{}, err
+	}
+	return a, token.Position{Filename: a.name, Line: p.Position.Line + 1, Column: col}, nil
+}
+
+func (s *Server) hover(p positionParams) (interface{}, error) {
+	a, pos, err := s.lookup(p, nil)
+	if err != nil {
+		return nil, err
+	}
+	id := a.interp.IdentAt(pos)
+	if id == nil || id.Kind == "" {
+		return nil, nil
+	}
+	path, _ := uriPath(p.TextDocument.URI)
+	value := "```go\n" + signature(id) + "\n```"
+	if id.Decl.Doc != "" {
+		value += "\n\n" + id.Decl.Doc
+	}
+	return hover{
+		Contents: markupContent{Kind: "markdown", Value: value},
+		Range:    s.lspRange(path, id.Pos, id.End),
+	}, nil
+}
+
+func (s *Server) definition(p positionParams) (interface{}, error) {
+	a, pos, err := s.lookup(p, nil)
+	if err != nil {
+		return nil, err
+	}
+	id := a.interp.IdentAt(pos)
+	if id == nil || !id.Decl.Pos.IsValid() {
+		return nil, nil
+	}
+	path, err := filepath.Abs(id.Decl.Pos.Filename)
+	if err != nil {
+		return nil, err
+	}
+	end := id.Decl.Pos
+	end.Offset += len(id.Decl.Name)
+	end.Column += len(id.Decl.Name)
+	return []location{{URI: fileURI(path), Range: s.lspRange(path, id.Decl.Pos, end)}}, nil
+}
+
+func (s *Server) completion(p positionParams) (interface{}, error) {
+	// A placeholder identifier is inserted if none is being typed, so the
+	// code can be parsed, as after a selector dot.
+	a, pos, err := s.lookup(p, func(src string, offset int) string {
+		if offset > 0 && offset <= len(src) && isIdentByte(src[offset-1]) {
+			return src
+		}
+		return src[:offset] + "_" + src[offset:]
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := completionList{Items: []completionItem{}}
+	for _, c := range a.interp.Completions(pos) {
+		res.Items = append(res.Items, completionItem{Label: c.Name, Kind: completionKind(c.Kind), Detail: c.Type})
+	}
+	return res, nil
+}
+
+// signature returns the Go representation of the declaration of id.
+func signature(id *interp.IdentInfo) string {
+	switch id.Kind {
+	case "function", "method":
+		return "func " + id.Name + strings.TrimPrefix(id.Type, "func")
+	case "variable":
+		return "var " + id.Name + " " + id.Type
+	case "constant":
+		return "const " + id.Name + " " + id.Type
+	case "type":
+		return "type " + id.Name + " " + id.Type
+	case "package":
+		return "package " + id.Name + " (" + strconv.Quote(id.Type) + ")"
+	case "field":
+		return "field " + id.Name + " " + id.Type
+	}
+	return strings.TrimSpace(id.Name + " " + id.Type)
+}
+
+func completionKind(kind string) int {
+	switch kind {
+	case "function", "builtin":
+		return completionFunction
+	case "method":
+		return completionMethod
+	case "variable", "value":
+		return completionVariable
+	case "constant":
+		return completionConstant
+	case "type":
+		return completionClass
+	case "package":
+		return completionModule
+	case "field":
+		return completionField
+	}
+	return 0
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+// text returns the content of the document at path, or of the file if the
+// document is not open.
+func (s *Server) text(path string) string {
+	if t, ok := s.docs[path]; ok {
+		return t
+	}
+	b, _ := os.ReadFile(path)
+	return string(b)
+}
+
+// lspRange returns the range of the source from start to end in the file
+// at path.
+func (s *Server) lspRange(path string, start, end token.Position) rangeLSP {
+	src := s.text(path)
+	r := rangeLSP{Start: lspPosition(src, start)}
+	r.End = r.Start
+	if end.IsValid() && end.Offset > start.Offset {
+		r.End = lspPosition(src, end)
+	}
+	return r
+}
+
+// lspPosition converts the source position pos, in bytes, into a position
+// in UTF-16 code units in src.
+func lspPosition(src string, pos token.Position) position {
+	if pos.Line < 1 {
+		return position{}
+	}
+	l, col := pos.Line-1, pos.Column-1
+	if n := strings.Count(src, "\n"); l > n {
+		// Past the end, as the closing brace added to scripts.
+		l, col = n, len(src)
+	}
+	line := lineText(src, l)
+	if col > len(line) {
+		col = len(line)
+	}
+	n := 0
+	for _, r := range line[:col] {
+		n += utf16Len(r)
+	}
+	return position{Line: l, Character: n}
+}
+
+// column returns the column in bytes, starting at 1, of the LSP position p.
+func column(src string, p position) int {
+	line := lineText(src, p.Line)
+	n := 0
+	for i, r := range line {
+		if n >= p.Character {
+			return i + 1
+		}
+		n += utf16Len(r)
+	}
+	return len(line) + 1
+}
+
+func utf16Len(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
+
+// lineOffset returns the byte offset of the line of index l in src.
+func lineOffset(src string, l int) int {
+	off := 0
+	for ; l > 0; l-- {
+		i := strings.IndexByte(src[off:], '\n')
+		if i < 0 {
+			return len(src)
+		}
+		off += i + 1
+	}
+	return off
+}
+
+// lineText returns the line of index l in src, without newline.
+func lineText(src string, l int) string {
+	line := src[lineOffset(src, l):]
+	if i := strings.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	return strings.TrimSuffix(line, "\r")
+}
+
+func uriPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported URI: %s", uri)
+	}
+	return filepath.FromSlash(u.Path), nil
+}
+
+func fileURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+// readMessage returns the content of the next message read from r.
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	length := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if k, v, ok := strings.Cut(line, ":"); ok && strings.EqualFold(k, "Content-Length") {
+			if length, err = strconv.Atoi(strings.TrimSpace(v)); err != nil {
+				return nil, fmt.Errorf("invalid header: %s", line)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, errors.New("missing Content-Length header")
+	}
+	body := make([]byte, length)
+	_, err := io.ReadFull(r, body)
+	return body, err
+}
+
+func (s *Server) notify(method string, params interface{}) error {
+	return s.write(notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func (s *Server) write(msg interface{}) error {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(b), b)
+	return err
+}
@@ -0,0 +1,153 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/traefik/yaegi/interp"
+)
+
+// session runs a server on the given requests, and returns the messages it
+// has written.
+func session(t *testing.T, s *Server, reqs ...interface{}) []map[string]interface{} {
+	t.Helper()
+	var in, out bytes.Buffer
+	for _, r := range reqs {
+		b, err := json.Marshal(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(b), b)
+	}
+	if err := s.Serve(&in, &out); err != nil {
+		t.Fatal(err)
+	}
+	var msgs []map[string]interface{}
+	r := bufio.NewReader(&out)
+	for {
+		body, err := readMessage(r)
+		if err != nil {
+			break
+		}
+		var m map[string]interface{}
+		if err := json.Unmarshal(body, &m); err != nil {
+			t.Fatal(err)
+		}
+		msgs = append(msgs, m)
+	}
+	return msgs
+}
+
+func req(id int, method string, params interface{}) map[string]interface{} {
+	m := map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params}
+	if id > 0 {
+		m["id"] = id
+	}
+	return m
+}
+
+func at(uri string, line, char int) map[string]interface{} {
+	return map[string]interface{}{
+		"textDocument": map[string]string{"uri": uri},
+		"position":     map[string]int{"line": line, "character": char},
+	}
+}
+
+// result returns the JSON representation of the result of request id.
+func result(t *testing.T, msgs []map[string]interface{}, id int) string {
+	t.Helper()
+	for _, m := range msgs {
+		if v, ok := m["id"]; ok && v == float64(id) {
+			if e, ok := m["error"]; ok {
+				t.Fatalf("request %d: %v", id, e)
+			}
+			b, _ := json.Marshal(m["result"])
+			return string(b)
+		}
+	}
+	t.Fatalf("no response to request %d", id)
+	return ""
+}
+
+func TestServer(t *testing.T) {
+	dir := t.TempDir()
+	pkgDir := filepath.Join(dir, "pkg")
+	if err := os.Mkdir(pkgDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	script := filepath.Join(dir, "script.go")
+	files := map[string]string{
+		script:                        "#!/usr/bin/env yaegi\nmsg := app.Greet(\"bob\")\nprintln(msg, undefinedX)\n",
+		filepath.Join(pkgDir, "a.go"): "package main\n\nfunc main() { println(helper()) }\n",
+		filepath.Join(pkgDir, "b.go"): "package main\n\n// helper returns a number.\nfunc helper() int { return 1 }\n",
+	}
+	for name, src := range files {
+		if err := os.WriteFile(name, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	scriptURI, aURI := fileURI(script), fileURI(filepath.Join(pkgDir, "a.go"))
+
+	s := &Server{Exports: []interp.Exports{{
+		"guthib.com/app/app": {"Greet": reflect.ValueOf(func(s string) string { return "hello " + s })},
+	}}}
+	msgs := session(t, s,
+		req(1, "initialize", map[string]interface{}{}),
+		req(0, "textDocument/didOpen", map[string]interface{}{"textDocument": map[string]string{"uri": scriptURI, "text": files[script]}}),
+		req(2, "textDocument/hover", at(scriptURI, 1, 12)),
+		// Completion of a selector, before the code is complete.
+		req(0, "textDocument/didChange", map[string]interface{}{
+			"textDocument":   map[string]string{"uri": scriptURI},
+			"contentChanges": []map[string]string{{"text": files[script] + "app.\n"}},
+		}),
+		req(3, "textDocument/completion", at(scriptURI, 3, 4)),
+		req(0, "textDocument/didOpen", map[string]interface{}{"textDocument": map[string]string{"uri": aURI, "text": files[filepath.Join(pkgDir, "a.go")]}}),
+		req(4, "textDocument/definition", at(aURI, 2, 24)),
+		req(5, "textDocument/hover", at(aURI, 2, 24)),
+		req(6, "shutdown", nil),
+		req(0, "exit", nil),
+	)
+
+	if got := result(t, msgs, 1); !strings.Contains(got, `"hoverProvider":true`) {
+		t.Errorf("got capabilities %s", got)
+	}
+
+	var diags []string
+	for _, m := range msgs {
+		if m["method"] == "textDocument/publishDiagnostics" {
+			b, _ := json.Marshal(m["params"])
+			diags = append(diags, string(b))
+		}
+	}
+	wantDiags := []string{
+		`{"diagnostics":[{"code":"undefined","message":"undefined: undefinedX","range":{"end":{"character":23,"line":2},"start":{"character":13,"line":2}},"severity":1,"source":"yaegi"}],"uri":"` + scriptURI + `"}`,
+		`{"diagnostics":[{"code":"syntax","message":"expected selector or type assertion, found '}'","range":{"end":{"character":0,"line":4},"start":{"character":0,"line":4}},"severity":1,"source":"yaegi"}],"uri":"` + scriptURI + `"}`,
+		`{"diagnostics":[],"uri":"` + aURI + `"}`,
+	}
+	if strings.Join(diags, "\n") != strings.Join(wantDiags, "\n") {
+		t.Errorf("got diagnostics:\n%s\nwant:\n%s", strings.Join(diags, "\n"), strings.Join(wantDiags, "\n"))
+	}
+
+	tests := []struct {
+		id   int
+		want string
+	}{
+		{2, `{"contents":{"kind":"markdown","value":"` + "```go\\nfunc Greet(string) string\\n```" + `"},"range":{"end":{"character":16,"line":1},"start":{"character":11,"line":1}}}`},
+		{3, `{"isIncomplete":false,"items":[{"detail":"func(string) string","kind":3,"label":"Greet"}]}`},
+		{4, `[{"range":{"end":{"character":11,"line":3},"start":{"character":5,"line":3}},"uri":"` + fileURI(filepath.Join(pkgDir, "b.go")) + `"}]`},
+		{5, `{"contents":{"kind":"markdown","value":"` + "```go\\nfunc helper() int\\n```\\n\\nhelper returns a number.\\n" + `"},"range":{"end":{"character":28,"line":2},"start":{"character":22,"line":2}}}`},
+		{6, `null`},
+	}
+	for _, test := range tests {
+		if got := result(t, msgs, test.id); got != test.want {
+			t.Errorf("request %d: got %s, want %s", test.id, got, test.want)
+		}
+	}
+}